@@ -27,6 +27,10 @@ const (
 	DefaultContainerdVersion   = "1.6.4"
 	DefaultContainerdCRISocket = "unix:///var/run/containerd/containerd.sock"
 
+	CrioType             = "crio"
+	DefaultCrioVersion   = "1.29.1"
+	DefaultCrioCRISocket = "unix:///var/run/crio/crio.sock"
+
 	DefaultCrictlVersion = "v1.24.0"
 )
 
@@ -37,7 +41,7 @@ type ContainerManager struct {
 	CRISocket string `json:"criSocket,omitempty"`
 
 	// Type defines the type of ContainerManager.
-	// "docker", "containerd"
+	// "docker", "containerd", "crio"
 	Type string `json:"type,omitempty"`
 
 	// Version defines the version of ContainerManager.
@@ -70,6 +70,11 @@ func defaultContainerManager(spec *KKMachineSpec) {
 			spec.ContainerManager.CRIDockerdVersion = DefaultCRIDockerdVersion
 		}
 		spec.ContainerManager.CRISocket = DefaultDockerCRISocket
+	case CrioType:
+		if spec.ContainerManager.Version == "" {
+			spec.ContainerManager.Version = DefaultCrioVersion
+		}
+		spec.ContainerManager.CRISocket = DefaultCrioCRISocket
 	}
 
 	if spec.ContainerManager.CRICTLVersion == "" {
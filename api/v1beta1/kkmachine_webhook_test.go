@@ -58,4 +58,22 @@ func TestKKMachine_Default(t *testing.T) {
 	g.Expect(kkm2.Spec.ContainerManager.Type).To(Equal(ContainerdType))
 	g.Expect(kkm2.Spec.ContainerManager.Version).To(Equal("1.6.4"))
 	g.Expect(kkm2.Spec.ContainerManager.CRICTLVersion).To(Equal("v1.24.0"))
+
+	kkm3 := &KKMachine{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "foobar",
+		},
+		Spec: KKMachineSpec{
+			ContainerManager: ContainerManager{
+				Type: CrioType,
+			},
+		},
+	}
+
+	t.Run("for KKMachine3", utildefaulting.DefaultValidateTest(kkm3))
+	kkm3.Default()
+
+	g.Expect(kkm3.Spec.ContainerManager.Type).To(Equal(CrioType))
+	g.Expect(kkm3.Spec.ContainerManager.Version).To(Equal(DefaultCrioVersion))
+	g.Expect(kkm3.Spec.ContainerManager.CRISocket).To(Equal(DefaultCrioCRISocket))
 }
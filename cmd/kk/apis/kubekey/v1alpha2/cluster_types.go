@@ -34,6 +34,7 @@ import (
 // ClusterSpec defines the desired state of Cluster
 type ClusterSpec struct {
 	Hosts                []HostCfg            `yaml:"hosts" json:"hosts,omitempty"`
+	Bastions             []BastionCfg         `yaml:"bastions" json:"bastions,omitempty"`
 	RoleGroups           map[string][]string  `yaml:"roleGroups" json:"roleGroups,omitempty"`
 	ControlPlaneEndpoint ControlPlaneEndpoint `yaml:"controlPlaneEndpoint" json:"controlPlaneEndpoint,omitempty"`
 	System               System               `yaml:"system" json:"system,omitempty"`
@@ -67,10 +68,24 @@ type HostCfg struct {
 	Arch            string `yaml:"arch,omitempty" json:"arch,omitempty"`
 	Timeout         *int64 `yaml:"timeout,omitempty" json:"timeout,omitempty"`
 
+	// Bastions overrides the bastions of the cluster for the host.
+	Bastions []BastionCfg `yaml:"bastions,omitempty" json:"bastions,omitempty"`
+
 	// Labels defines the kubernetes labels for the node.
 	Labels map[string]string `yaml:"labels,omitempty" json:"labels,omitempty"`
 }
 
+// BastionCfg defines a bastion (jump host) which is used to reach the hosts over ssh.
+// The bastions are ordered from the first hop, which is reachable from the machine running kk.
+type BastionCfg struct {
+	Address        string `yaml:"address,omitempty" json:"address,omitempty"`
+	Port           int    `yaml:"port,omitempty" json:"port,omitempty"`
+	User           string `yaml:"user,omitempty" json:"user,omitempty"`
+	Password       string `yaml:"password,omitempty" json:"password,omitempty"`
+	PrivateKey     string `yaml:"privateKey,omitempty" json:"privateKey,omitempty"`
+	PrivateKeyPath string `yaml:"privateKeyPath,omitempty" json:"privateKeyPath,omitempty"`
+}
+
 // ControlPlaneEndpoint defines the control plane endpoint information for cluster.
 type ControlPlaneEndpoint struct {
 	InternalLoadbalancer string  `yaml:"internalLoadbalancer" json:"internalLoadbalancer,omitempty"`
@@ -119,6 +134,9 @@ type RegistryConfig struct {
 	Auths              runtime.RawExtension    `yaml:"auths" json:"auths,omitempty"`
 	NamespaceRewrite   *NamespaceRewrite       `yaml:"namespaceRewrite" json:"namespaceRewrite"`
 	RemoteMirrors      map[string]MirrorConfig `yaml:"remoteMirrors" json:"remoteMirrors,omitempty"`
+	// ImageVerificationKey is the path of the armored OpenPGP public key which the verification result of the artifact
+	// images must be signed by. Images are pushed to the registry only if the signature is valid.
+	ImageVerificationKey string `yaml:"imageVerificationKey" json:"imageVerificationKey,omitempty"`
 }
 
 // MirrorConfig defines the mirror configuration for a registry
@@ -233,6 +251,16 @@ func toHosts(cfg HostCfg) *KubeHost {
 	host.PrivateKeyPath = cfg.PrivateKeyPath
 	host.Arch = cfg.Arch
 	host.Timeout = *cfg.Timeout
+	for _, b := range cfg.Bastions {
+		host.Bastions = append(host.Bastions, connector.Bastion{
+			Address:        b.Address,
+			Port:           b.Port,
+			User:           b.User,
+			Password:       b.Password,
+			PrivateKey:     b.PrivateKey,
+			PrivateKeyPath: b.PrivateKeyPath,
+		})
+	}
 
 	kubeHost := &KubeHost{
 		BaseHost: host,
@@ -46,6 +46,7 @@ const (
 	DefaultBuildxVersion           = "v0.14.0"
 	DefaultContainerdVersion       = "1.7.13"
 	DefaultRuncVersion             = "v1.1.12"
+	DefaultCrioVersion             = "v1.29.1"
 	DefaultCrictlVersion           = "v1.29.0"
 	DefaultKubeVersion             = "v1.23.17"
 	DefaultCalicoVersion           = "v3.27.4"
@@ -83,6 +84,8 @@ const (
 	DefaultEtcdBackupPeriod        = 1440
 	DefaultKeepBackNumber          = 5
 	DefaultEtcdBackupScriptDir     = "/usr/local/bin/kube-scripts"
+	DefaultEtcdBackupS3Region      = "us-east-1"
+	DefaultEtcdBackupS3Prefix      = "etcd-backup"
 	DefaultPodGateway              = "10.233.64.1"
 	DefaultJoinCIDR                = "100.64.0.0/16"
 	DefaultNetworkType             = "geneve"
@@ -112,6 +115,7 @@ func (cfg *ClusterSpec) SetDefaultClusterSpec() (*ClusterSpec, map[string][]*Kub
 	clusterCfg := ClusterSpec{}
 
 	clusterCfg.Hosts = SetDefaultHostsCfg(cfg)
+	clusterCfg.Bastions = cfg.Bastions
 	clusterCfg.RoleGroups = cfg.RoleGroups
 	clusterCfg.Etcd = SetDefaultEtcdCfg(cfg)
 	roleGroups := clusterCfg.GroupHosts()
@@ -187,11 +191,44 @@ func SetDefaultHostsCfg(cfg *ClusterSpec) []HostCfg {
 			host.Timeout = &timeout
 		}
 
+		if len(host.Bastions) == 0 {
+			host.Bastions = cfg.Bastions
+		}
+		host.Bastions = SetDefaultBastionsCfg(host)
+
 		hostCfg = append(hostCfg, host)
 	}
 	return hostCfg
 }
 
+// SetDefaultBastionsCfg sets the default port and user of the bastions of the host.
+// The bastion without credentials uses the credentials of the host.
+func SetDefaultBastionsCfg(host HostCfg) []BastionCfg {
+	if len(host.Bastions) == 0 {
+		return nil
+	}
+	bastions := make([]BastionCfg, 0, len(host.Bastions))
+	for _, bastion := range host.Bastions {
+		if bastion.Port == 0 {
+			bastion.Port = DefaultSSHPort
+		}
+		if bastion.User == "" {
+			bastion.User = host.User
+		}
+		if bastion.Password == "" && bastion.PrivateKey == "" && bastion.PrivateKeyPath == "" {
+			bastion.Password = host.Password
+			bastion.PrivateKey = host.PrivateKey
+			bastion.PrivateKeyPath = host.PrivateKeyPath
+		}
+		if bastion.PrivateKeyPath != "" && strings.HasPrefix(strings.TrimSpace(bastion.PrivateKeyPath), "~/") {
+			homeDir, _ := util.Home()
+			bastion.PrivateKeyPath = strings.Replace(bastion.PrivateKeyPath, "~/", fmt.Sprintf("%s/", homeDir), 1)
+		}
+		bastions = append(bastions, bastion)
+	}
+	return bastions
+}
+
 func SetDefaultLBCfg(cfg *ClusterSpec, masterGroup []*KubeHost) ControlPlaneEndpoint {
 	//Check whether LB should be configured
 	if len(masterGroup) >= 2 && !cfg.ControlPlaneEndpoint.IsInternalLBEnabled() && cfg.ControlPlaneEndpoint.Address == "" && !cfg.ControlPlaneEndpoint.EnableExternalDNS() {
@@ -358,6 +395,17 @@ func SetDefaultEtcdCfg(cfg *ClusterSpec) EtcdCluster {
 	if cfg.Etcd.BackupScriptDir == "" {
 		cfg.Etcd.BackupScriptDir = DefaultEtcdBackupScriptDir
 	}
+	if s3 := cfg.Etcd.BackupS3; s3 != nil {
+		if s3.Region == "" {
+			s3.Region = DefaultEtcdBackupS3Region
+		}
+		if s3.Prefix == "" {
+			s3.Prefix = DefaultEtcdBackupS3Prefix
+		}
+		if s3.KeepBackupNumber == 0 {
+			s3.KeepBackupNumber = cfg.Etcd.KeepBackupNumber
+		}
+	}
 
 	return cfg.Etcd
 }
@@ -26,25 +26,27 @@ type EtcdCluster struct {
 	// Type of etcd cluster, can be set to 'kubekey' 'kubeadm' 'external'
 	Type string `yaml:"type" json:"type,omitempty"`
 	// ExternalEtcd describes how to connect to an external etcd cluster when type is set to external
-	External                ExternalEtcd `yaml:"external" json:"external,omitempty"`
-	Port                    *int         `yaml:"port" json:"port,omitempty"`
-	PeerPort                *int         `yaml:"peerPort" json:"peerPort,omitempty"`
-	ExtraArgs               []string     `yaml:"extraArgs" json:"extraArgs,omitempty"`
-	BackupDir               string       `yaml:"backupDir" json:"backupDir,omitempty"`
-	BackupPeriod            int          `yaml:"backupPeriod" json:"backupPeriod,omitempty"`
-	KeepBackupNumber        int          `yaml:"keepBackupNumber" json:"keepBackupNumber,omitempty"`
-	BackupScriptDir         string       `yaml:"backupScript" json:"backupScript,omitempty"`
-	DataDir                 *string      `yaml:"dataDir" json:"dataDir,omitempty"`
-	HeartbeatInterval       *int         `yaml:"heartbeatInterval" json:"heartbeatInterval,omitempty"`
-	ElectionTimeout         *int         `yaml:"electionTimeout" json:"electionTimeout,omitempty"`
-	SnapshotCount           *int         `yaml:"snapshotCount" json:"snapshotCount,omitempty"`
-	AutoCompactionRetention *int         `yaml:"autoCompactionRetention" json:"autoCompactionRetention,omitempty"`
-	Metrics                 *string      `yaml:"metrics" json:"metrics,omitempty"`
-	QuotaBackendBytes       *int64       `yaml:"quotaBackendBytes" json:"quotaBackendBytes,omitempty"`
-	MaxRequestBytes         *int64       `yaml:"maxRequestBytes" json:"maxRequestBytes,omitempty"`
-	MaxSnapshots            *int         `yaml:"maxSnapshots" json:"maxSnapshots,omitempty"`
-	MaxWals                 *int         `yaml:"maxWals" json:"maxWals,omitempty"`
-	LogLevel                *string      `yaml:"logLevel" json:"logLevel"`
+	External         ExternalEtcd `yaml:"external" json:"external,omitempty"`
+	Port             *int         `yaml:"port" json:"port,omitempty"`
+	PeerPort         *int         `yaml:"peerPort" json:"peerPort,omitempty"`
+	ExtraArgs        []string     `yaml:"extraArgs" json:"extraArgs,omitempty"`
+	BackupDir        string       `yaml:"backupDir" json:"backupDir,omitempty"`
+	BackupPeriod     int          `yaml:"backupPeriod" json:"backupPeriod,omitempty"`
+	KeepBackupNumber int          `yaml:"keepBackupNumber" json:"keepBackupNumber,omitempty"`
+	BackupScriptDir  string       `yaml:"backupScript" json:"backupScript,omitempty"`
+	// BackupS3 is the S3-compatible storage which the etcd snapshots are uploaded to.
+	BackupS3                *EtcdBackupS3 `yaml:"backupS3" json:"backupS3,omitempty"`
+	DataDir                 *string       `yaml:"dataDir" json:"dataDir,omitempty"`
+	HeartbeatInterval       *int          `yaml:"heartbeatInterval" json:"heartbeatInterval,omitempty"`
+	ElectionTimeout         *int          `yaml:"electionTimeout" json:"electionTimeout,omitempty"`
+	SnapshotCount           *int          `yaml:"snapshotCount" json:"snapshotCount,omitempty"`
+	AutoCompactionRetention *int          `yaml:"autoCompactionRetention" json:"autoCompactionRetention,omitempty"`
+	Metrics                 *string       `yaml:"metrics" json:"metrics,omitempty"`
+	QuotaBackendBytes       *int64        `yaml:"quotaBackendBytes" json:"quotaBackendBytes,omitempty"`
+	MaxRequestBytes         *int64        `yaml:"maxRequestBytes" json:"maxRequestBytes,omitempty"`
+	MaxSnapshots            *int          `yaml:"maxSnapshots" json:"maxSnapshots,omitempty"`
+	MaxWals                 *int          `yaml:"maxWals" json:"maxWals,omitempty"`
+	LogLevel                *string       `yaml:"logLevel" json:"logLevel"`
 }
 
 // ExternalEtcd describes how to connect to an external etcd cluster
@@ -61,6 +63,23 @@ type ExternalEtcd struct {
 	KeyFile string `yaml:"keyFile" json:"keyFile,omitempty"`
 }
 
+// EtcdBackupS3 describes the S3-compatible storage (AWS S3, MinIO, etc.) used to store etcd snapshots.
+// It's used by "kk backup etcd" and the periodic backup script.
+type EtcdBackupS3 struct {
+	// Endpoint of the storage, e.g. https://s3.us-east-1.amazonaws.com or http://minio.local:9000.
+	Endpoint string `yaml:"endpoint" json:"endpoint,omitempty"`
+	Region   string `yaml:"region" json:"region,omitempty"`
+	Bucket   string `yaml:"bucket" json:"bucket,omitempty"`
+	// Prefix of the object keys. snapshots are stored as <prefix>/snapshot-<timestamp>.db.
+	Prefix          string `yaml:"prefix" json:"prefix,omitempty"`
+	AccessKeyID     string `yaml:"accessKeyID" json:"accessKeyID,omitempty"`
+	SecretAccessKey string `yaml:"secretAccessKey" json:"secretAccessKey,omitempty"`
+	// InsecureSkipVerify skips the verification of the endpoint certificate.
+	InsecureSkipVerify bool `yaml:"insecureSkipVerify" json:"insecureSkipVerify,omitempty"`
+	// KeepBackupNumber is the number of snapshots kept in the bucket. defaults to keepBackupNumber of etcd.
+	KeepBackupNumber int `yaml:"keepBackupNumber" json:"keepBackupNumber,omitempty"`
+}
+
 // GetPort returns the port of etcd cluster
 func (e *EtcdCluster) GetPort() int {
 	if e.Port == nil {
@@ -102,6 +102,27 @@ type ManifestRegistry struct {
 	Auths runtime.RawExtension `yaml:"auths" json:"auths,omitempty"`
 }
 
+// ImageVerification defines how the images are verified before they are exported to the artifact.
+// PolicyFile and Cosign can be used together, the image must pass both of them.
+type ImageVerification struct {
+	// PolicyFile is the path of a containers-policy.json(5) file, e.g. requires images signed by GPG keys.
+	PolicyFile string `yaml:"policyFile" json:"policyFile,omitempty"`
+	// Cosign verifies the cosign signatures of images. It requires the cosign binary in PATH.
+	Cosign *CosignVerification `yaml:"cosign" json:"cosign,omitempty"`
+	// SigningKey is the path of an armored OpenPGP private key without passphrase. The verification result saved in the
+	// artifact is signed with it, and checked with the public key in registry.imageVerificationKey before pushing.
+	SigningKey string `yaml:"signingKey" json:"signingKey,omitempty"`
+}
+
+// CosignVerification verifies images signed by a cosign key pair, or by keyless signing.
+type CosignVerification struct {
+	// PublicKey is the path of the cosign public key.
+	PublicKey string `yaml:"publicKey" json:"publicKey,omitempty"`
+	// CertificateIdentity and CertificateOIDCIssuer are the identity of keyless signing.
+	CertificateIdentity   string `yaml:"certificateIdentity" json:"certificateIdentity,omitempty"`
+	CertificateOIDCIssuer string `yaml:"certificateOIDCIssuer" json:"certificateOIDCIssuer,omitempty"`
+}
+
 // ManifestSpec defines the desired state of Manifest
 type ManifestSpec struct {
 	Arches                  []string                 `yaml:"arches" json:"arches"`
@@ -110,6 +131,8 @@ type ManifestSpec struct {
 	Components              Components               `yaml:"components" json:"components"`
 	Images                  []string                 `yaml:"images" json:"images"`
 	ManifestRegistry        ManifestRegistry         `yaml:"registry" json:"registry"`
+	// ImageVerification verifies the signatures of images when they are exported to the artifact.
+	ImageVerification *ImageVerification `yaml:"imageVerification" json:"imageVerification,omitempty"`
 }
 
 // Manifest is the Schema for the manifests API
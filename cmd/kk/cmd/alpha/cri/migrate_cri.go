@@ -75,7 +75,7 @@ func (o *MigrateCriOptions) Run() error {
 
 func (o *MigrateCriOptions) AddFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(&o.Role, "role", "", "", "Role groups for migrating. Support: master, worker, all.")
-	cmd.Flags().StringVarP(&o.Type, "type", "", "", "Type of target CRI. Support: docker, containerd.")
+	cmd.Flags().StringVarP(&o.Type, "type", "", "", "Type of target CRI. Support: docker, containerd, crio, isula.")
 	cmd.Flags().StringVarP(&o.ClusterCfgFile, "filename", "f", "", "Path to a configuration file")
 	cmd.Flags().StringVarP(&o.Kubernetes, "with-kubernetes", "", "", "Specify a supported version of kubernetes")
 	cmd.Flags().StringVarP(&o.DownloadCmd, "download-cmd", "", "curl -L -o %s %s",
@@ -94,7 +94,7 @@ func (o *MigrateCriOptions) Validate() error {
 	if o.Type == "" {
 		return errors.New("cri Type can not be empty")
 	}
-	if o.Type != common.Docker && o.Type != common.Containerd {
+	if o.Type != common.Docker && o.Type != common.Containerd && o.Type != common.Crio && o.Type != common.Isula {
 		return errors.Errorf("cri Type is invalid: %s", o.Type)
 	}
 	if o.ClusterCfgFile == "" {
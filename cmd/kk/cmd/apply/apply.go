@@ -0,0 +1,74 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apply
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/kubesys/kubekey/cmd/kk/cmd/options"
+	"github.com/kubesys/kubekey/cmd/kk/cmd/util"
+	"github.com/kubesys/kubekey/cmd/kk/pkg/common"
+	"github.com/kubesys/kubekey/cmd/kk/pkg/pipelines"
+)
+
+type ApplyOptions struct {
+	CommonOptions       *options.CommonOptions
+	ClusterCfgFile      string
+	SecurityEnhancement bool
+}
+
+func NewApplyOptions() *ApplyOptions {
+	return &ApplyOptions{
+		CommonOptions: options.NewCommonOptions(),
+	}
+}
+
+// NewCmdApply creates a new apply command
+func NewCmdApply() *cobra.Command {
+	o := NewApplyOptions()
+	cmd := &cobra.Command{
+		Use:   "apply",
+		Short: "Apply the control plane and kubelet configuration to the cluster node by node",
+		Long: `Apply the changes of apiserverArgs, controllerManagerArgs, schedulerArgs, kubeletConfiguration, featureGates and
+audit in the configuration to the cluster. The kubeadm config, the static pod manifests and the kubelet configuration
+are regenerated and rolled out node by node, the control plane nodes first. The unchanged nodes are skipped.
+
+The files changed on every node are backed up to /etc/kubernetes/backup/kk-apply-<time> first. If a node is not
+healthy after the change, all nodes and the configmaps are rolled back from the backup and the rest nodes are not rolled.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			util.CheckErr(o.Run())
+		},
+	}
+
+	o.CommonOptions.AddCommonFlag(cmd)
+	o.AddFlags(cmd)
+	return cmd
+}
+
+func (o *ApplyOptions) Run() error {
+	arg := common.Argument{
+		FilePath:            o.ClusterCfgFile,
+		Debug:               o.CommonOptions.Verbose,
+		SecurityEnhancement: o.SecurityEnhancement,
+	}
+	return pipelines.ApplyCluster(arg)
+}
+
+func (o *ApplyOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&o.ClusterCfgFile, "filename", "f", "", "Path to a configuration file")
+	cmd.Flags().BoolVarP(&o.SecurityEnhancement, "with-security-enhancement", "", false, "The cluster is created with security enhancement")
+}
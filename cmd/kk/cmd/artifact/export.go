@@ -18,6 +18,8 @@ package artifact
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 
 	"github.com/spf13/cobra"
 
@@ -37,6 +39,7 @@ type ArtifactExportOptions struct {
 	ImageStartIndex    int
 	ImageTransport     string
 	SkipRemoveArtifact bool
+	Base               string
 }
 
 func NewArtifactExportOptions() *ArtifactExportOptions {
@@ -74,6 +77,14 @@ func (o *ArtifactExportOptions) Validate(_ []string) error {
 	if o.ManifestFile == "" {
 		return fmt.Errorf("--manifest can not be an empty string")
 	}
+	if o.Base != "" {
+		if _, err := os.Stat(o.Base); err != nil {
+			return fmt.Errorf("base artifact %s: %w", o.Base, err)
+		}
+		if sameFile(o.Base, o.Output) {
+			return fmt.Errorf("--base and --output can not be the same file")
+		}
+	}
 	return nil
 }
 
@@ -87,6 +98,7 @@ func (o *ArtifactExportOptions) Run() error {
 		Debug:              o.CommonOptions.Verbose,
 		IgnoreErr:          o.CommonOptions.IgnoreErr,
 		SkipRemoveArtifact: o.SkipRemoveArtifact,
+		Base:               o.Base,
 	}
 
 	return pipelines.ArtifactExport(arg, o.DownloadCmd)
@@ -100,5 +112,12 @@ func (o *ArtifactExportOptions) AddFlags(cmd *cobra.Command) {
 	cmd.Flags().IntVarP(&o.ImageStartIndex, "image-start-index", "", 0, "Save images from specific index, default to 0")
 	cmd.Flags().StringVarP(&o.ImageTransport, "image-transport", "", "", "Image transport to pull from, take values from [docker, docker-daemon]")
 	cmd.Flags().BoolVarP(&o.SkipRemoveArtifact, "skip-remove-artifact", "", false, "Skip remove artifact")
+	cmd.Flags().StringVarP(&o.Base, "base", "", "", "Path to a base artifact, only the files missing from it are exported as a delta artifact")
+
+}
 
+func sameFile(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && absA == absB
 }
@@ -18,6 +18,8 @@ package artifact
 
 import (
 	"errors"
+	"fmt"
+	"os"
 
 	"github.com/spf13/cobra"
 
@@ -30,6 +32,7 @@ import (
 type ArtifactImportOptions struct {
 	CommonOptions *options.CommonOptions
 	Artifact      string
+	Base          string
 }
 
 func NewArtifactImportOptions() *ArtifactImportOptions {
@@ -57,19 +60,26 @@ func NewCmdArtifactImport() *cobra.Command {
 
 func (o *ArtifactImportOptions) Run() error {
 	arg := common.Argument{
-		Debug:    o.CommonOptions.Verbose,
-		Artifact: o.Artifact,
+		Debug:        o.CommonOptions.Verbose,
+		Artifact:     o.Artifact,
+		ArtifactBase: o.Base,
 	}
 	return artifact.ArtifactImport(arg)
 }
 
 func (o *ArtifactImportOptions) AddFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(&o.Artifact, "artifact", "a", "", "Path to a artifact gzip")
+	cmd.Flags().StringVarP(&o.Base, "base", "", "", "Path to the base artifact of a delta artifact, they are composed when importing")
 }
 
 func (o *ArtifactImportOptions) Validate(_ []string) error {
 	if o.Artifact == "" {
 		return errors.New("artifact path can not be empty")
 	}
+	if o.Base != "" {
+		if _, err := os.Stat(o.Base); err != nil {
+			return fmt.Errorf("base artifact %s: %w", o.Base, err)
+		}
+	}
 	return nil
 }
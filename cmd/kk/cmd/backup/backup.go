@@ -0,0 +1,47 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/kubesys/kubekey/cmd/kk/cmd/options"
+)
+
+type BackupOptions struct {
+	CommonOptions *options.CommonOptions
+}
+
+func NewBackupOptions() *BackupOptions {
+	return &BackupOptions{
+		CommonOptions: options.NewCommonOptions(),
+	}
+}
+
+// NewCmdBackup creates a new backup command
+func NewCmdBackup() *cobra.Command {
+	o := NewBackupOptions()
+	cmd := &cobra.Command{
+		Use:   "backup",
+		Short: "Backup cluster components",
+	}
+
+	o.CommonOptions.AddCommonFlag(cmd)
+
+	cmd.AddCommand(NewCmdBackupEtcd())
+	return cmd
+}
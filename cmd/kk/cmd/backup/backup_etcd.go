@@ -0,0 +1,86 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/kubesys/kubekey/cmd/kk/cmd/options"
+	"github.com/kubesys/kubekey/cmd/kk/cmd/util"
+	"github.com/kubesys/kubekey/cmd/kk/pkg/common"
+	"github.com/kubesys/kubekey/cmd/kk/pkg/pipelines"
+)
+
+type BackupEtcdOptions struct {
+	CommonOptions  *options.CommonOptions
+	ClusterCfgFile string
+}
+
+func NewBackupEtcdOptions() *BackupEtcdOptions {
+	return &BackupEtcdOptions{
+		CommonOptions: options.NewCommonOptions(),
+	}
+}
+
+// NewCmdBackupEtcd creates a new backup etcd command
+func NewCmdBackupEtcd() *cobra.Command {
+	o := NewBackupEtcdOptions()
+	cmd := &cobra.Command{
+		Use:   "etcd",
+		Short: "Take a snapshot of the etcd cluster and upload it to S3",
+		Run: func(cmd *cobra.Command, args []string) {
+			util.CheckErr(o.Run())
+		},
+	}
+
+	o.CommonOptions.AddCommonFlag(cmd)
+	o.AddFlags(cmd)
+
+	cmd.AddCommand(NewCmdBackupEtcdList())
+	return cmd
+}
+
+func (o *BackupEtcdOptions) Run() error {
+	arg := common.Argument{
+		FilePath: o.ClusterCfgFile,
+		Debug:    o.CommonOptions.Verbose,
+	}
+	return pipelines.BackupEtcd(arg)
+}
+
+func (o *BackupEtcdOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&o.ClusterCfgFile, "filename", "f", "", "Path to a configuration file")
+}
+
+// NewCmdBackupEtcdList creates a new command to list the etcd snapshots in S3
+func NewCmdBackupEtcdList() *cobra.Command {
+	o := NewBackupEtcdOptions()
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the etcd snapshots in S3",
+		Run: func(cmd *cobra.Command, args []string) {
+			util.CheckErr(pipelines.ListEtcdBackups(common.Argument{
+				FilePath: o.ClusterCfgFile,
+				Debug:    o.CommonOptions.Verbose,
+			}))
+		},
+	}
+
+	o.CommonOptions.AddCommonFlag(cmd)
+	o.AddFlags(cmd)
+	return cmd
+}
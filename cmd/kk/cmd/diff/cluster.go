@@ -0,0 +1,96 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diff
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubesys/kubekey/cmd/kk/cmd/options"
+	"github.com/kubesys/kubekey/cmd/kk/cmd/util"
+	"github.com/kubesys/kubekey/cmd/kk/pkg/common"
+	"github.com/kubesys/kubekey/cmd/kk/pkg/kubernetes"
+	"github.com/kubesys/kubekey/cmd/kk/pkg/pipelines"
+)
+
+// DriftExitCode is the exit code when the cluster drifts from the configuration, 1 is used by the errors.
+const DriftExitCode = 2
+
+type DiffClusterOptions struct {
+	CommonOptions       *options.CommonOptions
+	ClusterCfgFile      string
+	Output              string
+	SecurityEnhancement bool
+}
+
+func NewDiffClusterOptions() *DiffClusterOptions {
+	return &DiffClusterOptions{
+		CommonOptions: options.NewCommonOptions(),
+	}
+}
+
+// NewCmdDiffCluster creates a new diff cluster command
+func NewCmdDiffCluster() *cobra.Command {
+	o := NewDiffClusterOptions()
+	cmd := &cobra.Command{
+		Use:   "cluster",
+		Short: "Compare the cluster configuration with the live cluster to find the manual changes on the nodes",
+		Long: `Compare the cluster configuration with the live cluster: the nodes and roles, the Kubernetes version of
+every node, the arguments of the control plane static pods, the kubelet configuration, the CNI plugin and version,
+the registry mirrors and the etcd members.
+
+The exit code is 0 if there is no drift, 2 if the cluster drifts from the configuration, and 1 on errors.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			util.CheckErr(o.Validate())
+			drifted, err := o.Run()
+			util.CheckErr(err)
+			if drifted {
+				os.Exit(DriftExitCode)
+			}
+		},
+	}
+
+	o.CommonOptions.AddCommonFlag(cmd)
+	o.AddFlags(cmd)
+	return cmd
+}
+
+func (o *DiffClusterOptions) Validate() error {
+	switch o.Output {
+	case kubernetes.DiffOutputHuman, kubernetes.DiffOutputJSON:
+		return nil
+	default:
+		return fmt.Errorf("unsupported output %s, it must be one of %s and %s", o.Output, kubernetes.DiffOutputHuman, kubernetes.DiffOutputJSON)
+	}
+}
+
+func (o *DiffClusterOptions) Run() (bool, error) {
+	arg := common.Argument{
+		FilePath:            o.ClusterCfgFile,
+		Debug:               o.CommonOptions.Verbose,
+		SecurityEnhancement: o.SecurityEnhancement,
+	}
+	return pipelines.DiffCluster(arg, o.Output)
+}
+
+func (o *DiffClusterOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&o.ClusterCfgFile, "filename", "f", "", "Path to a configuration file")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", kubernetes.DiffOutputHuman, "Output format, one of human and json")
+	cmd.Flags().BoolVarP(&o.SecurityEnhancement, "with-security-enhancement", "", false, "The cluster is created with security enhancement")
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package restore
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubesys/kubekey/cmd/kk/cmd/options"
+	"github.com/kubesys/kubekey/cmd/kk/cmd/util"
+	"github.com/kubesys/kubekey/cmd/kk/pkg/common"
+	"github.com/kubesys/kubekey/cmd/kk/pkg/pipelines"
+)
+
+type RestoreEtcdOptions struct {
+	CommonOptions  *options.CommonOptions
+	ClusterCfgFile string
+	Snapshot       string
+}
+
+func NewRestoreEtcdOptions() *RestoreEtcdOptions {
+	return &RestoreEtcdOptions{
+		CommonOptions: options.NewCommonOptions(),
+	}
+}
+
+// NewCmdRestoreEtcd creates a new restore etcd command
+func NewCmdRestoreEtcd() *cobra.Command {
+	o := NewRestoreEtcdOptions()
+	cmd := &cobra.Command{
+		Use:   "etcd",
+		Short: "Restore the etcd cluster from a snapshot",
+		Run: func(cmd *cobra.Command, args []string) {
+			util.CheckErr(o.Validate())
+			util.CheckErr(o.Run())
+		},
+	}
+
+	o.CommonOptions.AddCommonFlag(cmd)
+	o.AddFlags(cmd)
+	return cmd
+}
+
+func (o *RestoreEtcdOptions) Validate() error {
+	if o.Snapshot == "" {
+		return fmt.Errorf("--snapshot is required")
+	}
+	info, err := os.Stat(o.Snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to find the etcd snapshot %s: %v", o.Snapshot, err)
+	}
+	if info.IsDir() {
+		return fmt.Errorf("the etcd snapshot %s is a directory", o.Snapshot)
+	}
+	return nil
+}
+
+func (o *RestoreEtcdOptions) Run() error {
+	arg := common.Argument{
+		FilePath:     o.ClusterCfgFile,
+		Debug:        o.CommonOptions.Verbose,
+		EtcdSnapshot: o.Snapshot,
+	}
+	return pipelines.RestoreEtcd(arg)
+}
+
+func (o *RestoreEtcdOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&o.ClusterCfgFile, "filename", "f", "", "Path to a configuration file")
+	cmd.Flags().StringVarP(&o.Snapshot, "snapshot", "", "", "Path to the etcd snapshot file to restore from")
+}
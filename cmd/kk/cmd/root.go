@@ -29,16 +29,21 @@ import (
 
 	"github.com/kubesys/kubekey/cmd/kk/cmd/add"
 	"github.com/kubesys/kubekey/cmd/kk/cmd/alpha"
+	"github.com/kubesys/kubekey/cmd/kk/cmd/apply"
 	"github.com/kubesys/kubekey/cmd/kk/cmd/artifact"
+	"github.com/kubesys/kubekey/cmd/kk/cmd/backup"
 	"github.com/kubesys/kubekey/cmd/kk/cmd/cert"
 	"github.com/kubesys/kubekey/cmd/kk/cmd/completion"
 	"github.com/kubesys/kubekey/cmd/kk/cmd/create"
 	"github.com/kubesys/kubekey/cmd/kk/cmd/delete"
+	"github.com/kubesys/kubekey/cmd/kk/cmd/diff"
 	initOs "github.com/kubesys/kubekey/cmd/kk/cmd/init"
 	"github.com/kubesys/kubekey/cmd/kk/cmd/options"
 	"github.com/kubesys/kubekey/cmd/kk/cmd/plugin"
+	"github.com/kubesys/kubekey/cmd/kk/cmd/restore"
 	"github.com/kubesys/kubekey/cmd/kk/cmd/upgrade"
 	"github.com/kubesys/kubekey/cmd/kk/cmd/version"
+	"github.com/kubesys/kubekey/cmd/kk/pkg/files"
 )
 
 type KubeKeyOptions struct {
@@ -97,6 +102,7 @@ func NewDefaultKubeKeyCommandWithArgs(o KubeKeyOptions) *cobra.Command {
 
 // NewKubeKeyCommand creates a new kubekey root command
 func NewKubeKeyCommand(o KubeKeyOptions) *cobra.Command {
+	catalog := files.CatalogOptions{}
 	cmds := &cobra.Command{
 		Use:   "kk",
 		Short: "Kubernetes/KubeSphere Deploy Tool",
@@ -104,7 +110,21 @@ func NewKubeKeyCommand(o KubeKeyOptions) *cobra.Command {
 1. Install Kubernetes only
 2. Install Kubernetes and KubeSphere together in one command
 3. Install Kubernetes first, then deploy KubeSphere on it using https://github.com/kubesys/ks-installer`,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			files.SetCatalogOptions(catalog)
+			if catalog.Source == "" {
+				return nil
+			}
+			return files.LoadCatalog(catalog)
+		},
 	}
+	cmds.PersistentFlags().StringVar(&catalog.Source, "components-catalog", os.Getenv("KK_COMPONENTS_CATALOG"),
+		"Path or http(s) url of an overlay catalog which adds the versions, urls and sha256 of components. Defaults to $KK_COMPONENTS_CATALOG")
+	cmds.PersistentFlags().StringVar(&catalog.Sha256, "components-catalog-sha256", "", "The expected sha256 of the overlay catalog")
+	cmds.PersistentFlags().StringVar(&catalog.KeyFile, "components-catalog-key", "",
+		"Path of an armored OpenPGP public key to verify the detached signature <catalog>.asc of the overlay catalog")
+	cmds.PersistentFlags().BoolVar(&catalog.Insecure, "components-catalog-insecure", false,
+		"Use the overlay catalog without --components-catalog-sha256 or --components-catalog-key")
 
 	cmds.AddCommand(initOs.NewCmdInit())
 
@@ -114,7 +134,11 @@ func NewKubeKeyCommand(o KubeKeyOptions) *cobra.Command {
 	cmds.AddCommand(delete.NewCmdDelete())
 	cmds.AddCommand(add.NewCmdAdd())
 	cmds.AddCommand(upgrade.NewCmdUpgrade())
+	cmds.AddCommand(diff.NewCmdDiff())
+	cmds.AddCommand(apply.NewCmdApply())
 	cmds.AddCommand(cert.NewCmdCerts())
+	cmds.AddCommand(backup.NewCmdBackup())
+	cmds.AddCommand(restore.NewCmdRestore())
 	cmds.AddCommand(artifact.NewCmdArtifact())
 
 	cmds.AddCommand(plugin.NewCmdPlugin(o.IOStreams))
@@ -0,0 +1,95 @@
+/*
+Copyright 2020 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package upgrade
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/kubesys/kubekey/cmd/kk/cmd/options"
+	"github.com/kubesys/kubekey/cmd/kk/cmd/util"
+	"github.com/kubesys/kubekey/cmd/kk/pkg/common"
+	"github.com/kubesys/kubekey/cmd/kk/pkg/pipelines"
+	"github.com/kubesys/kubekey/cmd/kk/pkg/version/kubernetes"
+	"github.com/kubesys/kubekey/cmd/kk/pkg/version/kubesphere"
+)
+
+type UpgradePlanOptions struct {
+	CommonOptions       *options.CommonOptions
+	ClusterCfgFile      string
+	Kubernetes          string
+	EnableKubeSphere    bool
+	KubeSphere          string
+	SkipDependencyCheck bool
+	EtcdUpgrade         bool
+}
+
+func NewUpgradePlanOptions() *UpgradePlanOptions {
+	return &UpgradePlanOptions{
+		CommonOptions: options.NewCommonOptions(),
+	}
+}
+
+// NewCmdUpgradePlan creates a new upgrade plan command
+func NewCmdUpgradePlan() *cobra.Command {
+	o := NewUpgradePlanOptions()
+	cmd := &cobra.Command{
+		Use:   "plan",
+		Short: "Print the upgrade plan and check the version skew of the components without upgrading",
+		Run: func(cmd *cobra.Command, args []string) {
+			util.CheckErr(o.Complete(cmd, args))
+			util.CheckErr(o.Run())
+		},
+	}
+	o.CommonOptions.AddCommonFlag(cmd)
+	o.AddFlags(cmd)
+
+	_ = cmd.RegisterFlagCompletionFunc("with-kubernetes", func(cmd *cobra.Command, args []string, toComplete string) (
+		strings []string, directive cobra.ShellCompDirective) {
+		return kubernetes.SupportedK8sVersionList(), cobra.ShellCompDirectiveNoFileComp
+	})
+	return cmd
+}
+
+func (o *UpgradePlanOptions) Complete(_ *cobra.Command, args []string) error {
+	if o.EnableKubeSphere && len(args) > 0 {
+		o.KubeSphere = args[0]
+	} else {
+		o.KubeSphere = kubesphere.Latest().Version
+	}
+	return nil
+}
+
+func (o *UpgradePlanOptions) Run() error {
+	arg := common.Argument{
+		FilePath:            o.ClusterCfgFile,
+		KubernetesVersion:   o.Kubernetes,
+		KsEnable:            o.EnableKubeSphere,
+		KsVersion:           o.KubeSphere,
+		Debug:               o.CommonOptions.Verbose,
+		SkipDependencyCheck: o.SkipDependencyCheck,
+		EtcdUpgrade:         o.EtcdUpgrade,
+	}
+	return pipelines.UpgradePlan(arg)
+}
+
+func (o *UpgradePlanOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&o.ClusterCfgFile, "filename", "f", "", "Path to a configuration file")
+	cmd.Flags().StringVarP(&o.Kubernetes, "with-kubernetes", "", "", "Specify a supported version of kubernetes")
+	cmd.Flags().BoolVarP(&o.EnableKubeSphere, "with-kubesphere", "", false, "Plan to upgrade kubesphere as well")
+	cmd.Flags().BoolVarP(&o.SkipDependencyCheck, "skip-dependency-check", "", false, "Skip kubernetes and kubesphere dependency version check")
+	cmd.Flags().BoolVarP(&o.EtcdUpgrade, "with-etcd", "", false, "Plan to upgrade etcd")
+}
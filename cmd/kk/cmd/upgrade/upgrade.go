@@ -39,6 +39,7 @@ type UpgradeOptions struct {
 	SkipPullImages      bool
 	SkipDependencyCheck bool
 	EtcdUpgrade         bool
+	SkipDrain           bool
 	DownloadCmd         string
 	Artifact            string
 }
@@ -62,6 +63,7 @@ func NewCmdUpgrade() *cobra.Command {
 	}
 	o.CommonOptions.AddCommonFlag(cmd)
 	o.AddFlags(cmd)
+	cmd.AddCommand(NewCmdUpgradePlan())
 
 	if err := completionSetting(cmd); err != nil {
 		panic(fmt.Sprintf("Got error with the completion setting"))
@@ -92,6 +94,7 @@ func (o *UpgradeOptions) Run() error {
 		Artifact:            o.Artifact,
 		SkipDependencyCheck: o.SkipDependencyCheck,
 		EtcdUpgrade:         o.EtcdUpgrade,
+		SkipDrain:           o.SkipDrain,
 	}
 	return pipelines.UpgradeCluster(arg, o.DownloadCmd)
 }
@@ -106,6 +109,7 @@ func (o *UpgradeOptions) AddFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(&o.Artifact, "artifact", "a", "", "Path to a KubeKey artifact")
 	cmd.Flags().BoolVarP(&o.SkipDependencyCheck, "skip-dependency-check", "", false, "Skip kubernetes and kubesphere dependency version check")
 	cmd.Flags().BoolVarP(&o.EtcdUpgrade, "with-etcd", "", false, "Upgrade etcd")
+	cmd.Flags().BoolVarP(&o.SkipDrain, "skip-drain", "", false, "Skip draining the nodes before upgrading their kubelet")
 }
 
 func completionSetting(cmd *cobra.Command) (err error) {
@@ -20,12 +20,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"sort"
 	"strings"
+	"text/tabwriter"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
 
+	"github.com/kubesys/kubekey/cmd/kk/pkg/files"
 	"github.com/kubesys/kubekey/cmd/kk/pkg/version/kubernetes"
 	"github.com/kubesys/kubekey/version"
 )
@@ -37,6 +40,7 @@ type Version struct {
 type VersionOptions struct {
 	Output                      string
 	ShowSupportedK8sVersionList bool
+	ShowSupported               bool
 }
 
 func NewVersionOptions() *VersionOptions {
@@ -54,6 +58,9 @@ func NewCmdVersion() *cobra.Command {
 			if o.ShowSupportedK8sVersionList {
 				return printSupportedK8sVersionList(cmd.OutOrStdout())
 			}
+			if o.ShowSupported {
+				return o.printSupported(cmd.OutOrStdout())
+			}
 			return o.Run()
 		},
 	}
@@ -65,6 +72,8 @@ func (o *VersionOptions) AddFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(&o.Output, "output", "o", "", "Output format; available options are 'yaml', 'json' and 'short'")
 	cmd.Flags().BoolVarP(&o.ShowSupportedK8sVersionList, "show-supported-k8s", "", false,
 		`print the version of supported k8s`)
+	cmd.Flags().BoolVarP(&o.ShowSupported, "show-supported", "", false,
+		`print the supported versions of all components, including the ones added by the overlay catalog`)
 }
 
 func (o *VersionOptions) Run() error {
@@ -100,3 +109,46 @@ func printSupportedK8sVersionList(output io.Writer) (err error) {
 	_, err = output.Write([]byte(fmt.Sprintln(strings.Join(kubernetes.SupportedK8sVersionList(), "\n"))))
 	return
 }
+
+// printSupported prints the supported versions of components in the merged catalog.
+func (o *VersionOptions) printSupported(output io.Writer) error {
+	supported := files.SupportedVersions()
+	switch o.Output {
+	case "yaml":
+		y, err := yaml.Marshal(supported)
+		if err != nil {
+			return err
+		}
+		_, err = output.Write(y)
+		return err
+	case "json":
+		y, err := json.MarshalIndent(supported, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(output, string(y))
+		return err
+	case "":
+	default:
+		return errors.Errorf("invalid output format: %s", o.Output)
+	}
+
+	names := make([]string, 0, len(supported))
+	for name := range supported {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	w := tabwriter.NewWriter(output, 10, 4, 3, ' ', 0)
+	_, _ = fmt.Fprintln(w, "COMPONENT\tARCH\tVERSIONS")
+	for _, name := range names {
+		arches := make([]string, 0, len(supported[name]))
+		for arch := range supported[name] {
+			arches = append(arches, arch)
+		}
+		sort.Strings(arches)
+		for _, arch := range arches {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", name, arch, strings.Join(supported[name][arch], ", "))
+		}
+	}
+	return w.Flush()
+}
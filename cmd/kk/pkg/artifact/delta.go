@@ -0,0 +1,216 @@
+/*
+ Copyright 2024 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package artifact
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// DeltaFileName is the manifest saved in a delta artifact.
+const DeltaFileName = "delta.json"
+
+// Delta describes a delta artifact, which only contains the files missing from the base artifact.
+type Delta struct {
+	Base DeltaBase `json:"base"`
+	// Files are the files of the artifact which are taken from the base, keyed by the name in the archive.
+	Files map[string]ArtifactFile `json:"files"`
+}
+
+// DeltaBase is the base artifact which the delta is exported against.
+type DeltaBase struct {
+	Name   string `json:"name"`
+	Sha256 string `json:"sha256"`
+}
+
+type ArtifactFile struct {
+	Sha256 string `json:"sha256"`
+	Size   int64  `json:"size"`
+}
+
+// ScanArtifact reads the artifact tarball once and returns its sha256 and the sha256 of every file in it.
+func ScanArtifact(path string) (string, map[string]ArtifactFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", nil, errors.Wrapf(errors.WithStack(err), "open artifact %s failed", path)
+	}
+	defer f.Close()
+
+	sum := sha256.New()
+	r := io.TeeReader(f, sum)
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return "", nil, errors.Wrapf(errors.WithStack(err), "read artifact %s failed", path)
+	}
+	defer gr.Close()
+
+	artifactFiles := make(map[string]ArtifactFile)
+	tr := tar.NewReader(gr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", nil, errors.Wrapf(errors.WithStack(err), "read artifact %s failed", path)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		h := sha256.New()
+		n, err := io.Copy(h, tr)
+		if err != nil {
+			return "", nil, errors.Wrapf(errors.WithStack(err), "read %s in artifact %s failed", hdr.Name, path)
+		}
+		artifactFiles[hdr.Name] = ArtifactFile{Sha256: fmt.Sprintf("%x", h.Sum(nil)), Size: n}
+	}
+	// the rest of the tarball (e.g. the padding) is a part of the checksum as well.
+	if _, err := io.Copy(io.Discard, r); err != nil {
+		return "", nil, errors.Wrapf(errors.WithStack(err), "read artifact %s failed", path)
+	}
+	return fmt.Sprintf("%x", sum.Sum(nil)), artifactFiles, nil
+}
+
+// NewDelta compares the files in dir with the files of the base artifact, and returns the delta whose
+// Files are the ones with the same name and content in the base.
+func NewDelta(dir string, base DeltaBase, baseFiles map[string]ArtifactFile) (*Delta, error) {
+	d := &Delta{Base: base, Files: make(map[string]ArtifactFile)}
+	err := filepath.Walk(dir, func(path string, info fs.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		name, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		name = filepath.ToSlash(name)
+		if name == DeltaFileName {
+			return nil
+		}
+		baseFile, ok := baseFiles[name]
+		if !ok || baseFile.Size != info.Size() {
+			return nil
+		}
+		file, err := fileSha256(path)
+		if err != nil {
+			return err
+		}
+		if file == baseFile {
+			d.Files[name] = file
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(errors.WithStack(err), "compare %s with the base artifact failed", dir)
+	}
+	return d, nil
+}
+
+// ReadDelta returns the delta manifest in the artifact tarball, or nil if it's a full artifact.
+// The delta manifest is the first file in a delta artifact, so only the first file is read.
+func ReadDelta(path string) (*Delta, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrapf(errors.WithStack(err), "open artifact %s failed", path)
+	}
+	defer f.Close()
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, errors.Wrapf(errors.WithStack(err), "read artifact %s failed", path)
+	}
+	defer gr.Close()
+
+	tr := tar.NewReader(gr)
+	hdr, err := tr.Next()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(errors.WithStack(err), "read artifact %s failed", path)
+	}
+	if hdr.Name != DeltaFileName {
+		return nil, nil
+	}
+	d := &Delta{}
+	if err := json.NewDecoder(tr).Decode(d); err != nil {
+		return nil, errors.Wrapf(errors.WithStack(err), "decode %s in artifact %s failed", DeltaFileName, path)
+	}
+	return d, nil
+}
+
+// Save writes the delta manifest into dir.
+func (d *Delta) Save(dir string) error {
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return errors.Wrapf(errors.WithStack(err), "marshal %s failed", DeltaFileName)
+	}
+	if err := os.WriteFile(filepath.Join(dir, DeltaFileName), data, 0644); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "write %s failed", DeltaFileName)
+	}
+	return nil
+}
+
+// Check all the files taken from the base artifact exist in dir, e.g. the base has been imported into the work dir before.
+func (d *Delta) Check(dir string) error {
+	var failed []string
+	for name, want := range d.Files {
+		got, err := fileSha256(filepath.Join(dir, filepath.FromSlash(name)))
+		switch {
+		case os.IsNotExist(errors.Cause(err)):
+			failed = append(failed, fmt.Sprintf("%s: not found", name))
+		case err != nil:
+			return err
+		case got != want:
+			failed = append(failed, fmt.Sprintf("%s: sha256 is %s, but expected %s", name, got.Sha256, want.Sha256))
+		}
+		if len(failed) >= 10 {
+			break
+		}
+	}
+	if len(failed) != 0 {
+		return errors.Errorf("the files of base artifact %s (sha256 %s) are missing, please import it with --base:\n%s",
+			d.Base.Name, d.Base.Sha256, strings.Join(failed, "\n"))
+	}
+	return nil
+}
+
+func fileSha256(path string) (ArtifactFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return ArtifactFile{}, errors.WithStack(err)
+	}
+	defer f.Close()
+	h := sha256.New()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return ArtifactFile{}, errors.Wrapf(errors.WithStack(err), "read %s failed", path)
+	}
+	return ArtifactFile{Sha256: fmt.Sprintf("%x", h.Sum(nil)), Size: n}, nil
+}
@@ -0,0 +1,151 @@
+/*
+ Copyright 2024 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package artifact
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	coreutil "github.com/kubesys/kubekey/cmd/kk/pkg/core/util"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDelta(t *testing.T) {
+	tmp := t.TempDir()
+
+	// the base artifact
+	baseDir := filepath.Join(tmp, "base")
+	writeFiles(t, baseDir, map[string]string{
+		"images/index.json":            `{"manifests": ["a"]}`,
+		"images/blobs/sha256/aaa":      "layer a",
+		"kube/v1.22.0/amd64/kubeadm":   "kubeadm v1.22.0",
+		"kube/v1.22.0/amd64/kubelet":   "kubelet v1.22.0",
+		"cni/v0.9.1/amd64/cni.tgz":     "cni v0.9.1",
+		"repository/amd64/centos7.iso": "centos7",
+	})
+	base := filepath.Join(tmp, "base.tar.gz")
+	if err := coreutil.Tar(baseDir, base, baseDir); err != nil {
+		t.Fatal(err)
+	}
+
+	// the new artifact
+	newDir := filepath.Join(tmp, "new")
+	newFiles := map[string]string{
+		"images/index.json":            `{"manifests": ["a", "b"]}`,
+		"images/blobs/sha256/aaa":      "layer a",
+		"images/blobs/sha256/bbb":      "layer b",
+		"kube/v1.22.0/amd64/kubeadm":   "kubeadm v1.22.1",
+		"kube/v1.22.0/amd64/kubelet":   "kubelet v1.22.0",
+		"cni/v0.9.1/amd64/cni.tgz":     "cni v0.9.1",
+		"repository/amd64/centos7.iso": "centos7",
+	}
+	writeFiles(t, newDir, newFiles)
+
+	sum, baseFiles, err := ScanArtifact(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantSum, err := fileSha256(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum != wantSum.Sha256 {
+		t.Errorf("ScanArtifact() sha256 = %v, want %v", sum, wantSum.Sha256)
+	}
+
+	d, err := NewDelta(newDir, DeltaBase{Name: "base.tar.gz", Sha256: sum}, baseFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for name := range d.Files {
+		got = append(got, name)
+	}
+	sort.Strings(got)
+	want := []string{"cni/v0.9.1/amd64/cni.tgz", "images/blobs/sha256/aaa", "kube/v1.22.0/amd64/kubelet", "repository/amd64/centos7.iso"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("NewDelta() files = %v, want %v", got, want)
+	}
+
+	if err := d.Save(newDir); err != nil {
+		t.Fatal(err)
+	}
+	deltaArtifact := filepath.Join(tmp, "delta.tar.gz")
+	// ReadDelta only reads the first file, which is after "cni/" if it's not archived first.
+	if err := coreutil.TarWithExclude(newDir, deltaArtifact, newDir, []string{DeltaFileName}, func(name string) bool {
+		_, ok := d.Files[name]
+		return ok
+	}); err != nil {
+		t.Fatal(err)
+	}
+	_, deltaFiles, err := ScanArtifact(deltaArtifact)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deltaFiles) != 4 {
+		t.Errorf("delta artifact has %d files, want 4 (3 changed files and %s)", len(deltaFiles), DeltaFileName)
+	}
+
+	readDelta, err := ReadDelta(deltaArtifact)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(readDelta, d) {
+		t.Errorf("ReadDelta() = %v, want %v", readDelta, d)
+	}
+	if full, err := ReadDelta(base); err != nil || full != nil {
+		t.Errorf("ReadDelta() of a full artifact = %v, %v, want nil", full, err)
+	}
+
+	// compose the base and the delta.
+	workDir := filepath.Join(tmp, "work")
+	if err := readDelta.Check(workDir); err == nil {
+		t.Errorf("Check() without base should fail")
+	}
+	if err := coreutil.Untar(base, workDir); err != nil {
+		t.Fatal(err)
+	}
+	if err := coreutil.Untar(deltaArtifact, workDir); err != nil {
+		t.Fatal(err)
+	}
+	if err := readDelta.Check(workDir); err != nil {
+		t.Errorf("Check() error = %v", err)
+	}
+	for name, content := range newFiles {
+		data, err := os.ReadFile(filepath.Join(workDir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != content {
+			t.Errorf("%s = %q, want %q", name, data, content)
+		}
+	}
+}
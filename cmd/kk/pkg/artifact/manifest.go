@@ -66,6 +66,16 @@ func CreateManifest(arg common.Argument, name string, registry bool) error {
 			Type:    containerStrArr[0],
 			Version: containerStrArr[1],
 		}
+		// the runtime names reported by the node are not the names of the kubekey container managers.
+		switch strings.ToLower(containerRuntime.Type) {
+		case "cri-o":
+			containerRuntime.Type = kubekeyv1alpha2.Crio
+			if !strings.HasPrefix(containerRuntime.Version, "v") {
+				containerRuntime.Version = "v" + containerRuntime.Version
+			}
+		case "isulad":
+			containerRuntime.Type = kubekeyv1alpha2.Isula
+		}
 		if containerRuntime.Type == "containerd" &&
 			versionutil.MustParseSemantic(containerRuntime.Version).LessThan(versionutil.MustParseSemantic("1.6.2")) {
 			containerRuntime.Version = "1.6.2"
@@ -48,6 +48,30 @@ func (r *RepositoryModule) Init() {
 	}
 }
 
+type DeltaModule struct {
+	common.ArtifactModule
+	Skip bool
+}
+
+func (d *DeltaModule) IsSkip() bool {
+	return d.Skip
+}
+
+func (d *DeltaModule) Init() {
+	d.Name = "ArtifactDeltaModule"
+	d.Desc = "Compute the delta against the base artifact"
+
+	delta := &task.LocalTask{
+		Name:   "ComputeArtifactDelta",
+		Desc:   "Compute the files missing from the base artifact",
+		Action: new(ComputeDelta),
+	}
+
+	d.Tasks = []task.Interface{
+		delta,
+	}
+}
+
 type ArchiveModule struct {
 	common.ArtifactModule
 }
@@ -56,6 +80,12 @@ func (a *ArchiveModule) Init() {
 	a.Name = "ArtifactArchiveModule"
 	a.Desc = "Archive the dependencies"
 
+	saveCatalog := &task.LocalTask{
+		Name:   "SaveComponentsCatalog",
+		Desc:   "Save the overlay components catalog into the artifact",
+		Action: new(SaveCatalog),
+	}
+
 	archive := &task.LocalTask{
 		Name:   "ArchiveDependencies",
 		Desc:   "Archive the dependencies",
@@ -63,6 +93,7 @@ func (a *ArchiveModule) Init() {
 	}
 
 	a.Tasks = []task.Interface{
+		saveCatalog,
 		archive,
 	}
 }
@@ -100,9 +131,16 @@ func (u *UnArchiveModule) Init() {
 		Action:  new(CreateMd5File),
 	}
 
+	loadCatalog := &task.LocalTask{
+		Name:   "LoadComponentsCatalog",
+		Desc:   "Load the overlay components catalog in the artifact",
+		Action: new(LoadCatalog),
+	}
+
 	u.Tasks = []task.Interface{
 		md5Check,
 		unArchive,
 		createMd5File,
+		loadCatalog,
 	}
 }
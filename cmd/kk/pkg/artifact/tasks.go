@@ -24,6 +24,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/docker/go-units"
 	"github.com/pkg/errors"
 
 	"github.com/kubesys/kubekey/cmd/kk/pkg/common"
@@ -111,13 +112,61 @@ func (l *LocalCopy) Execute(runtime connector.Runtime) error {
 	return nil
 }
 
+type ComputeDelta struct {
+	common.ArtifactAction
+}
+
+func (c *ComputeDelta) Execute(runtime connector.Runtime) error {
+	base := c.Manifest.Arg.Base
+	logger.Log.Infof("Scan the base artifact %s", base)
+	sum, baseFiles, err := ScanArtifact(base)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Join(runtime.GetWorkDir(), common.Artifact)
+	delta, err := NewDelta(dir, DeltaBase{Name: filepath.Base(base), Sha256: sum}, baseFiles)
+	if err != nil {
+		return err
+	}
+	if err := delta.Save(dir); err != nil {
+		return err
+	}
+	c.PipelineCache.Set(common.ArtifactDelta, delta)
+
+	var size int64
+	for _, f := range delta.Files {
+		size += f.Size
+	}
+	logger.Log.Messagef(common.LocalHost, "%d files (%s) are in the base artifact and skipped", len(delta.Files), units.BytesSize(float64(size)))
+	return nil
+}
+
 type ArchiveDependencies struct {
 	common.ArtifactAction
 }
 
 func (a *ArchiveDependencies) Execute(runtime connector.Runtime) error {
 	src := filepath.Join(runtime.GetWorkDir(), common.Artifact)
-	if err := coreutil.Tar(src, a.Manifest.Arg.Output, src); err != nil {
+
+	var (
+		first   []string
+		exclude func(name string) bool
+	)
+	if v, ok := a.PipelineCache.Get(common.ArtifactDelta); ok {
+		delta := v.(*Delta)
+		// the delta manifest is the first file, ReadDelta does not read the whole artifact.
+		first = []string{DeltaFileName}
+		exclude = func(name string) bool {
+			_, ok := delta.Files[name]
+			return ok
+		}
+	} else if err := os.Remove(filepath.Join(src, DeltaFileName)); err != nil && !os.IsNotExist(err) {
+		// the delta manifest is left by the previous export with --skip-remove-artifact.
+		return errors.Wrapf(errors.WithStack(err), "remove %s failed", DeltaFileName)
+	}
+
+	if err := coreutil.TarWithExclude(src, a.Manifest.Arg.Output, src, first, exclude); err != nil {
 		return errors.Wrapf(errors.WithStack(err), "archive %s failed", src)
 	}
 
@@ -138,8 +187,44 @@ type UnArchive struct {
 }
 
 func (u *UnArchive) Execute(runtime connector.Runtime) error {
-	if err := coreutil.Untar(u.KubeConf.Arg.Artifact, runtime.GetWorkDir()); err != nil {
-		return errors.Wrapf(errors.WithStack(err), "unArchive %s failed", u.KubeConf.Arg.Artifact)
+	artifact, base := u.KubeConf.Arg.Artifact, u.KubeConf.Arg.ArtifactBase
+	delta, err := ReadDelta(artifact)
+	if err != nil {
+		return err
+	}
+	if delta == nil && base != "" {
+		return errors.Errorf("%s is not a delta artifact, but the base artifact %s is specified", artifact, base)
+	}
+
+	// compose the base and the delta: the base is unarchived first, then the delta overwrites it.
+	if delta != nil && base != "" {
+		logger.Log.Infof("Check the base artifact %s", base)
+		sum, _, err := ScanArtifact(base)
+		if err != nil {
+			return err
+		}
+		if sum != delta.Base.Sha256 {
+			return errors.Errorf("sha256 of the base artifact %s is %s, but the delta is exported against %s (sha256 %s)",
+				base, sum, delta.Base.Name, delta.Base.Sha256)
+		}
+		if err := coreutil.Untar(base, runtime.GetWorkDir()); err != nil {
+			return errors.Wrapf(errors.WithStack(err), "unArchive %s failed", base)
+		}
+	}
+
+	if err := coreutil.Untar(artifact, runtime.GetWorkDir()); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "unArchive %s failed", artifact)
+	}
+	if delta == nil {
+		// the delta manifest is left by the previous import.
+		if err := os.Remove(filepath.Join(runtime.GetWorkDir(), DeltaFileName)); err != nil && !os.IsNotExist(err) {
+			return errors.Wrapf(errors.WithStack(err), "remove %s failed", DeltaFileName)
+		}
+		return nil
+	}
+	if base == "" {
+		// the base should have been imported into the work dir.
+		return delta.Check(runtime.GetWorkDir())
 	}
 	return nil
 }
@@ -187,3 +272,47 @@ func (c *CreateMd5File) Execute(runtime connector.Runtime) error {
 	}
 	return nil
 }
+
+type SaveCatalog struct {
+	common.ArtifactAction
+}
+
+func (s *SaveCatalog) Execute(runtime connector.Runtime) error {
+	overlay := files.CatalogOverlay()
+	if overlay == nil {
+		return nil
+	}
+	// the artifact carries the overlay catalog, so that the binaries of versions added by it can be checked offline.
+	path := filepath.Join(runtime.GetWorkDir(), common.Artifact, files.CatalogFileName)
+	if err := os.WriteFile(path, overlay, 0644); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "write components catalog %s failed", path)
+	}
+	// the signature is carried as well, so that the catalog can be verified by the same key when the artifact is used.
+	if signature := files.CatalogSignature(); signature != nil {
+		if err := os.WriteFile(path+files.SignatureSuffix, signature, 0644); err != nil {
+			return errors.Wrapf(errors.WithStack(err), "write components catalog signature %s failed", path+files.SignatureSuffix)
+		}
+	}
+	return nil
+}
+
+type LoadCatalog struct {
+	common.KubeAction
+}
+
+func (l *LoadCatalog) Execute(runtime connector.Runtime) error {
+	path := filepath.Join(runtime.GetWorkDir(), files.CatalogFileName)
+	if !coreutil.IsExist(path) {
+		return nil
+	}
+	loaded, err := files.LoadArtifactCatalog(path)
+	if err != nil {
+		return errors.Wrapf(err, "load components catalog %s failed", path)
+	}
+	if !loaded {
+		logger.Log.Infof("Skip the components catalog in the artifact, the one set by --components-catalog is used")
+		return nil
+	}
+	logger.Log.Infof("Load the components catalog in the artifact")
+	return nil
+}
@@ -43,6 +43,7 @@ func K8sFilesDownloadHTTP(kubeConf *common.KubeConf, path, version, arch string,
 	crictl := files.NewKubeBinary("crictl", arch, kubekeyapiv1alpha2.DefaultCrictlVersion, path, kubeConf.Arg.DownloadCommand)
 	containerd := files.NewKubeBinary("containerd", arch, kubekeyapiv1alpha2.DefaultContainerdVersion, path, kubeConf.Arg.DownloadCommand)
 	runc := files.NewKubeBinary("runc", arch, kubekeyapiv1alpha2.DefaultRuncVersion, path, kubeConf.Arg.DownloadCommand)
+	crio := files.NewKubeBinary("crio", arch, kubekeyapiv1alpha2.DefaultCrioVersion, path, kubeConf.Arg.DownloadCommand)
 	calicoctl := files.NewKubeBinary("calicoctl", arch, kubekeyapiv1alpha2.DefaultCalicoVersion, path, kubeConf.Arg.DownloadCommand)
 
 	buildx := files.NewKubeBinary(common.Buildx, arch, kubekeyapiv1alpha2.DefaultBuildxVersion, path, kubeConf.Arg.DownloadCommand)
@@ -59,6 +60,8 @@ func K8sFilesDownloadHTTP(kubeConf *common.KubeConf, path, version, arch string,
 		}
 	} else if kubeConf.Cluster.Kubernetes.ContainerManager == kubekeyapiv1alpha2.Containerd {
 		binaries = append(binaries, containerd, runc)
+	} else if kubeConf.Cluster.Kubernetes.ContainerManager == kubekeyapiv1alpha2.Crio {
+		binaries = append(binaries, crio)
 	}
 
 	if kubeConf.Cluster.Network.Plugin == "calico" {
@@ -128,6 +131,10 @@ func KubernetesComponentBinariesDownload(manifest *common.ArtifactManifest, path
 
 	containerManagerVersion := make(map[string]struct{})
 	for _, c := range m.Components.ContainerRuntimes {
+		if c.Type == common.Isula {
+			logger.Log.Messagef(common.LocalHost, "skip downloading %s %s, it is installed from the os packages", c.Type, c.Version)
+			continue
+		}
 		if _, ok := containerManagerVersion[c.Type+c.Version]; !ok {
 			containerManagerVersion[c.Type+c.Version] = struct{}{}
 			containerManager := files.NewKubeBinary(c.Type, arch, c.Version, path, manifest.Arg.DownloadCommand)
@@ -210,6 +217,13 @@ func CriDownloadHTTP(kubeConf *common.KubeConf, path, arch string, pipelineCache
 		runc := files.NewKubeBinary("runc", arch, kubekeyapiv1alpha2.DefaultRuncVersion, path, kubeConf.Arg.DownloadCommand)
 		crictl := files.NewKubeBinary("crictl", arch, kubekeyapiv1alpha2.DefaultCrictlVersion, path, kubeConf.Arg.DownloadCommand)
 		binaries = append(binaries, containerd, runc, crictl)
+	case common.Crio:
+		crio := files.NewKubeBinary("crio", arch, kubekeyapiv1alpha2.DefaultCrioVersion, path, kubeConf.Arg.DownloadCommand)
+		binaries = append(binaries, crio)
+	case common.Isula:
+		// isulad is installed from the os packages, only crictl is needed.
+		crictl := files.NewKubeBinary("crictl", arch, kubekeyapiv1alpha2.DefaultCrictlVersion, path, kubeConf.Arg.DownloadCommand)
+		binaries = append(binaries, crictl)
 	default:
 	}
 	binariesMap := make(map[string]*files.KubeBinary)
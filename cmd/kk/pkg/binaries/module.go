@@ -173,6 +173,10 @@ func (i *CriBinariesModule) Init() {
 		i.Tasks = CriBinaries(i)
 	case common.Containerd:
 		i.Tasks = CriBinaries(i)
+	case common.Crio:
+		i.Tasks = CriBinaries(i)
+	case common.Isula:
+		i.Tasks = CriBinaries(i)
 	default:
 	}
 
@@ -38,6 +38,8 @@ type ArtifactArgument struct {
 	ImageStartIndex    int
 	ImageTransport     string
 	SkipRemoveArtifact bool
+	// Base is the artifact which the delta artifact is exported against.
+	Base string
 }
 
 type ArtifactRuntime struct {
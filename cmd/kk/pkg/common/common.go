@@ -83,15 +83,27 @@ const (
 	DesiredK8sVersion      = "desiredK8sVersion"
 	PlanK8sVersion         = "planK8sVersion"
 	NodeK8sVersion         = "NodeK8sVersion"
+	NodeEtcdVersion        = "NodeEtcdVersion"
+	CNIVersion             = "CNIVersion"
+	UpgradePlan            = "upgradePlan"
 
 	// ETCDModule
 	ETCDCluster = "etcdCluster"
 	ETCDName    = "etcdName"
 	ETCDExist   = "etcdExist"
+	// ETCDSnapshot is the local path of the snapshot taken by "kk backup etcd".
+	ETCDSnapshot = "etcdSnapshot"
+	// ETCDSnapshotChecksum is the sha256 of ETCDSnapshot.
+	ETCDSnapshotChecksum = "etcdSnapshotChecksum"
+
+	// ArtifactDelta is the delta of the artifact exported against the base artifact.
+	ArtifactDelta = "artifactDelta"
 
 	// KubernetesModule
 	ClusterStatus = "clusterStatus"
 	ClusterExist  = "clusterExist"
+	// ApplyFailed is set when "kk apply" fails on a node, all nodes are rolled back and the rest nodes are not rolled.
+	ApplyFailed = "applyFailed"
 
 	// CertsModule
 	Certificate   = "certificate"
@@ -50,6 +50,7 @@ type Argument struct {
 	FromCluster         bool
 	KubeConfig          string
 	Artifact            string
+	ArtifactBase        string
 	ImageTransport      string
 	InstallPackages     bool
 	ImagesDir           string
@@ -58,9 +59,11 @@ type Argument struct {
 	Role                string
 	Type                string
 	EtcdUpgrade         bool
+	SkipDrain           bool
 	WithBuildx          bool
 	OnlyEtcd            bool
 	SkipEtcd            bool
+	EtcdSnapshot        string
 }
 
 func NewKubeRuntime(flag string, arg Argument) (*KubeRuntime, error) {
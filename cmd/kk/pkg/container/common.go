@@ -16,6 +16,25 @@
 
 package container
 
+import (
+	kubekeyapiv1alpha2 "github.com/kubesys/kubekey/cmd/kk/apis/kubekey/v1alpha2"
+	"github.com/kubesys/kubekey/cmd/kk/pkg/common"
+)
+
 const (
 	DefaultContainerdCRISocket = "/run/containerd/containerd.sock"
 )
+
+// CriEndpoint returns the default endpoint of the container runtime.
+func CriEndpoint(criType string) string {
+	switch criType {
+	case common.Containerd:
+		return kubekeyapiv1alpha2.DefaultContainerdEndpoint
+	case common.Crio:
+		return kubekeyapiv1alpha2.DefaultCrioEndpoint
+	case common.Isula:
+		return kubekeyapiv1alpha2.DefaultIsulaEndpoint
+	default:
+		return ""
+	}
+}
@@ -226,7 +226,14 @@ func (i *RestartCri) Execute(runtime connector.Runtime) error {
 		if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("systemctl daemon-reload && systemctl restart containerd"), true); err != nil {
 			return errors.Wrap(err, "restart containerd")
 		}
-
+	case common.Crio:
+		if _, err := runtime.GetRunner().SudoCmd("systemctl daemon-reload && systemctl restart crio", true); err != nil {
+			return errors.Wrap(err, "restart crio")
+		}
+	case common.Isula:
+		if _, err := runtime.GetRunner().SudoCmd("systemctl daemon-reload && systemctl restart isulad", true); err != nil {
+			return errors.Wrap(err, "restart isulad")
+		}
 	default:
 		logger.Log.Fatalf("Unsupported container runtime: %s", strings.TrimSpace(i.KubeConf.Arg.Type))
 	}
@@ -251,7 +258,17 @@ func (i *EditKubeletCri) Execute(runtime connector.Runtime) error {
 			true); err != nil {
 			return errors.Wrap(err, "Change KubeletTo Containerd failed")
 		}
-
+	case common.Crio, common.Isula:
+		// replace the endpoint of the previous remote runtime, or switch from the dockershim to the remote runtime.
+		endpoint := CriEndpoint(i.KubeConf.Arg.Type)
+		if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf(
+			"if grep -q -- '--container-runtime-endpoint' %[1]s; "+
+				"then sed -i -E 's#--container-runtime-endpoint=[^ \"]*#--container-runtime-endpoint=%[2]s#' %[1]s; "+
+				"else sed -i 's#--network-plugin=cni --pod#--network-plugin=cni --container-runtime=remote --container-runtime-endpoint=%[2]s --pod#' %[1]s; "+
+				"fi", "/var/lib/kubelet/kubeadm-flags.env", endpoint),
+			true); err != nil {
+			return errors.Wrapf(err, "Change Kubelet To %s failed", i.KubeConf.Arg.Type)
+		}
 	default:
 		logger.Log.Fatalf("Unsupported container runtime: %s", strings.TrimSpace(i.KubeConf.Arg.Type))
 	}
@@ -341,6 +358,30 @@ func MigrateSelfNodeCriTasks(runtime connector.Runtime, kubeAction common.KubeAc
 			Parallel: false,
 		}
 		tasks = append(tasks, CordonNode, DrainNode, Uninstall)
+	case common.Crio:
+		Uninstall := &task.RemoteTask{
+			Name:  "UninstallCrio",
+			Desc:  "Uninstall crio",
+			Hosts: []connector.Host{host},
+			Prepare: &prepare.PrepareCollection{
+				&CrioExist{Not: false},
+			},
+			Action:   new(DisableCrio),
+			Parallel: false,
+		}
+		tasks = append(tasks, CordonNode, DrainNode, Uninstall)
+	case common.Isula:
+		Uninstall := &task.RemoteTask{
+			Name:  "UninstallIsula",
+			Desc:  "Uninstall isulad",
+			Hosts: []connector.Host{host},
+			Prepare: &prepare.PrepareCollection{
+				&IsulaExist{Not: false},
+			},
+			Action:   new(DisableIsula),
+			Parallel: false,
+		}
+		tasks = append(tasks, CordonNode, DrainNode, Uninstall)
 	}
 	if kubeAction.KubeConf.Arg.Type == common.Docker {
 		syncBinaries := &task.RemoteTask{
@@ -502,6 +543,194 @@ func MigrateSelfNodeCriTasks(runtime connector.Runtime, kubeAction common.KubeAc
 		tasks = append(tasks, syncContainerd, syncCrictlBinaries, generateContainerdService, generateContainerdConfig,
 			generateCrictlConfig, enableContainerd, RestartCri, EditKubeletCri, RestartKubeletNode, UnCordonNode)
 	}
+	if kubeAction.KubeConf.Arg.Type == common.Crio {
+		auths := registry.DockerRegistryAuthEntries(kubeAction.KubeConf.Cluster.Registry.Auths)
+
+		syncCrio := &task.RemoteTask{
+			Name:  "SyncCrio",
+			Desc:  "Sync crio binaries",
+			Hosts: []connector.Host{host},
+			Prepare: &prepare.PrepareCollection{
+				&CrioExist{Not: true},
+			},
+			Action:   new(SyncCrio),
+			Parallel: false,
+		}
+
+		generateCrioConfig := &task.RemoteTask{
+			Name:  "GenerateCrioConfig",
+			Desc:  "Generate crio config",
+			Hosts: []connector.Host{host},
+			Prepare: &prepare.PrepareCollection{
+				&CrioExist{Not: true},
+			},
+			Action: &action.Template{
+				Template: templates.CrioConfig,
+				Dst:      filepath.Join("/etc/crio/crio.conf.d", templates.CrioConfig.Name()),
+				Data: util.Data{
+					"SandBoxImage": images.GetImage(runtime, kubeAction.KubeConf, "pause").ImageName(),
+					"Auths":        templates.RegistryAuths(auths),
+					"AuthFile":     templates.CrioAuthFile,
+				},
+			},
+			Parallel: false,
+		}
+
+		generateCrioRegistries := &task.RemoteTask{
+			Name:  "GenerateCrioRegistries",
+			Desc:  "Generate crio registries config",
+			Hosts: []connector.Host{host},
+			Prepare: &prepare.PrepareCollection{
+				&CrioExist{Not: true},
+			},
+			Action: &action.Template{
+				Template: templates.CrioRegistries,
+				Dst:      filepath.Join("/etc/containers/registries.conf.d", templates.CrioRegistries.Name()),
+				Data: util.Data{
+					"Mirrors":            templates.CrioMirrors(kubeAction.KubeConf),
+					"InsecureRegistries": templates.CrioInsecureRegistries(kubeAction.KubeConf.Cluster.Registry.InsecureRegistries, auths),
+				},
+			},
+			Parallel: false,
+		}
+
+		generateCrioAuth := &task.RemoteTask{
+			Name:  "GenerateCrioAuth",
+			Desc:  "Generate crio auth",
+			Hosts: []connector.Host{host},
+			Prepare: &prepare.PrepareCollection{
+				&CrioExist{Not: true},
+				&PrivateRegistryAuth{},
+			},
+			Action: &action.Template{
+				Template: templates.CrioAuth,
+				Dst:      templates.CrioAuthFile,
+				Data: util.Data{
+					"Auths": templates.RegistryAuths(auths),
+				},
+			},
+			Parallel: false,
+		}
+
+		syncCrioRegistryCerts := &task.RemoteTask{
+			Name:  "SyncCrioRegistryCerts",
+			Desc:  "Sync the certs of the private registries",
+			Hosts: []connector.Host{host},
+			Prepare: &prepare.PrepareCollection{
+				&CrioExist{Not: true},
+				&PrivateRegistryAuth{},
+			},
+			Action:   new(SyncCrioRegistryCerts),
+			Parallel: false,
+		}
+
+		generateCrictlConfig := &task.RemoteTask{
+			Name:  "GenerateCrictlConfig",
+			Desc:  "Generate crictl config",
+			Hosts: []connector.Host{host},
+			Prepare: &prepare.PrepareCollection{
+				&CrioExist{Not: true},
+			},
+			Action: &action.Template{
+				Template: templates.CrictlConfig,
+				Dst:      filepath.Join("/etc/", templates.CrictlConfig.Name()),
+				Data: util.Data{
+					"Endpoint": CriEndpoint(common.Crio),
+				},
+			},
+			Parallel: false,
+		}
+
+		enableCrio := &task.RemoteTask{
+			Name:     "EnableCrio",
+			Desc:     "Enable crio",
+			Hosts:    []connector.Host{host},
+			Action:   new(EnableCrio),
+			Parallel: false,
+		}
+		tasks = append(tasks, syncCrio, generateCrioConfig, generateCrioRegistries, generateCrioAuth, syncCrioRegistryCerts,
+			generateCrictlConfig, enableCrio, RestartCri, EditKubeletCri, RestartKubeletNode, UnCordonNode)
+	}
+	if kubeAction.KubeConf.Arg.Type == common.Isula {
+		installIsula := &task.RemoteTask{
+			Name:  "InstallIsula",
+			Desc:  "Install isulad package",
+			Hosts: []connector.Host{host},
+			Prepare: &prepare.PrepareCollection{
+				&IsulaExist{Not: true},
+			},
+			Action:   new(InstallIsulaPackage),
+			Parallel: false,
+		}
+
+		generateIsulaConfig := &task.RemoteTask{
+			Name:  "GenerateIsulaConfig",
+			Desc:  "Generate isulad config",
+			Hosts: []connector.Host{host},
+			Prepare: &prepare.PrepareCollection{
+				&IsulaExist{Not: true},
+			},
+			Action: &action.Template{
+				Template: templates.IsulaConfig,
+				Dst:      filepath.Join("/etc/isulad", templates.IsulaConfig.Name()),
+				Data: util.Data{
+					"Mirrors":            templates.IsulaMirrors(kubeAction.KubeConf),
+					"InsecureRegistries": kubeAction.KubeConf.Cluster.Registry.InsecureRegistries,
+					"SandBoxImage":       images.GetImage(runtime, kubeAction.KubeConf, "pause").ImageName(),
+				},
+			},
+			Parallel: false,
+		}
+
+		syncCrictlBinaries := &task.RemoteTask{
+			Name:  "SyncCrictlBinaries",
+			Desc:  "Sync crictl binaries",
+			Hosts: []connector.Host{host},
+			Prepare: &prepare.PrepareCollection{
+				&CrictlExist{Not: true},
+			},
+			Action:   new(SyncCrictlBinaries),
+			Parallel: false,
+		}
+
+		generateCrictlConfig := &task.RemoteTask{
+			Name:  "GenerateCrictlConfig",
+			Desc:  "Generate crictl config",
+			Hosts: []connector.Host{host},
+			Prepare: &prepare.PrepareCollection{
+				&IsulaExist{Not: true},
+			},
+			Action: &action.Template{
+				Template: templates.CrictlConfig,
+				Dst:      filepath.Join("/etc/", templates.CrictlConfig.Name()),
+				Data: util.Data{
+					"Endpoint": CriEndpoint(common.Isula),
+				},
+			},
+			Parallel: false,
+		}
+
+		enableIsula := &task.RemoteTask{
+			Name:     "EnableIsula",
+			Desc:     "Enable isulad",
+			Hosts:    []connector.Host{host},
+			Action:   new(EnableIsula),
+			Parallel: false,
+		}
+
+		isulaLoginRegistry := &task.RemoteTask{
+			Name:  "Login PrivateRegistry",
+			Desc:  "Add auths to container runtime",
+			Hosts: []connector.Host{host},
+			Prepare: &prepare.PrepareCollection{
+				&PrivateRegistryAuth{},
+			},
+			Action:   new(IsulaLoginRegistry),
+			Parallel: false,
+		}
+		tasks = append(tasks, installIsula, syncCrictlBinaries, generateIsulaConfig, generateCrictlConfig, enableIsula, isulaLoginRegistry,
+			RestartCri, EditKubeletCri, RestartKubeletNode, UnCordonNode)
+	}
 
 	for i := range tasks {
 		t := tasks[i]
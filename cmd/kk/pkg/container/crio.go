@@ -0,0 +1,137 @@
+/*
+ Copyright 2024 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package container
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	"github.com/kubesys/kubekey/cmd/kk/pkg/common"
+	"github.com/kubesys/kubekey/cmd/kk/pkg/container/templates"
+	"github.com/kubesys/kubekey/cmd/kk/pkg/core/connector"
+	"github.com/kubesys/kubekey/cmd/kk/pkg/files"
+	"github.com/kubesys/kubekey/cmd/kk/pkg/registry"
+	"github.com/kubesys/kubekey/cmd/kk/pkg/utils"
+)
+
+// crioInstallCmd installs the runtime binaries, the default configs and the systemd unit of the static bundle of cri-o.
+// The install script of the bundle is not used, it also overwrites the cni plugins in /opt/cni/bin and installs a bridge
+// cni config which shadows the network plugin of the cluster.
+const crioInstallCmd = "install -m 755 bin/* /usr/local/bin/ && " +
+	"install -D -m 644 contrib/crio.service /usr/local/lib/systemd/system/crio.service && " +
+	"install -D -m 644 etc/crio.conf /etc/crio/crio.conf && " +
+	"install -D -m 644 etc/10-crun.conf /etc/crio/crio.conf.d/10-crun.conf && " +
+	"([ -f /etc/containers/policy.json ] || install -D -m 644 contrib/policy.json /etc/containers/policy.json)"
+
+type SyncCrio struct {
+	common.KubeAction
+}
+
+func (s *SyncCrio) Execute(runtime connector.Runtime) error {
+	if err := utils.ResetTmpDir(runtime); err != nil {
+		return err
+	}
+
+	binariesMapObj, ok := s.PipelineCache.Get(common.KubeBinaries + "-" + runtime.RemoteHost().GetArch())
+	if !ok {
+		return errors.New("get KubeBinary by pipeline cache failed")
+	}
+	binariesMap := binariesMapObj.(map[string]*files.KubeBinary)
+
+	crio, ok := binariesMap[common.Crio]
+	if !ok {
+		return errors.New("get KubeBinary key crio by pipeline cache failed")
+	}
+
+	dst := filepath.Join(common.TmpDir, crio.FileName)
+	if err := runtime.GetRunner().Scp(crio.Path(), dst); err != nil {
+		return errors.Wrap(errors.WithStack(err), fmt.Sprintf("sync crio binaries failed"))
+	}
+
+	if _, err := runtime.GetRunner().SudoCmd(
+		fmt.Sprintf("cd %s && tar -zxf %s && cd cri-o && %s && cd .. && rm -rf cri-o", common.TmpDir, dst, crioInstallCmd),
+		false); err != nil {
+		return errors.Wrap(errors.WithStack(err), fmt.Sprintf("install crio binaries failed"))
+	}
+	return nil
+}
+
+type SyncCrioRegistryCerts struct {
+	common.KubeAction
+}
+
+func (s *SyncCrioRegistryCerts) Execute(runtime connector.Runtime) error {
+	auths := registry.DockerRegistryAuthEntries(s.KubeConf.Cluster.Registry.Auths)
+	for repo, entry := range auths {
+		if entry.CertsPath == "" {
+			continue
+		}
+		dst := filepath.Join(templates.CrioCertsDir, repo)
+		if err := runtime.GetRunner().SudoScp(entry.CertsPath, dst); err != nil {
+			return errors.Wrap(errors.WithStack(err), fmt.Sprintf("sync the certs of registry %s failed", repo))
+		}
+	}
+	return nil
+}
+
+type EnableCrio struct {
+	common.KubeAction
+}
+
+func (e *EnableCrio) Execute(runtime connector.Runtime) error {
+	if _, err := runtime.GetRunner().SudoCmd(
+		"systemctl daemon-reload && systemctl enable crio && systemctl start crio",
+		false); err != nil {
+		return errors.Wrap(errors.WithStack(err), fmt.Sprintf("enable and start crio failed"))
+	}
+	return nil
+}
+
+type DisableCrio struct {
+	common.KubeAction
+}
+
+func (d *DisableCrio) Execute(runtime connector.Runtime) error {
+	if _, err := runtime.GetRunner().SudoCmd(
+		"systemctl disable crio && systemctl stop crio", true); err != nil {
+		return errors.Wrap(errors.WithStack(err), fmt.Sprintf("disable and stop crio failed"))
+	}
+
+	// remove crio related files
+	files := []string{
+		"/usr/local/bin/crio*",
+		"/usr/local/bin/pinns",
+		"/usr/local/bin/conmon*",
+		"/usr/local/bin/crun",
+		"/usr/local/bin/runc",
+		"/usr/local/bin/crictl",
+		"/usr/local/lib/systemd/system/crio.service",
+		"/etc/crio",
+		filepath.Join("/etc/containers/registries.conf.d", templates.CrioRegistries.Name()),
+		templates.CrioCertsDir,
+		filepath.Join("/etc", templates.CrictlConfig.Name()),
+		"/var/lib/crio",
+		"/var/lib/containers/storage",
+	}
+
+	for _, file := range files {
+		_, _ = runtime.GetRunner().SudoCmd(fmt.Sprintf("rm -rf %s", file), true)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+/*
+ Copyright 2024 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package container
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	"github.com/kubesys/kubekey/cmd/kk/pkg/common"
+	"github.com/kubesys/kubekey/cmd/kk/pkg/container/templates"
+	"github.com/kubesys/kubekey/cmd/kk/pkg/core/connector"
+	"github.com/kubesys/kubekey/cmd/kk/pkg/registry"
+)
+
+type InstallIsulaPackage struct {
+	common.KubeAction
+}
+
+// Execute installs isulad from the package repositories of the node, there is no static release of isulad.
+// The repositories can be the iso repository of openEuler which is synced by '--with-packages'.
+func (i *InstallIsulaPackage) Execute(runtime connector.Runtime) error {
+	if _, err := runtime.GetRunner().SudoCmd(
+		"if command -v dnf > /dev/null; then dnf install -y iSulad; "+
+			"elif command -v yum > /dev/null; then yum install -y iSulad; "+
+			"else echo 'no supported package manager found' && exit 1; fi",
+		false); err != nil {
+		return errors.Wrap(errors.WithStack(err), fmt.Sprintf("install isulad package failed, please make sure the iSulad package is available in the repositories of node %s", runtime.RemoteHost().GetName()))
+	}
+	return nil
+}
+
+type EnableIsula struct {
+	common.KubeAction
+}
+
+func (e *EnableIsula) Execute(runtime connector.Runtime) error {
+	if _, err := runtime.GetRunner().SudoCmd(
+		"systemctl daemon-reload && systemctl enable isulad && systemctl restart isulad",
+		false); err != nil {
+		return errors.Wrap(errors.WithStack(err), fmt.Sprintf("enable and start isulad failed"))
+	}
+	return nil
+}
+
+type IsulaLoginRegistry struct {
+	common.KubeAction
+}
+
+func (p *IsulaLoginRegistry) Execute(runtime connector.Runtime) error {
+	auths := registry.DockerRegistryAuthEntries(p.KubeConf.Cluster.Registry.Auths)
+
+	for repo, entry := range auths {
+		if len(entry.Username) == 0 || len(entry.Password) == 0 {
+			continue
+		}
+		cmd := fmt.Sprintf("isula login --username '%s' --password '%s' %s", escapeSpecialCharacters(entry.Username), escapeSpecialCharacters(entry.Password), repo)
+		if _, err := runtime.GetRunner().SudoCmd(cmd, false); err != nil {
+			return errors.Wrapf(err, "login registry %s failed", repo)
+		}
+	}
+	return nil
+}
+
+type DisableIsula struct {
+	common.KubeAction
+}
+
+func (d *DisableIsula) Execute(runtime connector.Runtime) error {
+	if _, err := runtime.GetRunner().SudoCmd(
+		"systemctl disable isulad && systemctl stop isulad", true); err != nil {
+		return errors.Wrap(errors.WithStack(err), fmt.Sprintf("disable and stop isulad failed"))
+	}
+
+	if _, err := runtime.GetRunner().SudoCmd(
+		"if command -v dnf > /dev/null; then dnf remove -y iSulad; "+
+			"elif command -v yum > /dev/null; then yum remove -y iSulad; fi",
+		true); err != nil {
+		return errors.Wrap(errors.WithStack(err), fmt.Sprintf("remove isulad package failed"))
+	}
+
+	// remove isulad related files
+	files := []string{
+		filepath.Join("/etc/isulad", templates.IsulaConfig.Name()),
+		filepath.Join("/etc", templates.CrictlConfig.Name()),
+		"/var/lib/isulad",
+	}
+
+	for _, file := range files {
+		_, _ = runtime.GetRunner().SudoCmd(fmt.Sprintf("rm -rf %s", file), true)
+	}
+	return nil
+}
@@ -51,9 +51,9 @@ func (i *InstallContainerModule) Init() {
 	case common.Containerd:
 		i.Tasks = InstallContainerd(i)
 	case common.Crio:
-		// TODO: Add the steps of cri-o's installation.
+		i.Tasks = InstallCrio(i)
 	case common.Isula:
-		// TODO: Add the steps of iSula's installation.
+		i.Tasks = InstallIsula(i)
 	default:
 		logger.Log.Fatalf("Unsupported container runtime: %s", strings.TrimSpace(i.KubeConf.Cluster.Kubernetes.ContainerManager))
 	}
@@ -312,6 +312,234 @@ func InstallContainerd(m *InstallContainerModule) []task.Interface {
 	return tasks
 }
 
+func InstallCrio(m *InstallContainerModule) []task.Interface {
+	auths := registry.DockerRegistryAuthEntries(m.KubeConf.Cluster.Registry.Auths)
+
+	syncCrio := &task.RemoteTask{
+		Name:  "SyncCrio",
+		Desc:  "Sync crio binaries",
+		Hosts: m.Runtime.GetHostsByRole(common.K8s),
+		Prepare: &prepare.PrepareCollection{
+			&kubernetes.NodeInCluster{Not: true},
+			&CrioExist{Not: true},
+		},
+		Action:   new(SyncCrio),
+		Parallel: true,
+		Retry:    2,
+	}
+
+	generateCrioConfig := &task.RemoteTask{
+		Name:  "GenerateCrioConfig",
+		Desc:  "Generate crio config",
+		Hosts: m.Runtime.GetHostsByRole(common.K8s),
+		Prepare: &prepare.PrepareCollection{
+			&kubernetes.NodeInCluster{Not: true},
+			&CrioExist{Not: true},
+		},
+		Action: &action.Template{
+			Template: templates.CrioConfig,
+			Dst:      filepath.Join("/etc/crio/crio.conf.d", templates.CrioConfig.Name()),
+			Data: util.Data{
+				"SandBoxImage": images.GetImage(m.Runtime, m.KubeConf, "pause").ImageName(),
+				"Auths":        templates.RegistryAuths(auths),
+				"AuthFile":     templates.CrioAuthFile,
+			},
+		},
+		Parallel: true,
+	}
+
+	generateCrioRegistries := &task.RemoteTask{
+		Name:  "GenerateCrioRegistries",
+		Desc:  "Generate crio registries config",
+		Hosts: m.Runtime.GetHostsByRole(common.K8s),
+		Prepare: &prepare.PrepareCollection{
+			&kubernetes.NodeInCluster{Not: true},
+			&CrioExist{Not: true},
+		},
+		Action: &action.Template{
+			Template: templates.CrioRegistries,
+			Dst:      filepath.Join("/etc/containers/registries.conf.d", templates.CrioRegistries.Name()),
+			Data: util.Data{
+				"Mirrors":            templates.CrioMirrors(m.KubeConf),
+				"InsecureRegistries": templates.CrioInsecureRegistries(m.KubeConf.Cluster.Registry.InsecureRegistries, auths),
+			},
+		},
+		Parallel: true,
+	}
+
+	generateCrioAuth := &task.RemoteTask{
+		Name:  "GenerateCrioAuth",
+		Desc:  "Generate crio auth",
+		Hosts: m.Runtime.GetHostsByRole(common.K8s),
+		Prepare: &prepare.PrepareCollection{
+			&kubernetes.NodeInCluster{Not: true},
+			&CrioExist{Not: true},
+			&PrivateRegistryAuth{},
+		},
+		Action: &action.Template{
+			Template: templates.CrioAuth,
+			Dst:      templates.CrioAuthFile,
+			Data: util.Data{
+				"Auths": templates.RegistryAuths(auths),
+			},
+		},
+		Parallel: true,
+	}
+
+	syncCrioRegistryCerts := &task.RemoteTask{
+		Name:  "SyncCrioRegistryCerts",
+		Desc:  "Sync the certs of the private registries",
+		Hosts: m.Runtime.GetHostsByRole(common.K8s),
+		Prepare: &prepare.PrepareCollection{
+			&kubernetes.NodeInCluster{Not: true},
+			&CrioExist{Not: true},
+			&PrivateRegistryAuth{},
+		},
+		Action:   new(SyncCrioRegistryCerts),
+		Parallel: true,
+	}
+
+	enableCrio := &task.RemoteTask{
+		Name:  "EnableCrio",
+		Desc:  "Enable crio",
+		Hosts: m.Runtime.GetHostsByRole(common.K8s),
+		Prepare: &prepare.PrepareCollection{
+			&kubernetes.NodeInCluster{Not: true},
+			&CrioExist{Not: true},
+		},
+		Action:   new(EnableCrio),
+		Parallel: true,
+	}
+
+	generateCrictlConfig := &task.RemoteTask{
+		Name:  "GenerateCrictlConfig",
+		Desc:  "Generate crictl config",
+		Hosts: m.Runtime.GetHostsByRole(common.K8s),
+		Prepare: &prepare.PrepareCollection{
+			&kubernetes.NodeInCluster{Not: true},
+			&CrictlExist{Not: false},
+		},
+		Action: &action.Template{
+			Template: templates.CrictlConfig,
+			Dst:      filepath.Join("/etc/", templates.CrictlConfig.Name()),
+			Data: util.Data{
+				"Endpoint": m.KubeConf.Cluster.Kubernetes.ContainerRuntimeEndpoint,
+			},
+		},
+		Parallel: true,
+	}
+
+	return []task.Interface{
+		syncCrio,
+		generateCrioConfig,
+		generateCrioRegistries,
+		generateCrioAuth,
+		syncCrioRegistryCerts,
+		enableCrio,
+		generateCrictlConfig,
+	}
+}
+
+func InstallIsula(m *InstallContainerModule) []task.Interface {
+	installIsula := &task.RemoteTask{
+		Name:  "InstallIsula",
+		Desc:  "Install isulad package",
+		Hosts: m.Runtime.GetHostsByRole(common.K8s),
+		Prepare: &prepare.PrepareCollection{
+			&kubernetes.NodeInCluster{Not: true},
+			&IsulaExist{Not: true},
+		},
+		Action:   new(InstallIsulaPackage),
+		Parallel: true,
+		Retry:    2,
+	}
+
+	generateIsulaConfig := &task.RemoteTask{
+		Name:  "GenerateIsulaConfig",
+		Desc:  "Generate isulad config",
+		Hosts: m.Runtime.GetHostsByRole(common.K8s),
+		Prepare: &prepare.PrepareCollection{
+			&kubernetes.NodeInCluster{Not: true},
+			&IsulaExist{Not: true},
+		},
+		Action: &action.Template{
+			Template: templates.IsulaConfig,
+			Dst:      filepath.Join("/etc/isulad", templates.IsulaConfig.Name()),
+			Data: util.Data{
+				"Mirrors":            templates.IsulaMirrors(m.KubeConf),
+				"InsecureRegistries": m.KubeConf.Cluster.Registry.InsecureRegistries,
+				"SandBoxImage":       images.GetImage(m.Runtime, m.KubeConf, "pause").ImageName(),
+			},
+		},
+		Parallel: true,
+	}
+
+	enableIsula := &task.RemoteTask{
+		Name:  "EnableIsula",
+		Desc:  "Enable isulad",
+		Hosts: m.Runtime.GetHostsByRole(common.K8s),
+		Prepare: &prepare.PrepareCollection{
+			&kubernetes.NodeInCluster{Not: true},
+			&IsulaExist{Not: true},
+		},
+		Action:   new(EnableIsula),
+		Parallel: true,
+	}
+
+	isulaLoginRegistry := &task.RemoteTask{
+		Name:  "Login PrivateRegistry",
+		Desc:  "Add auths to container runtime",
+		Hosts: m.Runtime.GetHostsByRole(common.K8s),
+		Prepare: &prepare.PrepareCollection{
+			&kubernetes.NodeInCluster{Not: true},
+			&IsulaExist{},
+			&PrivateRegistryAuth{},
+		},
+		Action:   new(IsulaLoginRegistry),
+		Parallel: true,
+	}
+
+	syncCrictlBinaries := &task.RemoteTask{
+		Name:  "SyncCrictlBinaries",
+		Desc:  "Sync crictl binaries",
+		Hosts: m.Runtime.GetHostsByRole(common.K8s),
+		Prepare: &prepare.PrepareCollection{
+			&kubernetes.NodeInCluster{Not: true},
+			&CrictlExist{Not: true},
+		},
+		Action:   new(SyncCrictlBinaries),
+		Parallel: true,
+		Retry:    2,
+	}
+
+	generateCrictlConfig := &task.RemoteTask{
+		Name:  "GenerateCrictlConfig",
+		Desc:  "Generate crictl config",
+		Hosts: m.Runtime.GetHostsByRole(common.K8s),
+		Prepare: &prepare.PrepareCollection{
+			&kubernetes.NodeInCluster{Not: true},
+			&CrictlExist{Not: false},
+		},
+		Action: &action.Template{
+			Template: templates.CrictlConfig,
+			Dst:      filepath.Join("/etc/", templates.CrictlConfig.Name()),
+			Data: util.Data{
+				"Endpoint": m.KubeConf.Cluster.Kubernetes.ContainerRuntimeEndpoint,
+			},
+		},
+		Parallel: true,
+	}
+
+	return []task.Interface{
+		installIsula,
+		generateIsulaConfig,
+		enableIsula,
+		isulaLoginRegistry,
+		syncCrictlBinaries,
+		generateCrictlConfig,
+	}
+}
+
 type InstallCriDockerdModule struct {
 	common.KubeModule
 	Skip bool
@@ -426,9 +654,9 @@ func (i *UninstallContainerModule) Init() {
 	case common.Containerd:
 		i.Tasks = UninstallContainerd(i)
 	case common.Crio:
-		// TODO: Add the steps of cri-o's installation.
+		i.Tasks = UninstallCrio(i)
 	case common.Isula:
-		// TODO: Add the steps of iSula's installation.
+		i.Tasks = UninstallIsula(i)
 	default:
 		logger.Log.Fatalf("Unsupported container runtime: %s", strings.TrimSpace(i.KubeConf.Cluster.Kubernetes.ContainerManager))
 	}
@@ -469,6 +697,40 @@ func UninstallContainerd(m *UninstallContainerModule) []task.Interface {
 	}
 }
 
+func UninstallCrio(m *UninstallContainerModule) []task.Interface {
+	disableCrio := &task.RemoteTask{
+		Name:  "UninstallCrio",
+		Desc:  "Uninstall crio",
+		Hosts: m.Runtime.GetHostsByRole(common.K8s),
+		Prepare: &prepare.PrepareCollection{
+			&CrioExist{Not: false},
+		},
+		Action:   new(DisableCrio),
+		Parallel: true,
+	}
+
+	return []task.Interface{
+		disableCrio,
+	}
+}
+
+func UninstallIsula(m *UninstallContainerModule) []task.Interface {
+	disableIsula := &task.RemoteTask{
+		Name:  "UninstallIsula",
+		Desc:  "Uninstall isulad",
+		Hosts: m.Runtime.GetHostsByRole(common.K8s),
+		Prepare: &prepare.PrepareCollection{
+			&IsulaExist{Not: false},
+		},
+		Action:   new(DisableIsula),
+		Parallel: true,
+	}
+
+	return []task.Interface{
+		disableIsula,
+	}
+}
+
 type CriMigrateModule struct {
 	common.KubeModule
 
@@ -0,0 +1,164 @@
+/*
+ Copyright 2024 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package templates
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/lithammer/dedent"
+
+	"github.com/kubesys/kubekey/cmd/kk/pkg/common"
+	"github.com/kubesys/kubekey/cmd/kk/pkg/registry"
+)
+
+const (
+	// CrioAuthFile is the global auth file of cri-o, it uses the format of the docker config.json.
+	CrioAuthFile = "/etc/crio/auth.json"
+	// CrioCertsDir is the directory which cri-o looks up the certificates of the registries from.
+	CrioCertsDir = "/etc/containers/certs.d"
+)
+
+// CrioConfig is a drop-in of crio.conf, it only overrides the options managed by kubekey.
+var CrioConfig = template.Must(template.New("10-kubekey.conf").Parse(
+	dedent.Dedent(`[crio.runtime]
+cgroup_manager = "systemd"
+conmon_cgroup = "pod"
+
+[crio.image]
+pause_image = "{{ .SandBoxImage }}"
+{{- if .Auths }}
+global_auth_file = "{{ .AuthFile }}"
+{{- end }}
+
+[crio.network]
+network_dir = "/etc/cni/net.d/"
+plugin_dirs = ["/opt/cni/bin/"]
+    `)))
+
+// CrioRegistries is a drop-in of the containers-registries.conf (v2 format) used by cri-o.
+var CrioRegistries = template.Must(template.New("10-kubekey.conf").Parse(
+	dedent.Dedent(`unqualified-search-registries = ["docker.io"]
+
+[[registry]]
+prefix = "docker.io"
+location = "registry-1.docker.io"
+{{- range .Mirrors }}
+
+[[registry.mirror]]
+location = "{{ .Location }}"
+{{- if .Insecure }}
+insecure = true
+{{- end }}
+{{- end }}
+{{- range .InsecureRegistries }}
+
+[[registry]]
+location = "{{ . }}"
+insecure = true
+{{- end }}
+    `)))
+
+// CrioAuth is the auth file of the private registries, it is rendered only when the auths are configured.
+var CrioAuth = template.Must(template.New("auth.json").Parse(
+	dedent.Dedent(`{
+  "auths": {
+    {{- range $i, $auth := .Auths }}
+    {{- if $i }},{{ end }}
+    "{{ $auth.Registry }}": {
+      "auth": "{{ $auth.Auth }}"
+    }
+    {{- end }}
+  }
+}
+    `)))
+
+// CrioMirror is a mirror of docker.io in the registries.conf.
+type CrioMirror struct {
+	Location string
+	Insecure bool
+}
+
+// RegistryAuth is the credential of a registry encoded as the "auth" field of docker config.json.
+type RegistryAuth struct {
+	Registry string
+	Auth     string
+}
+
+// CrioMirrors converts the registry mirrors to the mirror locations of registries.conf, which must not contain the scheme.
+func CrioMirrors(kubeConf *common.KubeConf) []CrioMirror {
+	var mirrors []CrioMirror
+	for _, mirror := range kubeConf.Cluster.Registry.RegistryMirrors {
+		location, insecure := trimScheme(mirror)
+		if location == "" {
+			continue
+		}
+		mirrors = append(mirrors, CrioMirror{Location: location, Insecure: insecure})
+	}
+	return mirrors
+}
+
+// CrioInsecureRegistries returns the sorted registries which are insecure or configured to skip the tls verification.
+func CrioInsecureRegistries(insecureRegistries []string, auths map[string]*registry.DockerRegistryEntry) []string {
+	set := make(map[string]struct{})
+	for _, repo := range insecureRegistries {
+		set[repo] = struct{}{}
+	}
+	for repo, entry := range auths {
+		if entry.SkipTLSVerify {
+			set[repo] = struct{}{}
+		}
+	}
+	// docker.io has been defined with its mirrors, registries.conf refuses to define it twice.
+	delete(set, "docker.io")
+	delete(set, "")
+
+	registries := make([]string, 0, len(set))
+	for repo := range set {
+		registries = append(registries, repo)
+	}
+	sort.Strings(registries)
+	return registries
+}
+
+// RegistryAuths returns the sorted credentials of the registries, the entries without username or password are ignored.
+func RegistryAuths(auths map[string]*registry.DockerRegistryEntry) []RegistryAuth {
+	var res []RegistryAuth
+	for repo, entry := range auths {
+		if len(entry.Username) == 0 || len(entry.Password) == 0 {
+			continue
+		}
+		res = append(res, RegistryAuth{
+			Registry: repo,
+			Auth:     base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s", entry.Username, entry.Password))),
+		})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Registry < res[j].Registry
+	})
+	return res
+}
+
+func trimScheme(url string) (string, bool) {
+	url = strings.TrimSpace(url)
+	insecure := strings.HasPrefix(url, "http://")
+	url = strings.TrimPrefix(strings.TrimPrefix(url, "http://"), "https://")
+	return strings.TrimSuffix(url, "/"), insecure
+}
@@ -0,0 +1,135 @@
+/*
+ Copyright 2024 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package templates
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	kubekeyapiv1alpha2 "github.com/kubesys/kubekey/cmd/kk/apis/kubekey/v1alpha2"
+	"github.com/kubesys/kubekey/cmd/kk/pkg/common"
+	"github.com/kubesys/kubekey/cmd/kk/pkg/core/util"
+	"github.com/kubesys/kubekey/cmd/kk/pkg/registry"
+)
+
+func TestCrioMirrors(t *testing.T) {
+	kubeConf := &common.KubeConf{Cluster: &kubekeyapiv1alpha2.ClusterSpec{}}
+	kubeConf.Cluster.Registry.RegistryMirrors = []string{"https://mirror.example.com/", "http://10.0.0.1:5000", " "}
+
+	want := []CrioMirror{
+		{Location: "mirror.example.com"},
+		{Location: "10.0.0.1:5000", Insecure: true},
+	}
+	if got := CrioMirrors(kubeConf); !reflect.DeepEqual(got, want) {
+		t.Errorf("CrioMirrors() = %v, want %v", got, want)
+	}
+}
+
+func TestCrioInsecureRegistries(t *testing.T) {
+	tests := []struct {
+		name     string
+		insecure []string
+		auths    map[string]*registry.DockerRegistryEntry
+		want     []string
+	}{
+		{
+			name: "none",
+			want: []string{},
+		},
+		{
+			name:     "merge skip tls verify auths",
+			insecure: []string{"b.local", "docker.io"},
+			auths: map[string]*registry.DockerRegistryEntry{
+				"a.local": {SkipTLSVerify: true},
+				"b.local": {SkipTLSVerify: true},
+				"c.local": {},
+			},
+			want: []string{"a.local", "b.local"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CrioInsecureRegistries(tt.insecure, tt.auths); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CrioInsecureRegistries() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCrioAuth(t *testing.T) {
+	auths := RegistryAuths(map[string]*registry.DockerRegistryEntry{
+		"b.local": {Username: "admin", Password: "pass"},
+		"a.local": {Username: "user", Password: "p@ss:word"},
+		"c.local": {Username: "anonymous"},
+	})
+
+	var buf bytes.Buffer
+	if err := CrioAuth.Execute(&buf, util.Data{"Auths": auths}); err != nil {
+		t.Fatal(err)
+	}
+
+	var got struct {
+		Auths map[string]struct {
+			Auth string `json:"auth"`
+		} `json:"auths"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("the rendered auth file is not a valid json: %v\n%s", err, buf.String())
+	}
+	want := map[string]string{
+		"a.local": "dXNlcjpwQHNzOndvcmQ=",
+		"b.local": "YWRtaW46cGFzcw==",
+	}
+	if len(got.Auths) != len(want) {
+		t.Fatalf("got %d auths, want %d", len(got.Auths), len(want))
+	}
+	for repo, auth := range want {
+		if got.Auths[repo].Auth != auth {
+			t.Errorf("auth of %s = %s, want %s", repo, got.Auths[repo].Auth, auth)
+		}
+	}
+}
+
+func TestIsulaConfig(t *testing.T) {
+	kubeConf := &common.KubeConf{Cluster: &kubekeyapiv1alpha2.ClusterSpec{}}
+	kubeConf.Cluster.Registry.RegistryMirrors = []string{"https://a.example.com", "https://b.example.com"}
+
+	var buf bytes.Buffer
+	if err := IsulaConfig.Execute(&buf, util.Data{
+		"Mirrors":            IsulaMirrors(kubeConf),
+		"InsecureRegistries": []string{"dockerhub.kubekey.local"},
+		"SandBoxImage":       "kubesphere/pause:3.9",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	var got struct {
+		Mirrors  []string `json:"registry-mirrors"`
+		Insecure []string `json:"insecure-registries"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("the rendered daemon.json is not a valid json: %v\n%s", err, buf.String())
+	}
+	if want := []string{"a.example.com", "b.example.com"}; !reflect.DeepEqual(got.Mirrors, want) {
+		t.Errorf("registry-mirrors = %v, want %v", got.Mirrors, want)
+	}
+	if want := []string{"dockerhub.kubekey.local"}; !reflect.DeepEqual(got.Insecure, want) {
+		t.Errorf("insecure-registries = %v, want %v", got.Insecure, want)
+	}
+}
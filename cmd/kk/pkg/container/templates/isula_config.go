@@ -0,0 +1,84 @@
+/*
+ Copyright 2024 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package templates
+
+import (
+	"text/template"
+
+	"github.com/lithammer/dedent"
+
+	"github.com/kubesys/kubekey/cmd/kk/pkg/common"
+)
+
+// IsulaConfig is the daemon.json of isulad, it enables the cri of isulad with the cni network plugin.
+var IsulaConfig = template.Must(template.New("daemon.json").Parse(
+	dedent.Dedent(`{
+  "group": "isula",
+  "graph": "/var/lib/isulad",
+  "state": "/var/run/isulad",
+  "log-level": "ERROR",
+  "pidfile": "/var/run/isulad.pid",
+  "log-opts": {
+    "log-file-mode": "0600",
+    "log-path": "/var/lib/isulad",
+    "max-file": "1",
+    "max-size": "30KB"
+  },
+  "log-driver": "stdout",
+  "container-log": {
+    "driver": "json-file"
+  },
+  "hook-spec": "/etc/default/isulad/hooks/default.json",
+  "start-timeout": "2m",
+  "storage-driver": "overlay2",
+  "storage-opts": [
+    "overlay2.override_kernel_check=true"
+  ],
+  "registry-mirrors": [
+    {{- range $i, $mirror := .Mirrors }}
+    {{- if $i }},{{ end }}
+    "{{ $mirror }}"
+    {{- end }}
+  ],
+  "insecure-registries": [
+    {{- range $i, $repo := .InsecureRegistries }}
+    {{- if $i }},{{ end }}
+    "{{ $repo }}"
+    {{- end }}
+  ],
+  "pod-sandbox-image": "{{ .SandBoxImage }}",
+  "native.umask": "secure",
+  "network-plugin": "cni",
+  "cni-bin-dir": "/opt/cni/bin",
+  "cni-conf-dir": "/etc/cni/net.d",
+  "image-layer-check": false,
+  "use-decrypted-key": true,
+  "insecure-skip-verify-enforce": false,
+  "enable-cri-v1": true
+}
+    `)))
+
+// IsulaMirrors returns the registry mirrors without the scheme, isulad only accepts the host of the mirrors.
+func IsulaMirrors(kubeConf *common.KubeConf) []string {
+	var mirrors []string
+	for _, mirror := range kubeConf.Cluster.Registry.RegistryMirrors {
+		if location, _ := trimScheme(mirror); location != "" {
+			mirrors = append(mirrors, location)
+		}
+	}
+	return mirrors
+}
@@ -20,17 +20,23 @@ import (
 	"sync"
 	"time"
 
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+
 	"github.com/kubesys/kubekey/cmd/kk/pkg/core/logger"
 )
 
 type Dialer struct {
 	lock        sync.Mutex
 	connections map[string]Connection
+	// bastions caches the clients of the bastion chains, so they are dialed once and shared by the hosts behind them.
+	bastions map[string]*ssh.Client
 }
 
 func NewDialer() *Dialer {
 	return &Dialer{
 		connections: make(map[string]Connection),
+		bastions:    make(map[string]*ssh.Client),
 	}
 }
 
@@ -50,8 +56,27 @@ func (d *Dialer) Connect(host Host) (Connection, error) {
 			PrivateKey: host.GetPrivateKey(),
 			KeyFile:    host.GetPrivateKeyPath(),
 			Timeout:    time.Duration(host.GetTimeout()) * time.Second,
+			Bastions:   host.GetBastions(),
+		}
+		var proxy *ssh.Client
+		if len(opts.Bastions) > 0 {
+			if opts, err = validateOptions(opts); err != nil {
+				return nil, err
+			}
+			if proxy, _, err = dialBastions(opts.Bastions, opts.Timeout, d.bastions); err != nil {
+				return nil, err
+			}
+		}
+		conn, err = newConnection(opts, proxy)
+		if errors.Is(err, errBrokenProxy) {
+			// the cached client of the last bastion is broken, drop it and dial the bastions again.
+			logger.Log.Debugf("the cached connection of bastions for %s is broken, dial them again", host.GetName())
+			evictBastions(d.bastions, bastionsKey(opts.Bastions))
+			if proxy, _, err = dialBastions(opts.Bastions, opts.Timeout, d.bastions); err != nil {
+				return nil, err
+			}
+			conn, err = newConnection(opts, proxy)
 		}
-		conn, err = NewConnection(opts)
 		if err != nil {
 			return nil, err
 		}
@@ -83,4 +108,12 @@ func (d *Dialer) forgetConnection(conn *connection) {
 			delete(d.connections, k)
 		}
 	}
+
+	// the bastions are closed after all the hosts behind them are closed.
+	if len(d.connections) == 0 {
+		for k, client := range d.bastions {
+			_ = client.Close()
+			delete(d.bastions, k)
+		}
+	}
 }
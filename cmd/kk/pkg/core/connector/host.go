@@ -32,6 +32,8 @@ type BaseHost struct {
 	PrivateKeyPath  string `yaml:"privateKeyPath,omitempty" json:"privateKeyPath,omitempty"`
 	Arch            string `yaml:"arch,omitempty" json:"arch,omitempty"`
 	Timeout         int64  `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+	// Bastions are the jump hosts on the way to the host, ordered from the first hop.
+	Bastions []Bastion `yaml:"bastions,omitempty" json:"bastions,omitempty"`
 
 	Roles     []string        `json:"-"`
 	RoleTable map[string]bool `json:"-"`
@@ -123,6 +125,14 @@ func (b *BaseHost) SetPrivateKeyPath(path string) {
 	b.PrivateKeyPath = path
 }
 
+func (b *BaseHost) GetBastions() []Bastion {
+	return b.Bastions
+}
+
+func (b *BaseHost) SetBastions(bastions []Bastion) {
+	b.Bastions = bastions
+}
+
 func (b *BaseHost) GetArch() string {
 	return b.Arch
 }
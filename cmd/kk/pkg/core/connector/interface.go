@@ -83,6 +83,8 @@ type Host interface {
 	SetPrivateKey(privateKey string)
 	GetPrivateKeyPath() string
 	SetPrivateKeyPath(path string)
+	GetBastions() []Bastion
+	SetBastions(bastions []Bastion)
 	GetArch() string
 	SetArch(arch string)
 	GetTimeout() int64
@@ -19,7 +19,9 @@ package connector
 import (
 	"bufio"
 	"context"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"net"
@@ -50,9 +52,54 @@ type Cfg struct {
 	KeyFile     string
 	AgentSocket string
 	Timeout     time.Duration
+	// Bastion, BastionPort and BastionUser define a single bastion which uses the credentials of the host.
+	// They are kept for compatibility, Bastions takes precedence.
 	Bastion     string
 	BastionPort int
 	BastionUser string
+	// Bastions are the jump hosts on the way to the host, ordered from the first hop.
+	Bastions []Bastion
+}
+
+// Bastion defines a jump host on the way to the host.
+type Bastion struct {
+	Address        string `yaml:"address,omitempty" json:"address,omitempty"`
+	Port           int    `yaml:"port,omitempty" json:"port,omitempty"`
+	User           string `yaml:"user,omitempty" json:"user,omitempty"`
+	Password       string `yaml:"password,omitempty" json:"password,omitempty"`
+	PrivateKey     string `yaml:"privateKey,omitempty" json:"privateKey,omitempty"`
+	PrivateKeyPath string `yaml:"privateKeyPath,omitempty" json:"privateKeyPath,omitempty"`
+	AgentSocket    string `yaml:"-" json:"-"`
+}
+
+func (b Bastion) endpoint() string {
+	return net.JoinHostPort(b.Address, strconv.Itoa(b.Port))
+}
+
+// credentialID identifies the credentials of the bastion without exposing them.
+func (b Bastion) credentialID() string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{b.Password, b.PrivateKey, b.PrivateKeyPath, b.AgentSocket}, "\x00")))
+	return hex.EncodeToString(sum[:8])
+}
+
+// bastionsKey identifies the chain of bastions, the hosts behind the same chain share the connection of bastions.
+// The credentials are part of the key, so the hosts using different credentials for a bastion don't share it.
+func bastionsKey(bastions []Bastion) string {
+	keys := make([]string, 0, len(bastions))
+	for _, b := range bastions {
+		keys = append(keys, fmt.Sprintf("%s@%s#%s", b.User, b.endpoint(), b.credentialID()))
+	}
+	return strings.Join(keys, "/")
+}
+
+// evictBastions closes and removes the cached client of the chain and the clients dialed through it.
+func evictBastions(cache map[string]*ssh.Client, key string) {
+	for k, client := range cache {
+		if k == key || strings.HasPrefix(k, key+"/") {
+			_ = client.Close()
+			delete(cache, k)
+		}
+	}
 }
 
 const socketEnvPrefix = "env:"
@@ -61,35 +108,86 @@ type connection struct {
 	mu         sync.Mutex
 	sftpclient *sftp.Client
 	sshclient  *ssh.Client
-	ctx        context.Context
-	cancel     context.CancelFunc
+	// bastions are the clients of the bastions owned by this connection, they are not shared with other connections.
+	bastions []*ssh.Client
+	ctx      context.Context
+	cancel   context.CancelFunc
 }
 
 func NewConnection(cfg Cfg) (Connection, error) {
+	return newConnection(cfg, nil)
+}
+
+// newConnection connects to the host through the proxy, which is the client of the last bastion.
+// When the proxy is nil, the bastions of cfg are dialed and owned by the connection.
+func newConnection(cfg Cfg, proxy *ssh.Client) (Connection, error) {
 	cfg, err := validateOptions(cfg)
 	if err != nil {
 		return nil, errors.Wrap(err, "Failed to validate ssh connection parameters")
 	}
 
+	authMethods, err := newAuthMethods(cfg.Password, cfg.PrivateKey, cfg.AgentSocket)
+	if err != nil {
+		return nil, err
+	}
+
+	sshConfig := &ssh.ClientConfig{
+		User:            cfg.Username,
+		Timeout:         cfg.Timeout,
+		Auth:            authMethods,
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+	}
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+	sshConn := &connection{
+		ctx:    ctx,
+		cancel: cancelFn,
+	}
+
+	if proxy == nil && len(cfg.Bastions) > 0 {
+		proxy, sshConn.bastions, err = dialBastions(cfg.Bastions, cfg.Timeout, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	endpoint := net.JoinHostPort(cfg.Address, strconv.Itoa(cfg.Port))
+	client, err := dialSSH(proxy, endpoint, sshConfig)
+	if err != nil {
+		sshConn.Close()
+		return nil, errors.Wrapf(err, "could not establish connection to %s", endpoint)
+	}
+
+	sshConn.sshclient = client
+	sftpClient, err := sftp.NewClient(sshConn.sshclient)
+	if err != nil {
+		sshConn.Close()
+		return nil, errors.Wrapf(err, "new sftp client failed: %v", err)
+	}
+	sshConn.sftpclient = sftpClient
+	return sshConn, nil
+}
+
+func newAuthMethods(password, privateKey, agentSocket string) ([]ssh.AuthMethod, error) {
 	authMethods := make([]ssh.AuthMethod, 0)
 
-	if len(cfg.Password) > 0 {
-		authMethods = append(authMethods, ssh.Password(cfg.Password))
+	if len(password) > 0 {
+		authMethods = append(authMethods, ssh.Password(password))
 	}
 
-	if len(cfg.PrivateKey) > 0 {
-		signer, parseErr := ssh.ParsePrivateKey([]byte(cfg.PrivateKey))
+	if len(privateKey) > 0 {
+		signer, parseErr := ssh.ParsePrivateKey([]byte(privateKey))
 		if parseErr != nil {
 			return nil, errors.Wrap(parseErr, "The given SSH key could not be parsed")
 		}
 		authMethods = append(authMethods, ssh.PublicKeys(signer))
 	}
 
-	if len(cfg.AgentSocket) > 0 {
-		addr := cfg.AgentSocket
+	if len(agentSocket) > 0 {
+		addr := agentSocket
 
-		if strings.HasPrefix(cfg.AgentSocket, socketEnvPrefix) {
-			envName := strings.TrimPrefix(cfg.AgentSocket, socketEnvPrefix)
+		if strings.HasPrefix(agentSocket, socketEnvPrefix) {
+			envName := strings.TrimPrefix(agentSocket, socketEnvPrefix)
 
 			if envAddr := os.Getenv(envName); len(envAddr) > 0 {
 				addr = envAddr
@@ -111,66 +209,87 @@ func NewConnection(cfg Cfg) (Connection, error) {
 
 		authMethods = append(authMethods, ssh.PublicKeys(signers...))
 	}
+	return authMethods, nil
+}
 
-	sshConfig := &ssh.ClientConfig{
-		User:            cfg.Username,
-		Timeout:         cfg.Timeout,
-		Auth:            authMethods,
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
-	}
-
-	targetHost := cfg.Address
-	targetPort := strconv.Itoa(cfg.Port)
+// errBrokenProxy is returned by dialSSH when the client of bastion can not be used anymore,
+// such as the connection is closed by the bastion.
+var errBrokenProxy = errors.New("the connection of bastion is broken")
 
-	if cfg.Bastion != "" {
-		targetHost = cfg.Bastion
-		targetPort = strconv.Itoa(cfg.BastionPort)
-		sshConfig.User = cfg.BastionUser
+// dialSSH dials the endpoint directly, or through the proxy if it's not nil.
+func dialSSH(proxy *ssh.Client, endpoint string, sshConfig *ssh.ClientConfig) (*ssh.Client, error) {
+	if proxy == nil {
+		return ssh.Dial("tcp", endpoint, sshConfig)
 	}
 
-	endpoint := net.JoinHostPort(targetHost, targetPort)
-
-	client, err := ssh.Dial("tcp", endpoint, sshConfig)
+	conn, err := proxy.Dial("tcp", endpoint)
 	if err != nil {
-		return nil, errors.Wrapf(err, "could not establish connection to %s", endpoint)
-	}
-
-	ctx, cancelFn := context.WithCancel(context.Background())
-	sshConn := &connection{
-		ctx:    ctx,
-		cancel: cancelFn,
-	}
-
-	if cfg.Bastion == "" {
-		sshConn.sshclient = client
-		sftpClient, err := sftp.NewClient(sshConn.sshclient)
-		if err != nil {
-			return nil, errors.Wrapf(err, "new sftp client failed: %v", err)
+		// the bastion rejects the forwarding with an OpenChannelError, other errors mean the client is broken.
+		var rejected *ssh.OpenChannelError
+		if !errors.As(err, &rejected) {
+			return nil, errors.Wrap(errBrokenProxy, err.Error())
 		}
-		sshConn.sftpclient = sftpClient
-		return sshConn, nil
+		return nil, err
 	}
-
-	endpointBehindBastion := net.JoinHostPort(cfg.Address, strconv.Itoa(cfg.Port))
-
-	conn, err := client.Dial("tcp", endpointBehindBastion)
+	ncc, chans, reqs, err := ssh.NewClientConn(conn, endpoint, sshConfig)
 	if err != nil {
-		return nil, errors.Wrapf(err, "could not establish connection to %s", endpointBehindBastion)
+		_ = conn.Close()
+		return nil, err
 	}
+	return ssh.NewClient(ncc, chans, reqs), nil
+}
 
-	sshConfig.User = cfg.Username
-	ncc, chans, reqs, err := ssh.NewClientConn(conn, endpointBehindBastion, sshConfig)
-	if err != nil {
-		return nil, errors.Wrapf(err, "could not establish connection to %s", endpointBehindBastion)
-	}
+// dialBastions dials the bastions hop by hop and returns the client of the last hop and the clients it dialed.
+// The clients of the chains found in cache are reused, and the new ones are stored in cache if it's not nil.
+// A cached client which is broken is dropped from cache, and the chain is dialed again.
+func dialBastions(bastions []Bastion, timeout time.Duration, cache map[string]*ssh.Client) (*ssh.Client, []*ssh.Client, error) {
+	var (
+		proxy    *ssh.Client
+		proxyKey string
+		dialed   []*ssh.Client
+		redialed bool
+	)
+	for i := 0; i < len(bastions); i++ {
+		b := bastions[i]
+		key := bastionsKey(bastions[:i+1])
+		if client, ok := cache[key]; ok {
+			proxy, proxyKey = client, key
+			continue
+		}
 
-	sshConn.sshclient = ssh.NewClient(ncc, chans, reqs)
-	sftpClient, err := sftp.NewClient(sshConn.sshclient)
-	if err != nil {
-		return nil, errors.Wrapf(err, "new sftp client failed: %v", err)
+		authMethods, err := newAuthMethods(b.Password, b.PrivateKey, b.AgentSocket)
+		if err != nil {
+			return nil, dialed, errors.Wrapf(err, "invalid credentials of bastion %s", b.endpoint())
+		}
+		client, err := dialSSH(proxy, b.endpoint(), &ssh.ClientConfig{
+			User:            b.User,
+			Timeout:         timeout,
+			Auth:            authMethods,
+			HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		})
+		if errors.Is(err, errBrokenProxy) && cache != nil && !redialed {
+			logger.Log.Debugf("the cached connection of bastion %s is broken, dial it again", bastions[i-1].endpoint())
+			evictBastions(cache, proxyKey)
+			proxy, proxyKey, i, redialed = nil, "", -1, true
+			continue
+		}
+		if err != nil {
+			if cache == nil {
+				for _, c := range dialed {
+					_ = c.Close()
+				}
+			}
+			return nil, nil, errors.Wrapf(err, "could not establish connection to bastion %s", b.endpoint())
+		}
+		logger.Log.Debugf("connected to bastion %s", b.endpoint())
+
+		if cache != nil {
+			cache[key] = client
+		}
+		dialed = append(dialed, client)
+		proxy, proxyKey = client, key
 	}
-	sshConn.sftpclient = sftpClient
-	return sshConn, nil
+	return proxy, dialed, nil
 }
 
 func validateOptions(cfg Cfg) (Cfg, error) {
@@ -208,6 +327,43 @@ func validateOptions(cfg Cfg) (Cfg, error) {
 		cfg.BastionUser = cfg.Username
 	}
 
+	if cfg.Bastion != "" && len(cfg.Bastions) == 0 {
+		cfg.Bastions = []Bastion{{
+			Address: cfg.Bastion,
+			Port:    cfg.BastionPort,
+			User:    cfg.BastionUser,
+		}}
+	}
+
+	bastions := make([]Bastion, 0, len(cfg.Bastions))
+	for _, b := range cfg.Bastions {
+		if len(b.Address) == 0 {
+			return cfg, errors.New("No address specified for bastion")
+		}
+		if b.Port <= 0 {
+			b.Port = 22
+		}
+		if b.User == "" {
+			b.User = cfg.Username
+		}
+		// the bastion without credentials uses the credentials of the host.
+		if len(b.Password) == 0 && len(b.PrivateKey) == 0 && len(b.PrivateKeyPath) == 0 {
+			b.Password = cfg.Password
+			b.PrivateKey = cfg.PrivateKey
+			b.AgentSocket = cfg.AgentSocket
+		}
+		if len(b.PrivateKey) == 0 && len(b.PrivateKeyPath) > 0 {
+			content, err := os.ReadFile(b.PrivateKeyPath)
+			if err != nil {
+				return cfg, errors.Wrapf(err, "Failed to read keyfile %q of bastion %s", b.PrivateKeyPath, b.Address)
+			}
+			b.PrivateKey = string(content)
+			b.PrivateKeyPath = ""
+		}
+		bastions = append(bastions, b)
+	}
+	cfg.Bastions = bastions
+
 	if cfg.Timeout == 0 {
 		cfg.Timeout = 15 * time.Second
 	}
@@ -219,7 +375,7 @@ func (c *connection) Close() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	if c.sshclient == nil && c.sftpclient == nil {
+	if c.sshclient == nil && c.sftpclient == nil && len(c.bastions) == 0 {
 		return
 	}
 	c.cancel()
@@ -232,6 +388,11 @@ func (c *connection) Close() {
 		c.sftpclient.Close()
 		c.sftpclient = nil
 	}
+	// close the bastions from the last hop
+	for i := len(c.bastions) - 1; i >= 0; i-- {
+		_ = c.bastions[i].Close()
+	}
+	c.bastions = nil
 }
 
 func (c *connection) session() (*ssh.Session, error) {
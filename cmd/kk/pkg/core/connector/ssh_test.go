@@ -0,0 +1,106 @@
+/*
+ Copyright 2024 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package connector
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateOptionsBastions(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "id_rsa")
+	if err := os.WriteFile(keyFile, []byte("bastion key"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		cfg     Cfg
+		want    []Bastion
+		wantErr bool
+	}{
+		{
+			name: "no bastion",
+			cfg:  Cfg{Username: "root", Address: "10.0.0.3", Password: "pass"},
+			want: []Bastion{},
+		},
+		{
+			name: "legacy bastion uses the credentials of the host",
+			cfg:  Cfg{Username: "root", Address: "10.0.0.3", Password: "pass", Bastion: "192.168.0.1", BastionUser: "jump"},
+			want: []Bastion{{Address: "192.168.0.1", Port: 22, User: "jump", Password: "pass"}},
+		},
+		{
+			name: "multi hops",
+			cfg: Cfg{Username: "root", Address: "10.0.0.3", Password: "pass", Bastion: "ignored",
+				Bastions: []Bastion{
+					{Address: "203.0.113.10", Port: 2222, User: "jump", PrivateKeyPath: keyFile},
+					{Address: "10.0.0.2"},
+				}},
+			want: []Bastion{
+				{Address: "203.0.113.10", Port: 2222, User: "jump", PrivateKey: "bastion key"},
+				{Address: "10.0.0.2", Port: 22, User: "root", Password: "pass"},
+			},
+		},
+		{
+			name:    "bastion without address",
+			cfg:     Cfg{Username: "root", Address: "10.0.0.3", Password: "pass", Bastions: []Bastion{{User: "jump"}}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := validateOptions(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got.Bastions, tt.want) {
+				t.Errorf("validateOptions() bastions = %+v, want %+v", got.Bastions, tt.want)
+			}
+		})
+	}
+}
+
+func TestBastionsKey(t *testing.T) {
+	chain := []Bastion{
+		{Address: "203.0.113.10", Port: 2222, User: "jump"},
+		{Address: "10.0.0.2", Port: 22, User: "root"},
+	}
+	want := "jump@203.0.113.10:2222#" + chain[0].credentialID() + "/root@10.0.0.2:22#" + chain[1].credentialID()
+	if got := bastionsKey(chain); got != want {
+		t.Errorf("bastionsKey() = %s, want %s", got, want)
+	}
+	if bastionsKey(chain[:1]) == bastionsKey(chain) {
+		t.Errorf("the prefix of a chain must have a different key")
+	}
+
+	other := []Bastion{
+		{Address: "203.0.113.10", Port: 2222, User: "jump", Password: "other"},
+		{Address: "10.0.0.2", Port: 22, User: "root"},
+	}
+	if bastionsKey(other) == bastionsKey(chain) {
+		t.Errorf("the chains with different credentials must have different keys")
+	}
+	if strings.Contains(bastionsKey(other), "other") {
+		t.Errorf("bastionsKey() = %s, must not contain the credentials", bastionsKey(other))
+	}
+}
@@ -137,6 +137,13 @@ func WriteFile(fileName string, content []byte) error {
 }
 
 func Tar(src, dst, trimPrefix string) error {
+	return TarWithExclude(src, dst, trimPrefix, nil, nil)
+}
+
+// TarWithExclude archives src like Tar, but skips the files whose name in the archive is matched by exclude.
+// The files in first (names in the archive) are archived before the others, so they can be read without reading
+// the whole archive.
+func TarWithExclude(src, dst, trimPrefix string, first []string, exclude func(name string) bool) error {
 	fw, err := os.Create(dst)
 	if err != nil {
 		return err
@@ -149,12 +156,18 @@ func Tar(src, dst, trimPrefix string) error {
 	tw := tar.NewWriter(gw)
 	defer tw.Close()
 
-	return filepath.Walk(src, func(path string, info fs.FileInfo, err error) error {
+	trimPrefix = strings.TrimSuffix(trimPrefix, string(filepath.Separator))
+	for _, name := range first {
+		info, err := os.Stat(filepath.Join(trimPrefix, filepath.FromSlash(name)))
 		if err != nil {
 			return err
 		}
+		if err := tarFile(tw, filepath.Join(trimPrefix, filepath.FromSlash(name)), name, info); err != nil {
+			return err
+		}
+	}
 
-		hdr, err := tar.FileInfoHeader(info, "")
+	return filepath.Walk(src, func(path string, info fs.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
@@ -163,26 +176,42 @@ func Tar(src, dst, trimPrefix string) error {
 			return nil
 		}
 
-		fr, err := os.Open(path)
-		defer fr.Close()
-		if err != nil {
-			return err
+		name := strings.TrimPrefix(strings.TrimPrefix(path, trimPrefix), string(filepath.Separator))
+		if exclude != nil && exclude(name) {
+			return nil
+		}
+		for _, f := range first {
+			if f == name {
+				return nil
+			}
 		}
 
-		path = strings.TrimPrefix(path, trimPrefix)
-		fmt.Println(strings.TrimPrefix(path, string(filepath.Separator)))
+		return tarFile(tw, path, name, info)
+	})
+}
 
-		hdr.Name = strings.TrimPrefix(path, string(filepath.Separator))
-		if err := tw.WriteHeader(hdr); err != nil {
-			return err
-		}
+func tarFile(tw *tar.Writer, path, name string, info fs.FileInfo) error {
+	hdr, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return err
+	}
 
-		if _, err := io.Copy(tw, fr); err != nil {
-			return err
-		}
+	fr, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer fr.Close()
 
-		return nil
-	})
+	hdr.Name = name
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(tw, fr); err != nil {
+		return err
+	}
+
+	return nil
 }
 
 func Untar(src, dst string) error {
@@ -227,7 +256,7 @@ func Untar(src, dst string) error {
 				}
 			}
 
-			file, err := os.OpenFile(dstPath, os.O_CREATE|os.O_RDWR, os.FileMode(hdr.Mode))
+			file, err := os.OpenFile(dstPath, os.O_CREATE|os.O_RDWR|os.O_TRUNC, os.FileMode(hdr.Mode))
 			if err != nil {
 				return err
 			}
@@ -22,6 +22,7 @@ import (
 	kubekeyapiv1alpha2 "github.com/kubesys/kubekey/cmd/kk/apis/kubekey/v1alpha2"
 	"github.com/kubesys/kubekey/cmd/kk/pkg/common"
 	"github.com/kubesys/kubekey/cmd/kk/pkg/core/action"
+	"github.com/kubesys/kubekey/cmd/kk/pkg/core/prepare"
 	"github.com/kubesys/kubekey/cmd/kk/pkg/core/task"
 	"github.com/kubesys/kubekey/cmd/kk/pkg/core/util"
 	"github.com/kubesys/kubekey/cmd/kk/pkg/etcd/templates"
@@ -436,3 +437,162 @@ func (b *BackupModule) Init() {
 		enable,
 	}
 }
+
+type RestoreModule struct {
+	common.KubeModule
+}
+
+func (r *RestoreModule) Init() {
+	r.Name = "ETCDRestoreModule"
+	r.Desc = "Restore ETCD cluster data from snapshot"
+
+	syncSnapshot := &task.RemoteTask{
+		Name:     "SyncETCDSnapshot",
+		Desc:     "Synchronize etcd snapshot to all etcd nodes",
+		Hosts:    r.Runtime.GetHostsByRole(common.ETCD),
+		Action:   new(SyncSnapshot),
+		Parallel: true,
+		Retry:    1,
+	}
+
+	stopControlPlane := &task.RemoteTask{
+		Name:     "StopControlPlane",
+		Desc:     "Stop kube-apiserver, kube-controller-manager and kube-scheduler",
+		Hosts:    r.Runtime.GetHostsByRole(common.Master),
+		Action:   new(StopControlPlane),
+		Parallel: true,
+	}
+
+	stopETCD := &task.RemoteTask{
+		Name:     "StopETCD",
+		Desc:     "Stop etcd",
+		Hosts:    r.Runtime.GetHostsByRole(common.ETCD),
+		Action:   new(StopETCD),
+		Parallel: true,
+	}
+
+	restoreSnapshot := &task.RemoteTask{
+		Name:     "RestoreETCDSnapshot",
+		Desc:     "Restore etcd data from snapshot",
+		Hosts:    r.Runtime.GetHostsByRole(common.ETCD),
+		Action:   new(RestoreSnapshot),
+		Parallel: true,
+	}
+
+	// all members should be started at the same time, otherwise the first member waits for quorum.
+	restart := &task.RemoteTask{
+		Name:     "RestartETCD",
+		Desc:     "Restart etcd",
+		Hosts:    r.Runtime.GetHostsByRole(common.ETCD),
+		Action:   new(RestartETCD),
+		Parallel: true,
+	}
+
+	accessAddress := &task.RemoteTask{
+		Name:     "GenerateAccessAddress",
+		Desc:     "Generate access address",
+		Hosts:    r.Runtime.GetHostsByRole(common.ETCD),
+		Prepare:  new(FirstETCDNode),
+		Action:   new(GenerateAccessAddress),
+		Parallel: true,
+		Retry:    1,
+	}
+
+	allETCDNodeHealthCheck := &task.RemoteTask{
+		Name:     "AllETCDNodeHealthCheck",
+		Desc:     "Health check on all etcd",
+		Hosts:    r.Runtime.GetHostsByRole(common.ETCD),
+		Action:   new(HealthCheck),
+		Parallel: true,
+		Retry:    20,
+	}
+
+	startControlPlane := &task.RemoteTask{
+		Name:     "StartControlPlane",
+		Desc:     "Start kube-apiserver, kube-controller-manager and kube-scheduler",
+		Hosts:    r.Runtime.GetHostsByRole(common.Master),
+		Action:   new(StartControlPlane),
+		Parallel: true,
+	}
+
+	controlPlaneHealthCheck := &task.RemoteTask{
+		Name:     "ControlPlaneHealthCheck",
+		Desc:     "Health check on kube-apiserver",
+		Hosts:    r.Runtime.GetHostsByRole(common.Master),
+		Action:   new(ControlPlaneHealthCheck),
+		Parallel: true,
+		Retry:    30,
+	}
+
+	r.Tasks = []task.Interface{
+		syncSnapshot,
+		stopControlPlane,
+		stopETCD,
+		restoreSnapshot,
+		restart,
+		accessAddress,
+		allETCDNodeHealthCheck,
+		startControlPlane,
+		controlPlaneHealthCheck,
+	}
+}
+
+type SnapshotModule struct {
+	common.KubeModule
+}
+
+func (s *SnapshotModule) Init() {
+	s.Name = "ETCDSnapshotModule"
+	s.Desc = "Take a snapshot of ETCD cluster and upload it"
+
+	saveSnapshot := &task.RemoteTask{
+		Name:  "SaveETCDSnapshot",
+		Desc:  "Save etcd snapshot from the leader",
+		Hosts: s.Runtime.GetHostsByRole(common.ETCD),
+		Prepare: &prepare.PrepareCollection{
+			new(NodeETCDExist),
+			new(ETCDLeader),
+		},
+		Action: new(SaveSnapshot),
+		Retry:  1,
+	}
+
+	uploadSnapshot := &task.LocalTask{
+		Name:   "UploadETCDSnapshot",
+		Desc:   "Upload etcd snapshot to S3",
+		Action: new(UploadSnapshot),
+		Retry:  3,
+	}
+
+	pruneSnapshots := &task.LocalTask{
+		Name:    "PruneETCDSnapshots",
+		Desc:    "Delete expired etcd snapshots in S3",
+		Prepare: new(BackupS3Enabled),
+		Action:  new(PruneSnapshots),
+	}
+
+	s.Tasks = []task.Interface{
+		saveSnapshot,
+		uploadSnapshot,
+		pruneSnapshots,
+	}
+}
+
+type ListSnapshotsModule struct {
+	common.KubeModule
+}
+
+func (l *ListSnapshotsModule) Init() {
+	l.Name = "ETCDListSnapshotsModule"
+	l.Desc = "List ETCD snapshots in S3"
+
+	list := &task.LocalTask{
+		Name:   "ListETCDSnapshots",
+		Desc:   "List etcd snapshots in S3",
+		Action: new(ListSnapshots),
+	}
+
+	l.Tasks = []task.Interface{
+		list,
+	}
+}
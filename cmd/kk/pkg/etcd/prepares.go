@@ -17,6 +17,8 @@
 package etcd
 
 import (
+	"encoding/json"
+	"fmt"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -75,3 +77,62 @@ func (n *InstallOrUpgradeETCD) PreCheck(runtime connector.Runtime) (bool, error)
 	}
 	return false, errors.New("get etcd node status by host label failed")
 }
+
+type ETCDLeader struct {
+	common.KubePrepare
+	Not bool
+}
+
+// PreCheck asks the local etcd member whether it's the leader. The snapshot is taken from the leader
+// so that it contains the latest committed revision.
+func (e *ETCDLeader) PreCheck(runtime connector.Runtime) (bool, error) {
+	host := runtime.RemoteHost()
+	endpoint := fmt.Sprintf("https://%s:%d", host.GetInternalIPv4Address(), e.KubeConf.Cluster.Etcd.GetPort())
+	output, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("ETCDCTL_API=3 %s/etcdctl %s --endpoints=%s endpoint status -w json",
+		common.BinDir, etcdctlTLSFlags(host.GetName()), endpoint), false)
+	if err != nil {
+		return false, errors.Wrapf(errors.WithStack(err), "get etcd endpoint status of %s failed", host.GetName())
+	}
+	leader, err := isLeader(output)
+	if err != nil {
+		return false, err
+	}
+	if leader {
+		return !e.Not, nil
+	}
+	return e.Not, nil
+}
+
+// endpointStatus is the output of "etcdctl endpoint status -w json".
+type endpointStatus struct {
+	Endpoint string `json:"Endpoint"`
+	Status   struct {
+		Header struct {
+			MemberID uint64 `json:"member_id"`
+		} `json:"header"`
+		Leader uint64 `json:"leader"`
+	} `json:"Status"`
+}
+
+func isLeader(output string) (bool, error) {
+	var status []endpointStatus
+	if err := json.Unmarshal([]byte(strings.TrimSpace(output)), &status); err != nil {
+		return false, errors.Wrapf(errors.WithStack(err), "parse etcd endpoint status %q failed", output)
+	}
+	if len(status) == 0 {
+		return false, errors.New("etcd endpoint status is empty")
+	}
+	return status[0].Status.Header.MemberID != 0 && status[0].Status.Header.MemberID == status[0].Status.Leader, nil
+}
+
+type BackupS3Enabled struct {
+	common.KubePrepare
+	Not bool
+}
+
+func (b *BackupS3Enabled) PreCheck(_ connector.Runtime) (bool, error) {
+	if b.KubeConf.Cluster.Etcd.BackupS3 != nil {
+		return !b.Not, nil
+	}
+	return b.Not, nil
+}
@@ -0,0 +1,221 @@
+/*
+ Copyright 2024 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package etcd
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/pkg/errors"
+
+	kubekeyapiv1alpha2 "github.com/kubesys/kubekey/cmd/kk/apis/kubekey/v1alpha2"
+)
+
+const (
+	snapshotPrefix = "snapshot-"
+	snapshotSuffix = ".db"
+	// snapshotTimeFormat is the same as `date -u +%Y%m%d-%H%M%S` used by the backup script.
+	snapshotTimeFormat = "20060102-150405"
+)
+
+// SnapshotName returns the name of the snapshot taken at t.
+func SnapshotName(t time.Time) string {
+	return snapshotPrefix + t.UTC().Format(snapshotTimeFormat) + snapshotSuffix
+}
+
+// Snapshot is an etcd snapshot stored in S3.
+type Snapshot struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+	// Checksum is the sha256 of the snapshot, read from the "<key>.sha256" object.
+	Checksum string
+}
+
+// S3Client uploads, lists and prunes etcd snapshots in an S3-compatible storage.
+type S3Client struct {
+	cfg    *kubekeyapiv1alpha2.EtcdBackupS3
+	client *s3.S3
+	sess   *session.Session
+}
+
+func NewS3Client(cfg *kubekeyapiv1alpha2.EtcdBackupS3) (*S3Client, error) {
+	if cfg == nil {
+		return nil, errors.New("etcd.backupS3 is not set in the config file")
+	}
+	if cfg.Endpoint == "" || cfg.Bucket == "" {
+		return nil, errors.New("etcd.backupS3.endpoint and etcd.backupS3.bucket are required")
+	}
+
+	httpClient := http.DefaultClient
+	if cfg.InsecureSkipVerify {
+		httpClient = &http.Client{Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, // #nosec G402
+		}}
+	}
+	awsCfg := &aws.Config{
+		Endpoint:   aws.String(cfg.Endpoint),
+		Region:     aws.String(cfg.Region),
+		HTTPClient: httpClient,
+		// most of the S3-compatible storages (e.g. MinIO) only support path-style.
+		S3ForcePathStyle: aws.Bool(true),
+	}
+	if cfg.AccessKeyID != "" {
+		awsCfg.Credentials = credentials.NewStaticCredentials(cfg.AccessKeyID, cfg.SecretAccessKey, "")
+	}
+	sess, err := session.NewSession(awsCfg)
+	if err != nil {
+		return nil, errors.Wrap(errors.WithStack(err), "create s3 session failed")
+	}
+	return &S3Client{cfg: cfg, client: s3.New(sess), sess: sess}, nil
+}
+
+func (c *S3Client) key(name string) string {
+	return path.Join(c.cfg.Prefix, name)
+}
+
+// Upload the snapshot file and its checksum. the checksum is stored as "<key>.sha256" in sha256sum format.
+func (c *S3Client) Upload(file, checksum string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", errors.Wrapf(errors.WithStack(err), "open snapshot %s failed", file)
+	}
+	defer f.Close()
+
+	name := path.Base(file)
+	key := c.key(name)
+	if _, err := s3manager.NewUploader(c.sess).Upload(&s3manager.UploadInput{
+		Bucket: aws.String(c.cfg.Bucket),
+		Key:    aws.String(key),
+		Body:   f,
+	}); err != nil {
+		return "", errors.Wrapf(errors.WithStack(err), "upload snapshot to s3://%s/%s failed", c.cfg.Bucket, key)
+	}
+	if _, err := c.client.PutObject(&s3.PutObjectInput{
+		Bucket: aws.String(c.cfg.Bucket),
+		Key:    aws.String(key + ".sha256"),
+		Body:   strings.NewReader(fmt.Sprintf("%s  %s\n", checksum, name)),
+	}); err != nil {
+		return "", errors.Wrapf(errors.WithStack(err), "upload checksum of snapshot s3://%s/%s failed", c.cfg.Bucket, key)
+	}
+	return key, nil
+}
+
+// List the snapshots under prefix, sorted from the oldest to the newest.
+func (c *S3Client) List() ([]Snapshot, error) {
+	var snapshots []Snapshot
+	checksums := make(map[string]bool)
+	err := c.client.ListObjectsV2Pages(&s3.ListObjectsV2Input{
+		Bucket: aws.String(c.cfg.Bucket),
+		Prefix: aws.String(c.cfg.Prefix + "/"),
+	}, func(page *s3.ListObjectsV2Output, _ bool) bool {
+		for _, obj := range page.Contents {
+			key := aws.StringValue(obj.Key)
+			switch {
+			case isSnapshotKey(key):
+				snapshots = append(snapshots, Snapshot{
+					Key:          key,
+					Size:         aws.Int64Value(obj.Size),
+					LastModified: aws.TimeValue(obj.LastModified),
+				})
+			case strings.HasSuffix(key, snapshotSuffix+".sha256"):
+				checksums[strings.TrimSuffix(key, ".sha256")] = true
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, errors.Wrapf(errors.WithStack(err), "list snapshots in s3://%s/%s failed", c.cfg.Bucket, c.cfg.Prefix)
+	}
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].Key < snapshots[j].Key })
+
+	for i, s := range snapshots {
+		if !checksums[s.Key] {
+			continue
+		}
+		out, err := c.client.GetObject(&s3.GetObjectInput{
+			Bucket: aws.String(c.cfg.Bucket),
+			Key:    aws.String(s.Key + ".sha256"),
+		})
+		if err != nil {
+			return nil, errors.Wrapf(errors.WithStack(err), "get checksum of snapshot %s failed", s.Key)
+		}
+		data, err := io.ReadAll(out.Body)
+		out.Body.Close()
+		if err != nil {
+			return nil, errors.Wrapf(errors.WithStack(err), "read checksum of snapshot %s failed", s.Key)
+		}
+		if fields := strings.Fields(string(data)); len(fields) > 0 {
+			snapshots[i].Checksum = fields[0]
+		}
+	}
+	return snapshots, nil
+}
+
+// Prune deletes the oldest snapshots (and their checksums) which exceed KeepBackupNumber.
+func (c *S3Client) Prune() ([]string, error) {
+	snapshots, err := c.List()
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(snapshots))
+	for _, s := range snapshots {
+		keys = append(keys, s.Key)
+	}
+
+	expired := expiredSnapshots(keys, c.cfg.KeepBackupNumber)
+	for _, key := range expired {
+		for _, k := range []string{key, key + ".sha256"} {
+			if _, err := c.client.DeleteObject(&s3.DeleteObjectInput{
+				Bucket: aws.String(c.cfg.Bucket),
+				Key:    aws.String(k),
+			}); err != nil {
+				return nil, errors.Wrapf(errors.WithStack(err), "delete s3://%s/%s failed", c.cfg.Bucket, k)
+			}
+		}
+	}
+	return expired, nil
+}
+
+func isSnapshotKey(key string) bool {
+	name := path.Base(key)
+	return strings.HasPrefix(name, snapshotPrefix) && strings.HasSuffix(name, snapshotSuffix)
+}
+
+// expiredSnapshots returns the oldest keys which exceed keep. keys are named by timestamp so that
+// sorting by name is sorting by time. keep <= 0 means keep all.
+func expiredSnapshots(keys []string, keep int) []string {
+	if keep <= 0 || len(keys) <= keep {
+		return nil
+	}
+	sorted := append([]string(nil), keys...)
+	sort.Strings(sorted)
+	return sorted[:len(sorted)-keep]
+}
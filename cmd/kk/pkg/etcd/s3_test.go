@@ -0,0 +1,125 @@
+/*
+ Copyright 2024 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package etcd
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	kubekeyapiv1alpha2 "github.com/kubesys/kubekey/cmd/kk/apis/kubekey/v1alpha2"
+)
+
+func TestSnapshotName(t *testing.T) {
+	name := SnapshotName(time.Date(2024, 3, 5, 8, 9, 10, 0, time.FixedZone("UTC+8", 8*3600)))
+	if name != "snapshot-20240305-000910.db" {
+		t.Errorf("SnapshotName() = %v, want %v", name, "snapshot-20240305-000910.db")
+	}
+	if !isSnapshotKey("etcd-backup/" + name) {
+		t.Errorf("isSnapshotKey(%v) = false, want true", name)
+	}
+}
+
+func TestExpiredSnapshots(t *testing.T) {
+	keys := []string{
+		"etcd-backup/snapshot-20240103-000000.db",
+		"etcd-backup/snapshot-20240101-000000.db",
+		"etcd-backup/snapshot-20240102-000000.db",
+	}
+	tests := []struct {
+		keep int
+		want []string
+	}{
+		{
+			0,
+			nil,
+		},
+		{
+			3,
+			nil,
+		},
+		{
+			2,
+			[]string{"etcd-backup/snapshot-20240101-000000.db"},
+		},
+		{
+			1,
+			[]string{"etcd-backup/snapshot-20240101-000000.db", "etcd-backup/snapshot-20240102-000000.db"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run("", func(t *testing.T) {
+			if got := expiredSnapshots(keys, tt.keep); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expiredSnapshots() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsLeader(t *testing.T) {
+	tests := []struct {
+		output string
+		want   bool
+	}{
+		{
+			`[{"Endpoint":"https://10.0.0.1:2379","Status":{"header":{"cluster_id":1,"member_id":9372538179322589801,"revision":8},"leader":9372538179322589801}}]`,
+			true,
+		},
+		{
+			`[{"Endpoint":"https://10.0.0.2:2379","Status":{"header":{"cluster_id":1,"member_id":10501334649042878790,"revision":8},"leader":9372538179322589801}}]`,
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run("", func(t *testing.T) {
+			got, err := isLeader(tt.output)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("isLeader() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckCurlVersion(t *testing.T) {
+	tests := []struct {
+		output  string
+		wantErr bool
+	}{
+		{"curl 8.5.0 (x86_64-pc-linux-gnu) libcurl/8.5.0 OpenSSL/3.0.13", false},
+		{"curl 7.75.0 (x86_64-pc-linux-gnu) libcurl/7.75.0", false},
+		{"curl 7.61.1 (x86_64-redhat-linux-gnu) libcurl/7.61.1 OpenSSL/1.1.1k", true},
+		{"bash: curl: command not found", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.output, func(t *testing.T) {
+			if err := checkCurlVersion(tt.output); (err != nil) != tt.wantErr {
+				t.Errorf("checkCurlVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestS3CredentialsConfig(t *testing.T) {
+	got := s3CredentialsConfig(&kubekeyapiv1alpha2.EtcdBackupS3{AccessKeyID: "ak", SecretAccessKey: `s"e\c`})
+	want := "user = \"ak:s\\\"e\\\\c\"\n"
+	if got != want {
+		t.Errorf("s3CredentialsConfig() = %q, want %q", got, want)
+	}
+}
@@ -18,15 +18,21 @@ package etcd
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
 	"strings"
+	"text/tabwriter"
+	"time"
 
+	"github.com/docker/go-units"
 	"github.com/pkg/errors"
+	versionutil "k8s.io/apimachinery/pkg/util/version"
 
 	kubekeyapiv1alpha2 "github.com/kubesys/kubekey/cmd/kk/apis/kubekey/v1alpha2"
 	"github.com/kubesys/kubekey/cmd/kk/pkg/common"
 	"github.com/kubesys/kubekey/cmd/kk/pkg/core/action"
 	"github.com/kubesys/kubekey/cmd/kk/pkg/core/connector"
+	"github.com/kubesys/kubekey/cmd/kk/pkg/core/logger"
 	"github.com/kubesys/kubekey/cmd/kk/pkg/core/util"
 	"github.com/kubesys/kubekey/cmd/kk/pkg/etcd/templates"
 	"github.com/kubesys/kubekey/cmd/kk/pkg/files"
@@ -415,6 +421,20 @@ type BackupETCD struct {
 }
 
 func (b *BackupETCD) Execute(runtime connector.Runtime) error {
+	s3Credentials := filepath.Join(b.KubeConf.Cluster.Etcd.BackupScriptDir, "etcd-backup-s3.conf")
+	if s3 := b.KubeConf.Cluster.Etcd.BackupS3; s3 != nil {
+		output, err := runtime.GetRunner().SudoCmd("curl --version | head -n 1", false)
+		if err != nil {
+			return errors.Wrap(errors.WithStack(err), "get curl version failed, curl 7.75+ is required to upload etcd snapshots to S3")
+		}
+		if err := checkCurlVersion(output); err != nil {
+			return err
+		}
+		if err := putS3Credentials(runtime, s3, s3Credentials); err != nil {
+			return err
+		}
+	}
+
 	templateAction := action.Template{
 		Template: templates.EtcdBackupScript,
 		Dst:      filepath.Join(b.KubeConf.Cluster.Etcd.BackupScriptDir, "etcd-backup.sh"),
@@ -425,6 +445,8 @@ func (b *BackupETCD) Execute(runtime connector.Runtime) error {
 			"Backupdir":           b.KubeConf.Cluster.Etcd.BackupDir,
 			"KeepbackupNumber":    b.KubeConf.Cluster.Etcd.KeepBackupNumber + 1,
 			"EtcdBackupScriptDir": b.KubeConf.Cluster.Etcd.BackupScriptDir,
+			"S3":                  b.KubeConf.Cluster.Etcd.BackupS3,
+			"S3Credentials":       s3Credentials,
 		},
 	}
 
@@ -439,6 +461,48 @@ func (b *BackupETCD) Execute(runtime connector.Runtime) error {
 	return nil
 }
 
+// minCurlVersion is the first curl version which supports --aws-sigv4.
+var minCurlVersion = versionutil.MustParseGeneric("7.75.0")
+
+// checkCurlVersion checks the first line of `curl --version`, e.g. "curl 7.61.1 (x86_64-redhat-linux-gnu) ...".
+func checkCurlVersion(output string) error {
+	fields := strings.Fields(output)
+	if len(fields) < 2 || fields[0] != "curl" {
+		return errors.Errorf("unexpected output of curl --version: %q", output)
+	}
+	v, err := versionutil.ParseGeneric(fields[1])
+	if err != nil {
+		return errors.Wrapf(errors.WithStack(err), "parse curl version %s failed", fields[1])
+	}
+	if !v.AtLeast(minCurlVersion) {
+		return errors.Errorf("curl %s does not support --aws-sigv4, curl %s+ is required to upload etcd snapshots to S3", v, minCurlVersion)
+	}
+	return nil
+}
+
+// s3CredentialsConfig returns the curl config which contains the S3 credentials, so they never show up in the command line.
+func s3CredentialsConfig(s3 *kubekeyapiv1alpha2.EtcdBackupS3) string {
+	quote := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	return fmt.Sprintf("user = \"%s:%s\"\n", quote.Replace(s3.AccessKeyID), quote.Replace(s3.SecretAccessKey))
+}
+
+// putS3Credentials writes the curl config with the S3 credentials to dst, only root can read it.
+func putS3Credentials(runtime connector.Runtime, s3 *kubekeyapiv1alpha2.EtcdBackupS3, dst string) error {
+	local := filepath.Join(runtime.GetHostWorkDir(), filepath.Base(dst))
+	if err := os.WriteFile(local, []byte(s3CredentialsConfig(s3)), 0600); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "write file %s failed", local)
+	}
+	defer os.Remove(local)
+
+	if err := runtime.GetRunner().SudoScp(local, dst); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "scp file %s to remote %s failed", local, dst)
+	}
+	if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("chown root:root %[1]s && chmod 600 %[1]s", dst), false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "chmod etcd backup s3 credentials failed")
+	}
+	return nil
+}
+
 type EnableBackupETCDService struct {
 	common.KubeAction
 }
@@ -450,3 +514,282 @@ func (e *EnableBackupETCDService) Execute(runtime connector.Runtime) error {
 	}
 	return nil
 }
+
+// snapshotPath is where the snapshot to restore is stored on etcd nodes.
+func snapshotPath(kubeConf *common.KubeConf) string {
+	return filepath.Join(kubeConf.Cluster.Etcd.BackupDir, "restore", "snapshot.db")
+}
+
+// dataDir of etcd. it's the same as ETCD_DATA_DIR in etcd.env.
+func dataDir(kubeConf *common.KubeConf) string {
+	if d := kubeConf.Cluster.Etcd.DataDir; d != nil && *d != "" {
+		return *d
+	}
+	return "/var/lib/etcd"
+}
+
+type SyncSnapshot struct {
+	common.KubeAction
+}
+
+func (s *SyncSnapshot) Execute(runtime connector.Runtime) error {
+	host := runtime.RemoteHost()
+	if exist, ok := host.GetCache().GetMustBool(common.ETCDExist); !ok || !exist {
+		return fmt.Errorf("etcd is not installed on %s, only an existing etcd cluster can be restored", host.GetName())
+	}
+
+	localSum, err := files.Sha256sum(s.KubeConf.Arg.EtcdSnapshot)
+	if err != nil {
+		return errors.Wrapf(err, "get sha256 of snapshot %s failed", s.KubeConf.Arg.EtcdSnapshot)
+	}
+
+	dst := snapshotPath(s.KubeConf)
+	if err := runtime.GetRunner().SudoScp(s.KubeConf.Arg.EtcdSnapshot, dst); err != nil {
+		return errors.Wrap(errors.WithStack(err), "sync etcd snapshot failed")
+	}
+	remoteSum, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("sha256sum %s | cut -d' ' -f1", dst), false)
+	if err != nil {
+		return errors.Wrap(errors.WithStack(err), "get sha256 of etcd snapshot failed")
+	}
+	if strings.TrimSpace(remoteSum) != localSum {
+		return fmt.Errorf("sha256 of etcd snapshot on %s is %s, but expected %s", host.GetName(), remoteSum, localSum)
+	}
+	return nil
+}
+
+// controlPlaneManifests are the static pods which stopped when restore etcd.
+var controlPlaneManifests = []string{"kube-apiserver.yaml", "kube-controller-manager.yaml", "kube-scheduler.yaml"}
+
+const (
+	manifestsDir        = "/etc/kubernetes/manifests"
+	restoreManifestsDir = "/etc/kubernetes/manifests-etcd-restore"
+)
+
+type StopControlPlane struct {
+	common.KubeAction
+}
+
+func (s *StopControlPlane) Execute(runtime connector.Runtime) error {
+	// kubelet stops the static pods when their manifests are removed.
+	cmds := []string{fmt.Sprintf("mkdir -p %s", restoreManifestsDir)}
+	for _, m := range controlPlaneManifests {
+		cmds = append(cmds, fmt.Sprintf("if [ -f %[1]s/%[3]s ]; then mv -f %[1]s/%[3]s %[2]s/%[3]s; fi", manifestsDir, restoreManifestsDir, m))
+	}
+	if _, err := runtime.GetRunner().SudoCmd(strings.Join(cmds, " && "), false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "move control plane manifests failed")
+	}
+	waitCmd := "for i in $(seq 60); do pgrep -x kube-apiserver > /dev/null || exit 0; sleep 2; done; exit 1"
+	if _, err := runtime.GetRunner().SudoCmd(waitCmd, false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "wait for kube-apiserver stopped failed")
+	}
+	return nil
+}
+
+type StopETCD struct {
+	common.KubeAction
+}
+
+func (s *StopETCD) Execute(runtime connector.Runtime) error {
+	if _, err := runtime.GetRunner().SudoCmd("systemctl stop etcd", false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "stop etcd failed")
+	}
+	return nil
+}
+
+type RestoreSnapshot struct {
+	common.KubeAction
+}
+
+func (r *RestoreSnapshot) Execute(runtime connector.Runtime) error {
+	host := runtime.RemoteHost()
+	etcdName, ok := host.GetCache().GetMustString(common.ETCDName)
+	if !ok {
+		return errors.New("get etcd node status by host label failed")
+	}
+
+	// each member is restored with the same initial cluster, which is made up of all etcd nodes.
+	var initialCluster []string
+	for _, h := range runtime.GetHostsByRole(common.ETCD) {
+		name, ok := h.GetCache().GetMustString(common.ETCDName)
+		if !ok {
+			return fmt.Errorf("get etcd name of %s by host label failed", h.GetName())
+		}
+		initialCluster = append(initialCluster, fmt.Sprintf("%s=https://%s:%d", name, h.GetInternalIPv4Address(), r.KubeConf.Cluster.Etcd.GetPeerPort()))
+	}
+
+	dir := dataDir(r.KubeConf)
+	restoreDir := dir + "-restore"
+	restoreArgs := fmt.Sprintf("snapshot restore %s --name %s --initial-cluster %s --initial-cluster-token k8s_etcd --initial-advertise-peer-urls https://%s:%d --data-dir %s",
+		snapshotPath(r.KubeConf), etcdName, strings.Join(initialCluster, ","), host.GetInternalIPv4Address(), r.KubeConf.Cluster.Etcd.GetPeerPort(), restoreDir)
+	// etcdutl is shipped with etcd since v3.5. "etcdctl snapshot restore" is used for the older version.
+	restoreCmd := fmt.Sprintf("rm -rf %[1]s && if [ -x %[2]s/etcdutl ]; then %[2]s/etcdutl %[3]s; else ETCDCTL_API=3 %[2]s/etcdctl %[3]s; fi",
+		restoreDir, common.BinDir, restoreArgs)
+	if _, err := runtime.GetRunner().SudoCmd(restoreCmd, false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "restore etcd snapshot failed")
+	}
+
+	// keep the old data dir, it can be used to roll back manually.
+	replaceCmd := fmt.Sprintf("if [ -d %[1]s ]; then mv %[1]s %[1]s-$(date +%%Y-%%m-%%d-%%H-%%M-%%S).bak; fi && mv %[2]s %[1]s && chmod 700 %[1]s", dir, restoreDir)
+	if _, err := runtime.GetRunner().SudoCmd(replaceCmd, false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "replace etcd data dir failed")
+	}
+	return nil
+}
+
+type StartControlPlane struct {
+	common.KubeAction
+}
+
+func (s *StartControlPlane) Execute(runtime connector.Runtime) error {
+	var cmds []string
+	for _, m := range controlPlaneManifests {
+		cmds = append(cmds, fmt.Sprintf("if [ -f %[2]s/%[3]s ]; then mv -f %[2]s/%[3]s %[1]s/%[3]s; fi", manifestsDir, restoreManifestsDir, m))
+	}
+	cmds = append(cmds, fmt.Sprintf("rm -rf %s", restoreManifestsDir), "systemctl restart kubelet")
+	if _, err := runtime.GetRunner().SudoCmd(strings.Join(cmds, " && "), false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "start control plane failed")
+	}
+	return nil
+}
+
+type ControlPlaneHealthCheck struct {
+	common.KubeAction
+}
+
+func (c *ControlPlaneHealthCheck) Execute(runtime connector.Runtime) error {
+	if _, err := runtime.GetRunner().SudoCmd("/usr/local/bin/kubectl --kubeconfig /etc/kubernetes/admin.conf get --raw=/readyz", false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "kube-apiserver is not ready")
+	}
+	return nil
+}
+
+// etcdctlTLSFlags are the flags of etcdctl (v3 API) to connect etcd with the admin certs of host.
+func etcdctlTLSFlags(hostname string) string {
+	return fmt.Sprintf("--cacert=%[1]s/ca.pem --cert=%[1]s/admin-%[2]s.pem --key=%[1]s/admin-%[2]s-key.pem", common.ETCDCertDir, hostname)
+}
+
+type SaveSnapshot struct {
+	common.KubeAction
+}
+
+func (s *SaveSnapshot) Execute(runtime connector.Runtime) error {
+	host := runtime.RemoteHost()
+	name := SnapshotName(time.Now())
+	remote := filepath.Join(s.KubeConf.Cluster.Etcd.BackupDir, "snapshots", name)
+	endpoint := fmt.Sprintf("https://%s:%d", host.GetInternalIPv4Address(), s.KubeConf.Cluster.Etcd.GetPort())
+	saveCmd := fmt.Sprintf("mkdir -p %s && ETCDCTL_API=3 %s/etcdctl %s --endpoints=%s snapshot save %s",
+		filepath.Dir(remote), common.BinDir, etcdctlTLSFlags(host.GetName()), endpoint, remote)
+	if _, err := runtime.GetRunner().SudoCmd(saveCmd, false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "save etcd snapshot failed")
+	}
+	// the snapshot on the node is removed after fetched, the local one is the backup.
+	defer func() {
+		_, _ = runtime.GetRunner().SudoCmd(fmt.Sprintf("rm -f %s", remote), false)
+	}()
+
+	remoteSum, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("sha256sum %s | cut -d' ' -f1", remote), false)
+	if err != nil {
+		return errors.Wrap(errors.WithStack(err), "get sha256 of etcd snapshot failed")
+	}
+	remoteSum = strings.TrimSpace(remoteSum)
+
+	// the snapshot is only readable by root. copy it to the tmp dir and fetch it as the ssh user.
+	tmp := filepath.Join(common.TmpDir, name)
+	if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("mkdir -p %s && install -m 600 -o %s %s %s",
+		common.TmpDir, host.GetUser(), remote, tmp), false); err != nil {
+		return errors.Wrap(errors.WithStack(err), "copy etcd snapshot to tmp dir failed")
+	}
+	defer func() {
+		_, _ = runtime.GetRunner().SudoCmd(fmt.Sprintf("rm -f %s", tmp), false)
+	}()
+
+	local := filepath.Join(runtime.GetWorkDir(), "etcd-backup", name)
+	if err := util.CreateDir(filepath.Dir(local)); err != nil {
+		return errors.Wrap(errors.WithStack(err), "create local etcd backup dir failed")
+	}
+	if err := runtime.GetRunner().Fetch(local, tmp); err != nil {
+		return errors.Wrap(errors.WithStack(err), "fetch etcd snapshot failed")
+	}
+	localSum, err := files.Sha256sum(local)
+	if err != nil {
+		return errors.Wrapf(err, "get sha256 of snapshot %s failed", local)
+	}
+	if localSum != remoteSum {
+		return fmt.Errorf("sha256 of fetched etcd snapshot is %s, but %s on %s", localSum, remoteSum, host.GetName())
+	}
+
+	s.PipelineCache.Set(common.ETCDSnapshot, local)
+	s.PipelineCache.Set(common.ETCDSnapshotChecksum, localSum)
+	return nil
+}
+
+type UploadSnapshot struct {
+	common.KubeAction
+}
+
+func (u *UploadSnapshot) Execute(runtime connector.Runtime) error {
+	local, ok := u.PipelineCache.GetMustString(common.ETCDSnapshot)
+	if !ok {
+		return errors.New("no etcd snapshot is taken, the etcd cluster may have no leader")
+	}
+	checksum, _ := u.PipelineCache.GetMustString(common.ETCDSnapshotChecksum)
+	if u.KubeConf.Cluster.Etcd.BackupS3 == nil {
+		logger.Log.Messagef(common.LocalHost, "etcd snapshot is saved to %s (sha256: %s), etcd.backupS3 is not set and skip uploading", local, checksum)
+		return nil
+	}
+
+	client, err := NewS3Client(u.KubeConf.Cluster.Etcd.BackupS3)
+	if err != nil {
+		return err
+	}
+	key, err := client.Upload(local, checksum)
+	if err != nil {
+		return err
+	}
+	logger.Log.Messagef(common.LocalHost, "etcd snapshot is uploaded to s3://%s/%s (sha256: %s)", u.KubeConf.Cluster.Etcd.BackupS3.Bucket, key, checksum)
+	return nil
+}
+
+type PruneSnapshots struct {
+	common.KubeAction
+}
+
+func (p *PruneSnapshots) Execute(runtime connector.Runtime) error {
+	client, err := NewS3Client(p.KubeConf.Cluster.Etcd.BackupS3)
+	if err != nil {
+		return err
+	}
+	expired, err := client.Prune()
+	if err != nil {
+		return err
+	}
+	for _, key := range expired {
+		logger.Log.Messagef(common.LocalHost, "expired etcd snapshot s3://%s/%s is deleted", p.KubeConf.Cluster.Etcd.BackupS3.Bucket, key)
+	}
+	return nil
+}
+
+type ListSnapshots struct {
+	common.KubeAction
+}
+
+func (l *ListSnapshots) Execute(runtime connector.Runtime) error {
+	client, err := NewS3Client(l.KubeConf.Cluster.Etcd.BackupS3)
+	if err != nil {
+		return err
+	}
+	snapshots, err := client.List()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 10, 4, 3, ' ', 0)
+	_, _ = fmt.Fprintln(w, "SNAPSHOT\tSIZE\tLAST MODIFIED\tSHA256")
+	// the newest first.
+	for i := len(snapshots) - 1; i >= 0; i-- {
+		s := snapshots[i]
+		_, _ = fmt.Fprintf(w, "s3://%s/%s\t%s\t%s\t%s\n", l.KubeConf.Cluster.Etcd.BackupS3.Bucket, s.Key,
+			units.BytesSize(float64(s.Size)), s.LastModified.Format(time.RFC3339), s.Checksum)
+	}
+	return w.Flush()
+}
@@ -61,5 +61,43 @@ export ETCDCTL_API=3;$ETCDCTL_PATH --endpoints="$ENDPOINTS" snapshot save $BACKU
 sleep 3
 
 cd $BACKUP_DIR/../ && ls -lt |awk '{if(NR > '$KEEPBACKUPNUMBER'){print "rm -rf "$9}}'|sh
+{{- if .S3 }}
+
+# upload the snapshot to S3 from the leader only, and delete the expired snapshots in S3.
+# it requires curl 7.75+ which supports --aws-sigv4, the credentials are read from a curl config file only root can read.
+S3_ENDPOINT='{{ .S3.Endpoint }}'
+S3_REGION='{{ .S3.Region }}'
+S3_BUCKET='{{ .S3.Bucket }}'
+S3_PREFIX='{{ .S3.Prefix }}'
+S3_KEEPBACKUPNUMBER='{{ .S3.KeepBackupNumber }}'
+S3_CREDENTIALS='{{ .S3Credentials }}'
+
+s3_curl() {
+  curl -fsS {{ if .S3.InsecureSkipVerify }}-k {{ end }}--aws-sigv4 "aws:amz:${S3_REGION}:s3" -K "$S3_CREDENTIALS" "$@"
+}
+
+ETCD_STATUS=$(export ETCDCTL_API=3;$ETCDCTL_PATH --endpoints="$ENDPOINTS" endpoint status -w fields \
+                                   --cacert="$ETCDCTL_CA_FILE" \
+                                   --cert="$ETCDCTL_CERT" \
+                                   --key="$ETCDCTL_KEY")
+MEMBER_ID=$(echo "$ETCD_STATUS" | awk -F' : ' '/^"MemberID"/{print $2}')
+LEADER_ID=$(echo "$ETCD_STATUS" | awk -F' : ' '/^"Leader"/{print $2}')
+
+if [ -n "$MEMBER_ID" ] && [ "$MEMBER_ID" = "$LEADER_ID" ]; then
+  SNAPSHOT_NAME="snapshot-$(date -u +%Y%m%d-%H%M%S).db"
+  echo "$(sha256sum $BACKUP_DIR/snapshot.db | cut -d' ' -f1)  $SNAPSHOT_NAME" > $BACKUP_DIR/snapshot.db.sha256
+  s3_curl -T $BACKUP_DIR/snapshot.db "$S3_ENDPOINT/$S3_BUCKET/$S3_PREFIX/$SNAPSHOT_NAME"
+  s3_curl -T $BACKUP_DIR/snapshot.db.sha256 "$S3_ENDPOINT/$S3_BUCKET/$S3_PREFIX/$SNAPSHOT_NAME.sha256"
+
+  if [ "$S3_KEEPBACKUPNUMBER" -gt 0 ]; then
+    s3_curl "$S3_ENDPOINT/$S3_BUCKET?list-type=2&prefix=$S3_PREFIX/snapshot-" \
+      | grep -o '<Key>[^<]*\.db</Key>' | sed -e 's#<Key>##' -e 's#</Key>##' | sort \
+      | head -n -$S3_KEEPBACKUPNUMBER | while read -r key; do
+      s3_curl -X DELETE "$S3_ENDPOINT/$S3_BUCKET/$key"
+      s3_curl -X DELETE "$S3_ENDPOINT/$S3_BUCKET/$key.sha256"
+    done
+  fi
+fi
+{{- end }}
 
 `)))
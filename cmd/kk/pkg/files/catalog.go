@@ -0,0 +1,292 @@
+/*
+ Copyright 2024 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package files
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	versionutil "k8s.io/apimachinery/pkg/util/version"
+	"sigs.k8s.io/yaml"
+)
+
+// CatalogFileName is the name of the overlay catalog saved in the artifact.
+const CatalogFileName = "catalog.json"
+
+var (
+	// FileURL stores the download urls set by the overlay catalog. It overrides the default url of the binary.
+	FileURL = map[string]map[string]map[string]string{}
+
+	// catalogOverlay is the raw overlay catalog merged into FileSha256.
+	catalogOverlay []byte
+	// catalogSignature is the detached signature of catalogOverlay. It's nil if the catalog is not verified by a key.
+	catalogSignature []byte
+	// catalogOptions verifies the catalog carried by an artifact.
+	catalogOptions CatalogOptions
+
+	sha256Regexp = regexp.MustCompile(`^[a-f0-9]{64}$`)
+)
+
+// CatalogEntry is a version of a component in the catalog.
+type CatalogEntry struct {
+	Sha256 string `json:"sha256"`
+	// URL overrides the default download url. It must point to the same kind of file as the default one.
+	URL string `json:"url,omitempty"`
+}
+
+// UnmarshalJSON accepts the plain sha256 string used by version/components.json as well.
+func (e *CatalogEntry) UnmarshalJSON(data []byte) error {
+	var sum string
+	if err := json.Unmarshal(data, &sum); err == nil {
+		e.Sha256 = sum
+		return nil
+	}
+	type entry CatalogEntry
+	return json.Unmarshal(data, (*entry)(e))
+}
+
+// Catalog is keyed by component, arch and version, the same layout as version/components.json.
+type Catalog map[string]map[string]map[string]CatalogEntry
+
+// CatalogOptions defines where the overlay catalog is loaded from and how it's verified.
+type CatalogOptions struct {
+	// Source is a local file or a http(s) url of the catalog, in JSON or YAML.
+	Source string
+	// Sha256 is the expected checksum of the catalog.
+	Sha256 string
+	// KeyFile is an armored OpenPGP public key. The detached signature is read from "<Source>.asc".
+	KeyFile string
+	// Insecure allows to use the catalog without Sha256 or KeyFile.
+	Insecure bool
+}
+
+// ParseCatalog parses and validates the catalog.
+func ParseCatalog(data []byte) (Catalog, error) {
+	jsonData, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, errors.Wrap(errors.WithStack(err), "parse catalog failed")
+	}
+	catalog := Catalog{}
+	if err := json.Unmarshal(jsonData, &catalog); err != nil {
+		return nil, errors.Wrap(errors.WithStack(err), "parse catalog failed")
+	}
+
+	for name, arches := range catalog {
+		if !knownComponent(name) {
+			return nil, errors.Errorf("unknown component %q in catalog, supported: %s", name, strings.Join(knownComponents(), ", "))
+		}
+		for arch, versions := range arches {
+			if arch != amd64 && arch != arm64 {
+				return nil, errors.Errorf("unsupported arch %q of %s in catalog", arch, name)
+			}
+			for v, entry := range versions {
+				if !sha256Regexp.MatchString(entry.Sha256) {
+					return nil, errors.Errorf("invalid sha256 %q of %s %s %s in catalog", entry.Sha256, name, arch, v)
+				}
+			}
+		}
+	}
+	return catalog, nil
+}
+
+// SetCatalogOptions sets the options to verify the catalog carried by an artifact, see LoadArtifactCatalog.
+func SetCatalogOptions(o CatalogOptions) {
+	catalogOptions = o
+}
+
+// LoadCatalog loads the overlay catalog, verifies it and merges it into FileSha256.
+func LoadCatalog(o CatalogOptions) error {
+	data, err := readCatalogSource(o.Source)
+	if err != nil {
+		return err
+	}
+	var signature []byte
+	if o.KeyFile != "" {
+		if signature, err = readCatalogSource(o.Source + SignatureSuffix); err != nil {
+			return errors.Wrap(err, "read catalog signature failed")
+		}
+	}
+	if err := verifyCatalog(o, o.Source, data, signature); err != nil {
+		return err
+	}
+	if err := MergeCatalog(data); err != nil {
+		return err
+	}
+	catalogSignature = signature
+	return nil
+}
+
+// LoadArtifactCatalog loads the catalog carried by an artifact. It's verified by the options set by SetCatalogOptions,
+// the same as the catalog loaded by LoadCatalog. The catalog loaded by LoadCatalog takes precedence.
+func LoadArtifactCatalog(path string) (bool, error) {
+	if catalogOverlay != nil {
+		return false, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, errors.Wrapf(errors.WithStack(err), "read catalog %s failed", path)
+	}
+	var signature []byte
+	if catalogOptions.KeyFile != "" {
+		if signature, err = os.ReadFile(path + SignatureSuffix); err != nil {
+			return false, errors.Wrapf(errors.WithStack(err), "read catalog signature %s failed", path+SignatureSuffix)
+		}
+	}
+	if err := verifyCatalog(catalogOptions, path, data, signature); err != nil {
+		return false, err
+	}
+	if err := MergeCatalog(data); err != nil {
+		return false, err
+	}
+	catalogSignature = signature
+	return true, nil
+}
+
+func verifyCatalog(o CatalogOptions, source string, data, signature []byte) error {
+	if o.Sha256 == "" && o.KeyFile == "" {
+		if o.Insecure {
+			return nil
+		}
+		return errors.Errorf("catalog %s is not verified, set --components-catalog-sha256 or --components-catalog-key, "+
+			"or --components-catalog-insecure to use it without verification", source)
+	}
+	if o.Sha256 != "" {
+		if sum := fmt.Sprintf("%x", sha256.Sum256(data)); sum != strings.ToLower(o.Sha256) {
+			return errors.Errorf("sha256 of catalog %s is %s, but expected %s", source, sum, o.Sha256)
+		}
+	}
+	if o.KeyFile != "" {
+		if err := VerifySignature(o.KeyFile, data, signature); err != nil {
+			return errors.Wrapf(err, "verify signature of catalog %s failed", source)
+		}
+	}
+	return nil
+}
+
+// MergeCatalog merges the overlay catalog into FileSha256 and FileURL. The versions in overlay take precedence.
+func MergeCatalog(data []byte) error {
+	catalog, err := ParseCatalog(data)
+	if err != nil {
+		return err
+	}
+	for name, arches := range catalog {
+		for arch, versions := range arches {
+			for v, entry := range versions {
+				setNested(FileSha256, name, arch, v, entry.Sha256)
+				if entry.URL != "" {
+					setNested(FileURL, name, arch, v, entry.URL)
+				}
+			}
+		}
+	}
+	catalogOverlay = data
+	return nil
+}
+
+// CatalogOverlay returns the overlay catalog merged. It's nil if no overlay is loaded.
+func CatalogOverlay() []byte {
+	return catalogOverlay
+}
+
+// CatalogSignature returns the detached signature of the overlay catalog. It's nil if the catalog is not signed.
+func CatalogSignature() []byte {
+	return catalogSignature
+}
+
+// SupportedVersions returns the sorted versions of each component and arch in the merged catalog.
+func SupportedVersions() map[string]map[string][]string {
+	result := make(map[string]map[string][]string, len(FileSha256))
+	for name, arches := range FileSha256 {
+		result[name] = make(map[string][]string, len(arches))
+		for arch, versions := range arches {
+			list := make([]string, 0, len(versions))
+			for v := range versions {
+				list = append(list, v)
+			}
+			sort.Slice(list, func(i, j int) bool { return versionLess(list[i], list[j]) })
+			result[name][arch] = list
+		}
+	}
+	return result
+}
+
+func setNested(m map[string]map[string]map[string]string, name, arch, version, value string) {
+	if _, ok := m[name]; !ok {
+		m[name] = map[string]map[string]string{}
+	}
+	if _, ok := m[name][arch]; !ok {
+		m[name][arch] = map[string]string{}
+	}
+	m[name][arch][version] = value
+}
+
+func knownComponents() []string {
+	return []string{kubeadm, kubelet, kubectl, kubecni, etcd, helm, k3s, k8e, docker, cridockerd, crictl,
+		registry, harbor, compose, containerd, crio, runc, calicoctl, buildx}
+}
+
+func knownComponent(name string) bool {
+	for _, c := range knownComponents() {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
+
+func readCatalogSource(source string) ([]byte, error) {
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		data, err := os.ReadFile(source)
+		if err != nil {
+			return nil, errors.Wrapf(errors.WithStack(err), "read catalog %s failed", source)
+		}
+		return data, nil
+	}
+
+	client := &http.Client{Timeout: time.Minute}
+	resp, err := client.Get(source)
+	if err != nil {
+		return nil, errors.Wrapf(errors.WithStack(err), "download catalog %s failed", source)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("download catalog %s failed: %s", source, resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrapf(errors.WithStack(err), "download catalog %s failed", source)
+	}
+	return data, nil
+}
+
+func versionLess(a, b string) bool {
+	va, errA := versionutil.ParseGeneric(a)
+	vb, errB := versionutil.ParseGeneric(b)
+	if errA != nil || errB != nil {
+		return a < b
+	}
+	return va.LessThan(vb)
+}
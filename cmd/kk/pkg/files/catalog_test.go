@@ -0,0 +1,252 @@
+/*
+ Copyright 2024 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package files
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/crypto/openpgp" //nolint:staticcheck
+	"golang.org/x/crypto/openpgp/armor"
+)
+
+const testCatalog = `
+kubeadm:
+  amd64:
+    v1.99.1:
+      sha256: 1111111111111111111111111111111111111111111111111111111111111abc
+      url: https://mirror.example.com/kubeadm
+etcd:
+  arm64:
+    v3.99.0: 2222222222222222222222222222222222222222222222222222222222222abc
+`
+
+func TestParseCatalog(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{
+			name: "sha256 and entry",
+			data: testCatalog,
+		},
+		{
+			name: "components.json layout",
+			data: `{"runc": {"amd64": {"v1.9.0": "3333333333333333333333333333333333333333333333333333333333333abc"}}}`,
+		},
+		{
+			name:    "unknown component",
+			data:    `{"foo": {"amd64": {"v1.0.0": "3333333333333333333333333333333333333333333333333333333333333abc"}}}`,
+			wantErr: true,
+		},
+		{
+			name:    "unknown arch",
+			data:    `{"runc": {"s390x": {"v1.9.0": "3333333333333333333333333333333333333333333333333333333333333abc"}}}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid sha256",
+			data:    `{"runc": {"amd64": {"v1.9.0": "abc"}}}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseCatalog([]byte(tt.data)); (err != nil) != tt.wantErr {
+				t.Errorf("ParseCatalog() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// resetCatalog restores the catalog after test.
+func resetCatalog(t *testing.T) {
+	sha, url, overlay, signature, o := FileSha256, FileURL, catalogOverlay, catalogSignature, catalogOptions
+	FileSha256 = map[string]map[string]map[string]string{}
+	FileURL = map[string]map[string]map[string]string{}
+	catalogOverlay, catalogSignature, catalogOptions = nil, nil, CatalogOptions{}
+	t.Cleanup(func() {
+		FileSha256, FileURL, catalogOverlay, catalogSignature, catalogOptions = sha, url, overlay, signature, o
+	})
+}
+
+func TestMergeCatalog(t *testing.T) {
+	resetCatalog(t)
+	setNested(FileSha256, kubeadm, amd64, "v1.30.0", "0000000000000000000000000000000000000000000000000000000000000abc")
+	if err := MergeCatalog([]byte(testCatalog)); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := SupportedVersions()[kubeadm][amd64]; fmt.Sprint(got) != "[v1.30.0 v1.99.1]" {
+		t.Errorf("SupportedVersions() = %v, want [v1.30.0 v1.99.1]", got)
+	}
+	b := NewKubeBinary(kubeadm, amd64, "v1.99.1", t.TempDir(), nil)
+	if b.Url != "https://mirror.example.com/kubeadm" {
+		t.Errorf("Url = %v, want the url in catalog", b.Url)
+	}
+	if b.GetSha256() != "1111111111111111111111111111111111111111111111111111111111111abc" {
+		t.Errorf("GetSha256() = %v, want the sha256 in catalog", b.GetSha256())
+	}
+	if CatalogOverlay() == nil {
+		t.Errorf("CatalogOverlay() = nil, want the overlay catalog")
+	}
+}
+
+func TestLoadCatalog(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "catalog.yaml")
+	if err := os.WriteFile(source, []byte(testCatalog), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// sign the catalog with a new key.
+	entity, err := openpgp.NewEntity("kubekey", "", "kubekey@example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature := new(bytes.Buffer)
+	if err := openpgp.ArmoredDetachSign(signature, entity, bytes.NewReader([]byte(testCatalog)), nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(source+".asc", signature.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	key := new(bytes.Buffer)
+	w, err := armor.Encode(key, openpgp.PublicKeyType, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := entity.Serialize(w); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	keyFile := filepath.Join(dir, "key.asc")
+	if err := os.WriteFile(keyFile, key.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	otherEntity, err := openpgp.NewEntity("other", "", "other@example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey := new(bytes.Buffer)
+	w, err = armor.Encode(otherKey, openpgp.PublicKeyType, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := otherEntity.Serialize(w); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	otherKeyFile := filepath.Join(dir, "other.asc")
+	if err := os.WriteFile(otherKeyFile, otherKey.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		o       CatalogOptions
+		wantErr bool
+	}{
+		{
+			name:    "no verification",
+			o:       CatalogOptions{Source: source},
+			wantErr: true,
+		},
+		{
+			name: "insecure",
+			o:    CatalogOptions{Source: source, Insecure: true},
+		},
+		{
+			name: "checksum",
+			o:    CatalogOptions{Source: source, Sha256: fmt.Sprintf("%x", sha256.Sum256([]byte(testCatalog)))},
+		},
+		{
+			name:    "checksum mismatch",
+			o:       CatalogOptions{Source: source, Sha256: fmt.Sprintf("%x", sha256.Sum256(nil))},
+			wantErr: true,
+		},
+		{
+			name: "signature",
+			o:    CatalogOptions{Source: source, KeyFile: keyFile},
+		},
+		{
+			name:    "signed by other key",
+			o:       CatalogOptions{Source: source, KeyFile: otherKeyFile},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetCatalog(t)
+			if err := LoadCatalog(tt.o); (err != nil) != tt.wantErr {
+				t.Errorf("LoadCatalog() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// the catalog carried by an artifact is verified by the same options.
+	artifactTests := []struct {
+		name    string
+		o       CatalogOptions
+		wantErr bool
+	}{
+		{
+			name:    "artifact no verification",
+			wantErr: true,
+		},
+		{
+			name: "artifact insecure",
+			o:    CatalogOptions{Insecure: true},
+		},
+		{
+			name: "artifact checksum",
+			o:    CatalogOptions{Sha256: fmt.Sprintf("%x", sha256.Sum256([]byte(testCatalog)))},
+		},
+		{
+			name:    "artifact checksum mismatch",
+			o:       CatalogOptions{Sha256: fmt.Sprintf("%x", sha256.Sum256(nil))},
+			wantErr: true,
+		},
+		{
+			name: "artifact signature",
+			o:    CatalogOptions{KeyFile: keyFile},
+		},
+		{
+			name:    "artifact signed by other key",
+			o:       CatalogOptions{KeyFile: otherKeyFile},
+			wantErr: true,
+		},
+	}
+	for _, tt := range artifactTests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetCatalog(t)
+			SetCatalogOptions(tt.o)
+			loaded, err := LoadArtifactCatalog(source)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("LoadArtifactCatalog() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if loaded == tt.wantErr {
+				t.Errorf("LoadArtifactCatalog() loaded = %v, want %v", loaded, !tt.wantErr)
+			}
+		})
+	}
+}
@@ -50,6 +50,7 @@ const (
 	harbor     = "harbor"
 	compose    = "compose"
 	containerd = "containerd"
+	crio       = "crio"
 	runc       = "runc"
 	calicoctl  = "calicoctl"
 	buildx     = "buildx"
@@ -66,6 +67,7 @@ const (
 	KUBE       = "kube"
 	REGISTRY   = "registry"
 	CONTAINERD = "containerd"
+	CRIO       = "crio"
 	RUNC       = "runc"
 	BUILD      = "buildx"
 )
@@ -86,9 +88,12 @@ type KubeBinary struct {
 	Arch     string
 	Version  string
 	Url      string
-	BaseDir  string
-	Zone     string
-	getCmd   func(path, url string) string
+	// ChecksumUrl points to the checksum file published beside the binary. It is used when
+	// 'version/components.json' has no sha256 for the version.
+	ChecksumUrl string
+	BaseDir     string
+	Zone        string
+	getCmd      func(path, url string) string
 }
 
 func NewKubeBinary(name, arch, version, prePath string, getCmd func(path, url string) string) *KubeBinary {
@@ -213,6 +218,12 @@ func NewKubeBinary(name, arch, version, prePath string, getCmd func(path, url st
 		if component.Zone == "cn" {
 			component.Url = fmt.Sprintf("https://kubernetes-release.pek3b.qingstor.com/containerd/containerd/releases/download/v%s/containerd-%s-linux-%s.tar.gz", version, version, arch)
 		}
+	case crio:
+		// the static bundle of cri-o ships crio, conmon, crun, runc, pinns, crictl and the cni plugins.
+		component.Type = CRIO
+		component.FileName = fmt.Sprintf("cri-o.%s.%s.tar.gz", arch, version)
+		component.Url = fmt.Sprintf("https://storage.googleapis.com/cri-o/artifacts/cri-o.%s.%s.tar.gz", arch, version)
+		component.ChecksumUrl = component.Url + ".sha256sum"
 	case runc:
 		component.Type = RUNC
 		component.FileName = fmt.Sprintf("runc.%s", arch)
@@ -235,6 +246,14 @@ func NewKubeBinary(name, arch, version, prePath string, getCmd func(path, url st
 		logger.Log.Fatalf("unsupported kube binaries %s", name)
 	}
 
+	// the url set by the overlay catalog takes precedence.
+	if url := FileURL[name][arch][version]; url != "" {
+		component.Url = url
+		if component.ChecksumUrl != "" {
+			component.ChecksumUrl = url + ".sha256sum"
+		}
+	}
+
 	if component.BaseDir == "" {
 		component.BaseDir = filepath.Join(prePath, component.Type, component.Version, component.Arch)
 	}
@@ -266,10 +285,52 @@ func (b *KubeBinary) GetCmd() string {
 
 func (b *KubeBinary) GetSha256() string {
 	s := FileSha256[b.ID][b.Arch][b.Version]
+	if s == "" && b.ChecksumUrl != "" {
+		s, _ = ParseChecksumFile(b.checksumPath(), b.FileName)
+	}
 	return s
 }
 
+func (b *KubeBinary) checksumPath() string {
+	return b.Path() + ".sha256sum"
+}
+
+// DownloadChecksum downloads the checksum file published beside the binary. It does nothing
+// if the checksum of the version is built in.
+func (b *KubeBinary) DownloadChecksum() error {
+	if b.ChecksumUrl == "" || FileSha256[b.ID][b.Arch][b.Version] != "" {
+		return nil
+	}
+	if output, err := exec.Command("/bin/sh", "-c", b.getCmd(b.checksumPath(), b.ChecksumUrl)).CombinedOutput(); err != nil {
+		return errors.Wrapf(err, "Failed to download the checksum of %s: %s", b.ID, string(output))
+	}
+	return nil
+}
+
+// ParseChecksumFile returns the sha256 of fileName from a checksum file in the GNU coreutils format.
+func ParseChecksumFile(path, fileName string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+		switch len(fields) {
+		case 1:
+			return fields[0], nil
+		case 2:
+			if filepath.Base(strings.TrimPrefix(fields[1], "*")) == fileName {
+				return fields[0], nil
+			}
+		}
+	}
+	return "", errors.Errorf("no checksum of %s found in %s", fileName, path)
+}
+
 func (b *KubeBinary) Download() error {
+	if err := b.DownloadChecksum(); err != nil {
+		return err
+	}
 	for i := 5; i > 0; i-- {
 		cmd := exec.Command("/bin/sh", "-c", b.GetCmd())
 		stdout, err := cmd.StdoutPipe()
@@ -314,7 +375,7 @@ func (b *KubeBinary) Download() error {
 
 // SHA256Check is used to hash checks on downloaded binary. (sha256)
 func (b *KubeBinary) SHA256Check() error {
-	output, err := sha256sum(b.Path())
+	output, err := Sha256sum(b.Path())
 	if err != nil {
 		return errors.Wrap(err, fmt.Sprintf("Failed to check SHA256 of %s", b.Path()))
 	}
@@ -337,7 +398,7 @@ func SHA256CheckEqual(filePath string, checksum string) (bool, error) {
 		return false, nil
 	}
 
-	output, err := sha256sum(filePath)
+	output, err := Sha256sum(filePath)
 	if err != nil {
 		return false, errors.Wrap(err, fmt.Sprintf("Failed to check SHA256 of %s", filePath))
 	}
@@ -345,7 +406,8 @@ func SHA256CheckEqual(filePath string, checksum string) (bool, error) {
 	return output == checksum, nil
 }
 
-func sha256sum(path string) (string, error) {
+// Sha256sum returns the hex encoded sha256 of the file.
+func Sha256sum(path string) (string, error) {
 	file, err := os.Open(path)
 	if err != nil {
 		return "", err
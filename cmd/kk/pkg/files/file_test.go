@@ -0,0 +1,79 @@
+/*
+ Copyright 2024 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package files
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	kubekeyapiv1alpha2 "github.com/kubesys/kubekey/cmd/kk/apis/kubekey/v1alpha2"
+)
+
+func TestCrioSha256(t *testing.T) {
+	const sum = "3333333333333333333333333333333333333333333333333333333333333abc"
+	for _, arch := range []string{amd64, arm64} {
+		t.Run(arch, func(t *testing.T) {
+			publish := func(path, url string) string {
+				return fmt.Sprintf("printf '%s  cri-o.%s.%s.tar.gz\n' > %s", sum, arch, kubekeyapiv1alpha2.DefaultCrioVersion, path)
+			}
+			binary := NewKubeBinary(crio, arch, kubekeyapiv1alpha2.DefaultCrioVersion, t.TempDir(), publish)
+			if binary.ChecksumUrl != binary.Url+".sha256sum" {
+				t.Fatalf("unexpected checksum url %s", binary.ChecksumUrl)
+			}
+			if err := binary.CreateBaseDir(); err != nil {
+				t.Fatal(err)
+			}
+			if err := binary.DownloadChecksum(); err != nil {
+				t.Fatal(err)
+			}
+			if got := binary.GetSha256(); got != sum {
+				t.Fatalf("expected sha256 %s, got %q", sum, got)
+			}
+		})
+	}
+}
+
+func TestParseChecksumFile(t *testing.T) {
+	cases := []struct {
+		name    string
+		content string
+		want    string
+		wantErr bool
+	}{
+		{name: "gnu", content: "aaa  cri-o.amd64.v1.29.1.tar.gz\n", want: "aaa"},
+		{name: "binary mode with dir", content: "bbb *build/cri-o.amd64.v1.29.1.tar.gz\n", want: "bbb"},
+		{name: "bare", content: "ccc\n", want: "ccc"},
+		{name: "other file", content: "ddd  cri-o.arm64.v1.29.1.tar.gz\n", wantErr: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "sum")
+			if err := os.WriteFile(path, []byte(c.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := ParseChecksumFile(path, "cri-o.amd64.v1.29.1.tar.gz")
+			if (err != nil) != c.wantErr {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if got != c.want {
+				t.Fatalf("expected %q, got %q", c.want, got)
+			}
+		})
+	}
+}
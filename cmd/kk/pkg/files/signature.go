@@ -0,0 +1,81 @@
+/*
+ Copyright 2024 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package files
+
+import (
+	"bytes"
+	"os"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/openpgp" //nolint:staticcheck
+)
+
+// SignatureSuffix is the suffix of the armored detached signature of a file, e.g. catalog.json.asc.
+const SignatureSuffix = ".asc"
+
+// Sign returns the armored detached signature of data. The keyFile is an armored OpenPGP private key, it must not be
+// protected by a passphrase.
+func Sign(keyFile string, data []byte) ([]byte, error) {
+	keyring, err := readKeyRing(keyFile)
+	if err != nil {
+		return nil, err
+	}
+	var signer *openpgp.Entity
+	for _, entity := range keyring {
+		if entity.PrivateKey != nil {
+			signer = entity
+			break
+		}
+	}
+	if signer == nil {
+		return nil, errors.Errorf("no private key found in %s", keyFile)
+	}
+	if signer.PrivateKey.Encrypted {
+		return nil, errors.Errorf("private key in %s is protected by a passphrase", keyFile)
+	}
+
+	signature := new(bytes.Buffer)
+	if err := openpgp.ArmoredDetachSign(signature, signer, bytes.NewReader(data), nil); err != nil {
+		return nil, errors.Wrapf(errors.WithStack(err), "sign with %s failed", keyFile)
+	}
+	return signature.Bytes(), nil
+}
+
+// VerifySignature verifies the armored detached signature of data with the armored OpenPGP public key in keyFile.
+func VerifySignature(keyFile string, data, signature []byte) error {
+	keyring, err := readKeyRing(keyFile)
+	if err != nil {
+		return err
+	}
+	if _, err := openpgp.CheckArmoredDetachedSignature(keyring, bytes.NewReader(data), bytes.NewReader(signature)); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+func readKeyRing(keyFile string) (openpgp.EntityList, error) {
+	key, err := os.Open(keyFile)
+	if err != nil {
+		return nil, errors.Wrapf(errors.WithStack(err), "open key %s failed", keyFile)
+	}
+	defer key.Close()
+	keyring, err := openpgp.ReadArmoredKeyRing(key)
+	if err != nil {
+		return nil, errors.Wrapf(errors.WithStack(err), "read key %s failed", keyFile)
+	}
+	return keyring, nil
+}
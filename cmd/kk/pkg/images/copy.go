@@ -21,6 +21,7 @@ import (
 	"os"
 
 	"github.com/containers/image/v5/copy"
+	"github.com/containers/image/v5/manifest"
 	"github.com/containers/image/v5/signature"
 	"github.com/containers/image/v5/transports/alltransports"
 )
@@ -29,41 +30,57 @@ type CopyImageOptions struct {
 	srcImage           *srcImageOptions
 	destImage          *destImageOptions
 	imageListSelection copy.ImageListSelection
+	// policyFile is the containers-policy.json which the source image is verified against. accept anything if empty.
+	policyFile string
+	// removeSignatures is required when the destination (e.g. OCI layout) can't store signatures.
+	removeSignatures bool
 }
 
-func (c *CopyImageOptions) Copy() error {
-	policyContext, err := getPolicyContext()
+// Copy the image and return the digest of the manifest written to the destination.
+func (c *CopyImageOptions) Copy() (string, error) {
+	policyContext, err := getPolicyContext(c.policyFile)
 	if err != nil {
-		return err
+		return "", err
 	}
 	defer policyContext.Destroy()
 
 	srcRef, err := alltransports.ParseImageName(c.srcImage.imageName)
 	if err != nil {
-		return err
+		return "", err
 	}
 	destRef, err := alltransports.ParseImageName(c.destImage.imageName)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	srcContext := c.srcImage.systemContext()
 	destContext := c.destImage.systemContext()
 
-	_, err = copy.Image(context.Background(), policyContext, destRef, srcRef, &copy.Options{
+	copiedManifest, err := copy.Image(context.Background(), policyContext, destRef, srcRef, &copy.Options{
 		ReportWriter:       os.Stdout,
 		SourceCtx:          srcContext,
 		DestinationCtx:     destContext,
 		ImageListSelection: c.imageListSelection,
+		RemoveSignatures:   c.removeSignatures,
 	})
 	if err != nil {
-		return err
+		return "", err
 	}
-	return nil
+	dgst, err := manifest.Digest(copiedManifest)
+	if err != nil {
+		return "", err
+	}
+	return dgst.String(), nil
 }
 
-func getPolicyContext() (*signature.PolicyContext, error) {
+func getPolicyContext(policyFile string) (*signature.PolicyContext, error) {
 	policy := &signature.Policy{Default: []signature.PolicyRequirement{signature.NewPRInsecureAcceptAnything()}}
+	if policyFile != "" {
+		var err error
+		if policy, err = signature.NewPolicyFromFile(policyFile); err != nil {
+			return nil, err
+		}
+	}
 	return signature.NewPolicyContext(policy)
 }
 
@@ -72,6 +89,7 @@ type Index struct {
 }
 
 type Manifest struct {
+	Digest      string
 	Annotations annotations
 }
 
@@ -17,6 +17,7 @@
 package images
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -169,6 +170,15 @@ func (s *SaveImages) Execute(runtime connector.Runtime) error {
 	if err := coreutil.Mkdir(dirName); err != nil {
 		return errors.Wrapf(errors.WithStack(err), "mkdir %s failed", dirName)
 	}
+	verifyCfg := s.Manifest.Spec.ImageVerification
+	var verification *Verification
+	if verifyCfg != nil {
+		v, err := newVerification(verifyCfg, dirName)
+		if err != nil {
+			return err
+		}
+		verification = v
+	}
 	for index, image := range s.Manifest.Spec.Images {
 		if s.ImageStartIndex > index {
 			continue
@@ -184,6 +194,23 @@ func (s *SaveImages) Execute(runtime connector.Runtime) error {
 		}
 
 		srcName := formatImageName(s.ImageTransport, image)
+		var verifiedBy []string
+		if verification != nil {
+			src := &srcImageOptions{dockerImage: dockerImageOptions{
+				username:       auth.Username,
+				password:       auth.Password,
+				SkipTLSVerify:  auth.SkipTLSVerify,
+				dockerCertPath: auth.CertsPath,
+			}}
+			pinned, by, err := pinImage(context.Background(), verifyCfg, src.systemContext(), srcName)
+			if err != nil {
+				return errors.Wrapf(err, "verify image %s failed", image)
+			}
+			logger.Log.Infof("[%d]Verified: %s", index, pinned)
+			// copy the verified digest instead of the tag which may be changed.
+			srcName = formatImageName(s.ImageTransport, pinned)
+			verifiedBy = by
+		}
 		for _, platform := range s.Manifest.Spec.Arches {
 			arch, variant := ParseArchVariant(platform)
 			// placeholder
@@ -219,11 +246,18 @@ func (s *SaveImages) Execute(runtime connector.Runtime) error {
 					},
 				},
 			}
+			if verification != nil {
+				o.policyFile = verifyCfg.PolicyFile
+				// OCI layout can't store signatures, the verification result is recorded instead.
+				o.removeSignatures = true
+			}
 
 			// Copy image
 			// retry 3 times
+			var dgst string
 			for i := 0; i < 3; i++ {
-				if err := o.Copy(); err != nil {
+				d, err := o.Copy()
+				if err != nil {
 					if i == 2 {
 						return errors.Wrapf(err, "copy image %s failed", srcName)
 					}
@@ -231,8 +265,24 @@ func (s *SaveImages) Execute(runtime connector.Runtime) error {
 					time.Sleep(5 * time.Second)
 					continue
 				}
+				dgst = d
 				break
 			}
+
+			if verification != nil {
+				verification.Images[strings.TrimPrefix(destName, fmt.Sprintf("oci:%s:", dirName))] = VerifiedImage{
+					Source:     strings.TrimPrefix(srcName, "docker://"),
+					Digest:     dgst,
+					VerifiedBy: verifiedBy,
+					VerifiedAt: time.Now().UTC(),
+				}
+			}
+		}
+		if verification != nil {
+			// save after each image, so that the result is kept when export is continued from an image index.
+			if err := verification.Save(dirName, verifyCfg.SigningKey); err != nil {
+				return err
+			}
 		}
 	}
 	return nil
@@ -262,6 +312,22 @@ func (c *CopyImagesToRegistry) Execute(runtime connector.Runtime) error {
 		return errors.Wrap(errors.WithStack(err), "unmarshal index.json failed: %s")
 	}
 
+	keyFile := c.KubeConf.Cluster.Registry.ImageVerificationKey
+	verification, err := LoadVerification(imagesPath, keyFile)
+	if err != nil {
+		return err
+	}
+	if verification != nil {
+		if keyFile == "" {
+			logger.Log.Warnf("%s is not signature checked, set registry.imageVerificationKey to verify it", VerificationFile)
+		}
+		// re-verify the images are the ones verified when exported, before pushing them to the registry.
+		if err := verification.Check(index); err != nil {
+			return err
+		}
+		logger.Log.Infof("All %d images match the verification result in %s", len(index.Manifests), VerificationFile)
+	}
+
 	auths := registry.DockerRegistryAuthEntries(c.KubeConf.Cluster.Registry.Auths)
 
 	manifestList := make(map[string][]manifesttypes.ManifestEntry)
@@ -352,7 +418,7 @@ func (c *CopyImagesToRegistry) Execute(runtime connector.Runtime) error {
 
 		retry, maxRetry := 0, 5
 		for ; retry < maxRetry; retry++ {
-			if err := o.Copy(); err == nil {
+			if _, err := o.Copy(); err == nil {
 				break
 			} else {
 				fmt.Println(errors.WithStack(err))
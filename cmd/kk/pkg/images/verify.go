@@ -0,0 +1,230 @@
+/*
+ Copyright 2024 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package images
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/containers/image/v5/docker"
+	"github.com/containers/image/v5/docker/reference"
+	"github.com/containers/image/v5/transports/alltransports"
+	"github.com/containers/image/v5/types"
+	"github.com/pkg/errors"
+
+	kubekeyv1alpha2 "github.com/kubesys/kubekey/cmd/kk/apis/kubekey/v1alpha2"
+	"github.com/kubesys/kubekey/cmd/kk/pkg/files"
+)
+
+// VerificationFile records the verification result of images in the artifact images dir.
+const VerificationFile = "verification.json"
+
+const (
+	verifiedByPolicy = "policy"
+	verifiedByCosign = "cosign"
+)
+
+// Verification is the verification result of the images exported to the artifact.
+type Verification struct {
+	// PolicySha256 is the sha256 of the containers-policy.json used to verify images.
+	PolicySha256 string                              `json:"policySha256,omitempty"`
+	Cosign       *kubekeyv1alpha2.CosignVerification `json:"cosign,omitempty"`
+	// Images is keyed by the ref name in the OCI index, e.g. docker.io/calico/cni:v3.20.0-amd64.
+	Images map[string]VerifiedImage `json:"images"`
+}
+
+// VerifiedImage is an image which passed the verification.
+type VerifiedImage struct {
+	// Source is the verified image pinned by digest, e.g. docker.io/calico/cni@sha256:...
+	Source string `json:"source"`
+	// Digest of the manifest stored in the artifact.
+	Digest     string    `json:"digest"`
+	VerifiedBy []string  `json:"verifiedBy"`
+	VerifiedAt time.Time `json:"verifiedAt"`
+}
+
+// LoadVerification reads the verification result in dir. It returns nil if the images are exported without verification.
+// If keyFile is set, the verification result must be signed by the key, so that it can't be forged along with the images.
+func LoadVerification(dir, keyFile string) (*Verification, error) {
+	data, err := os.ReadFile(filepath.Join(dir, VerificationFile))
+	if os.IsNotExist(err) {
+		if keyFile != "" {
+			return nil, errors.Errorf("%s is required by the image verification key, but the images are exported without verification", VerificationFile)
+		}
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(errors.WithStack(err), "read %s failed", VerificationFile)
+	}
+	if keyFile != "" {
+		signature, err := os.ReadFile(filepath.Join(dir, VerificationFile+files.SignatureSuffix))
+		if err != nil {
+			return nil, errors.Wrapf(errors.WithStack(err), "read signature of %s failed", VerificationFile)
+		}
+		if err := files.VerifySignature(keyFile, data, signature); err != nil {
+			return nil, errors.Wrapf(err, "verify signature of %s failed", VerificationFile)
+		}
+	}
+	v := &Verification{}
+	if err := json.Unmarshal(data, v); err != nil {
+		return nil, errors.Wrapf(errors.WithStack(err), "unmarshal %s failed", VerificationFile)
+	}
+	return v, nil
+}
+
+// Save the verification result in dir. It's signed with signingKey if set, the signature is saved beside it.
+func (v *Verification) Save(dir, signingKey string) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return errors.Wrapf(errors.WithStack(err), "marshal %s failed", VerificationFile)
+	}
+	if err := os.WriteFile(filepath.Join(dir, VerificationFile), data, 0644); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "write %s failed", VerificationFile)
+	}
+	if signingKey == "" {
+		return nil
+	}
+	signature, err := files.Sign(signingKey, data)
+	if err != nil {
+		return errors.Wrapf(err, "sign %s failed", VerificationFile)
+	}
+	if err := os.WriteFile(filepath.Join(dir, VerificationFile+files.SignatureSuffix), signature, 0644); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "write signature of %s failed", VerificationFile)
+	}
+	return nil
+}
+
+// Check every image in the OCI index has been verified and is not modified after exported.
+func (v *Verification) Check(index *Index) error {
+	var failed []string
+	for _, m := range index.Manifests {
+		ref := m.Annotations.RefName
+		image, ok := v.Images[ref]
+		switch {
+		case !ok:
+			failed = append(failed, fmt.Sprintf("%s: not verified", ref))
+		case image.Digest != m.Digest:
+			failed = append(failed, fmt.Sprintf("%s: digest is %s, but %s is verified", ref, m.Digest, image.Digest))
+		}
+	}
+	if len(failed) != 0 {
+		sort.Strings(failed)
+		return errors.Errorf("images in the artifact failed the verification:\n%s", strings.Join(failed, "\n"))
+	}
+	return nil
+}
+
+// newVerification creates the verification of the images exported with cfg. the result of the previous export in dir is kept
+// if the policies are not changed, so that the export can be continued from an image index.
+func newVerification(cfg *kubekeyv1alpha2.ImageVerification, dir string) (*Verification, error) {
+	v := &Verification{Cosign: cfg.Cosign, Images: make(map[string]VerifiedImage)}
+	if cfg.PolicyFile != "" {
+		sum, err := files.Sha256sum(cfg.PolicyFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "read policy file %s failed", cfg.PolicyFile)
+		}
+		v.PolicySha256 = sum
+	}
+
+	old, err := LoadVerification(dir, "")
+	if err != nil {
+		return nil, err
+	}
+	if old != nil && old.PolicySha256 == v.PolicySha256 && cosignEqual(old.Cosign, v.Cosign) {
+		for k, image := range old.Images {
+			v.Images[k] = image
+		}
+	}
+	return v, nil
+}
+
+func cosignEqual(a, b *kubekeyv1alpha2.CosignVerification) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// pinImage verifies the source image with cosign (if configured) and returns the image name pinned by digest,
+// so that the content copied is exactly the one verified.
+func pinImage(ctx context.Context, cfg *kubekeyv1alpha2.ImageVerification, sys *types.SystemContext, imageName string) (string, []string, error) {
+	ref, err := alltransports.ParseImageName(imageName)
+	if err != nil {
+		return "", nil, err
+	}
+	if ref.Transport().Name() != docker.Transport.Name() {
+		return "", nil, errors.Errorf("image verification only supports the docker transport, but got %s", imageName)
+	}
+	dgst, err := docker.GetDigest(ctx, sys, ref)
+	if err != nil {
+		return "", nil, errors.Wrapf(errors.WithStack(err), "get digest of %s failed", imageName)
+	}
+	pinned := fmt.Sprintf("%s@%s", reference.TrimNamed(ref.DockerReference()).String(), dgst.String())
+
+	var verifiedBy []string
+	if cfg.PolicyFile != "" {
+		// the policy is evaluated when copying the image.
+		verifiedBy = append(verifiedBy, verifiedByPolicy)
+	}
+	if cfg.Cosign != nil {
+		if err := cosignVerify(ctx, cfg.Cosign, sys, pinned); err != nil {
+			return "", nil, err
+		}
+		verifiedBy = append(verifiedBy, verifiedByCosign)
+	}
+	return pinned, verifiedBy, nil
+}
+
+// cosignArgs returns the arguments of "cosign verify".
+func cosignArgs(cfg *kubekeyv1alpha2.CosignVerification, sys *types.SystemContext, image string) ([]string, error) {
+	args := []string{"verify", "--output", "json"}
+	switch {
+	case cfg.PublicKey != "":
+		args = append(args, "--key", cfg.PublicKey)
+	case cfg.CertificateIdentity != "" && cfg.CertificateOIDCIssuer != "":
+		args = append(args, "--certificate-identity", cfg.CertificateIdentity, "--certificate-oidc-issuer", cfg.CertificateOIDCIssuer)
+	default:
+		return nil, errors.New("imageVerification.cosign requires publicKey, or certificateIdentity and certificateOIDCIssuer")
+	}
+	if sys != nil && sys.DockerInsecureSkipTLSVerify == types.OptionalBoolTrue {
+		args = append(args, "--allow-insecure-registry")
+	}
+	return append(args, image), nil
+}
+
+func cosignVerify(ctx context.Context, cfg *kubekeyv1alpha2.CosignVerification, sys *types.SystemContext, image string) error {
+	args, err := cosignArgs(cfg, sys, image)
+	if err != nil {
+		return err
+	}
+	bin, err := exec.LookPath("cosign")
+	if err != nil {
+		return errors.Wrap(errors.WithStack(err), "cosign is required to verify images")
+	}
+	// cosign reads the registry credentials from the docker config (docker login).
+	if out, err := exec.CommandContext(ctx, bin, args...).CombinedOutput(); err != nil {
+		return errors.Errorf("cosign verify %s failed: %v\n%s", image, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+/*
+ Copyright 2024 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package images
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"golang.org/x/crypto/openpgp" //nolint:staticcheck
+	"golang.org/x/crypto/openpgp/armor"
+
+	kubekeyv1alpha2 "github.com/kubesys/kubekey/cmd/kk/apis/kubekey/v1alpha2"
+)
+
+func TestVerificationCheck(t *testing.T) {
+	v := &Verification{Images: map[string]VerifiedImage{
+		"docker.io/calico/cni:v3.20.0-amd64": {Digest: "sha256:aaa"},
+	}}
+	manifest := func(ref, digest string) Manifest {
+		m := Manifest{Digest: digest}
+		m.Annotations.RefName = ref
+		return m
+	}
+	tests := []struct {
+		name    string
+		index   *Index
+		wantErr bool
+	}{
+		{
+			name:  "verified",
+			index: &Index{Manifests: []Manifest{manifest("docker.io/calico/cni:v3.20.0-amd64", "sha256:aaa")}},
+		},
+		{
+			name:    "modified",
+			index:   &Index{Manifests: []Manifest{manifest("docker.io/calico/cni:v3.20.0-amd64", "sha256:bbb")}},
+			wantErr: true,
+		},
+		{
+			name:    "not verified",
+			index:   &Index{Manifests: []Manifest{manifest("docker.io/calico/node:v3.20.0-amd64", "sha256:aaa")}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := v.Check(tt.index); (err != nil) != tt.wantErr {
+				t.Errorf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewVerification(t *testing.T) {
+	dir := t.TempDir()
+	policy := filepath.Join(dir, "policy.json")
+	if err := os.WriteFile(policy, []byte(`{"default":[{"type":"reject"}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &kubekeyv1alpha2.ImageVerification{PolicyFile: policy}
+	v, err := newVerification(cfg, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.Images["docker.io/calico/cni:v3.20.0-amd64"] = VerifiedImage{Digest: "sha256:aaa"}
+	if err := v.Save(dir, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	// the previous result is kept with the same policies.
+	v, err = newVerification(cfg, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(v.Images) != 1 {
+		t.Errorf("newVerification() with the same policies got %d images, want 1", len(v.Images))
+	}
+
+	// the previous result is dropped when the policies are changed.
+	v, err = newVerification(&kubekeyv1alpha2.ImageVerification{
+		PolicyFile: policy,
+		Cosign:     &kubekeyv1alpha2.CosignVerification{PublicKey: "cosign.pub"},
+	}, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(v.Images) != 0 {
+		t.Errorf("newVerification() with changed policies got %d images, want 0", len(v.Images))
+	}
+}
+
+func TestCosignArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     *kubekeyv1alpha2.CosignVerification
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "public key",
+			cfg:  &kubekeyv1alpha2.CosignVerification{PublicKey: "cosign.pub"},
+			want: []string{"verify", "--output", "json", "--key", "cosign.pub", "docker.io/library/nginx@sha256:aaa"},
+		},
+		{
+			name: "keyless",
+			cfg: &kubekeyv1alpha2.CosignVerification{
+				CertificateIdentity:   "https://github.com/kubesphere/kubekey/.github/workflows/release.yaml@refs/heads/main",
+				CertificateOIDCIssuer: "https://token.actions.githubusercontent.com",
+			},
+			want: []string{"verify", "--output", "json",
+				"--certificate-identity", "https://github.com/kubesphere/kubekey/.github/workflows/release.yaml@refs/heads/main",
+				"--certificate-oidc-issuer", "https://token.actions.githubusercontent.com",
+				"docker.io/library/nginx@sha256:aaa"},
+		},
+		{
+			name:    "no identity",
+			cfg:     &kubekeyv1alpha2.CosignVerification{CertificateIdentity: "someone"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cosignArgs(tt.cfg, nil, "docker.io/library/nginx@sha256:aaa")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("cosignArgs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("cosignArgs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSignedVerification(t *testing.T) {
+	dir := t.TempDir()
+	writeKey := func(name string, entity *openpgp.Entity, private bool) string {
+		buf := new(bytes.Buffer)
+		blockType := openpgp.PublicKeyType
+		if private {
+			blockType = openpgp.PrivateKeyType
+		}
+		w, err := armor.Encode(buf, blockType, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if private {
+			err = entity.SerializePrivate(w, nil)
+		} else {
+			err = entity.Serialize(w)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Close()
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, buf.Bytes(), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	entity, err := openpgp.NewEntity("kubekey", "", "kubekey@example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := openpgp.NewEntity("other", "", "other@example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signingKey := writeKey("private.asc", entity, true)
+	publicKey := writeKey("public.asc", entity, false)
+	otherKey := writeKey("other.asc", other, false)
+
+	imagesDir := filepath.Join(dir, "images")
+	if err := os.Mkdir(imagesDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	// unsigned verification is rejected when a key is required.
+	if _, err := LoadVerification(imagesDir, publicKey); err == nil {
+		t.Error("LoadVerification() without verification.json succeeded, want error")
+	}
+
+	v := &Verification{Images: map[string]VerifiedImage{"docker.io/calico/cni:v3.20.0-amd64": {Digest: "sha256:aaa"}}}
+	if err := v.Save(imagesDir, signingKey); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := LoadVerification(imagesDir, publicKey); err != nil {
+		t.Errorf("LoadVerification() error = %v", err)
+	} else if !reflect.DeepEqual(got.Images, v.Images) {
+		t.Errorf("LoadVerification() got %v, want %v", got.Images, v.Images)
+	}
+	if _, err := LoadVerification(imagesDir, otherKey); err == nil {
+		t.Error("LoadVerification() with other key succeeded, want error")
+	}
+
+	// the verification modified after signing is rejected.
+	v.Images["docker.io/calico/cni:v3.20.0-amd64"] = VerifiedImage{Digest: "sha256:bbb"}
+	if err := v.Save(imagesDir, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadVerification(imagesDir, publicKey); err == nil {
+		t.Error("LoadVerification() of modified verification succeeded, want error")
+	}
+}
@@ -0,0 +1,209 @@
+/*
+ Copyright 2024 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kubesys/kubekey/cmd/kk/pkg/common"
+	"github.com/kubesys/kubekey/cmd/kk/pkg/core/cache"
+)
+
+func runApplyCmd(t *testing.T, cmd string) {
+	t.Helper()
+	if output, err := exec.Command("/bin/bash", "-c", cmd).CombinedOutput(); err != nil {
+		t.Fatalf("run %q failed: %v: %s", cmd, err, output)
+	}
+}
+
+func writeApplyFile(t *testing.T, file, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readApplyFile(t *testing.T, file string) string {
+	t.Helper()
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestBackupAndRollbackApplyFiles(t *testing.T) {
+	tests := []struct {
+		name string
+		// change is called between the first backup and the rollback.
+		change func(t *testing.T, manifests, config string, backup func())
+		want   map[string]string
+	}{
+		{
+			name: "restore the changed files",
+			change: func(t *testing.T, manifests, config string, _ func()) {
+				writeApplyFile(t, filepath.Join(manifests, "kube-apiserver.yaml"), "apiserver v2")
+				writeApplyFile(t, config, "kubelet v2")
+			},
+			want: map[string]string{"manifests/kube-apiserver.yaml": "apiserver v1", "config.yaml": "kubelet v1"},
+		},
+		{
+			name: "the backup is not overwritten",
+			change: func(t *testing.T, manifests, config string, backup func()) {
+				writeApplyFile(t, filepath.Join(manifests, "kube-apiserver.yaml"), "apiserver v2")
+				writeApplyFile(t, config, "kubelet v2")
+				backup()
+				writeApplyFile(t, config, "kubelet v3")
+			},
+			want: map[string]string{"manifests/kube-apiserver.yaml": "apiserver v1", "config.yaml": "kubelet v1"},
+		},
+		{
+			name:   "the missing file is skipped",
+			change: func(*testing.T, string, string, func()) {},
+			want:   map[string]string{"manifests/kube-apiserver.yaml": "apiserver v1", "config.yaml": "kubelet v1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			manifests := filepath.Join(dir, "manifests")
+			config := filepath.Join(dir, "config.yaml")
+			missing := filepath.Join(dir, "kubeadm-config.yaml")
+			files := []string{manifests, config, missing}
+			backupDir := filepath.Join(dir, "backup")
+			backup := func() { runApplyCmd(t, backupApplyFilesCmd(files, backupDir)) }
+
+			writeApplyFile(t, filepath.Join(manifests, "kube-apiserver.yaml"), "apiserver v1")
+			writeApplyFile(t, config, "kubelet v1")
+			backup()
+			tt.change(t, manifests, config, backup)
+			runApplyCmd(t, rollbackApplyFilesCmd(files, backupDir))
+
+			for file, content := range tt.want {
+				if got := readApplyFile(t, filepath.Join(dir, file)); got != content {
+					t.Errorf("%s = %q, want %q", file, got, content)
+				}
+			}
+			if _, err := os.Stat(missing); !os.IsNotExist(err) {
+				t.Errorf("%s should not be created by the rollback", missing)
+			}
+		})
+	}
+}
+
+func TestConfigMapsCmd(t *testing.T) {
+	backupDir := "/etc/kubernetes/backup/kk-apply-20240101000000"
+	backup := backupConfigMapsCmd(backupDir)
+	for _, want := range []string{
+		"if [ ! -e " + backupDir + "/configmaps.yaml ]",
+		"grep -xE 'configmap/(kubeadm-config|kubelet-config(-[0-9.]+)?)'",
+		"sed '/^ *resourceVersion:/d'",
+	} {
+		if !strings.Contains(backup, want) {
+			t.Errorf("backupConfigMapsCmd() = %q, should contain %q", backup, want)
+		}
+	}
+	// it's wrapped by double quotes in SudoCmd.
+	if strings.ContainsAny(backup, "\"$`") {
+		t.Errorf("backupConfigMapsCmd() = %q, should not contain the characters expanded in double quotes", backup)
+	}
+	if got, want := rollbackConfigMapsCmd(backupDir),
+		"if [ -f "+backupDir+"/configmaps.yaml ]; then /usr/local/bin/kubectl replace -f "+backupDir+"/configmaps.yaml; fi"; got != want {
+		t.Errorf("rollbackConfigMapsCmd() = %q, want %q", got, want)
+	}
+}
+
+func TestApplyNotFailed(t *testing.T) {
+	tests := []struct {
+		name  string
+		cache map[string]interface{}
+		want  bool
+	}{
+		{name: "not applied", want: true},
+		{name: "applied", cache: map[string]interface{}{common.ApplyFailed: false}, want: true},
+		{name: "failed", cache: map[string]interface{}{common.ApplyFailed: true}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &ApplyNotFailed{}
+			p.Init(cache.NewCache(), cache.NewCache())
+			for k, v := range tt.cache {
+				p.ModuleCache.Set(k, v)
+			}
+			got, err := p.PreCheck(nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("PreCheck() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyChanges(t *testing.T) {
+	staticPod := Drift{Category: DriftStaticPod, Node: "node1", Field: "kube-apiserver.audit-log-maxage", Desired: "30", Actual: "7"}
+	version := Drift{Category: DriftVersion, Node: "node1", Field: "kube-apiserver", Desired: "v1.23.17", Actual: "v1.23.10"}
+	kubelet := Drift{Category: DriftKubeletConfig, Node: "node1", Field: "maxPods", Desired: "220", Actual: "110"}
+
+	tests := []struct {
+		name           string
+		staticPods     []Drift
+		kubelet        []Drift
+		wantStaticPods []Drift
+		wantKubelet    []Drift
+	}{
+		{
+			name: "unchanged node is skipped",
+		},
+		{
+			name:       "version is changed by kk upgrade",
+			staticPods: []Drift{version},
+		},
+		{
+			name:           "static pods and kubelet are changed",
+			staticPods:     []Drift{version, staticPod},
+			kubelet:        []Drift{kubelet},
+			wantStaticPods: []Drift{staticPod},
+			wantKubelet:    []Drift{kubelet},
+		},
+		{
+			name:        "only kubelet is changed",
+			kubelet:     []Drift{kubelet},
+			wantKubelet: []Drift{kubelet},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotStaticPods, gotKubelet := applyChanges(tt.staticPods, tt.kubelet)
+			if !reflect.DeepEqual(gotStaticPods, tt.wantStaticPods) {
+				t.Errorf("static pod changes = %v, want %v", gotStaticPods, tt.wantStaticPods)
+			}
+			if !reflect.DeepEqual(gotKubelet, tt.wantKubelet) {
+				t.Errorf("kubelet changes = %v, want %v", gotKubelet, tt.wantKubelet)
+			}
+		})
+	}
+}
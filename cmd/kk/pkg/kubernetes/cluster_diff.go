@@ -0,0 +1,592 @@
+/*
+ Copyright 2024 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/yaml"
+
+	kubekeyv1alpha2 "github.com/kubesys/kubekey/cmd/kk/apis/kubekey/v1alpha2"
+	"github.com/kubesys/kubekey/cmd/kk/pkg/common"
+)
+
+const (
+	DriftNode            = "node"
+	DriftVersion         = "version"
+	DriftStaticPod       = "static-pod"
+	DriftKubeletConfig   = "kubelet-config"
+	DriftCNI             = "cni"
+	DriftRegistryMirrors = "registry-mirrors"
+	DriftEtcd            = "etcd"
+
+	DiffOutputHuman = "human"
+	DiffOutputJSON  = "json"
+
+	// unset is the value of a drift which is not set at all.
+	unset = "<unset>"
+)
+
+// Drift is a difference between the desired cluster configuration and the live cluster.
+type Drift struct {
+	Category string `json:"category"`
+	Node     string `json:"node,omitempty"`
+	Field    string `json:"field"`
+	Desired  string `json:"desired"`
+	Actual   string `json:"actual"`
+}
+
+// ClusterDiff collects the drifts found by the tasks of ClusterDiffModule, the tasks run in parallel.
+type ClusterDiff struct {
+	mu     sync.Mutex
+	Drifts []Drift
+}
+
+func (d *ClusterDiff) Add(drifts ...Drift) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.Drifts = append(d.Drifts, drifts...)
+}
+
+// Drifted returns true if the live cluster drifts from the configuration.
+func (d *ClusterDiff) Drifted() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.Drifts) > 0
+}
+
+// Print prints the drifts sorted by category and node in the human or json output.
+func (d *ClusterDiff) Print(w io.Writer, output string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	drifts := append([]Drift{}, d.Drifts...)
+	sort.SliceStable(drifts, func(i, j int) bool {
+		if drifts[i].Category != drifts[j].Category {
+			return drifts[i].Category < drifts[j].Category
+		}
+		if drifts[i].Node != drifts[j].Node {
+			return drifts[i].Node < drifts[j].Node
+		}
+		return drifts[i].Field < drifts[j].Field
+	})
+
+	switch output {
+	case DiffOutputJSON:
+		b, err := json.MarshalIndent(struct {
+			Drifted bool    `json:"drifted"`
+			Drifts  []Drift `json:"drifts"`
+		}{Drifted: len(drifts) > 0, Drifts: drifts}, "", "  ")
+		if err != nil {
+			return errors.Wrap(errors.WithStack(err), "marshal cluster diff failed")
+		}
+		_, err = fmt.Fprintln(w, string(b))
+		return err
+	case DiffOutputHuman, "":
+		if len(drifts) == 0 {
+			_, err := fmt.Fprintln(w, "No drift found, the cluster matches the configuration.")
+			return err
+		}
+		tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(tw, "CATEGORY\tNODE\tFIELD\tDESIRED\tACTUAL")
+		for _, drift := range drifts {
+			node := drift.Node
+			if node == "" {
+				node = "-"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", drift.Category, node, drift.Field, drift.Desired, drift.Actual)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+		_, err := fmt.Fprintf(w, "\n%d drift(s) found.\n", len(drifts))
+		return err
+	default:
+		return errors.Errorf("unsupported output %s, it must be one of %s and %s", output, DiffOutputHuman, DiffOutputJSON)
+	}
+}
+
+// NodeState is the name, roles and kubelet version of a node.
+type NodeState struct {
+	Name         string
+	ControlPlane bool
+	Worker       bool
+	Version      string
+}
+
+func (n NodeState) roles() string {
+	var roles []string
+	if n.ControlPlane {
+		roles = append(roles, "control-plane")
+	}
+	if n.Worker {
+		roles = append(roles, "worker")
+	}
+	if len(roles) == 0 {
+		return "<none>"
+	}
+	return strings.Join(roles, ",")
+}
+
+// ParseNodes parses the output of "kubectl get nodes -o json".
+func ParseNodes(output string) ([]NodeState, error) {
+	var list corev1.NodeList
+	if err := json.Unmarshal([]byte(output), &list); err != nil {
+		return nil, errors.Wrap(errors.WithStack(err), "parse the nodes failed")
+	}
+	nodes := make([]NodeState, 0, len(list.Items))
+	for _, item := range list.Items {
+		_, master := item.Labels["node-role.kubernetes.io/master"]
+		_, controlPlane := item.Labels["node-role.kubernetes.io/control-plane"]
+		_, worker := item.Labels["node-role.kubernetes.io/worker"]
+		nodes = append(nodes, NodeState{
+			Name:         item.Name,
+			ControlPlane: master || controlPlane,
+			Worker:       worker,
+			Version:      item.Status.NodeInfo.KubeletVersion,
+		})
+	}
+	return nodes, nil
+}
+
+// DiffNodes compares the nodes, their roles and kubelet versions.
+func DiffNodes(desired, actual []NodeState) []Drift {
+	actualNodes := make(map[string]NodeState, len(actual))
+	for _, node := range actual {
+		actualNodes[node.Name] = node
+	}
+
+	var drifts []Drift
+	for _, want := range desired {
+		got, ok := actualNodes[want.Name]
+		if !ok {
+			drifts = append(drifts, Drift{Category: DriftNode, Node: want.Name, Field: "node", Desired: "present", Actual: "absent"})
+			continue
+		}
+		delete(actualNodes, want.Name)
+		if want.roles() != got.roles() {
+			drifts = append(drifts, Drift{Category: DriftNode, Node: want.Name, Field: "roles", Desired: want.roles(), Actual: got.roles()})
+		}
+		if want.Version != "" && normalizeVersion(want.Version) != normalizeVersion(got.Version) {
+			drifts = append(drifts, Drift{Category: DriftVersion, Node: want.Name, Field: "kubelet", Desired: want.Version, Actual: got.Version})
+		}
+	}
+	for _, got := range actual {
+		if _, ok := actualNodes[got.Name]; ok {
+			drifts = append(drifts, Drift{Category: DriftNode, Node: got.Name, Field: "node", Desired: "absent", Actual: "present"})
+		}
+	}
+	return drifts
+}
+
+// ParseStaticPod parses a static pod manifest, it returns the image and the "--key=value" arguments of the first container.
+func ParseStaticPod(manifest string) (string, map[string]string, error) {
+	var pod corev1.Pod
+	if err := yaml.Unmarshal([]byte(manifest), &pod); err != nil {
+		return "", nil, errors.Wrap(errors.WithStack(err), "parse the static pod manifest failed")
+	}
+	if len(pod.Spec.Containers) == 0 {
+		return "", nil, errors.New("no container in the static pod manifest")
+	}
+	container := pod.Spec.Containers[0]
+	args := make(map[string]string)
+	for _, arg := range append(append([]string{}, container.Command...), container.Args...) {
+		if !strings.HasPrefix(arg, "--") {
+			continue
+		}
+		kv := strings.SplitN(strings.TrimPrefix(arg, "--"), "=", 2)
+		if len(kv) == 2 {
+			args[kv[0]] = kv[1]
+		} else {
+			args[kv[0]] = ""
+		}
+	}
+	return container.Image, args, nil
+}
+
+// kubeadmGeneratedArgs are the arguments of the control plane components which kubeadm generates from the
+// ClusterConfiguration (certificates, kubeconfig files, etcd and networking) and the InitConfiguration. They are
+// expected in the static pods although KubeKey does not set them as extra args, so they are not reported when they are
+// only in the static pods. The other arguments which are only in the static pods are added manually.
+var kubeadmGeneratedArgs = map[string]sets.String{
+	"kube-apiserver": sets.NewString(
+		"advertise-address", "allow-privileged", "authorization-mode", "client-ca-file", "enable-admission-plugins",
+		"enable-bootstrap-token-auth", "etcd-cafile", "etcd-certfile", "etcd-keyfile", "etcd-servers", "insecure-port",
+		"kubelet-client-certificate", "kubelet-client-key", "kubelet-preferred-address-types", "proxy-client-cert-file",
+		"proxy-client-key-file", "requestheader-allowed-names", "requestheader-client-ca-file",
+		"requestheader-extra-headers-prefix", "requestheader-group-headers", "requestheader-username-headers",
+		"secure-port", "service-account-issuer", "service-account-key-file", "service-account-signing-key-file",
+		"service-cluster-ip-range", "tls-cert-file", "tls-private-key-file",
+	),
+	"kube-controller-manager": sets.NewString(
+		"allocate-node-cidrs", "authentication-kubeconfig", "authorization-kubeconfig", "client-ca-file",
+		"cluster-cidr", "cluster-name", "cluster-signing-cert-file", "cluster-signing-key-file", "controllers",
+		"kubeconfig", "leader-elect", "node-cidr-mask-size", "node-cidr-mask-size-ipv4", "node-cidr-mask-size-ipv6",
+		"port", "requestheader-client-ca-file", "root-ca-file", "service-account-private-key-file",
+		"service-cluster-ip-range", "use-service-account-credentials",
+	),
+	"kube-scheduler": sets.NewString(
+		"authentication-kubeconfig", "authorization-kubeconfig", "kubeconfig", "leader-elect", "port",
+	),
+}
+
+// DiffArgs compares the desired arguments of a component with the actual ones. The arguments which are only in the
+// actual ones are reported as well, except the ones generated by kubeadm (see kubeadmGeneratedArgs).
+func DiffArgs(component, node string, desired, actual map[string]string) []Drift {
+	keys := sets.StringKeySet(desired)
+	for k := range actual {
+		if !kubeadmGeneratedArgs[component].Has(k) {
+			keys.Insert(k)
+		}
+	}
+
+	var drifts []Drift
+	for _, k := range keys.List() {
+		want, wanted := desired[k]
+		got, ok := actual[k]
+		switch {
+		case !wanted:
+			want = unset
+		case !ok:
+			got = unset
+		case k == "feature-gates" && normalizeList(want) == normalizeList(got):
+			continue
+		case want == got:
+			continue
+		}
+		drifts = append(drifts, Drift{Category: DriftStaticPod, Node: node, Field: fmt.Sprintf("%s --%s", component, k), Desired: want, Actual: got})
+	}
+	return drifts
+}
+
+// DiffImageVersion compares the tag of a control plane image with the desired Kubernetes version.
+func DiffImageVersion(component, node, version, image string) []Drift {
+	tag := image
+	if i := strings.LastIndex(image, ":"); i >= 0 && !strings.Contains(image[i:], "/") {
+		tag = image[i+1:]
+	}
+	if normalizeVersion(tag) == normalizeVersion(version) {
+		return nil
+	}
+	return []Drift{{Category: DriftVersion, Node: node, Field: component, Desired: version, Actual: tag}}
+}
+
+// DiffKubeletConfig compares the desired kubelet configuration with the content of /var/lib/kubelet/config.yaml.
+// Only the fields configured by kk are compared, the rest are defaulted by kubeadm.
+func DiffKubeletConfig(node string, desired map[string]interface{}, actualConfig string) ([]Drift, error) {
+	b, err := json.Marshal(desired)
+	if err != nil {
+		return nil, errors.Wrap(errors.WithStack(err), "marshal the desired kubelet configuration failed")
+	}
+	want := make(map[string]interface{})
+	if err := json.Unmarshal(b, &want); err != nil {
+		return nil, errors.Wrap(errors.WithStack(err), "unmarshal the desired kubelet configuration failed")
+	}
+	got := make(map[string]interface{})
+	if err := yaml.Unmarshal([]byte(actualConfig), &got); err != nil {
+		return nil, errors.Wrap(errors.WithStack(err), "parse the kubelet configuration failed")
+	}
+
+	keys := make([]string, 0, len(want))
+	for k := range want {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var drifts []Drift
+	for _, k := range keys {
+		if equalConfigValue(want[k], got[k]) {
+			continue
+		}
+		drifts = append(drifts, Drift{Category: DriftKubeletConfig, Node: node, Field: k, Desired: formatConfigValue(want[k]), Actual: formatConfigValue(got[k])})
+	}
+	return drifts, nil
+}
+
+// equalConfigValue compares two values of a kubelet configuration. A missing value equals the zero value since
+// kubeadm omits them, and the durations are compared by their length, e.g. 2m equals 2m0s.
+func equalConfigValue(want, got interface{}) bool {
+	if got == nil || want == nil {
+		return isZeroConfigValue(want) && isZeroConfigValue(got)
+	}
+	switch w := want.(type) {
+	case string:
+		g, ok := got.(string)
+		if !ok {
+			return false
+		}
+		if w == g {
+			return true
+		}
+		wd, werr := time.ParseDuration(w)
+		gd, gerr := time.ParseDuration(g)
+		return werr == nil && gerr == nil && wd == gd
+	case map[string]interface{}:
+		g, ok := got.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for k := range g {
+			if _, ok := w[k]; !ok && !isZeroConfigValue(g[k]) {
+				return false
+			}
+		}
+		for k := range w {
+			if !equalConfigValue(w[k], g[k]) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		g, ok := got.([]interface{})
+		if !ok || len(w) != len(g) {
+			return false
+		}
+		for i := range w {
+			if !equalConfigValue(w[i], g[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(want, got)
+	}
+}
+
+func isZeroConfigValue(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Map, reflect.Slice:
+		return rv.Len() == 0
+	default:
+		return rv.IsZero()
+	}
+}
+
+func formatConfigValue(v interface{}) string {
+	if v == nil {
+		return unset
+	}
+	if s, ok := v.(string); ok {
+		return s
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(b)
+}
+
+// cniDaemonSets is the daemonset deployed by every CNI plugin, the version is the tag of its first container.
+var cniDaemonSets = map[string]string{
+	"calico-node":      common.Calico,
+	"kube-flannel-ds":  common.Flannel,
+	"cilium":           common.Cilium,
+	"kube-ovn-cni":     common.Kubeovn,
+	"hybridnet-daemon": common.Hybridnet,
+}
+
+// CNIVersions is the default version of the CNI plugins deployed by kk.
+var CNIVersions = map[string]string{
+	common.Calico:    kubekeyv1alpha2.DefaultCalicoVersion,
+	common.Flannel:   kubekeyv1alpha2.DefaultFlannelVersion,
+	common.Cilium:    kubekeyv1alpha2.DefaultCiliumVersion,
+	common.Kubeovn:   kubekeyv1alpha2.DefaultKubeovnVersion,
+	common.Hybridnet: kubekeyv1alpha2.DefaulthybridnetVersion,
+}
+
+// ParseCNIPlugins parses the "<name> <image>" lines of the daemonsets, it returns the versions of the CNI plugins found.
+func ParseCNIPlugins(output string) map[string]string {
+	plugins := make(map[string]string)
+	for _, line := range strings.Split(strings.ReplaceAll(output, "\r\n", "\n"), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		plugin, ok := cniDaemonSets[fields[0]]
+		if !ok {
+			continue
+		}
+		var version string
+		if i := strings.LastIndex(fields[1], ":"); i >= 0 && !strings.Contains(fields[1][i:], "/") {
+			version = fields[1][i+1:]
+		}
+		plugins[plugin] = version
+	}
+	return plugins
+}
+
+// DiffCNI compares the desired CNI plugin and version with the plugins found in the cluster.
+func DiffCNI(plugin, version string, actual map[string]string) []Drift {
+	var drifts []Drift
+	if plugin != "" && plugin != "none" {
+		got, ok := actual[plugin]
+		switch {
+		case !ok:
+			drifts = append(drifts, Drift{Category: DriftCNI, Field: "plugin", Desired: plugin, Actual: unset})
+		case version != "" && normalizeVersion(version) != normalizeVersion(got):
+			drifts = append(drifts, Drift{Category: DriftCNI, Field: fmt.Sprintf("%s version", plugin), Desired: version, Actual: got})
+		}
+	}
+
+	others := make([]string, 0, len(actual))
+	for p := range actual {
+		if p != plugin {
+			others = append(others, p)
+		}
+	}
+	sort.Strings(others)
+	for _, p := range others {
+		drifts = append(drifts, Drift{Category: DriftCNI, Field: "plugin", Desired: unset, Actual: p})
+	}
+	return drifts
+}
+
+var (
+	containerdDockerMirrors = regexp.MustCompile(`(?s)mirrors\."docker\.io"\]\s*endpoint\s*=\s*\[([^\]]*)\]`)
+	crioMirrorLocation      = regexp.MustCompile(`(?s)\[\[registry\.mirror\]\]\s*location\s*=\s*"([^"]*)"`)
+	quoted                  = regexp.MustCompile(`"([^"]*)"`)
+)
+
+// ParseRegistryMirrors parses the docker.io mirrors of the container manager from its configuration file.
+func ParseRegistryMirrors(containerManager, config string) ([]string, error) {
+	if strings.TrimSpace(config) == "" {
+		return nil, nil
+	}
+	var mirrors []string
+	switch containerManager {
+	case common.Docker, common.Isula, "":
+		var daemon struct {
+			RegistryMirrors []string `json:"registry-mirrors"`
+		}
+		if err := json.Unmarshal([]byte(config), &daemon); err != nil {
+			return nil, errors.Wrap(errors.WithStack(err), "parse the daemon.json failed")
+		}
+		mirrors = daemon.RegistryMirrors
+	case common.Containerd:
+		if m := containerdDockerMirrors.FindStringSubmatch(config); m != nil {
+			for _, endpoint := range quoted.FindAllStringSubmatch(m[1], -1) {
+				// the upstream registry is always the last endpoint.
+				if endpoint[1] != "https://registry-1.docker.io" {
+					mirrors = append(mirrors, endpoint[1])
+				}
+			}
+		}
+	case common.Crio:
+		for _, m := range crioMirrorLocation.FindAllStringSubmatch(config, -1) {
+			mirrors = append(mirrors, m[1])
+		}
+	default:
+		return nil, errors.Errorf("unsupported container manager %s", containerManager)
+	}
+	return mirrors, nil
+}
+
+// DiffRegistryMirrors compares the registry mirrors in order, the schemes are ignored since cri-o does not keep them.
+func DiffRegistryMirrors(node string, desired, actual []string) []Drift {
+	if normalizeMirrors(desired) == normalizeMirrors(actual) {
+		return nil
+	}
+	return []Drift{{Category: DriftRegistryMirrors, Node: node, Field: "docker.io", Desired: formatList(desired), Actual: formatList(actual)}}
+}
+
+func normalizeMirrors(mirrors []string) string {
+	normalized := make([]string, 0, len(mirrors))
+	for _, mirror := range mirrors {
+		mirror = strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(mirror), "http://"), "https://")
+		normalized = append(normalized, strings.TrimSuffix(mirror, "/"))
+	}
+	return strings.Join(normalized, ",")
+}
+
+// ParseEtcdMembers parses the member names from the output of "etcdctl member list -w json".
+func ParseEtcdMembers(output string) ([]string, error) {
+	var list struct {
+		Members []struct {
+			Name string `json:"name"`
+		} `json:"members"`
+	}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(output)), &list); err != nil {
+		return nil, errors.Wrap(errors.WithStack(err), "parse the etcd member list failed")
+	}
+	members := make([]string, 0, len(list.Members))
+	for _, m := range list.Members {
+		members = append(members, m.Name)
+	}
+	return members, nil
+}
+
+// DiffEtcdMembers compares the desired etcd members with the actual ones.
+func DiffEtcdMembers(desired, actual []string) []Drift {
+	actualMembers := make(map[string]bool, len(actual))
+	for _, m := range actual {
+		actualMembers[m] = true
+	}
+	desiredMembers := make(map[string]bool, len(desired))
+	var drifts []Drift
+	for _, m := range desired {
+		desiredMembers[m] = true
+		if !actualMembers[m] {
+			drifts = append(drifts, Drift{Category: DriftEtcd, Field: "member", Desired: m, Actual: unset})
+		}
+	}
+	for _, m := range actual {
+		if !desiredMembers[m] {
+			drifts = append(drifts, Drift{Category: DriftEtcd, Field: "member", Desired: unset, Actual: m})
+		}
+	}
+	return drifts
+}
+
+func normalizeVersion(version string) string {
+	version = strings.TrimSpace(version)
+	if version != "" && !strings.HasPrefix(version, "v") {
+		version = "v" + version
+	}
+	return version
+}
+
+// normalizeList sorts a comma separated list, e.g. the feature gates.
+func normalizeList(list string) string {
+	items := strings.Split(list, ",")
+	for i := range items {
+		items[i] = strings.TrimSpace(items[i])
+	}
+	sort.Strings(items)
+	return strings.Join(items, ",")
+}
+
+func formatList(list []string) string {
+	if len(list) == 0 {
+		return unset
+	}
+	return strings.Join(list, ",")
+}
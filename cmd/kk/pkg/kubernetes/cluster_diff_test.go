@@ -0,0 +1,286 @@
+/*
+ Copyright 2024 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffNodes(t *testing.T) {
+	output := `{"items": [
+  {"metadata": {"name": "node1", "labels": {"node-role.kubernetes.io/control-plane": ""}},
+   "status": {"nodeInfo": {"kubeletVersion": "v1.23.17"}}},
+  {"metadata": {"name": "node2", "labels": {"node-role.kubernetes.io/worker": ""}},
+   "status": {"nodeInfo": {"kubeletVersion": "v1.23.10"}}},
+  {"metadata": {"name": "node4", "labels": {"node-role.kubernetes.io/worker": ""}},
+   "status": {"nodeInfo": {"kubeletVersion": "v1.23.17"}}}
+]}`
+	actual, err := ParseNodes(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	desired := []NodeState{
+		{Name: "node1", ControlPlane: true, Worker: true, Version: "v1.23.17"},
+		{Name: "node2", Worker: true, Version: "v1.23.17"},
+		{Name: "node3", Worker: true, Version: "v1.23.17"},
+	}
+	want := []Drift{
+		{Category: DriftNode, Node: "node1", Field: "roles", Desired: "control-plane,worker", Actual: "control-plane"},
+		{Category: DriftVersion, Node: "node2", Field: "kubelet", Desired: "v1.23.17", Actual: "v1.23.10"},
+		{Category: DriftNode, Node: "node3", Field: "node", Desired: "present", Actual: "absent"},
+		{Category: DriftNode, Node: "node4", Field: "node", Desired: "absent", Actual: "present"},
+	}
+	if got := DiffNodes(desired, actual); !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffNodes() = %v, want %v", got, want)
+	}
+}
+
+func TestDiffStaticPod(t *testing.T) {
+	manifest := `apiVersion: v1
+kind: Pod
+metadata:
+  name: kube-apiserver
+  namespace: kube-system
+spec:
+  containers:
+  - command:
+    - kube-apiserver
+    - --advertise-address=192.168.0.2
+    - --bind-address=0.0.0.0
+    - --feature-gates=TTLAfterFinished=true,RotateKubeletServerCertificate=true
+    - --profiling=true
+    - --anonymous-auth=true
+    image: kubesphere/kube-apiserver:v1.23.10
+    name: kube-apiserver
+`
+	image, args, err := ParseStaticPod(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if image != "kubesphere/kube-apiserver:v1.23.10" {
+		t.Errorf("image = %s", image)
+	}
+
+	desired := map[string]string{
+		"bind-address":      "0.0.0.0",
+		"feature-gates":     "RotateKubeletServerCertificate=true,TTLAfterFinished=true",
+		"profiling":         "false",
+		"audit-log-maxsize": "200",
+	}
+	want := []Drift{
+		{Category: DriftStaticPod, Node: "node1", Field: "kube-apiserver --anonymous-auth", Desired: unset, Actual: "true"},
+		{Category: DriftStaticPod, Node: "node1", Field: "kube-apiserver --audit-log-maxsize", Desired: "200", Actual: unset},
+		{Category: DriftStaticPod, Node: "node1", Field: "kube-apiserver --profiling", Desired: "false", Actual: "true"},
+	}
+	if got := DiffArgs("kube-apiserver", "node1", desired, args); !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffArgs() = %v, want %v", got, want)
+	}
+
+	wantVersion := []Drift{{Category: DriftVersion, Node: "node1", Field: "kube-apiserver", Desired: "v1.23.17", Actual: "v1.23.10"}}
+	if got := DiffImageVersion("kube-apiserver", "node1", "v1.23.17", image); !reflect.DeepEqual(got, wantVersion) {
+		t.Errorf("DiffImageVersion() = %v, want %v", got, wantVersion)
+	}
+	if got := DiffImageVersion("kube-apiserver", "node1", "v1.23.10", "registry:5000/kube-apiserver:v1.23.10"); got != nil {
+		t.Errorf("DiffImageVersion() = %v, want nil", got)
+	}
+}
+
+func TestDiffKubeletConfig(t *testing.T) {
+	desired := map[string]interface{}{
+		"maxPods":                 110,
+		"podPidsLimit":            10000,
+		"readOnlyPort":            0,
+		"rotateCertificates":      true,
+		"clusterDNS":              []string{"169.254.25.10"},
+		"evictionSoftGracePeriod": map[string]string{"memory.available": "2m"},
+		"featureGates":            map[string]bool{"RotateKubeletServerCertificate": true},
+	}
+	actual := `apiVersion: kubelet.config.k8s.io/v1beta1
+kind: KubeletConfiguration
+clusterDNS:
+- 169.254.25.10
+evictionSoftGracePeriod:
+  memory.available: 2m0s
+featureGates:
+  RotateKubeletServerCertificate: true
+  SeccompDefault: true
+maxPods: 200
+podPidsLimit: 10000
+rotateCertificates: true
+`
+	got, err := DiffKubeletConfig("node1", desired, actual)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Drift{
+		{Category: DriftKubeletConfig, Node: "node1", Field: "featureGates",
+			Desired: `{"RotateKubeletServerCertificate":true}`, Actual: `{"RotateKubeletServerCertificate":true,"SeccompDefault":true}`},
+		{Category: DriftKubeletConfig, Node: "node1", Field: "maxPods", Desired: "110", Actual: "200"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffKubeletConfig() = %v, want %v", got, want)
+	}
+}
+
+func TestDiffCNI(t *testing.T) {
+	output := "calico-node calico/node:v3.26.1\r\nkube-proxy kubesphere/kube-proxy:v1.23.17\r\nkube-flannel-ds flannel/flannel:v0.21.3\r\n"
+	plugins := ParseCNIPlugins(output)
+	if want := map[string]string{"calico": "v3.26.1", "flannel": "v0.21.3"}; !reflect.DeepEqual(plugins, want) {
+		t.Fatalf("ParseCNIPlugins() = %v, want %v", plugins, want)
+	}
+
+	tests := []struct {
+		plugin  string
+		version string
+		want    []Drift
+	}{
+		{
+			plugin:  "calico",
+			version: "v3.27.4",
+			want: []Drift{
+				{Category: DriftCNI, Field: "calico version", Desired: "v3.27.4", Actual: "v3.26.1"},
+				{Category: DriftCNI, Field: "plugin", Desired: unset, Actual: "flannel"},
+			},
+		},
+		{
+			plugin:  "cilium",
+			version: "v1.15.3",
+			want: []Drift{
+				{Category: DriftCNI, Field: "plugin", Desired: "cilium", Actual: unset},
+				{Category: DriftCNI, Field: "plugin", Desired: unset, Actual: "calico"},
+				{Category: DriftCNI, Field: "plugin", Desired: unset, Actual: "flannel"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.plugin, func(t *testing.T) {
+			if got := DiffCNI(tt.plugin, tt.version, plugins); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffCNI() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRegistryMirrors(t *testing.T) {
+	tests := []struct {
+		containerManager string
+		config           string
+		want             []string
+	}{
+		{
+			containerManager: "docker",
+			config:           `{"log-opts": {"max-size": "5m"}, "registry-mirrors": ["https://mirror.example.com"]}`,
+			want:             []string{"https://mirror.example.com"},
+		},
+		{
+			containerManager: "containerd",
+			config: `    [plugins."io.containerd.grpc.v1.cri".registry]
+        [plugins."io.containerd.grpc.v1.cri".registry.mirrors]
+        [plugins."io.containerd.grpc.v1.cri".registry.mirrors."docker.io"]
+          endpoint = ["https://mirror.example.com", "https://registry-1.docker.io"]
+        [plugins."io.containerd.grpc.v1.cri".registry.mirrors."harbor.local"]
+          endpoint = ["http://harbor.local"]
+`,
+			want: []string{"https://mirror.example.com"},
+		},
+		{
+			containerManager: "crio",
+			config: `[[registry]]
+prefix = "docker.io"
+location = "registry-1.docker.io"
+
+[[registry.mirror]]
+location = "mirror.example.com"
+`,
+			want: []string{"mirror.example.com"},
+		},
+		{
+			containerManager: "containerd",
+			config:           "",
+			want:             nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.containerManager, func(t *testing.T) {
+			got, err := ParseRegistryMirrors(tt.containerManager, tt.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseRegistryMirrors() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if got := DiffRegistryMirrors("node1", []string{"https://mirror.example.com/"}, []string{"mirror.example.com"}); got != nil {
+		t.Errorf("DiffRegistryMirrors() = %v, want nil", got)
+	}
+	want := []Drift{{Category: DriftRegistryMirrors, Node: "node1", Field: "docker.io", Desired: "https://mirror.example.com", Actual: unset}}
+	if got := DiffRegistryMirrors("node1", []string{"https://mirror.example.com"}, nil); !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffRegistryMirrors() = %v, want %v", got, want)
+	}
+}
+
+func TestDiffEtcdMembers(t *testing.T) {
+	actual, err := ParseEtcdMembers(`{"header":{"cluster_id":1},"members":[{"ID":1,"name":"etcd-node1"},{"ID":2,"name":"etcd-node4"}]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Drift{
+		{Category: DriftEtcd, Field: "member", Desired: "etcd-node2", Actual: unset},
+		{Category: DriftEtcd, Field: "member", Desired: unset, Actual: "etcd-node4"},
+	}
+	if got := DiffEtcdMembers([]string{"etcd-node1", "etcd-node2"}, actual); !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffEtcdMembers() = %v, want %v", got, want)
+	}
+}
+
+func TestClusterDiffPrint(t *testing.T) {
+	diff := &ClusterDiff{}
+	var buf bytes.Buffer
+	if err := diff.Print(&buf, DiffOutputJSON); err != nil {
+		t.Fatal(err)
+	}
+	var report struct {
+		Drifted bool    `json:"drifted"`
+		Drifts  []Drift `json:"drifts"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Drifted || report.Drifts == nil {
+		t.Errorf("Print() = %s, want no drift and an empty list", buf.String())
+	}
+
+	diff.Add(Drift{Category: DriftVersion, Node: "node2", Field: "kubelet", Desired: "v1.23.17", Actual: "v1.23.10"},
+		Drift{Category: DriftCNI, Field: "plugin", Desired: "calico", Actual: unset})
+	buf.Reset()
+	if err := diff.Print(&buf, DiffOutputHuman); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 5 || !strings.HasPrefix(lines[1], "cni") || !strings.HasPrefix(lines[4], "2 drift(s) found") {
+		t.Errorf("Print() = %s", buf.String())
+	}
+	if err := diff.Print(&buf, "yaml"); err == nil {
+		t.Error("Print() with unsupported output should fail")
+	}
+}
@@ -19,15 +19,18 @@ package kubernetes
 import (
 	"fmt"
 	"path/filepath"
+	"time"
 
 	"github.com/kubesys/kubekey/cmd/kk/pkg/core/util"
 	"github.com/kubesys/kubekey/cmd/kk/pkg/plugins/dns"
 
 	"github.com/pkg/errors"
 
+	kubekeyapiv1alpha2 "github.com/kubesys/kubekey/cmd/kk/apis/kubekey/v1alpha2"
 	"github.com/kubesys/kubekey/cmd/kk/pkg/binaries"
 	"github.com/kubesys/kubekey/cmd/kk/pkg/common"
 	"github.com/kubesys/kubekey/cmd/kk/pkg/core/action"
+	"github.com/kubesys/kubekey/cmd/kk/pkg/core/connector"
 	"github.com/kubesys/kubekey/cmd/kk/pkg/core/prepare"
 	"github.com/kubesys/kubekey/cmd/kk/pkg/core/task"
 	"github.com/kubesys/kubekey/cmd/kk/pkg/images"
@@ -458,6 +461,52 @@ func (d *DeleteKubeNodeModule) Init() {
 	}
 }
 
+type UpgradePlanModule struct {
+	common.KubeModule
+}
+
+func (u *UpgradePlanModule) Init() {
+	u.Name = "UpgradePlanModule"
+	u.Desc = "Calculate the upgrade plan and check the version skew"
+
+	var etcdHosts []connector.Host
+	switch u.KubeConf.Cluster.Etcd.Type {
+	case kubekeyapiv1alpha2.KubeKey:
+		etcdHosts = u.Runtime.GetHostsByRole(common.ETCD)
+	case kubekeyapiv1alpha2.Kubeadm:
+		etcdHosts = u.Runtime.GetHostsByRole(common.Master)
+	}
+
+	etcdVersion := &task.RemoteTask{
+		Name:     "GetEtcdVersion",
+		Desc:     "Get etcd version",
+		Hosts:    etcdHosts,
+		Action:   new(GetEtcdVersion),
+		Parallel: true,
+	}
+
+	cniVersion := &task.RemoteTask{
+		Name:     "GetCNIVersion",
+		Desc:     "Get CNI plugin version",
+		Hosts:    u.Runtime.GetHostsByRole(common.Master),
+		Prepare:  new(common.OnlyFirstMaster),
+		Action:   new(GetCNIVersion),
+		Parallel: true,
+	}
+
+	plan := &task.LocalTask{
+		Name:   "CalculateUpgradePlan",
+		Desc:   "Calculate the upgrade plan and check the version skew",
+		Action: new(CalculateUpgradePlan),
+	}
+
+	u.Tasks = []task.Interface{
+		etcdVersion,
+		cniVersion,
+		plan,
+	}
+}
+
 type SetUpgradePlanModule struct {
 	common.KubeModule
 	Step UpgradeStep
@@ -759,3 +808,172 @@ func (s *SecurityEnhancementModule) Init() {
 		nodesSecurityEnhancement,
 	}
 }
+
+type ClusterDiffModule struct {
+	common.KubeModule
+	Diff   *ClusterDiff
+	Output string
+}
+
+func (c *ClusterDiffModule) Init() {
+	c.Name = "ClusterDiffModule"
+	c.Desc = "Compare the cluster configuration with the live cluster"
+
+	var etcdHosts []connector.Host
+	switch c.KubeConf.Cluster.Etcd.Type {
+	case kubekeyapiv1alpha2.KubeKey:
+		if hosts := c.Runtime.GetHostsByRole(common.ETCD); len(hosts) > 0 {
+			etcdHosts = hosts[:1]
+		}
+	case kubekeyapiv1alpha2.Kubeadm:
+		if hosts := c.Runtime.GetHostsByRole(common.Master); len(hosts) > 0 {
+			etcdHosts = hosts[:1]
+		}
+	}
+
+	nodes := &task.RemoteTask{
+		Name:    "CompareNodes",
+		Desc:    "Compare the nodes, roles and kubelet versions",
+		Hosts:   c.Runtime.GetHostsByRole(common.Master),
+		Prepare: new(common.OnlyFirstMaster),
+		Action:  &CompareNodes{Diff: c.Diff},
+	}
+
+	cni := &task.RemoteTask{
+		Name:    "CompareCNI",
+		Desc:    "Compare the CNI plugin and version",
+		Hosts:   c.Runtime.GetHostsByRole(common.Master),
+		Prepare: new(common.OnlyFirstMaster),
+		Action:  &CompareCNI{Diff: c.Diff},
+	}
+
+	etcdMembers := &task.RemoteTask{
+		Name:   "CompareEtcdMembers",
+		Desc:   "Compare the etcd members",
+		Hosts:  etcdHosts,
+		Action: &CompareEtcdMembers{Diff: c.Diff},
+	}
+
+	staticPods := &task.RemoteTask{
+		Name:     "CompareStaticPods",
+		Desc:     "Compare the control plane static pods",
+		Hosts:    c.Runtime.GetHostsByRole(common.Master),
+		Action:   &CompareStaticPods{Diff: c.Diff},
+		Parallel: true,
+	}
+
+	// not parallel, the default kubelet configuration is generated from the shared feature gates.
+	kubeletConfig := &task.RemoteTask{
+		Name:   "CompareKubeletConfig",
+		Desc:   "Compare the kubelet configuration",
+		Hosts:  c.Runtime.GetHostsByRole(common.K8s),
+		Action: &CompareKubeletConfig{Diff: c.Diff},
+	}
+
+	registryMirrors := &task.RemoteTask{
+		Name:     "CompareRegistryMirrors",
+		Desc:     "Compare the registry mirrors of the container manager",
+		Hosts:    c.Runtime.GetHostsByRole(common.K8s),
+		Action:   &CompareRegistryMirrors{Diff: c.Diff},
+		Parallel: true,
+	}
+
+	printDiff := &task.LocalTask{
+		Name:   "PrintClusterDiff",
+		Desc:   "Print the drifts of the cluster",
+		Action: &PrintClusterDiff{Diff: c.Diff, Output: c.Output},
+	}
+
+	c.Tasks = []task.Interface{
+		nodes,
+		cni,
+		etcdMembers,
+		staticPods,
+		kubeletConfig,
+		registryMirrors,
+		printDiff,
+	}
+}
+
+type ApplyConfigModule struct {
+	common.KubeModule
+}
+
+func (a *ApplyConfigModule) Init() {
+	a.Name = "ApplyConfigModule"
+	a.Desc = "Apply the control plane and kubelet configuration node by node"
+
+	backupDir := filepath.Join(common.KubeConfigDir, "backup", fmt.Sprintf("kk-apply-%s", time.Now().Format("20060102150405")))
+
+	checkVersion := &task.LocalTask{
+		Name:   "CheckApplyVersion",
+		Desc:   "Check the Kubernetes version is not changed",
+		Action: new(CheckApplyVersion),
+	}
+
+	// the files are backed up on every node before any of them is changed, including the audit files.
+	backupFiles := &task.RemoteTask{
+		Name:     "BackupApplyFiles",
+		Desc:     "Back up the control plane and kubelet configuration",
+		Hosts:    applyHosts(a.Runtime),
+		Action:   &BackupApplyFiles{BackupDir: backupDir},
+		Parallel: true,
+		Retry:    2,
+	}
+
+	generateAuditPolicy := &task.RemoteTask{
+		Name:    "GenerateAduitPolicy",
+		Desc:    "Generate audit policy",
+		Hosts:   a.Runtime.GetHostsByRole(common.Master),
+		Prepare: new(common.EnableAudit),
+		Action: &action.Template{
+			Template: templates.AuditPolicy,
+			Dst:      filepath.Join("/etc/kubernetes/audit", templates.AuditPolicy.Name()),
+		},
+		Parallel: true,
+		Retry:    2,
+	}
+
+	generateAuditWebhook := &task.RemoteTask{
+		Name:    "GenerateAduitWebhook",
+		Desc:    "Generate audit webhook",
+		Hosts:   a.Runtime.GetHostsByRole(common.Master),
+		Prepare: new(common.EnableAudit),
+		Action: &action.Template{
+			Template: templates.AuditWebhook,
+			Dst:      filepath.Join("/etc/kubernetes/audit", templates.AuditWebhook.Name()),
+		},
+		Parallel: true,
+		Retry:    2,
+	}
+
+	uploadConfig := &task.RemoteTask{
+		Name:    "UploadKubeadmConfig",
+		Desc:    "Generate and upload the kubeadm config",
+		Hosts:   a.Runtime.GetHostsByRole(common.Master),
+		Prepare: new(common.OnlyFirstMaster),
+		Action:  &UploadKubeadmConfig{BackupDir: backupDir},
+		Retry:   2,
+	}
+
+	// roll the control plane nodes first, then the workers, one by one.
+	apply := &task.RemoteTask{
+		Name:     "ApplyNodeConfig",
+		Desc:     "Apply the configuration and wait for the node to be healthy",
+		Hosts:    applyHosts(a.Runtime),
+		Prepare:  new(ApplyNotFailed),
+		Action:   &ApplyNodeConfig{BackupDir: backupDir},
+		Parallel: false,
+		// it's rolled back on failure instead.
+		Retry: 1,
+	}
+
+	a.Tasks = []task.Interface{
+		checkVersion,
+		backupFiles,
+		generateAuditPolicy,
+		generateAuditWebhook,
+		uploadConfig,
+		apply,
+	}
+}
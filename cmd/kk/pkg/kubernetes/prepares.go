@@ -131,3 +131,14 @@ func (n *NotEqualDesiredVersion) PreCheck(runtime connector.Runtime) (bool, erro
 	}
 	return true, nil
 }
+
+type ApplyNotFailed struct {
+	common.KubePrepare
+}
+
+func (a *ApplyNotFailed) PreCheck(_ connector.Runtime) (bool, error) {
+	if failed, ok := a.ModuleCache.GetMustBool(common.ApplyFailed); ok && failed {
+		return false, nil
+	}
+	return true, nil
+}
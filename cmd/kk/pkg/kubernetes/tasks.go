@@ -36,7 +36,9 @@ import (
 
 	kubekeyv1alpha2 "github.com/kubesys/kubekey/cmd/kk/apis/kubekey/v1alpha2"
 	"github.com/kubesys/kubekey/cmd/kk/pkg/common"
+	containertemplates "github.com/kubesys/kubekey/cmd/kk/pkg/container/templates"
 	"github.com/kubesys/kubekey/cmd/kk/pkg/core/action"
+	"github.com/kubesys/kubekey/cmd/kk/pkg/core/cache"
 	"github.com/kubesys/kubekey/cmd/kk/pkg/core/connector"
 	"github.com/kubesys/kubekey/cmd/kk/pkg/core/logger"
 	"github.com/kubesys/kubekey/cmd/kk/pkg/core/task"
@@ -587,6 +589,133 @@ func (k *KubectlDeleteNode) Execute(runtime connector.Runtime) error {
 	return nil
 }
 
+type GetEtcdVersion struct {
+	common.KubeAction
+}
+
+func (g *GetEtcdVersion) Execute(runtime connector.Runtime) error {
+	var cmd string
+	switch g.KubeConf.Cluster.Etcd.Type {
+	case kubekeyv1alpha2.KubeKey:
+		cmd = "/usr/local/bin/etcd --version | grep 'etcd Version' | awk '{print $3}'"
+	case kubekeyv1alpha2.Kubeadm:
+		cmd = "cat /etc/kubernetes/manifests/etcd.yaml | grep 'image:' | rev | cut -d ':' -f1 | rev"
+	default:
+		return nil
+	}
+	version, err := runtime.GetRunner().SudoCmd(cmd, false)
+	if err != nil || strings.TrimSpace(version) == "" {
+		// the version is unknown, it's reported by the upgrade plan.
+		logger.Log.Warnf("get etcd version on %s failed: %v", runtime.RemoteHost().GetName(), err)
+		return nil
+	}
+	runtime.RemoteHost().GetCache().Set(common.NodeEtcdVersion, trimEtcdImageTag(version))
+	return nil
+}
+
+type GetCNIVersion struct {
+	common.KubeAction
+}
+
+func (g *GetCNIVersion) Execute(runtime connector.Runtime) error {
+	var daemonSet string
+	switch g.KubeConf.Cluster.Network.Plugin {
+	case "calico":
+		daemonSet = "calico-node"
+	case "cilium":
+		daemonSet = "cilium"
+	default:
+		return nil
+	}
+	image, err := runtime.GetRunner().SudoCmd(fmt.Sprintf(
+		"/usr/local/bin/kubectl -n kube-system get ds %s -o jsonpath='{.spec.template.spec.containers[0].image}'", daemonSet), false)
+	if err != nil {
+		logger.Log.Warnf("get %s version failed: %v", g.KubeConf.Cluster.Network.Plugin, err)
+		return nil
+	}
+	if i := strings.LastIndex(image, ":"); i >= 0 {
+		g.PipelineCache.Set(common.CNIVersion, strings.TrimSpace(image[i+1:]))
+	}
+	return nil
+}
+
+type CalculateUpgradePlan struct {
+	common.KubeAction
+}
+
+func (c *CalculateUpgradePlan) Execute(runtime connector.Runtime) error {
+	currentVersion, ok := c.PipelineCache.GetMustString(common.K8sVersion)
+	if !ok {
+		return errors.New("get current Kubernetes version failed by pipeline cache")
+	}
+	desiredVersion, ok := c.PipelineCache.GetMustString(common.DesiredK8sVersion)
+	if !ok {
+		return errors.New("get desired Kubernetes version failed by pipeline cache")
+	}
+	hops, err := PlanHops(currentVersion, desiredVersion)
+	if err != nil {
+		return errors.Wrap(err, "calculate upgrade plan failed")
+	}
+	plan := &UpgradePlan{Current: currentVersion, Target: desiredVersion, Hops: hops, KubeSphereHop: len(hops) - 1}
+
+	nodes := make(map[string]string)
+	var etcdVersion *versionutil.Version
+	for _, host := range runtime.GetAllHosts() {
+		if v, ok := host.GetCache().GetMustString(common.NodeK8sVersion); ok && host.IsRole(common.K8s) {
+			nodes[host.GetName()] = v
+		}
+		if v, ok := host.GetCache().GetMustString(common.NodeEtcdVersion); ok {
+			if parsed, err := versionutil.ParseGeneric(v); err == nil && (etcdVersion == nil || parsed.LessThan(etcdVersion)) {
+				etcdVersion = parsed
+			}
+		}
+	}
+	plan.Checks = append(plan.Checks, CheckKubeletSkew(nodes, hops)...)
+
+	if c.KubeConf.Cluster.Etcd.Type == kubekeyv1alpha2.KubeKey && c.KubeConf.Arg.EtcdUpgrade {
+		// etcd is upgraded before Kubernetes.
+		etcdVersion = versionutil.MustParseGeneric(kubekeyv1alpha2.DefaultEtcdVersion)
+	}
+	var etcd string
+	if etcdVersion != nil {
+		etcd = fmt.Sprintf("v%s", etcdVersion)
+	}
+	plan.Checks = append(plan.Checks, CheckEtcdSkew(etcd, hops))
+
+	if plugin := c.KubeConf.Cluster.Network.Plugin; plugin != "" && plugin != "none" {
+		cniVersion, _ := c.PipelineCache.GetMustString(common.CNIVersion)
+		plan.Checks = append(plan.Checks, CheckCNISkew(plugin, cniVersion, hops))
+	}
+
+	var kubeSphereUpgrade bool
+	if installed, _ := c.PipelineCache.GetMustString(common.KubeSphereVersion); installed != "" {
+		desired := c.KubeConf.Cluster.KubeSphere.Version
+		kubeSphereUpgrade = c.KubeConf.Cluster.KubeSphere.Enabled && installed != desired
+		if kubeSphereUpgrade {
+			plan.KubeSphereHop = PlanKubeSphere(installed, hops)
+		}
+		plan.Checks = append(plan.Checks, CheckKubeSphereSkew(installed, desired, kubeSphereUpgrade, plan))
+	}
+
+	addons := make([]string, 0, len(c.KubeConf.Cluster.Addons))
+	for _, addon := range c.KubeConf.Cluster.Addons {
+		addons = append(addons, addon.Name)
+	}
+	plan.Checks = append(plan.Checks, CheckAddonsSkew(addons, desiredVersion)...)
+
+	plan.Print(os.Stdout, kubeSphereUpgrade)
+	c.PipelineCache.Set(common.UpgradePlan, plan)
+
+	if plan.Failed() {
+		if c.KubeConf.Arg.SkipDependencyCheck {
+			logger.Log.Warnln("the upgrade plan failed the version skew checks, ignored by --skip-dependency-check")
+			return nil
+		}
+		return errors.New("the upgrade plan failed the version skew checks, fix the errors above or skip the checks with --skip-dependency-check")
+	}
+	return nil
+}
+
 type SetUpgradePlan struct {
 	common.KubeAction
 	Step UpgradeStep
@@ -612,22 +741,27 @@ func (s *SetUpgradePlan) Execute(_ connector.Runtime) error {
 		os.Exit(0)
 	}
 
-	if s.Step == ToV121 {
-		v122 := versionutil.MustParseSemantic("v1.22.0")
-		atLeast := versionutil.MustParseSemantic(desiredVersion).AtLeast(v122)
-		cmp, err := versionutil.MustParseSemantic(currentVersion).Compare("v1.21.5")
+	if s.Step == ToKubeSphereSupported {
+		plan, err := getUpgradePlan(s.PipelineCache)
 		if err != nil {
 			return err
 		}
-		if atLeast && cmp <= 0 {
-			desiredVersion = "v1.21.5"
-		}
+		// stop at the last version supported by the installed KubeSphere, it's upgraded before the rest hops.
+		desiredVersion = plan.KubeSphereVersion()
 	}
 
 	s.PipelineCache.Set(common.PlanK8sVersion, desiredVersion)
 	return nil
 }
 
+func getUpgradePlan(pipelineCache *cache.Cache) (*UpgradePlan, error) {
+	v, ok := pipelineCache.Get(common.UpgradePlan)
+	if !ok {
+		return nil, errors.New("get upgrade plan failed by pipeline cache")
+	}
+	return v.(*UpgradePlan), nil
+}
+
 type CalculateNextVersion struct {
 	common.KubeAction
 }
@@ -641,10 +775,18 @@ func (c *CalculateNextVersion) Execute(_ connector.Runtime) error {
 	if !ok {
 		return errors.New("get upgrade plan Kubernetes version failed by pipeline cache")
 	}
-	nextVersionStr, err := calculateNextStr(currentVersion, planVersion)
+	plan, err := getUpgradePlan(c.PipelineCache)
+	if err != nil {
+		return err
+	}
+	nextVersionStr, err := plan.Next(currentVersion)
 	if err != nil {
 		return errors.Wrap(err, "calculate next version failed")
 	}
+	if versionutil.MustParseSemantic(planVersion).LessThan(versionutil.MustParseSemantic(nextVersionStr)) {
+		return errors.Errorf("the next version %s is greater than the plan version %s", nextVersionStr, planVersion)
+	}
+	logger.Log.Messagef(common.LocalHost, "Upgrade Kubernetes from %s to %s", currentVersion, nextVersionStr)
 	c.KubeConf.Cluster.Kubernetes.Version = nextVersionStr
 	return nil
 }
@@ -713,6 +855,10 @@ func (u *UpgradeKubeMaster) Execute(runtime connector.Runtime) error {
 		return errors.Wrap(errors.WithStack(err), fmt.Sprintf("upgrade cluster using kubeadm failed: %s", host.GetName()))
 	}
 
+	if err := DrainUpgradeNodeTasks(runtime, u.KubeAction, false); err != nil {
+		return err
+	}
+
 	if _, err := runtime.GetRunner().SudoCmd("systemctl stop kubelet", false); err != nil {
 		return errors.Wrap(errors.WithStack(err), fmt.Sprintf("stop kubelet failed: %s", host.GetName()))
 	}
@@ -731,7 +877,7 @@ func (u *UpgradeKubeMaster) Execute(runtime connector.Runtime) error {
 	}
 
 	time.Sleep(10 * time.Second)
-	return nil
+	return DrainUpgradeNodeTasks(runtime, u.KubeAction, true)
 }
 
 type UpgradeKubeWorker struct {
@@ -745,6 +891,9 @@ func (u *UpgradeKubeWorker) Execute(runtime connector.Runtime) error {
 	if _, err := runtime.GetRunner().SudoCmd("/usr/local/bin/kubeadm upgrade node", true); err != nil {
 		return errors.Wrap(errors.WithStack(err), fmt.Sprintf("upgrade node using kubeadm failed: %s", host.GetName()))
 	}
+	if err := DrainUpgradeNodeTasks(runtime, u.KubeAction, false); err != nil {
+		return err
+	}
 	if _, err := runtime.GetRunner().SudoCmd("systemctl stop kubelet", true); err != nil {
 		return errors.Wrap(errors.WithStack(err), fmt.Sprintf("stop kubelet failed: %s", host.GetName()))
 	}
@@ -760,6 +909,67 @@ func (u *UpgradeKubeWorker) Execute(runtime connector.Runtime) error {
 		return errors.Wrap(errors.WithStack(err), fmt.Sprintf("restart kubelet failed: %s", host.GetName()))
 	}
 	time.Sleep(10 * time.Second)
+	return DrainUpgradeNodeTasks(runtime, u.KubeAction, true)
+}
+
+// DrainUpgradeNodeTasks drains the node before its kubelet is upgraded, or uncordons it after, with kubectl on
+// the first master. It's skipped with --skip-drain or if there is no other node to evict the pods to.
+func DrainUpgradeNodeTasks(runtime connector.Runtime, kubeAction common.KubeAction, uncordon bool) error {
+	if kubeAction.KubeConf.Arg.SkipDrain || len(runtime.GetHostsByRole(common.K8s)) < 2 {
+		return nil
+	}
+	node := runtime.RemoteHost().GetName()
+
+	var t task.Interface
+	if uncordon {
+		t = &task.RemoteTask{
+			Name:     "UncordonNode",
+			Desc:     "Uncordon the upgraded node",
+			Hosts:    runtime.GetHostsByRole(common.Master)[:1],
+			Action:   &UncordonUpgradeNode{Node: node},
+			Parallel: false,
+			Retry:    5,
+		}
+	} else {
+		t = &task.RemoteTask{
+			Name:     "DrainNode",
+			Desc:     "Drain the node to upgrade",
+			Hosts:    runtime.GetHostsByRole(common.Master)[:1],
+			Action:   &DrainUpgradeNode{Node: node},
+			Parallel: false,
+			Retry:    2,
+		}
+	}
+	t.Init(runtime, kubeAction.ModuleCache, kubeAction.PipelineCache)
+	if res := t.Execute(); res.IsFailed() {
+		return res.CombineErr()
+	}
+	return nil
+}
+
+type DrainUpgradeNode struct {
+	common.KubeAction
+	Node string
+}
+
+func (d *DrainUpgradeNode) Execute(runtime connector.Runtime) error {
+	if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf(
+		"/usr/local/bin/kubectl drain %s --delete-emptydir-data --ignore-daemonsets --timeout=5m --force", d.Node),
+		true); err != nil {
+		return errors.Wrapf(err, "drain the node %s failed", d.Node)
+	}
+	return nil
+}
+
+type UncordonUpgradeNode struct {
+	common.KubeAction
+	Node string
+}
+
+func (u *UncordonUpgradeNode) Execute(runtime connector.Runtime) error {
+	if _, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("/usr/local/bin/kubectl uncordon %s", u.Node), true); err != nil {
+		return errors.Wrapf(err, "uncordon the node %s failed", u.Node)
+	}
 	return nil
 }
 
@@ -1241,3 +1451,598 @@ func (n *NodesSecurityEnhancemenAction) Execute(runtime connector.Runtime) error
 
 	return nil
 }
+
+type CompareNodes struct {
+	common.KubeAction
+	Diff *ClusterDiff
+}
+
+func (d *CompareNodes) Execute(runtime connector.Runtime) error {
+	output, err := runtime.GetRunner().SudoCmd("/usr/local/bin/kubectl get nodes -o json", false)
+	if err != nil {
+		return errors.Wrap(errors.WithStack(err), "kubectl get nodes failed")
+	}
+	actual, err := ParseNodes(output)
+	if err != nil {
+		return err
+	}
+
+	var desired []NodeState
+	for _, host := range runtime.GetHostsByRole(common.K8s) {
+		desired = append(desired, NodeState{
+			Name:         host.GetName(),
+			ControlPlane: host.IsRole(common.Master),
+			Worker:       host.IsRole(common.Worker),
+			Version:      d.KubeConf.Cluster.Kubernetes.Version,
+		})
+	}
+	d.Diff.Add(DiffNodes(desired, actual)...)
+	return nil
+}
+
+type CompareCNI struct {
+	common.KubeAction
+	Diff *ClusterDiff
+}
+
+func (d *CompareCNI) Execute(runtime connector.Runtime) error {
+	output, err := runtime.GetRunner().SudoCmd(
+		"/usr/local/bin/kubectl get ds -A -o jsonpath='{range .items[*]}{.metadata.name}{\" \"}{.spec.template.spec.containers[0].image}{\"\\n\"}{end}'",
+		false)
+	if err != nil {
+		return errors.Wrap(errors.WithStack(err), "kubectl get daemonsets failed")
+	}
+	plugin := d.KubeConf.Cluster.Network.Plugin
+	d.Diff.Add(DiffCNI(plugin, CNIVersions[plugin], ParseCNIPlugins(output))...)
+	return nil
+}
+
+type CompareEtcdMembers struct {
+	common.KubeAction
+	Diff *ClusterDiff
+}
+
+func (d *CompareEtcdMembers) Execute(runtime connector.Runtime) error {
+	var desired, actual []string
+	switch d.KubeConf.Cluster.Etcd.Type {
+	case kubekeyv1alpha2.KubeKey:
+		host := runtime.RemoteHost()
+		output, err := runtime.GetRunner().SudoCmd(fmt.Sprintf(
+			"ETCDCTL_API=3 %[1]s/etcdctl --cacert=%[2]s/ca.pem --cert=%[2]s/admin-%[3]s.pem --key=%[2]s/admin-%[3]s-key.pem --endpoints=https://%[4]s:%[5]d member list -w json",
+			common.BinDir, common.ETCDCertDir, host.GetName(), host.GetInternalIPv4Address(), d.KubeConf.Cluster.Etcd.GetPort()), false)
+		if err != nil {
+			return errors.Wrap(errors.WithStack(err), "list etcd members failed")
+		}
+		if actual, err = ParseEtcdMembers(output); err != nil {
+			return err
+		}
+		for _, h := range runtime.GetHostsByRole(common.ETCD) {
+			desired = append(desired, fmt.Sprintf("etcd-%s", h.GetName()))
+		}
+	case kubekeyv1alpha2.Kubeadm:
+		// the stacked etcd members are the static pods on the control plane nodes.
+		output, err := runtime.GetRunner().SudoCmd(
+			"/usr/local/bin/kubectl -n kube-system get pods -l component=etcd -o jsonpath='{.items[*].spec.nodeName}'", false)
+		if err != nil {
+			return errors.Wrap(errors.WithStack(err), "kubectl get etcd pods failed")
+		}
+		actual = strings.Fields(output)
+		for _, h := range runtime.GetHostsByRole(common.Master) {
+			desired = append(desired, h.GetName())
+		}
+	default:
+		return nil
+	}
+	d.Diff.Add(DiffEtcdMembers(desired, actual)...)
+	return nil
+}
+
+type CompareStaticPods struct {
+	common.KubeAction
+	Diff *ClusterDiff
+}
+
+func (d *CompareStaticPods) Execute(runtime connector.Runtime) error {
+	drifts, err := staticPodDrifts(runtime, d.KubeConf)
+	if err != nil {
+		return err
+	}
+	d.Diff.Add(drifts...)
+	return nil
+}
+
+// staticPodDrifts compares the control plane static pods of the remote host with the arguments generated in the
+// kubeadm config.
+func staticPodDrifts(runtime connector.Runtime, kubeConf *common.KubeConf) ([]Drift, error) {
+	k8s := kubeConf.Cluster.Kubernetes
+	securityEnhancement := kubeConf.Arg.SecurityEnhancement
+	_, apiServerArgs := util.GetArgs(templates.GetApiServerArgs(securityEnhancement, k8s.EnableAudit()), k8s.ApiServerArgs)
+	_, controllerManagerArgs := util.GetArgs(templates.GetControllermanagerArgs(k8s.Version, securityEnhancement), k8s.ControllerManagerArgs)
+	_, schedulerArgs := util.GetArgs(templates.GetSchedulerArgs(securityEnhancement), k8s.SchedulerArgs)
+	components := []struct {
+		name string
+		args map[string]string
+	}{
+		{name: "kube-apiserver", args: templates.UpdateFeatureGatesConfiguration(apiServerArgs, kubeConf)},
+		{name: "kube-controller-manager", args: templates.UpdateFeatureGatesConfiguration(controllerManagerArgs, kubeConf)},
+		{name: "kube-scheduler", args: templates.UpdateFeatureGatesConfiguration(schedulerArgs, kubeConf)},
+	}
+
+	var drifts []Drift
+	node := runtime.RemoteHost().GetName()
+	for _, component := range components {
+		manifest, err := runtime.GetRunner().SudoCmd(fmt.Sprintf("cat /etc/kubernetes/manifests/%s.yaml", component.name), false)
+		if err != nil {
+			drifts = append(drifts, Drift{Category: DriftStaticPod, Node: node, Field: component.name, Desired: "present", Actual: "absent"})
+			continue
+		}
+		image, args, err := ParseStaticPod(manifest)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parse the %s manifest on %s failed", component.name, node)
+		}
+		drifts = append(drifts, DiffImageVersion(component.name, node, k8s.Version, image)...)
+		drifts = append(drifts, DiffArgs(component.name, node, component.args, args)...)
+	}
+	return drifts, nil
+}
+
+type CompareKubeletConfig struct {
+	common.KubeAction
+	Diff *ClusterDiff
+}
+
+func (d *CompareKubeletConfig) Execute(runtime connector.Runtime) error {
+	drifts, err := kubeletConfigDrifts(runtime, d.KubeConf)
+	if err != nil {
+		return err
+	}
+	d.Diff.Add(drifts...)
+	return nil
+}
+
+// kubeletConfigDrifts compares the kubelet configuration of the remote host with the one generated in the kubeadm config.
+func kubeletConfigDrifts(runtime connector.Runtime, kubeConf *common.KubeConf) ([]Drift, error) {
+	node := runtime.RemoteHost().GetName()
+	config, err := runtime.GetRunner().SudoCmd("cat /var/lib/kubelet/config.yaml", false)
+	if err != nil {
+		return []Drift{{Category: DriftKubeletConfig, Node: node, Field: "/var/lib/kubelet/config.yaml", Desired: "present", Actual: "absent"}}, nil
+	}
+	desired := templates.GetKubeletConfiguration(runtime, kubeConf, kubeConf.Cluster.Kubernetes.ContainerRuntimeEndpoint,
+		kubeConf.Arg.SecurityEnhancement)
+	drifts, err := DiffKubeletConfig(node, desired, config)
+	if err != nil {
+		return nil, errors.Wrapf(err, "compare the kubelet configuration on %s failed", node)
+	}
+	return drifts, nil
+}
+
+// registryMirrorsConfig is the configuration file of the container managers which contains the registry mirrors.
+var registryMirrorsConfig = map[string]string{
+	common.Docker:     "/etc/docker/daemon.json",
+	common.Containerd: "/etc/containerd/config.toml",
+	common.Crio:       filepath.Join("/etc/containers/registries.conf.d", containertemplates.CrioRegistries.Name()),
+	common.Isula:      "/etc/isulad/daemon.json",
+}
+
+type CompareRegistryMirrors struct {
+	common.KubeAction
+	Diff *ClusterDiff
+}
+
+func (d *CompareRegistryMirrors) Execute(runtime connector.Runtime) error {
+	containerManager := d.KubeConf.Cluster.Kubernetes.ContainerManager
+	path, ok := registryMirrorsConfig[containerManager]
+	if !ok {
+		return nil
+	}
+	node := runtime.RemoteHost().GetName()
+	var config string
+	if exist, err := runtime.GetRunner().FileExist(path); err != nil {
+		return err
+	} else if exist {
+		if config, err = runtime.GetRunner().SudoCmd(fmt.Sprintf("cat %s", path), false); err != nil {
+			return errors.Wrapf(errors.WithStack(err), "read %s on %s failed", path, node)
+		}
+	}
+	actual, err := ParseRegistryMirrors(containerManager, config)
+	if err != nil {
+		return errors.Wrapf(err, "parse the registry mirrors on %s failed", node)
+	}
+	d.Diff.Add(DiffRegistryMirrors(node, d.KubeConf.Cluster.Registry.RegistryMirrors, actual)...)
+	return nil
+}
+
+type PrintClusterDiff struct {
+	common.KubeAction
+	Diff   *ClusterDiff
+	Output string
+}
+
+func (p *PrintClusterDiff) Execute(_ connector.Runtime) error {
+	return p.Diff.Print(os.Stdout, p.Output)
+}
+
+type CheckApplyVersion struct {
+	common.KubeAction
+}
+
+func (c *CheckApplyVersion) Execute(_ connector.Runtime) error {
+	currentVersion, ok := c.PipelineCache.GetMustString(common.K8sVersion)
+	if !ok {
+		return errors.New("get current Kubernetes version failed by pipeline cache")
+	}
+	if desired := c.KubeConf.Cluster.Kubernetes.Version; normalizeVersion(currentVersion) != normalizeVersion(desired) {
+		return errors.Errorf("the cluster is running Kubernetes %s but %s is configured, kk apply does not change the version, "+
+			"please upgrade the cluster with kk upgrade first", currentVersion, desired)
+	}
+	return nil
+}
+
+// applyFiles is the files changed by "kk apply", they are backed up before and restored on failure.
+var applyFiles = []string{
+	"/etc/kubernetes/audit",
+	"/etc/kubernetes/manifests",
+	"/var/lib/kubelet/config.yaml",
+	filepath.Join(common.KubeConfigDir, templates.KubeadmConfig.Name()),
+}
+
+// applyConfigMapsBackup is the backup file of the kubeadm-config and kubelet-config configmaps in the backup dir.
+const applyConfigMapsBackup = "configmaps.yaml"
+
+// backupApplyFilesCmd returns the command to back up the files to backupDir. The files backed up are not
+// overwritten, so that the first master keeps its files before the kubeadm config is uploaded.
+func backupApplyFilesCmd(files []string, backupDir string) string {
+	cmds := []string{fmt.Sprintf("mkdir -p %s", backupDir)}
+	for _, file := range files {
+		cmds = append(cmds, fmt.Sprintf("if [ -e %[1]s ] && [ ! -e %[3]s ]; then cp -a %[1]s %[2]s/; fi",
+			file, backupDir, filepath.Join(backupDir, filepath.Base(file))))
+	}
+	return strings.Join(cmds, " && ")
+}
+
+// rollbackApplyFilesCmd returns the command to restore the files backed up by backupApplyFilesCmd.
+func rollbackApplyFilesCmd(files []string, backupDir string) string {
+	cmds := make([]string, 0, len(files))
+	for _, file := range files {
+		backup := filepath.Join(backupDir, filepath.Base(file))
+		cmds = append(cmds, fmt.Sprintf("if [ -d %[1]s ]; then cp -af %[1]s/. %[2]s/; elif [ -f %[1]s ]; then cp -af %[1]s %[2]s; fi", backup, file))
+	}
+	return strings.Join(cmds, " && ")
+}
+
+// backupApplyFiles backs up the files changed by "kk apply" to backupDir.
+func backupApplyFiles(runtime connector.Runtime, backupDir string) error {
+	if _, err := runtime.GetRunner().SudoCmd(backupApplyFilesCmd(applyFiles, backupDir), false); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "back up the configuration of %s to %s failed", runtime.RemoteHost().GetName(), backupDir)
+	}
+	return nil
+}
+
+type BackupApplyFiles struct {
+	common.KubeAction
+	BackupDir string
+}
+
+func (b *BackupApplyFiles) Execute(runtime connector.Runtime) error {
+	return backupApplyFiles(runtime, b.BackupDir)
+}
+
+// applyHosts returns the nodes rolled by "kk apply", the control plane nodes first, then the workers.
+func applyHosts(runtime connector.ModuleRuntime) []connector.Host {
+	hosts := append([]connector.Host{}, runtime.GetHostsByRole(common.Master)...)
+	for _, host := range runtime.GetHostsByRole(common.Worker) {
+		if !host.IsRole(common.Master) {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
+
+// RollbackApplyFilesTasks restores the files backed up by backupApplyFiles and restarts kubelet on every node
+// rolled by "kk apply", so that the nodes applied before the failed one are consistent with the restored configmaps.
+func RollbackApplyFilesTasks(runtime connector.Runtime, kubeAction common.KubeAction, backupDir string) error {
+	t := &task.RemoteTask{
+		Name:     "RollbackApplyFiles",
+		Desc:     "Roll back the control plane and kubelet configuration",
+		Hosts:    applyHosts(runtime),
+		Action:   &RollbackApplyFiles{BackupDir: backupDir},
+		Parallel: true,
+		Retry:    2,
+	}
+	t.Init(runtime, kubeAction.ModuleCache, kubeAction.PipelineCache)
+	if res := t.Execute(); res.IsFailed() {
+		return res.CombineErr()
+	}
+	return nil
+}
+
+type RollbackApplyFiles struct {
+	common.KubeAction
+	BackupDir string
+}
+
+func (r *RollbackApplyFiles) Execute(runtime connector.Runtime) error {
+	if _, err := runtime.GetRunner().SudoCmd(rollbackApplyFilesCmd(applyFiles, r.BackupDir)+" && systemctl restart kubelet", false); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "roll back the configuration of %s from %s failed", runtime.RemoteHost().GetName(), r.BackupDir)
+	}
+	return nil
+}
+
+// backupConfigMapsCmd returns the command to back up the kubeadm-config and kubelet-config configmaps to backupDir.
+// The resourceVersion is removed, so that they can be replaced after being uploaded. The backup is not overwritten.
+func backupConfigMapsCmd(backupDir string) string {
+	backup := filepath.Join(backupDir, applyConfigMapsBackup)
+	return fmt.Sprintf("mkdir -p %[1]s && if [ ! -e %[2]s ]; then set -o pipefail && "+
+		"/usr/local/bin/kubectl -n kube-system get cm -o name | grep -xE 'configmap/(kubeadm-config|kubelet-config(-[0-9.]+)?)' | "+
+		"xargs /usr/local/bin/kubectl -n kube-system get -o yaml | sed '/^ *resourceVersion:/d' > %[2]s.tmp && mv -f %[2]s.tmp %[2]s; fi",
+		backupDir, backup)
+}
+
+// rollbackConfigMapsCmd returns the command to restore the configmaps backed up by backupConfigMapsCmd.
+func rollbackConfigMapsCmd(backupDir string) string {
+	backup := filepath.Join(backupDir, applyConfigMapsBackup)
+	return fmt.Sprintf("if [ -f %[1]s ]; then /usr/local/bin/kubectl replace -f %[1]s; fi", backup)
+}
+
+// RollbackConfigMapsTasks restores the kubeadm-config and kubelet-config configmaps with kubectl on the first master.
+func RollbackConfigMapsTasks(runtime connector.Runtime, kubeAction common.KubeAction, backupDir string) error {
+	t := &task.RemoteTask{
+		Name:     "RollbackConfigMaps",
+		Desc:     "Roll back the kubeadm-config and kubelet-config configmaps",
+		Hosts:    runtime.GetHostsByRole(common.Master)[:1],
+		Action:   &RollbackConfigMaps{BackupDir: backupDir},
+		Parallel: false,
+		Retry:    2,
+	}
+	t.Init(runtime, kubeAction.ModuleCache, kubeAction.PipelineCache)
+	if res := t.Execute(); res.IsFailed() {
+		return res.CombineErr()
+	}
+	return nil
+}
+
+type RollbackConfigMaps struct {
+	common.KubeAction
+	BackupDir string
+}
+
+func (r *RollbackConfigMaps) Execute(runtime connector.Runtime) error {
+	if _, err := runtime.GetRunner().SudoCmd(rollbackConfigMapsCmd(r.BackupDir), false); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "roll back the configmaps from %s failed", r.BackupDir)
+	}
+	return nil
+}
+
+type UploadKubeadmConfig struct {
+	common.KubeAction
+	BackupDir string
+}
+
+func (u *UploadKubeadmConfig) Execute(runtime connector.Runtime) error {
+	if _, err := runtime.GetRunner().SudoCmd(backupConfigMapsCmd(u.BackupDir), false); err != nil {
+		return errors.Wrapf(errors.WithStack(err), "back up the configmaps to %s failed", u.BackupDir)
+	}
+	if err := GenerateKubeadmConfigTasks(runtime, u.KubeAction); err != nil {
+		return err
+	}
+	// the kubeadm-config and kubelet-config configmaps are used by "kubeadm upgrade node" and the nodes joined later.
+	if _, err := runtime.GetRunner().SudoCmd(
+		"/usr/local/bin/kubeadm init phase upload-config all --config /etc/kubernetes/kubeadm-config.yaml", true); err != nil {
+		if rollbackErr := RollbackConfigMapsTasks(runtime, u.KubeAction, u.BackupDir); rollbackErr != nil {
+			logger.Log.Warnf("%v", rollbackErr)
+		}
+		return errors.Wrap(errors.WithStack(err), "upload the kubeadm config failed")
+	}
+	return nil
+}
+
+// GenerateKubeadmConfigTasks regenerates the kubeadm config of the remote host.
+func GenerateKubeadmConfigTasks(runtime connector.Runtime, kubeAction common.KubeAction) error {
+	t := &task.RemoteTask{
+		Name:  "GenerateKubeadmConfig",
+		Desc:  "Generate kubeadm config",
+		Hosts: []connector.Host{runtime.RemoteHost()},
+		Action: &GenerateKubeadmConfig{
+			IsInitConfiguration:     true,
+			WithSecurityEnhancement: kubeAction.KubeConf.Arg.SecurityEnhancement,
+		},
+		Parallel: false,
+	}
+	t.Init(runtime, kubeAction.ModuleCache, kubeAction.PipelineCache)
+	if res := t.Execute(); res.IsFailed() {
+		return res.CombineErr()
+	}
+	return nil
+}
+
+// controlPlaneComponents is the static pods regenerated by "kubeadm init phase control-plane all".
+var controlPlaneComponents = []string{"kube-apiserver", "kube-controller-manager", "kube-scheduler"}
+
+type ApplyNodeConfig struct {
+	common.KubeAction
+	BackupDir string
+}
+
+func (a *ApplyNodeConfig) Execute(runtime connector.Runtime) error {
+	host := runtime.RemoteHost()
+	controlPlane := host.IsRole(common.Master)
+
+	var podDrifts []Drift
+	if controlPlane {
+		drifts, err := staticPodDrifts(runtime, a.KubeConf)
+		if err != nil {
+			return err
+		}
+		podDrifts = drifts
+	}
+	kubeletDrifts, err := kubeletConfigDrifts(runtime, a.KubeConf)
+	if err != nil {
+		return err
+	}
+	staticPodChanges, kubeletChanges := applyChanges(podDrifts, kubeletDrifts)
+	if len(staticPodChanges) == 0 && len(kubeletChanges) == 0 {
+		logger.Log.Messagef(host.GetName(), "the configuration is unchanged, skipped")
+		return nil
+	}
+	for _, d := range append(staticPodChanges, kubeletChanges...) {
+		logger.Log.Messagef(host.GetName(), "%s: %s -> %s", d.Field, d.Actual, d.Desired)
+	}
+
+	if err := a.apply(runtime, controlPlane, len(staticPodChanges) > 0, len(kubeletChanges) > 0); err != nil {
+		a.ModuleCache.Set(common.ApplyFailed, true)
+		logger.Log.Warnf("apply the configuration on %s failed, roll back all nodes from %s", host.GetName(), a.BackupDir)
+		if rollbackErr := RollbackApplyFilesTasks(runtime, a.KubeAction, a.BackupDir); rollbackErr != nil {
+			logger.Log.Warnf("%v", rollbackErr)
+		}
+		if rollbackErr := RollbackConfigMapsTasks(runtime, a.KubeAction, a.BackupDir); rollbackErr != nil {
+			logger.Log.Warnf("%v", rollbackErr)
+		}
+		return errors.Wrapf(err, "apply the configuration on %s failed, all nodes are rolled back", host.GetName())
+	}
+	return nil
+}
+
+// applyChanges returns the drifts of static pods and kubelet which are applied by "kk apply".
+// The version is changed by "kk upgrade", so it's not applied.
+func applyChanges(staticPodDrifts, kubeletDrifts []Drift) (staticPodChanges, kubeletChanges []Drift) {
+	for _, d := range staticPodDrifts {
+		if d.Category == DriftStaticPod {
+			staticPodChanges = append(staticPodChanges, d)
+		}
+	}
+	for _, d := range kubeletDrifts {
+		if d.Category == DriftKubeletConfig {
+			kubeletChanges = append(kubeletChanges, d)
+		}
+	}
+	return staticPodChanges, kubeletChanges
+}
+
+func (a *ApplyNodeConfig) apply(runtime connector.Runtime, controlPlane, staticPods, kubelet bool) error {
+	host := runtime.RemoteHost()
+	if controlPlane {
+		if err := GenerateKubeadmConfigTasks(runtime, a.KubeAction); err != nil {
+			return err
+		}
+	}
+
+	if staticPods {
+		before := make(map[string]string, len(controlPlaneComponents))
+		for _, component := range controlPlaneComponents {
+			before[component], _ = runtime.GetRunner().SudoCmd(fmt.Sprintf("cat /etc/kubernetes/manifests/%s.yaml", component), false)
+		}
+		if _, err := runtime.GetRunner().SudoCmd(
+			"/usr/local/bin/kubeadm init phase control-plane all --config /etc/kubernetes/kubeadm-config.yaml", true); err != nil {
+			return errors.Wrap(errors.WithStack(err), "regenerate the static pod manifests failed")
+		}
+		for _, component := range controlPlaneComponents {
+			after, _ := runtime.GetRunner().SudoCmd(fmt.Sprintf("cat /etc/kubernetes/manifests/%s.yaml", component), false)
+			if after == before[component] {
+				continue
+			}
+			if err := WaitStaticPodTasks(runtime, a.KubeAction, component); err != nil {
+				return err
+			}
+		}
+	}
+
+	if kubelet {
+		if _, err := runtime.GetRunner().SudoCmd("/usr/local/bin/kubeadm upgrade node phase kubelet-config", true); err != nil {
+			return errors.Wrap(errors.WithStack(err), "regenerate the kubelet config failed")
+		}
+		if _, err := runtime.GetRunner().SudoCmd("systemctl restart kubelet", true); err != nil {
+			return errors.Wrap(errors.WithStack(err), fmt.Sprintf("restart kubelet failed: %s", host.GetName()))
+		}
+		if err := WaitKubeletTasks(runtime, a.KubeAction); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WaitStaticPodTasks waits for the static pod of the component on the remote host to be recreated from the new
+// manifest and to be ready. The mirror pod has a new config hash once it's recreated.
+func WaitStaticPodTasks(runtime connector.Runtime, kubeAction common.KubeAction, component string) error {
+	host := runtime.RemoteHost()
+	pod := fmt.Sprintf("%s-%s", component, host.GetName())
+	hash, _ := runtime.GetRunner().SudoCmd(fmt.Sprintf(
+		"/usr/local/bin/kubectl -n kube-system get pod %s -o jsonpath='{.metadata.annotations.kubernetes\\.io/config\\.hash}'", pod), false)
+
+	t := &task.RemoteTask{
+		Name:     "WaitStaticPod",
+		Desc:     fmt.Sprintf("Wait for %s to be ready", component),
+		Hosts:    []connector.Host{host},
+		Action:   &WaitStaticPod{Pod: pod, Hash: strings.TrimSpace(hash)},
+		Parallel: false,
+		Retry:    30,
+		Delay:    10 * time.Second,
+	}
+	t.Init(runtime, kubeAction.ModuleCache, kubeAction.PipelineCache)
+	if res := t.Execute(); res.IsFailed() {
+		return res.CombineErr()
+	}
+	return nil
+}
+
+type WaitStaticPod struct {
+	common.KubeAction
+	Pod  string
+	Hash string
+}
+
+func (w *WaitStaticPod) Execute(runtime connector.Runtime) error {
+	output, err := runtime.GetRunner().SudoCmd(fmt.Sprintf(
+		"/usr/local/bin/kubectl -n kube-system get pod %s -o jsonpath='{.metadata.annotations.kubernetes\\.io/config\\.hash} "+
+			"{.status.conditions[?(@.type==\"Ready\")].status}'", w.Pod), false)
+	if err != nil {
+		return errors.Wrapf(errors.WithStack(err), "get the pod %s failed", w.Pod)
+	}
+	fields := strings.Fields(output)
+	if len(fields) != 2 || fields[0] == w.Hash {
+		return errors.Errorf("the pod %s has not been recreated yet", w.Pod)
+	}
+	if fields[1] != "True" {
+		return errors.Errorf("the pod %s is not ready", w.Pod)
+	}
+	return nil
+}
+
+// WaitKubeletTasks waits for the kubelet of the remote host to be healthy and the node to be ready, with kubectl on
+// the first master. The health of kubelet is proxied by kube-apiserver.
+func WaitKubeletTasks(runtime connector.Runtime, kubeAction common.KubeAction) error {
+	t := &task.RemoteTask{
+		Name:     "WaitKubelet",
+		Desc:     "Wait for kubelet to be healthy",
+		Hosts:    runtime.GetHostsByRole(common.Master)[:1],
+		Action:   &WaitKubelet{Node: runtime.RemoteHost().GetName()},
+		Parallel: false,
+		Retry:    30,
+		Delay:    10 * time.Second,
+	}
+	t.Init(runtime, kubeAction.ModuleCache, kubeAction.PipelineCache)
+	if res := t.Execute(); res.IsFailed() {
+		return res.CombineErr()
+	}
+	return nil
+}
+
+type WaitKubelet struct {
+	common.KubeAction
+	Node string
+}
+
+func (w *WaitKubelet) Execute(runtime connector.Runtime) error {
+	if output, err := runtime.GetRunner().SudoCmd(fmt.Sprintf(
+		"/usr/local/bin/kubectl get --raw /api/v1/nodes/%s/proxy/healthz", w.Node), false); err != nil || strings.TrimSpace(output) != "ok" {
+		return errors.Errorf("the kubelet of %s is not healthy: %s %v", w.Node, output, err)
+	}
+	output, err := runtime.GetRunner().SudoCmd(fmt.Sprintf(
+		"/usr/local/bin/kubectl get node %s -o jsonpath='{.status.conditions[?(@.type==\"Ready\")].status}'", w.Node), false)
+	if err != nil {
+		return errors.Wrapf(errors.WithStack(err), "get the node %s failed", w.Node)
+	}
+	if strings.TrimSpace(output) != "True" {
+		return errors.Errorf("the node %s is not ready", w.Node)
+	}
+	return nil
+}
@@ -0,0 +1,343 @@
+/*
+ Copyright 2024 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	versionutil "k8s.io/apimachinery/pkg/util/version"
+
+	"github.com/kubesys/kubekey/cmd/kk/pkg/version/kubesphere"
+)
+
+const (
+	SkewOK      = "OK"
+	SkewWarning = "Warning"
+	SkewError   = "Error"
+	SkewUnknown = "Unknown"
+)
+
+// UpgradePlan is the minor-version hops from the current to the target Kubernetes version,
+// and the version skew checks of the components for every hop.
+type UpgradePlan struct {
+	Current string
+	Target  string
+	// Hops are the versions upgraded to one by one. The last one is Target.
+	Hops []string
+	// KubeSphereHop is the index of the hop after which KubeSphere is upgraded, it's -1 if KubeSphere
+	// is upgraded before any hop.
+	KubeSphereHop int
+	Checks        []SkewCheck
+}
+
+type SkewCheck struct {
+	Component string
+	Version   string
+	Status    string
+	Message   string
+}
+
+// PlanHops returns the versions to upgrade to one by one: the latest supported patch of every minor
+// version between current and target, then target. kubeadm can only upgrade one minor version at a time.
+func PlanHops(current, target string) ([]string, error) {
+	currentVersion, err := versionutil.ParseSemantic(current)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse current version %s failed", current)
+	}
+	targetVersion, err := versionutil.ParseSemantic(target)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse target version %s failed", target)
+	}
+	if currentVersion.Major() != targetVersion.Major() {
+		return nil, errors.Errorf("can not upgrade from %s to %s across major versions", current, target)
+	}
+	if !currentVersion.LessThan(targetVersion) {
+		return nil, nil
+	}
+
+	var hops []string
+	for v := current; v != target; {
+		next, err := calculateNextStr(v, target)
+		if err != nil {
+			return nil, err
+		}
+		hops = append(hops, next)
+		v = next
+	}
+	return hops, nil
+}
+
+// Next returns the hop after current.
+func (p *UpgradePlan) Next(current string) (string, error) {
+	currentVersion, err := versionutil.ParseSemantic(current)
+	if err != nil {
+		return "", errors.Wrapf(err, "parse current version %s failed", current)
+	}
+	for _, hop := range p.Hops {
+		if currentVersion.LessThan(versionutil.MustParseSemantic(hop)) {
+			return hop, nil
+		}
+	}
+	return "", errors.Errorf("no hop after %s in the upgrade plan", current)
+}
+
+// KubeSphereVersion returns the Kubernetes version KubeSphere is upgraded at.
+func (p *UpgradePlan) KubeSphereVersion() string {
+	if p.KubeSphereHop < 0 || len(p.Hops) == 0 {
+		return p.Current
+	}
+	return p.Hops[p.KubeSphereHop]
+}
+
+// Failed returns true if any check fails.
+func (p *UpgradePlan) Failed() bool {
+	for _, c := range p.Checks {
+		if c.Status == SkewError {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *UpgradePlan) Print(w io.Writer, kubeSphereUpgrade bool) {
+	fmt.Fprintf(w, "Kubernetes upgrade plan: %s to %s\n", p.Current, p.Target)
+	if len(p.Hops) == 0 {
+		fmt.Fprintln(w, "  the cluster is up to date")
+	}
+	if kubeSphereUpgrade && p.KubeSphereHop < 0 {
+		fmt.Fprintln(w, "  0. upgrade KubeSphere")
+	}
+	from := p.Current
+	for i, hop := range p.Hops {
+		fmt.Fprintf(w, "  %d. %s -> %s: upgrade the control plane nodes one by one, then the worker nodes one by one\n", i+1, from, hop)
+		if kubeSphereUpgrade && p.KubeSphereHop == i {
+			fmt.Fprintf(w, "  %d. upgrade KubeSphere\n", i+1)
+		}
+		from = hop
+	}
+	fmt.Fprintln(w)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, "COMPONENT\tVERSION\tSTATUS\tMESSAGE")
+	for _, c := range p.Checks {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", c.Component, c.Version, c.Status, c.Message)
+	}
+	tw.Flush()
+	fmt.Fprintln(w)
+}
+
+// maxKubeletSkew is the number of minor versions the kubelet may be older than kube-apiserver.
+// It's 3 since Kubernetes v1.28, and 2 before.
+func maxKubeletSkew(apiserver *versionutil.Version) uint {
+	if apiserver.AtLeast(versionutil.MustParseSemantic("v1.28.0")) {
+		return 3
+	}
+	return 2
+}
+
+// CheckKubeletSkew checks the kubelet of every node is within the supported skew of the first hop.
+// The kubelets are upgraded in every hop, so that the later hops are in the skew as well.
+func CheckKubeletSkew(nodes map[string]string, hops []string) []SkewCheck {
+	names := make([]string, 0, len(nodes))
+	for name := range nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	checks := make([]SkewCheck, 0, len(names))
+	for _, name := range names {
+		c := SkewCheck{Component: fmt.Sprintf("kubelet (%s)", name), Version: nodes[name], Status: SkewOK}
+		kubelet, err := versionutil.ParseSemantic(nodes[name])
+		switch {
+		case err != nil:
+			c.Status, c.Message = SkewUnknown, fmt.Sprintf("parse version failed: %v", err)
+		case len(hops) > 0:
+			apiserver := versionutil.MustParseSemantic(hops[0])
+			if skew := maxKubeletSkew(apiserver); kubelet.Minor()+skew < apiserver.Minor() {
+				c.Status = SkewError
+				c.Message = fmt.Sprintf("more than %d minor versions older than kube-apiserver %s, upgrade the node first", skew, hops[0])
+			}
+		}
+		checks = append(checks, c)
+	}
+	return checks
+}
+
+// minEtcdVersions is the minimum etcd version supported by Kubernetes, the same as MinExternalEtcdVersion of kubeadm.
+var minEtcdVersions = []struct {
+	kubernetes string
+	etcd       string
+}{
+	{kubernetes: "v1.22.0", etcd: "v3.4.13"},
+	{kubernetes: "v1.13.0", etcd: "v3.2.18"},
+}
+
+// CheckEtcdSkew checks the etcd version is supported by every hop. The version is empty if it's unknown.
+func CheckEtcdSkew(version string, hops []string) SkewCheck {
+	c := SkewCheck{Component: "etcd", Version: version, Status: SkewOK}
+	if version == "" {
+		c.Status, c.Message = SkewUnknown, "the version of etcd is unknown, please check it manually"
+		return c
+	}
+	etcd, err := versionutil.ParseGeneric(version)
+	if err != nil {
+		c.Status, c.Message = SkewUnknown, fmt.Sprintf("parse version failed: %v", err)
+		return c
+	}
+	for _, hop := range hops {
+		k8s := versionutil.MustParseSemantic(hop)
+		for _, m := range minEtcdVersions {
+			if !k8s.AtLeast(versionutil.MustParseSemantic(m.kubernetes)) {
+				continue
+			}
+			if etcd.LessThan(versionutil.MustParseGeneric(m.etcd)) {
+				c.Status = SkewError
+				c.Message = fmt.Sprintf("Kubernetes %s requires etcd %s or later", hop, m.etcd)
+				return c
+			}
+			break
+		}
+	}
+	return c
+}
+
+// cniSupportedVersions is the Kubernetes minor versions tested by the CNI releases.
+var cniSupportedVersions = map[string]map[string][2]string{
+	"calico": {
+		"v3.23": {"v1.21", "v1.23"},
+		"v3.24": {"v1.22", "v1.24"},
+		"v3.25": {"v1.23", "v1.25"},
+		"v3.26": {"v1.24", "v1.28"},
+		"v3.27": {"v1.27", "v1.29"},
+		"v3.28": {"v1.27", "v1.30"},
+	},
+	"cilium": {
+		"v1.13": {"v1.16", "v1.26"},
+		"v1.14": {"v1.16", "v1.27"},
+		"v1.15": {"v1.16", "v1.29"},
+	},
+}
+
+// CheckCNISkew checks the CNI plugin supports every hop. The CNI is not upgraded by "kk upgrade".
+func CheckCNISkew(plugin, version string, hops []string) SkewCheck {
+	c := SkewCheck{Component: fmt.Sprintf("cni (%s)", plugin), Version: version, Status: SkewOK}
+	matrix, ok := cniSupportedVersions[plugin]
+	if !ok {
+		c.Status, c.Message = SkewUnknown, "not checked, please make sure it supports the target version"
+		return c
+	}
+	v, err := versionutil.ParseGeneric(version)
+	if err != nil {
+		c.Status, c.Message = SkewUnknown, fmt.Sprintf("the version of %s is unknown, please check it manually", plugin)
+		return c
+	}
+	supported, ok := matrix[fmt.Sprintf("v%d.%d", v.Major(), v.Minor())]
+	if !ok {
+		c.Status, c.Message = SkewWarning, fmt.Sprintf("%s %s is not in the compatibility matrix, please check it manually", plugin, version)
+		return c
+	}
+	minVersion := versionutil.MustParseGeneric(supported[0])
+	maxVersion := versionutil.MustParseGeneric(supported[1])
+	for _, hop := range hops {
+		k8s := versionutil.MustParseSemantic(hop)
+		minor := versionutil.MustParseGeneric(fmt.Sprintf("v%d.%d", k8s.Major(), k8s.Minor()))
+		if minor.LessThan(minVersion) || maxVersion.LessThan(minor) {
+			c.Status = SkewWarning
+			c.Message = fmt.Sprintf("%s %s supports Kubernetes %s to %s, but not %s", plugin, version, supported[0], supported[1], hop)
+			return c
+		}
+	}
+	return c
+}
+
+// PlanKubeSphere returns the index of the hop after which KubeSphere is upgraded: the last hop the installed
+// KubeSphere supports. It's len(hops)-1 if the installed one supports all the hops.
+func PlanKubeSphere(installed string, hops []string) int {
+	ks, ok := kubesphere.VersionMap[installed]
+	if !ok {
+		return len(hops) - 1
+	}
+	for i, hop := range hops {
+		if !ks.K8sSupport(hop) {
+			return i - 1
+		}
+	}
+	return len(hops) - 1
+}
+
+// CheckKubeSphereSkew checks KubeSphere supports the hops it runs on. installed is upgraded to desired after the
+// KubeSphereHop if upgrade is true.
+func CheckKubeSphereSkew(installed, desired string, upgrade bool, p *UpgradePlan) SkewCheck {
+	c := SkewCheck{Component: "kubesphere", Version: installed, Status: SkewOK}
+	hops := p.Hops
+	if upgrade && installed != desired {
+		c.Version = fmt.Sprintf("%s -> %s", installed, desired)
+		if ks, ok := kubesphere.VersionMap[desired]; ok {
+			for _, hop := range hops[p.KubeSphereHop+1:] {
+				if !ks.K8sSupport(hop) {
+					c.Status = SkewError
+					c.Message = fmt.Sprintf("KubeSphere %s does not support running on Kubernetes %s", desired, hop)
+					return c
+				}
+			}
+		}
+		hops = hops[:p.KubeSphereHop+1]
+	}
+	ks, ok := kubesphere.VersionMap[installed]
+	if !ok {
+		c.Status, c.Message = SkewUnknown, "not checked, please make sure it supports the target version"
+		return c
+	}
+	for _, hop := range hops {
+		if !ks.K8sSupport(hop) {
+			c.Status = SkewWarning
+			c.Message = fmt.Sprintf("KubeSphere %s does not support running on Kubernetes %s, please upgrade it with --with-kubesphere", installed, hop)
+			return c
+		}
+	}
+	return c
+}
+
+// CheckAddonsSkew lists the addons which are not upgraded by kk, they should be checked manually.
+func CheckAddonsSkew(addons []string, target string) []SkewCheck {
+	checks := make([]SkewCheck, 0, len(addons))
+	for _, name := range addons {
+		checks = append(checks, SkewCheck{
+			Component: fmt.Sprintf("addon (%s)", name),
+			Status:    SkewUnknown,
+			Message:   fmt.Sprintf("not upgraded by kk, please make sure it supports Kubernetes %s", target),
+		})
+	}
+	return checks
+}
+
+// trimEtcdImageTag trims the revision of the etcd image tag used by kubeadm, e.g. 3.5.9-0.
+func trimEtcdImageTag(tag string) string {
+	tag = strings.TrimSpace(tag)
+	if i := strings.Index(tag, "-"); i > 0 {
+		tag = tag[:i]
+	}
+	if tag != "" && !strings.HasPrefix(tag, "v") {
+		tag = "v" + tag
+	}
+	return tag
+}
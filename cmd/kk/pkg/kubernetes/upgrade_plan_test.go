@@ -0,0 +1,170 @@
+/*
+ Copyright 2022 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPlanHops(t *testing.T) {
+	tests := []struct {
+		current string
+		target  string
+		want    []string
+		wantErr bool
+	}{
+		{
+			current: "v1.26.5",
+			target:  "v1.29.3",
+			want:    []string{"v1.27.16", "v1.28.15", "v1.29.3"},
+		},
+		{
+			current: "v1.23.10",
+			target:  "v1.23.17",
+			want:    []string{"v1.23.17"},
+		},
+		{
+			current: "v1.29.3",
+			target:  "v1.29.3",
+			want:    nil,
+		},
+		{
+			current: "v1.29.3",
+			target:  "v1.28.15",
+			want:    nil,
+		},
+		{
+			current: "v1.17.5",
+			target:  "v1.21.5",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.current+"-"+tt.target, func(t *testing.T) {
+			got, err := PlanHops(tt.current, tt.target)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("PlanHops() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PlanHops() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpgradePlanNext(t *testing.T) {
+	p := &UpgradePlan{Current: "v1.26.5", Target: "v1.28.15", Hops: []string{"v1.27.16", "v1.28.15"}}
+	if got, err := p.Next("v1.26.5"); err != nil || got != "v1.27.16" {
+		t.Errorf("Next() got = %v, %v, want v1.27.16", got, err)
+	}
+	if got, err := p.Next("v1.27.16"); err != nil || got != "v1.28.15" {
+		t.Errorf("Next() got = %v, %v, want v1.28.15", got, err)
+	}
+	if _, err := p.Next("v1.28.15"); err == nil {
+		t.Errorf("Next() of the target should fail")
+	}
+}
+
+func TestCheckKubeletSkew(t *testing.T) {
+	nodes := map[string]string{
+		"node1": "v1.26.5",
+		"node2": "v1.25.0",
+		"node3": "v1.23.0",
+	}
+	got := CheckKubeletSkew(nodes, []string{"v1.27.16", "v1.28.15"})
+	want := []string{SkewOK, SkewOK, SkewError}
+	for i, c := range got {
+		if c.Status != want[i] {
+			t.Errorf("CheckKubeletSkew() %s = %v, want %v", c.Component, c.Status, want[i])
+		}
+	}
+
+	// the kubelet skew is 3 minor versions since v1.28
+	got = CheckKubeletSkew(map[string]string{"node1": "v1.25.0"}, []string{"v1.28.15"})
+	if got[0].Status != SkewOK {
+		t.Errorf("CheckKubeletSkew() = %v, want %v", got[0].Status, SkewOK)
+	}
+}
+
+func TestCheckEtcdSkew(t *testing.T) {
+	tests := []struct {
+		version string
+		hops    []string
+		want    string
+	}{
+		{version: "v3.5.13", hops: []string{"v1.27.16", "v1.28.15"}, want: SkewOK},
+		{version: "v3.4.3", hops: []string{"v1.21.14", "v1.22.17"}, want: SkewError},
+		{version: "v3.4.3", hops: []string{"v1.21.14"}, want: SkewOK},
+		{version: "", hops: []string{"v1.21.14"}, want: SkewUnknown},
+	}
+	for _, tt := range tests {
+		if got := CheckEtcdSkew(tt.version, tt.hops); got.Status != tt.want {
+			t.Errorf("CheckEtcdSkew(%s, %v) = %v, want %v", tt.version, tt.hops, got.Status, tt.want)
+		}
+	}
+}
+
+func TestCheckCNISkew(t *testing.T) {
+	tests := []struct {
+		plugin  string
+		version string
+		hops    []string
+		want    string
+	}{
+		{plugin: "calico", version: "v3.27.4", hops: []string{"v1.27.16", "v1.28.15", "v1.29.3"}, want: SkewOK},
+		{plugin: "calico", version: "v3.23.2", hops: []string{"v1.23.17", "v1.24.0"}, want: SkewWarning},
+		{plugin: "calico", version: "v3.10.0", hops: []string{"v1.23.17"}, want: SkewWarning},
+		{plugin: "calico", version: "", hops: []string{"v1.23.17"}, want: SkewUnknown},
+		{plugin: "flannel", version: "v0.21.3", hops: []string{"v1.23.17"}, want: SkewUnknown},
+	}
+	for _, tt := range tests {
+		if got := CheckCNISkew(tt.plugin, tt.version, tt.hops); got.Status != tt.want {
+			t.Errorf("CheckCNISkew(%s, %s, %v) = %v, want %v", tt.plugin, tt.version, tt.hops, got.Status, tt.want)
+		}
+	}
+}
+
+func TestPlanKubeSphere(t *testing.T) {
+	hops := []string{"v1.21.14", "v1.22.17", "v1.23.17"}
+	tests := []struct {
+		installed string
+		want      int
+	}{
+		{installed: "v3.1.1", want: -1},
+		{installed: "v3.2.0", want: 1},
+		{installed: "v3.4.1", want: 2},
+		{installed: "unknown", want: 2},
+	}
+	for _, tt := range tests {
+		if got := PlanKubeSphere(tt.installed, hops); got != tt.want {
+			t.Errorf("PlanKubeSphere(%s) = %v, want %v", tt.installed, got, tt.want)
+		}
+	}
+
+	p := &UpgradePlan{Current: "v1.20.15", Hops: hops, KubeSphereHop: PlanKubeSphere("v3.2.0", hops)}
+	if got := p.KubeSphereVersion(); got != "v1.22.17" {
+		t.Errorf("KubeSphereVersion() = %v, want v1.22.17", got)
+	}
+	if c := CheckKubeSphereSkew("v3.2.0", "v3.4.1", true, p); c.Status != SkewOK {
+		t.Errorf("CheckKubeSphereSkew() = %v, want %v: %s", c.Status, SkewOK, c.Message)
+	}
+	if c := CheckKubeSphereSkew("v3.2.0", "v3.2.0", false, p); c.Status != SkewWarning {
+		t.Errorf("CheckKubeSphereSkew() = %v, want %v", c.Status, SkewWarning)
+	}
+}
@@ -19,21 +19,23 @@ package kubernetes
 type UpgradeStep int
 
 const (
-	ToV121 UpgradeStep = iota + 1
-	ToV122
+	// ToKubeSphereSupported upgrades to the last version of the upgrade plan supported by the installed KubeSphere.
+	ToKubeSphereSupported UpgradeStep = iota + 1
+	// ToTarget upgrades to the target version.
+	ToTarget
 )
 
 var UpgradeStepList = []UpgradeStep{
-	ToV121,
-	ToV122,
+	ToKubeSphereSupported,
+	ToTarget,
 }
 
 func (u UpgradeStep) String() string {
 	switch u {
-	case ToV121:
-		return "to v1.21"
-	case ToV122:
-		return "to v1.22"
+	case ToKubeSphereSupported:
+		return "to the version supported by kubesphere"
+	case ToTarget:
+		return "to the target version"
 	default:
 		return "invalid option"
 	}
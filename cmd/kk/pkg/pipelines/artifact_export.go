@@ -37,6 +37,7 @@ func NewArtifactExportPipeline(runtime *common.ArtifactRuntime) error {
 		&images.CopyImagesToLocalModule{ImageStartIndex: runtime.Arg.ImageStartIndex, ImageTransport: runtime.Arg.ImageTransport},
 		&binaries.ArtifactBinariesModule{},
 		&artifact.RepositoryModule{},
+		&artifact.DeltaModule{Skip: runtime.Arg.Base == ""},
 		&artifact.ArchiveModule{},
 		&filesystem.ChownOutputModule{},
 		&filesystem.ChownWorkDirModule{},
@@ -61,6 +62,7 @@ func NewK3sArtifactExportPipeline(runtime *common.ArtifactRuntime) error {
 		&images.CopyImagesToLocalModule{ImageStartIndex: runtime.Arg.ImageStartIndex},
 		&binaries.K3sArtifactBinariesModule{},
 		&artifact.RepositoryModule{},
+		&artifact.DeltaModule{Skip: runtime.Arg.Base == ""},
 		&artifact.ArchiveModule{},
 		&filesystem.ChownOutputModule{},
 		&filesystem.ChownWorkDirModule{},
@@ -85,6 +87,7 @@ func NewK8eArtifactExportPipeline(runtime *common.ArtifactRuntime) error {
 		&images.CopyImagesToLocalModule{ImageStartIndex: runtime.Arg.ImageStartIndex},
 		&binaries.K8eArtifactBinariesModule{},
 		&artifact.RepositoryModule{},
+		&artifact.DeltaModule{Skip: runtime.Arg.Base == ""},
 		&artifact.ArchiveModule{},
 		&filesystem.ChownOutputModule{},
 		&filesystem.ChownWorkDirModule{},
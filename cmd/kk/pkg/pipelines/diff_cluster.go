@@ -0,0 +1,74 @@
+/*
+ Copyright 2024 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package pipelines
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/kubesys/kubekey/cmd/kk/pkg/bootstrap/precheck"
+	"github.com/kubesys/kubekey/cmd/kk/pkg/common"
+	"github.com/kubesys/kubekey/cmd/kk/pkg/core/module"
+	"github.com/kubesys/kubekey/cmd/kk/pkg/core/pipeline"
+	"github.com/kubesys/kubekey/cmd/kk/pkg/kubernetes"
+)
+
+func NewDiffClusterPipeline(runtime *common.KubeRuntime, diff *kubernetes.ClusterDiff, output string) error {
+	m := []module.Module{
+		&precheck.GreetingsModule{},
+		&kubernetes.ClusterDiffModule{Diff: diff, Output: output},
+	}
+
+	p := pipeline.Pipeline{
+		Name:    "DiffClusterPipeline",
+		Modules: m,
+		Runtime: runtime,
+		// keep the json output parseable.
+		SkipPrintLogo: output == kubernetes.DiffOutputJSON,
+	}
+	if err := p.Start(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// DiffCluster compares the cluster configuration with the live cluster and prints the drifts.
+// It returns true if the live cluster drifts from the configuration.
+func DiffCluster(args common.Argument, output string) (bool, error) {
+	var loaderType string
+	if args.FilePath != "" {
+		loaderType = common.File
+	} else {
+		loaderType = common.AllInOne
+	}
+
+	runtime, err := common.NewKubeRuntime(loaderType, args)
+	if err != nil {
+		return false, err
+	}
+
+	diff := &kubernetes.ClusterDiff{}
+	switch runtime.Cluster.Kubernetes.Type {
+	case common.Kubernetes:
+		if err := NewDiffClusterPipeline(runtime, diff, output); err != nil {
+			return false, err
+		}
+	default:
+		return false, errors.New("unsupported cluster kubernetes type")
+	}
+
+	return diff.Drifted(), nil
+}
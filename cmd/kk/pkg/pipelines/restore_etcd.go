@@ -0,0 +1,70 @@
+/*
+ Copyright 2024 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package pipelines
+
+import (
+	"fmt"
+
+	kubekeyapiv1alpha2 "github.com/kubesys/kubekey/cmd/kk/apis/kubekey/v1alpha2"
+	"github.com/kubesys/kubekey/cmd/kk/pkg/bootstrap/precheck"
+	"github.com/kubesys/kubekey/cmd/kk/pkg/common"
+	"github.com/kubesys/kubekey/cmd/kk/pkg/core/module"
+	"github.com/kubesys/kubekey/cmd/kk/pkg/core/pipeline"
+	"github.com/kubesys/kubekey/cmd/kk/pkg/etcd"
+)
+
+func RestoreEtcdPipeline(runtime *common.KubeRuntime) error {
+	m := []module.Module{
+		&precheck.GreetingsModule{},
+		&etcd.PreCheckModule{},
+		&etcd.RestoreModule{},
+	}
+
+	p := pipeline.Pipeline{
+		Name:    "RestoreEtcdPipeline",
+		Modules: m,
+		Runtime: runtime,
+	}
+	if err := p.Start(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func RestoreEtcd(args common.Argument) error {
+	var loaderType string
+	if args.FilePath != "" {
+		loaderType = common.File
+	} else {
+		loaderType = common.AllInOne
+	}
+
+	runtime, err := common.NewKubeRuntime(loaderType, args)
+	if err != nil {
+		return err
+	}
+
+	// only the etcd installed by kubekey (binary with systemd) can be restored.
+	if runtime.Cluster.Etcd.Type != kubekeyapiv1alpha2.KubeKey {
+		return fmt.Errorf("restoring etcd of type %q is not supported, only %q is supported", runtime.Cluster.Etcd.Type, kubekeyapiv1alpha2.KubeKey)
+	}
+
+	if err := RestoreEtcdPipeline(runtime); err != nil {
+		return err
+	}
+	return nil
+}
@@ -48,6 +48,7 @@ func NewUpgradeClusterPipeline(runtime *common.KubeRuntime) error {
 		&precheck.GreetingsModule{},
 		&precheck.NodePreCheckModule{},
 		&precheck.ClusterPreCheckModule{SkipDependencyCheck: runtime.Arg.SkipDependencyCheck},
+		&kubernetes.UpgradePlanModule{},
 		&confirm.UpgradeConfirmModule{Skip: runtime.Arg.SkipConfirmCheck},
 		&artifact.UnArchiveModule{Skip: noArtifact},
 		&binaries.NodeBinariesModule{},
@@ -57,15 +58,15 @@ func NewUpgradeClusterPipeline(runtime *common.KubeRuntime) error {
 		&etcd.InstallETCDBinaryModule{Skip: skipUpgradeETCD},
 		&etcd.ConfigureModule{Skip: skipUpgradeETCD},
 		&etcd.BackupModule{Skip: skipUpgradeETCD},
-		&kubernetes.SetUpgradePlanModule{Step: kubernetes.ToV121},
-		&kubernetes.ProgressiveUpgradeModule{Step: kubernetes.ToV121},
+		&kubernetes.SetUpgradePlanModule{Step: kubernetes.ToKubeSphereSupported},
+		&kubernetes.ProgressiveUpgradeModule{Step: kubernetes.ToKubeSphereSupported},
 		&loadbalancer.HaproxyModule{Skip: !runtime.Cluster.ControlPlaneEndpoint.IsInternalLBEnabled()},
 		&kubesphere.CleanClusterConfigurationModule{Skip: !runtime.Cluster.KubeSphere.Enabled},
 		&kubesphere.ConvertModule{Skip: !runtime.Cluster.KubeSphere.Enabled},
 		&kubesphere.DeployModule{Skip: !runtime.Cluster.KubeSphere.Enabled},
 		&kubesphere.CheckResultModule{Skip: !runtime.Cluster.KubeSphere.Enabled},
-		&kubernetes.SetUpgradePlanModule{Step: kubernetes.ToV122},
-		&kubernetes.ProgressiveUpgradeModule{Step: kubernetes.ToV122},
+		&kubernetes.SetUpgradePlanModule{Step: kubernetes.ToTarget},
+		&kubernetes.ProgressiveUpgradeModule{Step: kubernetes.ToTarget},
 		&filesystem.ChownModule{},
 		&certs.AutoRenewCertsModule{Skip: !runtime.Cluster.Kubernetes.EnableAutoRenewCerts()},
 	}
@@ -81,6 +82,50 @@ func NewUpgradeClusterPipeline(runtime *common.KubeRuntime) error {
 	return nil
 }
 
+func NewUpgradePlanPipeline(runtime *common.KubeRuntime) error {
+	m := []module.Module{
+		&precheck.GreetingsModule{},
+		&precheck.ClusterPreCheckModule{SkipDependencyCheck: runtime.Arg.SkipDependencyCheck},
+		&kubernetes.UpgradePlanModule{},
+	}
+
+	p := pipeline.Pipeline{
+		Name:    "UpgradePlanPipeline",
+		Modules: m,
+		Runtime: runtime,
+	}
+	if err := p.Start(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// UpgradePlan prints the upgrade plan and the version skew checks without upgrading the cluster.
+func UpgradePlan(args common.Argument) error {
+	var loaderType string
+	if args.FilePath != "" {
+		loaderType = common.File
+	} else {
+		loaderType = common.AllInOne
+	}
+
+	runtime, err := common.NewKubeRuntime(loaderType, args)
+	if err != nil {
+		return err
+	}
+
+	switch runtime.Cluster.Kubernetes.Type {
+	case common.Kubernetes:
+		if err := NewUpgradePlanPipeline(runtime); err != nil {
+			return err
+		}
+	default:
+		return errors.New("unsupported cluster kubernetes type")
+	}
+
+	return nil
+}
+
 func UpgradeCluster(args common.Argument, downloadCmd string) error {
 	args.DownloadCommand = func(path, url string) string {
 		// this is an extension point for downloading tools, for example users can set the timeout, proxy or retry under
@@ -23,7 +23,7 @@ import (
 	"embed"
 )
 
-//go:embed playbooks roles
+//go:embed playbooks roles schema
 var BuiltinPipeline embed.FS
 
 //go:embed inventory/inventory.yaml
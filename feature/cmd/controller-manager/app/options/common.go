@@ -28,6 +28,8 @@ import (
 	"github.com/google/gops/agent"
 	"github.com/spf13/pflag"
 	"k8s.io/klog/v2"
+
+	"github.com/kubesphere/kubekey/v4/pkg/proxy"
 )
 
 // ======================================================================================
@@ -148,6 +150,23 @@ func InitGOPS() error {
 	return nil
 }
 
+// ======================================================================================
+//                                       STORAGE
+// ======================================================================================
+
+var storageFsync bool
+
+// AddStorageFlags to NewControllerManagerCommand
+func AddStorageFlags(flags *pflag.FlagSet) {
+	flags.BoolVar(&storageFsync, "storage-fsync", false, "Whether to flush the resources stored in local runtime dir to disk after each write. "+
+		"It's safer on power loss but slower.")
+}
+
+// InitStorage set the options of the resources stored in local runtime dir.
+func InitStorage() {
+	proxy.SetFsync(storageFsync)
+}
+
 // ======================================================================================
 //                                       KLOG
 // ======================================================================================
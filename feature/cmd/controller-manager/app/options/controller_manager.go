@@ -29,6 +29,12 @@ type ControllerManagerServerOptions struct {
 
 	MaxConcurrentReconciles int
 	LeaderElection          bool
+	// Webhook enable the validating webhook for Pipeline, Inventory and Config.
+	Webhook bool
+	// WebhookPort is the port the validating webhook server listens on.
+	WebhookPort int
+	// MetricsBindAddress is the address the prometheus metrics endpoint binds to. "0" disables it.
+	MetricsBindAddress string
 }
 
 // NewControllerManagerServerOptions for NewControllerManagerCommand
@@ -36,6 +42,8 @@ func NewControllerManagerServerOptions() *ControllerManagerServerOptions {
 	return &ControllerManagerServerOptions{
 		WorkDir:                 "/kubekey",
 		MaxConcurrentReconciles: 1,
+		WebhookPort:             9443,
+		MetricsBindAddress:      ":8080",
 	}
 }
 
@@ -48,6 +56,10 @@ func (o *ControllerManagerServerOptions) Flags() cliflag.NamedFlagSets {
 	cfs := fss.FlagSet("controller-manager")
 	cfs.IntVar(&o.MaxConcurrentReconciles, "max-concurrent-reconciles", o.MaxConcurrentReconciles, "The number of maximum concurrent reconciles for controller.")
 	cfs.BoolVar(&o.LeaderElection, "leader-election", o.LeaderElection, "Whether to enable leader election for controller-manager.")
+	cfs.BoolVar(&o.Webhook, "webhook", o.Webhook, "Whether to enable the validating webhook for Pipeline, Inventory and Config.")
+	cfs.IntVar(&o.WebhookPort, "webhook-port", o.WebhookPort, "The port the validating webhook server listens on. "+
+		"The serving certificate is read from /tmp/k8s-webhook-server/serving-certs.")
+	cfs.StringVar(&o.MetricsBindAddress, "metrics-bind-address", o.MetricsBindAddress, "The address the prometheus metrics endpoint binds to. Set 0 to disable it.")
 
 	return fss
 }
@@ -40,6 +40,7 @@ func NewControllerManagerCommand() *cobra.Command {
 			if err := options.InitGOPS(); err != nil {
 				return err
 			}
+			options.InitStorage()
 
 			return options.InitProfiling(ctx)
 		},
@@ -65,6 +66,7 @@ func NewControllerManagerCommand() *cobra.Command {
 	options.AddProfilingFlags(flags)
 	options.AddKlogFlags(flags)
 	options.AddGOPSFlags(flags)
+	options.AddStorageFlags(flags)
 
 	fs := cmd.Flags()
 	for _, f := range o.Flags().FlagSets {
@@ -80,5 +82,8 @@ func run(ctx context.Context, o *options.ControllerManagerServerOptions) error {
 	return manager.NewControllerManager(manager.ControllerManagerOptions{
 		MaxConcurrentReconciles: o.MaxConcurrentReconciles,
 		LeaderElection:          o.LeaderElection,
+		Webhook:                 o.Webhook,
+		WebhookPort:             o.WebhookPort,
+		MetricsBindAddress:      o.MetricsBindAddress,
 	}).Run(ctx)
 }
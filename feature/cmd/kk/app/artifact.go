@@ -59,7 +59,7 @@ func newArtifactExportCommand() *cobra.Command {
 			// when package an artifact, should not contains certs
 			pipeline.Spec.SkipTags = []string{"certs"}
 
-			return run(ctx, pipeline, config, inventory)
+			return run(ctx, pipeline, config, inventory, o.Callbacks)
 		},
 	}
 
@@ -91,7 +91,7 @@ func newArtifactImagesCommand() *cobra.Command {
 				}
 			}
 
-			return run(ctx, pipeline, config, inventory)
+			return run(ctx, pipeline, config, inventory, o.Callbacks)
 		},
 	}
 
@@ -59,7 +59,7 @@ func newCreateClusterCommand() *cobra.Command {
 				}
 			}
 
-			return run(ctx, pipeline, config, inventory)
+			return run(ctx, pipeline, config, inventory, o.Callbacks)
 		},
 	}
 
@@ -57,7 +57,7 @@ func newInitOSCommand() *cobra.Command {
 				}
 			}
 
-			return run(ctx, pipeline, config, inventory)
+			return run(ctx, pipeline, config, inventory, o.Callbacks)
 		},
 	}
 
@@ -89,7 +89,7 @@ func newInitRegistryCommand() *cobra.Command {
 				}
 			}
 
-			return run(ctx, pipeline, config, inventory)
+			return run(ctx, pipeline, config, inventory, o.Callbacks)
 		},
 	}
 
@@ -0,0 +1,105 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubesphere/kubekey/v4/cmd/kk/app/options"
+	kkcorev1 "github.com/kubesphere/kubekey/v4/pkg/apis/core/v1"
+	kkprojectv1 "github.com/kubesphere/kubekey/v4/pkg/apis/project/v1"
+	_const "github.com/kubesphere/kubekey/v4/pkg/const"
+	"github.com/kubesphere/kubekey/v4/pkg/lint"
+	"github.com/kubesphere/kubekey/v4/pkg/project"
+)
+
+func newLintCommand() *cobra.Command {
+	o := options.NewKubeKeyRunOptions()
+
+	cmd := &cobra.Command{
+		Use:   "lint [playbook]",
+		Short: "Check a playbook without connecting to any host. the same as \"kk run --syntax-check\"",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o.SyntaxCheck = true
+			pipeline, _, _, err := o.Complete(cmd, args)
+			if err != nil {
+				return err
+			}
+			_const.SetWorkDir(o.WorkDir)
+
+			return lintPlaybook(ctx, pipeline, o, cmd.OutOrStdout())
+		},
+	}
+
+	for _, f := range o.Flags().FlagSets {
+		cmd.Flags().AddFlagSet(f)
+	}
+
+	return cmd
+}
+
+// lintPlaybook check the playbook of pipeline, and list tasks or tags of it. no host is connected.
+func lintPlaybook(ctx context.Context, pipeline *kkcorev1.Pipeline, o *options.KubeKeyRunOptions, w io.Writer) error {
+	pj, err := project.New(ctx, *pipeline, false)
+	if err != nil {
+		return fmt.Errorf("deal project error: %w", err)
+	}
+	pb, err := pj.MarshalPlaybook()
+	if err != nil {
+		return fmt.Errorf("convert playbook error: %w", err)
+	}
+	result := lint.Lint(pj, pb)
+
+	if o.ListTasks {
+		var play string
+		for _, t := range result.Tasks {
+			if !(kkprojectv1.Taggable{Tags: t.Tags}).IsEnabled(pipeline.Spec.Tags, pipeline.Spec.SkipTags) {
+				continue
+			}
+			if t.Play != play {
+				play = t.Play
+				fmt.Fprintf(w, "play: %s\n", play)
+			}
+			name := t.Name
+			if t.Role != "" {
+				name = t.Role + " : " + name
+			}
+			fmt.Fprintf(w, "  %s\tTAGS: [%s]\n", name, strings.Join(t.Tags, ", "))
+		}
+	}
+	if o.ListTags {
+		fmt.Fprintf(w, "TAGS: [%s]\n", strings.Join(result.Tags(), ", "))
+	}
+
+	if len(result.Issues) != 0 {
+		for _, i := range result.Issues {
+			fmt.Fprintln(w, i.String())
+		}
+
+		return fmt.Errorf("found %d issues in playbook %s", len(result.Issues), pipeline.Spec.Playbook)
+	}
+	if o.SyntaxCheck {
+		fmt.Fprintf(w, "playbook: %s\n", pipeline.Spec.Playbook)
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubesphere/kubekey/v4/cmd/kk/app/options"
+	"github.com/kubesphere/kubekey/v4/pkg/callback"
+	_const "github.com/kubesphere/kubekey/v4/pkg/const"
+)
+
+func newLogsCommand() *cobra.Command {
+	o := options.NewLogsOptions()
+
+	cmd := &cobra.Command{
+		Use:   "logs [pipeline]",
+		Short: "Print the output of tasks in each host of a pipeline",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(cmd, args); err != nil {
+				return err
+			}
+
+			logDir := filepath.Join(o.WorkDir, _const.LogDir)
+			if o.ListRuns {
+				runs, err := callback.ListRuns(logDir, o.Namespace, o.Pipeline)
+				if err != nil {
+					return err
+				}
+				for _, run := range runs {
+					fmt.Fprintln(cmd.OutOrStdout(), run)
+				}
+
+				return nil
+			}
+
+			return callback.ReadTaskLogs(logDir, o.Namespace, o.Pipeline, o.Run, o.Task, o.Host, cmd.OutOrStdout())
+		},
+	}
+
+	fs := cmd.Flags()
+	for _, f := range o.Flags().FlagSets {
+		fs.AddFlagSet(f)
+	}
+
+	return cmd
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	cliflag "k8s.io/component-base/cli/flag"
+	"k8s.io/klog/v2"
+)
+
+// LogsOptions for NewLogsOptions
+type LogsOptions struct {
+	// Pipeline is the name of pipeline which logs to read.
+	Pipeline  string
+	Namespace string
+	// WorkDir is the baseDir which the logs stored in.
+	WorkDir string
+	// Run is the run of pipeline which logs to read. it's the latest run by default.
+	Run string
+	// ListRuns only list the runs of pipeline.
+	ListRuns bool
+	// Task filter logs by the name or id of task.
+	Task string
+	// Host filter logs by host.
+	Host string
+}
+
+// NewLogsOptions for newLogsCommand
+func NewLogsOptions() *LogsOptions {
+	o := &LogsOptions{
+		Namespace: metav1.NamespaceDefault,
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		klog.ErrorS(err, "get current dir error")
+		o.WorkDir = "/tmp/kubekey"
+	} else {
+		o.WorkDir = filepath.Join(wd, "kubekey")
+	}
+
+	return o
+}
+
+// Flags add to newLogsCommand
+func (o *LogsOptions) Flags() cliflag.NamedFlagSets {
+	fss := cliflag.NamedFlagSets{}
+	lfs := fss.FlagSet("logs flags")
+	lfs.StringVarP(&o.Namespace, "namespace", "n", o.Namespace, "namespace of pipeline")
+	lfs.StringVar(&o.WorkDir, "work-dir", o.WorkDir, "the base Dir for kubekey. Default current dir. ")
+	lfs.StringVar(&o.Run, "run", o.Run, "show the logs of the run of pipeline. Default the latest run")
+	lfs.BoolVar(&o.ListRuns, "list-runs", o.ListRuns, "list the runs of pipeline which have logs")
+	lfs.StringVar(&o.Task, "task", o.Task, "only show the logs of task. match the name or id of task")
+	lfs.StringVar(&o.Host, "host", o.Host, "only show the logs of host")
+
+	return fss
+}
+
+// Complete options. get pipeline name from args.
+func (o *LogsOptions) Complete(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("%s\nSee '%s -h' for help and examples", cmd.Use, cmd.CommandPath())
+	}
+	o.Pipeline = args[0]
+
+	return nil
+}
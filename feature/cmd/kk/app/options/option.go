@@ -23,6 +23,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -46,6 +47,10 @@ var defaultInventory = &kkcorev1.Inventory{
 	},
 	ObjectMeta: metav1.ObjectMeta{Name: "default"}}
 
+const callbackUsage = "the callback which receive the events of pipeline. format: name[=path]. support: " +
+	"progress (print progress bar, default), summary (print failed tasks and recap of hosts), " +
+	"json (write events as json lines to path, or stdout if path is empty), junit (write junit xml report to path)"
+
 type commonOptions struct {
 	// Playbook which to execute.
 	Playbook string
@@ -63,6 +68,10 @@ type commonOptions struct {
 	Debug bool
 	// Namespace for all resources.
 	Namespace string
+	// Callbacks receive the events of pipeline. format: name[=path]
+	Callbacks []string
+	// Timeout is the deadline of pipeline. 0 means no timeout.
+	Timeout time.Duration
 }
 
 func newCommonOptions() commonOptions {
@@ -91,6 +100,8 @@ func (o *commonOptions) flags() cliflag.NamedFlagSets {
 	gfs.StringVarP(&o.InventoryFile, "inventory", "i", o.InventoryFile, "the host list file path. support *.ini")
 	gfs.BoolVarP(&o.Debug, "debug", "d", o.Debug, "Debug mode, after a successful execution of Pipeline, will retain runtime data, which includes task execution status and parameters.")
 	gfs.StringVarP(&o.Namespace, "namespace", "n", o.Namespace, "the namespace which pipeline will be executed, all reference resources(pipeline, config, inventory, task) should in the same namespace")
+	gfs.StringArrayVar(&o.Callbacks, "callback", o.Callbacks, callbackUsage)
+	gfs.DurationVar(&o.Timeout, "timeout", o.Timeout, "the deadline of pipeline, such as 30m or 2h. the pipeline will be failed when reached. 0 means no timeout.")
 
 	return fss
 }
@@ -108,6 +119,9 @@ func (o *commonOptions) completeRef(pipeline *kkcorev1.Pipeline) (*kkcorev1.Conf
 	if err != nil {
 		return nil, nil, fmt.Errorf("generate config error: %w", err)
 	}
+	if o.Timeout > 0 {
+		pipeline.Spec.Timeout = &metav1.Duration{Duration: o.Timeout}
+	}
 	pipeline.Spec.ConfigRef = &corev1.ObjectReference{
 		Kind:            config.Kind,
 		Namespace:       config.Namespace,
@@ -10,6 +10,7 @@ type PipelineOptions struct {
 	Name      string
 	Namespace string
 	WorkDir   string
+	Callbacks []string
 }
 
 // NewPipelineOptions for newPipelineCommand
@@ -27,6 +28,33 @@ func (o *PipelineOptions) Flags() cliflag.NamedFlagSets {
 	pfs.StringVar(&o.Name, "name", o.Name, "name of pipeline")
 	pfs.StringVarP(&o.Namespace, "namespace", "n", o.Namespace, "namespace of pipeline")
 	pfs.StringVar(&o.WorkDir, "work-dir", o.WorkDir, "the base Dir for kubekey. Default current dir. ")
+	pfs.StringArrayVar(&o.Callbacks, "callback", o.Callbacks, callbackUsage)
+
+	return fss
+}
+
+// PipelineCancelOptions for NewPipelineCancelOptions
+type PipelineCancelOptions struct {
+	Name      string
+	Namespace string
+	WorkDir   string
+}
+
+// NewPipelineCancelOptions for newPipelineCancelCommand
+func NewPipelineCancelOptions() *PipelineCancelOptions {
+	return &PipelineCancelOptions{
+		Namespace: metav1.NamespaceDefault,
+		WorkDir:   "/kubekey",
+	}
+}
+
+// Flags add to newPipelineCancelCommand
+func (o *PipelineCancelOptions) Flags() cliflag.NamedFlagSets {
+	fss := cliflag.NamedFlagSets{}
+	pfs := fss.FlagSet("pipeline flags")
+	pfs.StringVar(&o.Name, "name", o.Name, "name of pipeline")
+	pfs.StringVarP(&o.Namespace, "namespace", "n", o.Namespace, "namespace of pipeline")
+	pfs.StringVar(&o.WorkDir, "work-dir", o.WorkDir, "the base Dir for kubekey. it should be the same as the running pipeline when resources are stored local.")
 
 	return fss
 }
@@ -0,0 +1,68 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	cliflag "k8s.io/component-base/cli/flag"
+)
+
+// ProjectPushOptions for NewProjectPushOptions
+type ProjectPushOptions struct {
+	// ProjectDir is the local project dir to push.
+	ProjectDir string
+	// Target is where the project push to.
+	// When starting with oci://, it will be pushed to an oci registry.
+	// Otherwise, it will be written to the local tarball file.
+	Target string
+	// InsecureSkipTLS skip tls or not when push to oci registry.
+	InsecureSkipTLS bool
+	// Token of Authorization for oci registry. If empty, use the credentials in docker config.
+	Token string
+	// SignKey is the armored OpenPGP private key file which signs the project.
+	SignKey string
+}
+
+// NewProjectPushOptions for newProjectPushCommand
+func NewProjectPushOptions() *ProjectPushOptions {
+	return &ProjectPushOptions{}
+}
+
+// Flags add to newProjectPushCommand
+func (o *ProjectPushOptions) Flags() cliflag.NamedFlagSets {
+	fss := cliflag.NamedFlagSets{}
+	pfs := fss.FlagSet("push flags")
+	pfs.BoolVar(&o.InsecureSkipTLS, "insecure-skip-tls", o.InsecureSkipTLS, "skip tls or not when push to oci registry.")
+	pfs.StringVar(&o.Token, "token", o.Token, "the token for oci registry. Default use the credentials in docker config.")
+	pfs.StringVar(&o.SignKey, "sign-key", o.SignKey, "the armored OpenPGP private key file to sign the project. "+
+		"the signature is pushed as a layer to oci registry, or written to the tarball file with suffix .asc")
+
+	return fss
+}
+
+// Complete options. get project dir and target from args.
+func (o *ProjectPushOptions) Complete(cmd *cobra.Command, args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("%s\nSee '%s -h' for help and examples", cmd.Use, cmd.CommandPath())
+	}
+	o.ProjectDir = args[0]
+	o.Target = args[1]
+
+	return nil
+}
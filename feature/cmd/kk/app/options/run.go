@@ -18,6 +18,7 @@ package options
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/spf13/cobra"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -31,6 +32,7 @@ type KubeKeyRunOptions struct {
 	commonOptions
 	// ProjectAddr is the storage for executable packages (in Ansible format).
 	// When starting with http or https, it will be obtained from a Git repository.
+	// When starting with oci:// or ending with .tgz, it will be obtained from an archive.
 	// When starting with file path, it will be obtained from the local path.
 	ProjectAddr string
 	// ProjectName is the name of project. it will store to project dir use this name.
@@ -44,10 +46,20 @@ type KubeKeyRunOptions struct {
 	ProjectInsecureSkipTLS bool
 	// ProjectToken to clone and pull git project
 	ProjectToken string
+	// ProjectChecksum is the expected digest of oci or tarball project.
+	ProjectChecksum string
+	// ProjectPublicKey is the file of armored OpenPGP public key which verifies the signature of oci or tarball project.
+	ProjectPublicKey string
 	// Tags is the tags of playbook which to execute
 	Tags []string
 	// SkipTags is the tags of playbook which skip execute
 	SkipTags []string
+	// SyntaxCheck only check the playbook without connecting to any host.
+	SyntaxCheck bool
+	// ListTasks list all tasks which would be executed without connecting to any host.
+	ListTasks bool
+	// ListTags list all tags in playbook without connecting to any host.
+	ListTags bool
 }
 
 // NewKubeKeyRunOptions for newRunCommand
@@ -66,16 +78,24 @@ func (o *KubeKeyRunOptions) Flags() cliflag.NamedFlagSets {
 	gitfs := fss.FlagSet("project")
 	gitfs.StringVar(&o.ProjectAddr, "project-addr", o.ProjectAddr, "the storage for executable packages (in Ansible format)."+
 		" When starting with http or https, it will be obtained from a Git repository."+
-		"When starting with file path, it will be obtained from the local path.")
+		" When starting with oci:// or ending with .tgz, it will be obtained from an oci registry or a tarball."+
+		" When starting with file path, it will be obtained from the local path.")
 	gitfs.StringVar(&o.ProjectBranch, "project-branch", o.ProjectBranch, "the git branch of the remote Addr")
 	gitfs.StringVar(&o.ProjectTag, "project-tag", o.ProjectTag, "the git tag of the remote Addr")
 	gitfs.BoolVar(&o.ProjectInsecureSkipTLS, "project-insecure-skip-tls", o.ProjectInsecureSkipTLS, "skip tls or not when git addr is https.")
 	gitfs.StringVar(&o.ProjectToken, "project-token", o.ProjectToken, "the token for private project.")
+	gitfs.StringVar(&o.ProjectChecksum, "project-checksum", o.ProjectChecksum, "the expected digest of oci or tarball project. format is sha256:<hex>")
+	gitfs.StringVar(&o.ProjectPublicKey, "project-public-key", o.ProjectPublicKey, "the armored OpenPGP public key file which verifies the signature of oci or tarball project.")
 
 	tfs := fss.FlagSet("tags")
 	tfs.StringArrayVar(&o.Tags, "tags", o.Tags, "the tags of playbook which to execute")
 	tfs.StringArrayVar(&o.SkipTags, "skip-tags", o.SkipTags, "the tags of playbook which skip execute")
 
+	cfs := fss.FlagSet("check")
+	cfs.BoolVar(&o.SyntaxCheck, "syntax-check", o.SyntaxCheck, "only check the playbook without connecting to any host")
+	cfs.BoolVar(&o.ListTasks, "list-tasks", o.ListTasks, "list all tasks which would be executed without connecting to any host")
+	cfs.BoolVar(&o.ListTags, "list-tags", o.ListTags, "list all tags in playbook without connecting to any host")
+
 	return fss
 }
 
@@ -93,6 +113,13 @@ func (o *KubeKeyRunOptions) Complete(cmd *cobra.Command, args []string) (*kkcore
 		return nil, nil, nil, fmt.Errorf("%s\nSee '%s -h' for help and examples", cmd.Use, cmd.CommandPath())
 	}
 	o.Playbook = args[0]
+	var publicKey []byte
+	if o.ProjectPublicKey != "" {
+		var err error
+		if publicKey, err = os.ReadFile(o.ProjectPublicKey); err != nil {
+			return nil, nil, nil, fmt.Errorf("read public key %s error: %w", o.ProjectPublicKey, err)
+		}
+	}
 
 	pipeline.Spec = kkcorev1.PipelineSpec{
 		Project: kkcorev1.PipelineProject{
@@ -102,6 +129,8 @@ func (o *KubeKeyRunOptions) Complete(cmd *cobra.Command, args []string) (*kkcore
 			Tag:             o.ProjectTag,
 			InsecureSkipTLS: o.ProjectInsecureSkipTLS,
 			Token:           o.ProjectToken,
+			Checksum:        o.ProjectChecksum,
+			PublicKey:       string(publicKey),
 		},
 		Playbook: o.Playbook,
 		Tags:     o.Tags,
@@ -24,21 +24,9 @@ func newPipelineCommand() *cobra.Command {
 		Short: "Executor a pipeline in kubernetes",
 		RunE: func(*cobra.Command, []string) error {
 			_const.SetWorkDir(o.WorkDir)
-			restconfig, err := ctrl.GetConfig()
+			client, err := newPipelineClient()
 			if err != nil {
-				klog.Infof("kubeconfig in empty, store resources local")
-				restconfig = &rest.Config{}
-			}
-			restconfig, err = proxy.NewConfig(restconfig)
-			if err != nil {
-				return fmt.Errorf("could not get rest config: %w", err)
-			}
-
-			client, err := ctrlclient.New(restconfig, ctrlclient.Options{
-				Scheme: _const.Scheme,
-			})
-			if err != nil {
-				return fmt.Errorf("could not create client: %w", err)
+				return err
 			}
 			// get pipeline
 			var pipeline = new(kkcorev1.Pipeline)
@@ -70,10 +58,43 @@ func newPipelineCommand() *cobra.Command {
 				Config:    config,
 				Inventory: inventory,
 				Client:    client,
+				Callbacks: o.Callbacks,
 			}).Run(ctx)
 		},
 	}
 
+	fs := cmd.Flags()
+	for _, f := range o.Flags().FlagSets {
+		fs.AddFlagSet(f)
+	}
+	cmd.AddCommand(newPipelineCancelCommand())
+
+	return cmd
+}
+
+func newPipelineCancelCommand() *cobra.Command {
+	o := options.NewPipelineCancelOptions()
+
+	cmd := &cobra.Command{
+		Use:   "cancel",
+		Short: "Cancel a running pipeline",
+		RunE: func(*cobra.Command, []string) error {
+			_const.SetWorkDir(o.WorkDir)
+			client, err := newPipelineClient()
+			if err != nil {
+				return err
+			}
+			pipeline := &kkcorev1.Pipeline{}
+			if err := client.Get(ctx, ctrlclient.ObjectKey{Name: o.Name, Namespace: o.Namespace}, pipeline); err != nil {
+				return err
+			}
+			cp := pipeline.DeepCopy()
+			pipeline.Spec.Cancel = true
+
+			return client.Patch(ctx, pipeline, ctrlclient.MergeFrom(cp))
+		},
+	}
+
 	fs := cmd.Flags()
 	for _, f := range o.Flags().FlagSets {
 		fs.AddFlagSet(f)
@@ -81,3 +102,25 @@ func newPipelineCommand() *cobra.Command {
 
 	return cmd
 }
+
+// newPipelineClient return a client which store resources in kubernetes, or local when kubeconfig is empty.
+func newPipelineClient() (ctrlclient.Client, error) {
+	restconfig, err := ctrl.GetConfig()
+	if err != nil {
+		klog.Infof("kubeconfig in empty, store resources local")
+		restconfig = &rest.Config{}
+	}
+	restconfig, err = proxy.NewConfig(restconfig)
+	if err != nil {
+		return nil, fmt.Errorf("could not get rest config: %w", err)
+	}
+
+	client, err := ctrlclient.New(restconfig, ctrlclient.Options{
+		Scheme: _const.Scheme,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not create client: %w", err)
+	}
+
+	return client, nil
+}
@@ -49,7 +49,7 @@ func newPreCheckCommand() *cobra.Command {
 				}
 			}
 
-			return run(ctx, pipeline, config, inventory)
+			return run(ctx, pipeline, config, inventory, o.Callbacks)
 		},
 	}
 
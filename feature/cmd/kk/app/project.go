@@ -0,0 +1,91 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubesphere/kubekey/v4/cmd/kk/app/options"
+	"github.com/kubesphere/kubekey/v4/pkg/project"
+)
+
+func newProjectCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "project",
+		Short: "Manage the distribution of projects",
+	}
+	cmd.AddCommand(newProjectPushCommand())
+
+	return cmd
+}
+
+func newProjectPushCommand() *cobra.Command {
+	o := options.NewProjectPushOptions()
+
+	cmd := &cobra.Command{
+		Use:   "push [project dir] [target]",
+		Short: "Push a project to an oci registry (oci://registry/repo:tag) or a tarball file",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.Complete(cmd, args); err != nil {
+				return err
+			}
+			if project.IsOCIProject(o.Target) {
+				digest, err := project.Push(cmd.Context(), o.ProjectDir, o.Target, o.InsecureSkipTLS, o.Token, o.SignKey)
+				if err != nil {
+					return err
+				}
+				cmd.Printf("pushed %s\ndigest: %s\n", o.Target, digest)
+
+				return nil
+			}
+
+			buf := &bytes.Buffer{}
+			if err := project.Archive(o.ProjectDir, buf); err != nil {
+				return err
+			}
+			if err := os.WriteFile(o.Target, buf.Bytes(), 0o644); err != nil {
+				return fmt.Errorf("write file %s error: %w", o.Target, err)
+			}
+			if o.SignKey != "" {
+				signature, err := project.Sign(o.SignKey, buf.Bytes())
+				if err != nil {
+					return err
+				}
+				if err := os.WriteFile(o.Target+project.SignatureSuffix, signature, 0o644); err != nil {
+					return fmt.Errorf("write file %s error: %w", o.Target+project.SignatureSuffix, err)
+				}
+			}
+			sum := sha256.Sum256(buf.Bytes())
+			cmd.Printf("archived %s\nchecksum: sha256:%s\n", o.Target, hex.EncodeToString(sum[:]))
+
+			return nil
+		},
+	}
+
+	fs := cmd.Flags()
+	for _, f := range o.Flags().FlagSets {
+		fs.AddFlagSet(f)
+	}
+
+	return cmd
+}
@@ -47,6 +47,7 @@ func NewRootCommand() *cobra.Command {
 			if err := options.InitGOPS(); err != nil {
 				return err
 			}
+			options.InitStorage()
 
 			return options.InitProfiling(ctx)
 		},
@@ -61,9 +62,13 @@ func NewRootCommand() *cobra.Command {
 	options.AddProfilingFlags(flags)
 	options.AddKlogFlags(flags)
 	options.AddGOPSFlags(flags)
+	options.AddStorageFlags(flags)
 
 	cmd.AddCommand(newRunCommand())
 	cmd.AddCommand(newPipelineCommand())
+	cmd.AddCommand(newLogsCommand())
+	cmd.AddCommand(newProjectCommand())
+	cmd.AddCommand(newLintCommand())
 	cmd.AddCommand(newVersionCommand())
 	// internal command
 	cmd.AddCommand(internalCommand...)
@@ -22,6 +22,7 @@ import (
 	"os"
 
 	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/client-go/rest"
 	"k8s.io/klog/v2"
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
@@ -52,8 +53,11 @@ func newRunCommand() *cobra.Command {
 					return err
 				}
 			}
+			if o.SyntaxCheck || o.ListTasks || o.ListTags {
+				return lintPlaybook(ctx, kk, o, cmd.OutOrStdout())
+			}
 
-			return run(ctx, kk, config, inventory)
+			return run(ctx, kk, config, inventory, o.Callbacks)
 		},
 	}
 
@@ -64,7 +68,7 @@ func newRunCommand() *cobra.Command {
 	return cmd
 }
 
-func run(ctx context.Context, pipeline *kkcorev1.Pipeline, config *kkcorev1.Config, inventory *kkcorev1.Inventory) error {
+func run(ctx context.Context, pipeline *kkcorev1.Pipeline, config *kkcorev1.Config, inventory *kkcorev1.Inventory, callbacks []string) error {
 	restconfig, err := proxy.NewConfig(&rest.Config{})
 	if err != nil {
 		return fmt.Errorf("could not get rest config: %w", err)
@@ -77,13 +81,13 @@ func run(ctx context.Context, pipeline *kkcorev1.Pipeline, config *kkcorev1.Conf
 	}
 
 	// create config
-	if err := client.Create(ctx, config); err != nil {
+	if err := createOrUpdate(ctx, client, config); err != nil {
 		klog.ErrorS(err, "Create config error", "pipeline", ctrlclient.ObjectKeyFromObject(pipeline))
 
 		return err
 	}
 	// create inventory
-	if err := client.Create(ctx, inventory); err != nil {
+	if err := createOrUpdate(ctx, client, inventory); err != nil {
 		klog.ErrorS(err, "Create inventory error", "pipeline", ctrlclient.ObjectKeyFromObject(pipeline))
 
 		return err
@@ -101,5 +105,24 @@ func run(ctx context.Context, pipeline *kkcorev1.Pipeline, config *kkcorev1.Conf
 		Config:    config,
 		Inventory: inventory,
 		Client:    client,
+		Callbacks: callbacks,
 	}).Run(ctx)
 }
+
+// createOrUpdate create obj. if obj exists (kept in runtime dir by previous run), update it.
+func createOrUpdate(ctx context.Context, client ctrlclient.Client, obj ctrlclient.Object) error {
+	err := client.Create(ctx, obj)
+	if !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+	existing, ok := obj.DeepCopyObject().(ctrlclient.Object)
+	if !ok {
+		return err
+	}
+	if err := client.Get(ctx, ctrlclient.ObjectKeyFromObject(obj), existing); err != nil {
+		return err
+	}
+	obj.SetResourceVersion(existing.GetResourceVersion())
+
+	return client.Update(ctx, obj)
+}
@@ -17,8 +17,11 @@ limitations under the License.
 package v1
 
 import (
+	"time"
+
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 // PipelinePhase of Pipeline
@@ -33,6 +36,8 @@ const (
 	PipelinePhaseFailed PipelinePhase = "Failed"
 	// PipelinePhaseSucceed of Pipeline. all Tasks run success.
 	PipelinePhaseSucceed PipelinePhase = "Succeed"
+	// PipelinePhaseCancelled of Pipeline. Pipeline is cancelled by spec.cancel or signal.
+	PipelinePhaseCancelled PipelinePhase = "Cancelled"
 )
 
 const (
@@ -66,6 +71,19 @@ type PipelineSpec struct {
 	// when execute in kubernetes, pipeline will create ob or cornJob to execute.
 	// +optional
 	JobSpec PipelineJobSpec `json:"jobSpec,omitempty"`
+	// Cancel the running pipeline. the running tasks will be stopped, and the pipeline will be Cancelled.
+	// +optional
+	Cancel bool `json:"cancel,omitempty"`
+	// Timeout is the deadline of the whole pipeline. the pipeline will be Failed when it's reached.
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	// DependsOn is the name of pipelines in the same namespace. the pipeline runs after all of them are Succeed,
+	// and will be Failed if any of them is Failed or Cancelled. the outputs of them are merged to the runtime variables.
+	// +optional
+	DependsOn []string `json:"dependsOn,omitempty"`
+	// Outputs is the name of variables which export to status.outputs for each host after the pipeline is Succeed.
+	// +optional
+	Outputs []string `json:"outputs,omitempty"`
 }
 
 // PipelineJobSpec set the spec of the job that allows configuration
@@ -115,6 +133,11 @@ type PipelineJobSpec struct {
 	// +optional
 	TTLSecondsAfterFinished *int32 `json:"ttlSecondsAfterFinished,omitempty"`
 
+	// LogVolumeClaim is the name of PersistentVolumeClaim which stores the task logs.
+	// it's mounted to the log dir of executor, the logs can be read by "kk logs" in the pod which mounts the same claim.
+	// +optional
+	LogVolumeClaim string `json:"logVolumeClaim,omitempty"`
+
 	// Volumes in job pod.
 	// +optional
 	Volumes []corev1.Volume `json:"workVolume,omitempty"`
@@ -128,6 +151,8 @@ type PipelineJobSpec struct {
 type PipelineProject struct {
 	// Addr is the storage for executable packages (in Ansible file format).
 	// When starting with http or https, it will be obtained from a Git repository.
+	// When starting with oci://, it will be pulled from an OCI registry.
+	// When ending with .tgz or .tar.gz, it will be downloaded as a tarball.
 	// When starting with file path, it will be obtained from the local path.
 	// +optional
 	Addr string `json:"addr,omitempty"`
@@ -146,6 +171,14 @@ type PipelineProject struct {
 	// Token of Authorization for http request
 	// +optional
 	Token string `json:"token,omitempty"`
+	// Checksum is the expected digest of oci or tarball Addr, in format "sha256:<hex>".
+	// For oci Addr, it's the digest of manifest. For tarball Addr, it's the sha256 of the file.
+	// +optional
+	Checksum string `json:"checksum,omitempty"`
+	// PublicKey is the armored OpenPGP public key which signs the oci or tarball Addr.
+	// When it's set, the project is rejected if its signature is not found or not valid.
+	// +optional
+	PublicKey string `json:"publicKey,omitempty"`
 }
 
 // PipelineStatus of Pipeline
@@ -158,6 +191,54 @@ type PipelineStatus struct {
 	Reason string `json:"reason,omitempty"`
 	// FailedDetail will record the failed tasks.
 	FailedDetail []PipelineFailedDetail `json:"failedDetail,omitempty"`
+	// StartTimestamp is the time when the executor start to run the pipeline.
+	StartTimestamp *metav1.Time `json:"startTimestamp,omitempty"`
+	// CompletionTimestamp is the time when the executor finish the pipeline.
+	CompletionTimestamp *metav1.Time `json:"completionTimestamp,omitempty"`
+	// TaskDurations record the duration of the slowest executed tasks, at most 10, sorted from the slowest.
+	TaskDurations []PipelineTaskDuration `json:"taskDurations,omitempty"`
+	// TaskDurationHistogram is the histogram of the duration of all executed tasks. the key is the phase of task.
+	TaskDurationHistogram map[string]PipelineTaskDurationHistogram `json:"taskDurationHistogram,omitempty"`
+	// Outputs is the variables defined in spec.outputs for each host. the key is hostname.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Outputs runtime.RawExtension `json:"outputs,omitempty"`
+}
+
+// PipelineTaskDuration is the duration of a task.
+type PipelineTaskDuration struct {
+	// Task name.
+	Task string `json:"task,omitempty"`
+	// Phase of task, such as Success, Failed, Ignored.
+	Phase string `json:"phase,omitempty"`
+	// Duration of task in all hosts.
+	Duration metav1.Duration `json:"duration,omitempty"`
+}
+
+// TaskDurationBuckets is the upper bounds in seconds of the buckets in PipelineTaskDurationHistogram.
+var TaskDurationBuckets = []float64{0.5, 1, 2, 4, 8, 16, 32, 64, 128, 256, 512, 1024, 2048, 4096}
+
+// PipelineTaskDurationHistogram is the histogram of the duration of tasks.
+type PipelineTaskDurationHistogram struct {
+	// Count is the number of tasks.
+	Count int64 `json:"count,omitempty"`
+	// Sum is the total duration of tasks.
+	Sum metav1.Duration `json:"sum,omitempty"`
+	// Buckets is the cumulative number of tasks whose duration is not greater than each bound in TaskDurationBuckets.
+	Buckets []int64 `json:"buckets,omitempty"`
+}
+
+// Observe adds the duration of a task to the histogram.
+func (h *PipelineTaskDurationHistogram) Observe(d time.Duration) {
+	if len(h.Buckets) != len(TaskDurationBuckets) {
+		h.Buckets = make([]int64, len(TaskDurationBuckets))
+	}
+	h.Count++
+	h.Sum.Duration += d
+	for i, bound := range TaskDurationBuckets {
+		if d.Seconds() <= bound {
+			h.Buckets[i]++
+		}
+	}
 }
 
 // PipelineTaskResult of Pipeline
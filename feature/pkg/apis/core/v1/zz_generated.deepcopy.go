@@ -22,6 +22,7 @@ package v1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -406,6 +407,21 @@ func (in *PipelineSpec) DeepCopyInto(out *PipelineSpec) {
 		copy(*out, *in)
 	}
 	in.JobSpec.DeepCopyInto(&out.JobSpec)
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.DependsOn != nil {
+		in, out := &in.DependsOn, &out.DependsOn
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Outputs != nil {
+		in, out := &in.Outputs, &out.Outputs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineSpec.
@@ -429,6 +445,27 @@ func (in *PipelineStatus) DeepCopyInto(out *PipelineStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.StartTimestamp != nil {
+		in, out := &in.StartTimestamp, &out.StartTimestamp
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTimestamp != nil {
+		in, out := &in.CompletionTimestamp, &out.CompletionTimestamp
+		*out = (*in).DeepCopy()
+	}
+	if in.TaskDurations != nil {
+		in, out := &in.TaskDurations, &out.TaskDurations
+		*out = make([]PipelineTaskDuration, len(*in))
+		copy(*out, *in)
+	}
+	if in.TaskDurationHistogram != nil {
+		in, out := &in.TaskDurationHistogram, &out.TaskDurationHistogram
+		*out = make(map[string]PipelineTaskDurationHistogram, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	in.Outputs.DeepCopyInto(&out.Outputs)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineStatus.
@@ -441,6 +478,43 @@ func (in *PipelineStatus) DeepCopy() *PipelineStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PipelineTaskDuration) DeepCopyInto(out *PipelineTaskDuration) {
+	*out = *in
+	out.Duration = in.Duration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineTaskDuration.
+func (in *PipelineTaskDuration) DeepCopy() *PipelineTaskDuration {
+	if in == nil {
+		return nil
+	}
+	out := new(PipelineTaskDuration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PipelineTaskDurationHistogram) DeepCopyInto(out *PipelineTaskDurationHistogram) {
+	*out = *in
+	out.Sum = in.Sum
+	if in.Buckets != nil {
+		in, out := &in.Buckets, &out.Buckets
+		*out = make([]int64, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineTaskDurationHistogram.
+func (in *PipelineTaskDurationHistogram) DeepCopy() *PipelineTaskDurationHistogram {
+	if in == nil {
+		return nil
+	}
+	out := new(PipelineTaskDurationHistogram)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PipelineTaskResult) DeepCopyInto(out *PipelineTaskResult) {
 	*out = *in
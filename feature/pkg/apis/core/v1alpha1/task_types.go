@@ -35,6 +35,8 @@ const (
 	TaskPhaseFailed TaskPhase = "Failed"
 	// TaskPhaseIgnored of Task. once host run failed and set ignore_errors.
 	TaskPhaseIgnored TaskPhase = "Ignored"
+	// TaskPhaseCancelled of Task. the pipeline is cancelled when task running.
+	TaskPhaseCancelled TaskPhase = "Cancelled"
 )
 
 const (
@@ -48,6 +50,8 @@ type TaskSpec struct {
 	Hosts       []string `json:"hosts,omitempty"`
 	IgnoreError *bool    `json:"ignoreError,omitempty"`
 	Retries     int      `json:"retries,omitempty"`
+	// Timeout in seconds of task in each host. 0 means no timeout.
+	Timeout int `json:"timeout,omitempty"`
 
 	When       []string             `json:"when,omitempty"`
 	FailedWhen []string             `json:"failedWhen,omitempty"`
@@ -99,9 +103,9 @@ type TaskList struct {
 	Items           []Task `json:"items"`
 }
 
-// IsComplete if Task IsSucceed or IsFailed
+// IsComplete if Task IsSucceed, IsFailed or IsCancelled
 func (t Task) IsComplete() bool {
-	return t.IsSucceed() || t.IsFailed()
+	return t.IsSucceed() || t.IsFailed() || t.IsCancelled()
 }
 
 // IsSucceed if Task.Status.Phase TaskPhaseSuccess or TaskPhaseIgnored
@@ -114,6 +118,11 @@ func (t Task) IsFailed() bool {
 	return t.Status.Phase == TaskPhaseFailed && t.Spec.Retries <= t.Status.RestartCount
 }
 
+// IsCancelled if Task.Status.Phase is TaskPhaseCancelled
+func (t Task) IsCancelled() bool {
+	return t.Status.Phase == TaskPhaseCancelled
+}
+
 func init() {
 	SchemeBuilder.Register(&Task{}, &TaskList{})
 }
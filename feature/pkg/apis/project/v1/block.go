@@ -28,11 +28,33 @@ type Block struct {
 	BlockBase
 	// If it has Block, Task should be empty
 	Task
-	IncludeTasks string `yaml:"include_tasks,omitempty"`
+	BlockInclude
 
 	BlockInfo
 }
 
+// BlockInclude defined in project.
+// "include_tasks" and "include_role" are dynamic, which are evaluated in each host at execution time.
+// "import_tasks" and "import_role" are static, which are converted to block when load project.
+type BlockInclude struct {
+	IncludeTasks string   `yaml:"include_tasks,omitempty"`
+	ImportTasks  string   `yaml:"import_tasks,omitempty"`
+	IncludeRole  *RoleRef `yaml:"include_role,omitempty"`
+	ImportRole   *RoleRef `yaml:"import_role,omitempty"`
+}
+
+// RoleRef is the role which included or imported by block.
+type RoleRef struct {
+	Name string `yaml:"name"`
+	// TasksFrom is the file under tasks dir of role. default is main.
+	TasksFrom string `yaml:"tasks_from,omitempty"`
+}
+
+// IsInclude check if the block is dynamic include.
+func (b BlockInclude) IsInclude() bool {
+	return b.IncludeTasks != "" || b.IncludeRole != nil
+}
+
 // BlockBase defined in project.
 type BlockBase struct {
 	Base             `yaml:",inline"`
@@ -85,9 +107,14 @@ func (b *Block) UnmarshalYAML(unmarshal func(any) error) error {
 		return err
 	}
 
-	if includeTasks, ok := handleIncludeTasks(m); ok {
-		// Set the IncludeTasks field if "include_tasks" exists and is valid.
-		b.IncludeTasks = includeTasks
+	if m["include_tasks"] != nil || m["import_tasks"] != nil || m["include_role"] != nil || m["import_role"] != nil {
+		// include or import support loop and other task arguments.
+		bi, t, err := handleInclude(unmarshal)
+		if err != nil {
+			return err
+		}
+		b.BlockInclude = bi
+		b.Task = t
 
 		return nil
 	}
@@ -101,7 +128,7 @@ func (b *Block) UnmarshalYAML(unmarshal func(any) error) error {
 		}
 		b.BlockInfo = bi
 	default:
-		// If neither "include_tasks" nor "block" are present, treat the data as a task.
+		// If neither include nor "block" are present, treat the data as a task.
 		t, err := handleTask(m, unmarshal)
 		if err != nil {
 			return err
@@ -114,16 +141,22 @@ func (b *Block) UnmarshalYAML(unmarshal func(any) error) error {
 	return nil
 }
 
-// handleIncludeTasks checks if the "include_tasks" key exists in the map and is of type string.
-// If so, it returns the string value and true, otherwise it returns an empty string and false.
-func handleIncludeTasks(m map[string]any) (string, bool) {
-	if v, ok := m["include_tasks"]; ok {
-		if it, ok := v.(string); ok {
-			return it, true
-		}
+// handleInclude attempts to unmarshal the include data into a BlockInclude and Task structure.
+func handleInclude(unmarshal func(any) error) (BlockInclude, Task, error) {
+	var bi BlockInclude
+	if err := unmarshal(&bi); err != nil {
+		klog.Errorf("unmarshal data to include error: %v", err)
+
+		return bi, Task{}, err
+	}
+	var t Task
+	if err := unmarshal(&t); err != nil {
+		klog.Errorf("unmarshal data to task error: %v", err)
+
+		return bi, t, err
 	}
 
-	return "", false
+	return bi, t, nil
 }
 
 // handleBlock attempts to unmarshal the block data into a BlockInfo structure.
@@ -159,9 +192,7 @@ func deleteExistField(rt reflect.Type, m map[string]any) {
 		if field.Anonymous {
 			deleteExistField(field.Type, m)
 		} else {
-			if isFound := deleteField(rt.Field(i), m); isFound {
-				break
-			}
+			deleteField(rt.Field(i), m)
 		}
 	}
 }
@@ -41,7 +41,7 @@ Play
 |  15  |   gather_facts         |     ✔︎      |
 |  16  |   gather_subset        |     ✘      |
 |  17  |   gather_timeout       |     ✘      |
-|  18  |   handlers             |     ✘      |
+|  18  |   handlers             |     ✔︎      |
 |  19  |   hosts                |     ✔︎      |
 |  20  |   ignore_errors        |     ✔︎      |
 |  21  |   ignore_unreachable   |     ✘      |
@@ -61,7 +61,7 @@ Play
 |  35  |   tags                 |     ✔︎      |
 |  36  |   tasks                |     ✔︎      |
 |  37  |   throttle             |     ✘      |
-|  38  |   timeout              |     ✘      |
+|  38  |   timeout              |     ✔︎      |
 |  39  |   vars                 |     ✔︎      |
 |  40  |   vars_files           |     ✘      |
 |  41  |   vars_prompt          |     ✘      |
@@ -133,7 +133,7 @@ Block
 |  26  |   run_once             |     ✘      |
 |  27  |   tags                 |     ✔︎      |
 |  28  |   throttle             |     ✘      |
-|  29  |   timeout              |     ✘      |
+|  29  |   timeout              |     ✔︎      |
 |  30  |   vars                 |     ✔︎      |
 |  31  |   when                 |     ✔︎      |
 +------+------------------------+------------+
@@ -170,7 +170,7 @@ Task
 |  25  |   module_defaults      |     ✘      |
 |  26  |   name                 |     ✔︎      |
 |  27  |   no_log               |     ✘      |
-|  28  |   notify               |     ✘      |
+|  28  |   notify               |     ✔︎      |
 |  29  |   poll                 |     ✘      |
 |  30  |   port                 |     ✘      |
 |  31  |   register             |     ✔︎      |
@@ -179,7 +179,7 @@ Task
 |  34  |   run_once             |     ✘      |
 |  35  |   tags                 |     ✔︎      |
 |  36  |   throttle             |     ✘      |
-|  37  |   timeout              |     ✘      |
+|  37  |   timeout              |     ✔︎      |
 |  38  |   until                |     ✘      |
 |  39  |   vars                 |     ✔︎      |
 |  40  |   when                 |     ✔︎      |
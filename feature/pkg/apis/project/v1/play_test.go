@@ -179,6 +179,52 @@ func TestUnmarshalYaml(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "Unmarshal notify with single value",
+			data: []byte(`---
+- name: test play
+  hosts: localhost
+  tasks:
+    - name: test
+      custom-module: abc
+      notify: restart
+`),
+			excepted: []Play{
+				{
+					Base:     Base{Name: "test play"},
+					PlayHost: PlayHost{Hosts: []string{"localhost"}},
+					Tasks: []Block{
+						{
+							BlockBase: BlockBase{Base: Base{Name: "test"}, Notifiable: Notifiable{Notify: Notify{Data: []string{"restart"}}}},
+							Task:      Task{UnknownField: map[string]any{"custom-module": "abc"}},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Unmarshal notify with multiple value",
+			data: []byte(`---
+- name: test play
+  hosts: localhost
+  tasks:
+    - name: test
+      custom-module: abc
+      notify: ["restart", "reload"]
+`),
+			excepted: []Play{
+				{
+					Base:     Base{Name: "test play"},
+					PlayHost: PlayHost{Hosts: []string{"localhost"}},
+					Tasks: []Block{
+						{
+							BlockBase: BlockBase{Base: Base{Name: "test"}, Notifiable: Notifiable{Notify: Notify{Data: []string{"restart", "reload"}}}},
+							Task:      Task{UnknownField: map[string]any{"custom-module": "abc"}},
+						},
+					},
+				},
+			},
+		},
 		{
 			name: "Unmarshal multi level block",
 			data: []byte(`---
@@ -32,6 +32,8 @@ type RoleInfo struct {
 	Role string `yaml:"role,omitempty"`
 
 	Block []Block
+	// Handlers defined in handlers/main.yaml of role. they are merged to the handlers of play.
+	Handlers []Block `yaml:"-"`
 }
 
 // UnmarshalYAML yaml string to role.
@@ -52,3 +54,37 @@ func (r *Role) UnmarshalYAML(unmarshal func(any) error) error {
 
 	return nil
 }
+
+// RoleMeta defined in meta/main.yaml of role.
+type RoleMeta struct {
+	// Dependencies are the roles which should run before the role.
+	Dependencies []Role `yaml:"dependencies,omitempty"`
+	// AllowDuplicates allow the role run more than once in a play with the same vars.
+	AllowDuplicates bool `yaml:"allow_duplicates,omitempty"`
+}
+
+// RoleArgumentSpecs defined in meta/argument_specs.yaml of role.
+type RoleArgumentSpecs struct {
+	// ArgumentSpecs key is the entry point of role. only "main" is supported.
+	ArgumentSpecs map[string]RoleArgumentSpec `yaml:"argument_specs,omitempty"`
+}
+
+// RoleArgumentSpec is the arguments of an entry point.
+type RoleArgumentSpec struct {
+	ShortDescription string                        `yaml:"short_description,omitempty" json:"short_description,omitempty"`
+	Options          map[string]RoleArgumentOption `yaml:"options,omitempty" json:"options,omitempty"`
+}
+
+// RoleArgumentOption describes an argument of role.
+type RoleArgumentOption struct {
+	Description any  `yaml:"description,omitempty" json:"description,omitempty"`
+	Required    bool `yaml:"required,omitempty" json:"required,omitempty"`
+	// Type of argument. support: str, int, float, bool, list, dict, raw. default is str.
+	Type string `yaml:"type,omitempty" json:"type,omitempty"`
+	// Elements is the type of list elements when Type is list.
+	Elements string `yaml:"elements,omitempty" json:"elements,omitempty"`
+	Choices  []any  `yaml:"choices,omitempty" json:"choices,omitempty"`
+	Default  any    `yaml:"default,omitempty" json:"default,omitempty"`
+	// Options is the sub arguments when Type is dict, or Elements is dict.
+	Options map[string]RoleArgumentOption `yaml:"options,omitempty" json:"options,omitempty"`
+}
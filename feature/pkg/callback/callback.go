@@ -0,0 +1,174 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package callback
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	kkcorev1 "github.com/kubesphere/kubekey/v4/pkg/apis/core/v1"
+)
+
+// EventType of Event
+type EventType string
+
+const (
+	// EventPipelineStart is sent before the pipeline start to run.
+	EventPipelineStart EventType = "pipeline_start"
+	// EventPlayStart is sent before each play start to run.
+	EventPlayStart EventType = "play_start"
+	// EventTaskStart is sent before each task start to run.
+	EventTaskStart EventType = "task_start"
+	// EventHostResult is sent when a task finished in a host.
+	EventHostResult EventType = "host_result"
+	// EventPipelineEnd is sent after the pipeline finished.
+	EventPipelineEnd EventType = "pipeline_end"
+)
+
+// Status of task in a host.
+type Status string
+
+const (
+	// StatusSuccess task run success in host.
+	StatusSuccess Status = "success"
+	// StatusChanged task run success and changed the host.
+	StatusChanged Status = "changed"
+	// StatusSkip task is skipped in host.
+	StatusSkip Status = "skip"
+	// StatusIgnored task run failed in host, but the error is ignored.
+	StatusIgnored Status = "ignored"
+	// StatusFailed task run failed in host.
+	StatusFailed Status = "failed"
+)
+
+// Event of pipeline execution.
+type Event struct {
+	Type EventType `json:"type"`
+	Time time.Time `json:"time"`
+	// Pipeline is the namespace/name of pipeline.
+	Pipeline string `json:"pipeline"`
+	Play     string `json:"play,omitempty"`
+	Role     string `json:"role,omitempty"`
+	// TaskID is the name of task resource. which is unique in a pipeline.
+	TaskID string `json:"task_id,omitempty"`
+	Task   string `json:"task,omitempty"`
+	// Hosts are the hosts of play or task.
+	Hosts  []string `json:"hosts,omitempty"`
+	Host   string   `json:"host,omitempty"`
+	Status Status   `json:"status,omitempty"`
+	Stdout string   `json:"stdout,omitempty"`
+	Stderr string   `json:"stderr,omitempty"`
+	// Phase and TaskResult are the result of pipeline. only set in EventPipelineEnd.
+	Phase      kkcorev1.PipelinePhase       `json:"phase,omitempty"`
+	TaskResult *kkcorev1.PipelineTaskResult `json:"task_result,omitempty"`
+}
+
+// Callback receives the events of pipeline execution.
+type Callback interface {
+	// OnEvent is called for each event. events are delivered one by one, not concurrently.
+	OnEvent(event Event)
+	// Close flush and release the resource of callback.
+	Close() error
+}
+
+// New callbacks from specs. each spec is formatted as "name" or "name=path".
+// supported callbacks:
+//   - progress: print the progress bar of each host to out. it's the default callback.
+//   - summary: only print the failed tasks and a recap of hosts to out.
+//   - json: write events as json lines to the path. write to out if path is empty.
+//   - junit: write the task results as junit xml to the path.
+func New(specs []string, out io.Writer) (Callback, error) {
+	if len(specs) == 0 {
+		specs = []string{"progress"}
+	}
+	m := &multiCallback{}
+	for _, spec := range specs {
+		name, path, _ := strings.Cut(spec, "=")
+		var cb Callback
+		switch name {
+		case "progress":
+			cb = NewProgressCallback(out)
+		case "summary":
+			cb = NewSummaryCallback(out)
+		case "json":
+			if path == "" {
+				cb = NewJSONCallback(out)
+
+				break
+			}
+			f, err := os.Create(path)
+			if err != nil {
+				return nil, errors.Join(fmt.Errorf("create json callback file %q error: %w", path, err), m.Close())
+			}
+			cb = &jsonCallback{w: f, closer: f}
+		case "junit":
+			if path == "" {
+				return nil, errors.Join(errors.New("junit callback should set the path of report. format: junit=path"), m.Close())
+			}
+			cb = NewJUnitCallback(path)
+		default:
+			return nil, errors.Join(fmt.Errorf("unsupported callback %q", name), m.Close())
+		}
+		m.callbacks = append(m.callbacks, cb)
+	}
+
+	return m, nil
+}
+
+// Join callbacks to one. the event is delivered to each callback in order.
+func Join(callbacks ...Callback) Callback {
+	return &multiCallback{callbacks: callbacks}
+}
+
+// multiCallback deliver event to each callback in order.
+type multiCallback struct {
+	sync.Mutex
+	callbacks []Callback
+}
+
+// OnEvent implements Callback.
+func (m *multiCallback) OnEvent(event Event) {
+	m.Lock()
+	defer m.Unlock()
+
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	for _, cb := range m.callbacks {
+		cb.OnEvent(event)
+	}
+}
+
+// Close implements Callback.
+func (m *multiCallback) Close() error {
+	m.Lock()
+	defer m.Unlock()
+
+	var errs []error
+	for _, cb := range m.callbacks {
+		if err := cb.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package callback
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	kkcorev1 "github.com/kubesphere/kubekey/v4/pkg/apis/core/v1"
+)
+
+var testEvents = []Event{
+	{Type: EventPipelineStart, Pipeline: "default/test"},
+	{Type: EventPlayStart, Pipeline: "default/test", Play: "play1", Hosts: []string{"node1", "node2"}},
+	{Type: EventTaskStart, Pipeline: "default/test", Play: "play1", TaskID: "task-1", Task: "task1", Hosts: []string{"node1", "node2"}},
+	{Type: EventHostResult, Pipeline: "default/test", Play: "play1", TaskID: "task-1", Task: "task1", Host: "node1", Status: StatusChanged, Stdout: "changed"},
+	{Type: EventHostResult, Pipeline: "default/test", Play: "play1", TaskID: "task-1", Task: "task1", Host: "node2", Status: StatusFailed, Stderr: "error"},
+	{Type: EventPipelineEnd, Pipeline: "default/test", Phase: kkcorev1.PipelinePhaseFailed,
+		TaskResult: &kkcorev1.PipelineTaskResult{Total: 1, Failed: 1}},
+}
+
+func TestNew(t *testing.T) {
+	testcases := []struct {
+		name  string
+		specs []string
+		err   bool
+	}{
+		{
+			name: "default",
+		},
+		{
+			name:  "multi callbacks",
+			specs: []string{"summary", "json", "json=" + filepath.Join(t.TempDir(), "events.json"), "junit=" + filepath.Join(t.TempDir(), "junit.xml")},
+		},
+		{
+			name:  "junit without path",
+			specs: []string{"junit"},
+			err:   true,
+		},
+		{
+			name:  "unsupported callback",
+			specs: []string{"progress", "unknown"},
+			err:   true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			cb, err := New(tc.specs, &bytes.Buffer{})
+			if tc.err {
+				assert.Error(t, err)
+
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.NoError(t, cb.Close())
+		})
+	}
+}
+
+func TestJSONCallback(t *testing.T) {
+	buf := &bytes.Buffer{}
+	cb := NewJSONCallback(buf)
+	for _, e := range testEvents {
+		cb.OnEvent(e)
+	}
+	if err := cb.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, len(testEvents))
+	var event Event
+	if err := json.Unmarshal([]byte(lines[4]), &event); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, testEvents[4], event)
+}
+
+func TestJUnitCallback(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "junit.xml")
+	cb := NewJUnitCallback(path)
+	now := time.Now()
+	for i, e := range testEvents {
+		e.Time = now.Add(time.Duration(i) * time.Second)
+		cb.OnEvent(e)
+	}
+	if err := cb.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := string(data)
+	assert.Contains(t, report, `<testsuites name="default/test" tests="2" failures="1" skipped="0" time="5">`)
+	assert.Contains(t, report, `<testsuite name="play1" tests="2" failures="1" skipped="0" time="3"`)
+	assert.Contains(t, report, `<testcase name="task1" classname="node2" time="2">`)
+	assert.Contains(t, report, `<failure message="task failed">error</failure>`)
+}
+
+func TestSummaryCallback(t *testing.T) {
+	buf := &bytes.Buffer{}
+	cb := NewSummaryCallback(buf)
+	for _, e := range testEvents {
+		cb.OnEvent(e)
+	}
+	if err := cb.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Contains(t, buf.String(), "[node2] failed: task1: error")
+	assert.Contains(t, buf.String(), "[Pipeline default/test] Failed")
+	assert.Contains(t, buf.String(), "node1 : success=0 changed=1 skip=0 ignored=0 failed=0")
+	assert.Contains(t, buf.String(), "node2 : success=0 changed=0 skip=0 ignored=0 failed=1")
+	assert.NotContains(t, buf.String(), "task1\n")
+}
@@ -0,0 +1,51 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package callback
+
+import (
+	"encoding/json"
+	"io"
+
+	"k8s.io/klog/v2"
+)
+
+// NewJSONCallback write each event as a json line to w.
+func NewJSONCallback(w io.Writer) Callback {
+	return &jsonCallback{w: w}
+}
+
+type jsonCallback struct {
+	w io.Writer
+	// closer is set when the writer is opened by callback.
+	closer io.Closer
+}
+
+// OnEvent implements Callback.
+func (c *jsonCallback) OnEvent(event Event) {
+	if err := json.NewEncoder(c.w).Encode(event); err != nil {
+		klog.ErrorS(err, "write json event error", "type", event.Type)
+	}
+}
+
+// Close implements Callback.
+func (c *jsonCallback) Close() error {
+	if c.closer == nil {
+		return nil
+	}
+
+	return c.closer.Close()
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package callback
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"time"
+)
+
+// NewJUnitCallback write the task results in hosts as junit xml report to path when the callback closed.
+// each play is a testsuite, and each task in a host is a testcase.
+func NewJUnitCallback(path string) Callback {
+	return &junitCallback{path: path, started: make(map[string]time.Time)}
+}
+
+type junitCallback struct {
+	path   string
+	suites junitTestSuites
+	// start is the start time of pipeline.
+	start time.Time
+	// started is the start time of task. key is the TaskID.
+	started map[string]time.Time
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     float64          `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      float64         `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      float64       `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr,omitempty"`
+	Content string `xml:",chardata"`
+}
+
+// OnEvent implements Callback.
+func (c *junitCallback) OnEvent(event Event) {
+	switch event.Type {
+	case EventPipelineStart:
+		c.suites.Name = event.Pipeline
+		c.start = event.Time
+	case EventPlayStart:
+		c.suites.Suites = append(c.suites.Suites, junitTestSuite{
+			Name:      event.Play,
+			Timestamp: event.Time.Format(time.RFC3339),
+		})
+	case EventTaskStart:
+		c.started[event.TaskID] = event.Time
+	case EventHostResult:
+		if len(c.suites.Suites) == 0 {
+			c.suites.Suites = append(c.suites.Suites, junitTestSuite{Name: event.Play})
+		}
+		suite := &c.suites.Suites[len(c.suites.Suites)-1]
+		name := event.Task
+		if event.Role != "" {
+			name = fmt.Sprintf("[%s] %s", event.Role, event.Task)
+		}
+		tc := junitTestCase{
+			Name:      name,
+			Classname: event.Host,
+			Time:      event.Time.Sub(c.started[event.TaskID]).Seconds(),
+			SystemOut: event.Stdout,
+		}
+		switch event.Status {
+		case StatusFailed:
+			tc.Failure = &junitMessage{Message: "task failed", Content: event.Stderr}
+			suite.Failures++
+			c.suites.Failures++
+		case StatusSkip:
+			tc.Skipped = &junitMessage{}
+			suite.Skipped++
+			c.suites.Skipped++
+		case StatusIgnored:
+			tc.SystemOut = event.Stderr
+		}
+		suite.Cases = append(suite.Cases, tc)
+		suite.Tests++
+		suite.Time += tc.Time
+		c.suites.Tests++
+	case EventPipelineEnd:
+		c.suites.Time = event.Time.Sub(c.start).Seconds()
+	}
+}
+
+// Close implements Callback. write the report to file.
+func (c *junitCallback) Close() error {
+	data, err := xml.MarshalIndent(c.suites, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal junit report error: %w", err)
+	}
+	if err := os.WriteFile(c.path, append([]byte(xml.Header), data...), os.ModePerm); err != nil {
+		return fmt.Errorf("write junit report to %q error: %w", c.path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package callback
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/schollz/progressbar/v3"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+)
+
+// NewProgressCallback print a progress bar of each host to w when task running.
+func NewProgressCallback(w io.Writer) Callback {
+	return &progressCallback{w: w, bars: make(map[string]*progressbar.ProgressBar)}
+}
+
+type progressCallback struct {
+	w io.Writer
+	// bars of running task. key is TaskID/host.
+	bars map[string]*progressbar.ProgressBar
+	// hostMaxLen is used to align the host of bars.
+	hostMaxLen int
+}
+
+// OnEvent implements Callback.
+func (c *progressCallback) OnEvent(event Event) {
+	switch event.Type {
+	case EventPipelineStart:
+		fmt.Fprint(c.w, `
+
+ _   __      _          _   __           
+| | / /     | |        | | / /           
+| |/ / _   _| |__   ___| |/ /  ___ _   _ 
+|    \| | | | '_ \ / _ \    \ / _ \ | | |
+| |\  \ |_| | |_) |  __/ |\  \  __/ |_| |
+\_| \_/\__,_|_.__/ \___\_| \_/\___|\__, |
+                                    __/ |
+                                   |___/
+
+`)
+		fmt.Fprintf(c.w, "%s [Pipeline %s] start\n", event.Time.Format(time.TimeOnly+" MST"), event.Pipeline)
+	case EventPlayStart:
+		for _, h := range event.Hosts {
+			c.hostMaxLen = max(c.hostMaxLen, len(h))
+		}
+	case EventTaskStart:
+		var roleLog string
+		if event.Role != "" {
+			roleLog = "[" + event.Role + "] "
+		}
+		fmt.Fprintf(c.w, "%s %s%s\n", event.Time.Format(time.TimeOnly+" MST"), roleLog, event.Task)
+		for _, h := range event.Hosts {
+			c.bars[event.TaskID+"/"+h] = c.newBar(h)
+		}
+	case EventHostResult:
+		bar, ok := c.bars[event.TaskID+"/"+event.Host]
+		if !ok {
+			return
+		}
+		delete(c.bars, event.TaskID+"/"+event.Host)
+		color := "34"
+		switch event.Status {
+		case StatusFailed:
+			color = "31"
+		case StatusChanged:
+			color = "33"
+		}
+		bar.Describe(fmt.Sprintf("[\033[36m%s\033[0m]%s \033[%sm%-7s\033[0m", event.Host, c.placeholder(event.Host), color, event.Status))
+		if err := bar.Finish(); err != nil {
+			klog.ErrorS(err, "finish bar error")
+		}
+	case EventPipelineEnd:
+		var total, success, ignored, failed int
+		if event.TaskResult != nil {
+			total, success, ignored, failed = event.TaskResult.Total, event.TaskResult.Success, event.TaskResult.Ignored, event.TaskResult.Failed
+		}
+		fmt.Fprintf(c.w, "%s [Pipeline %s] finish. total: %v,success: %v,ignored: %v,failed: %v\n", event.Time.Format(time.TimeOnly+" MST"), event.Pipeline,
+			total, success, ignored, failed)
+	}
+}
+
+// newBar create a running progress bar for host.
+func (c *progressCallback) newBar(h string) *progressbar.ProgressBar {
+	bar := progressbar.NewOptions(-1,
+		progressbar.OptionSetWriter(c.w),
+		progressbar.OptionSpinnerCustom([]string{"            "}),
+		progressbar.OptionEnableColorCodes(true),
+		progressbar.OptionSetDescription(fmt.Sprintf("[\033[36m%s\033[0m]%s \033[36mrunning\033[0m", h, c.placeholder(h))),
+		progressbar.OptionOnCompletion(func() {
+			if _, err := io.WriteString(c.w, "\n"); err != nil {
+				klog.ErrorS(err, "failed to write output", "host", h)
+			}
+		}),
+	)
+	// run progress
+	go func() {
+		err := wait.PollUntilContextCancel(context.Background(), 100*time.Millisecond, true, func(context.Context) (bool, error) {
+			if bar.IsFinished() {
+				return true, nil
+			}
+			if err := bar.Add(1); err != nil {
+				return false, err
+			}
+
+			return false, nil
+		})
+		if err != nil {
+			klog.ErrorS(err, "failed to wait for task run to finish", "host", h)
+		}
+	}()
+
+	return bar
+}
+
+// placeholder format task log
+func (c *progressCallback) placeholder(h string) string {
+	return strings.Repeat(" ", max(c.hostMaxLen-len(h), 0))
+}
+
+// Close implements Callback. finish the bars which are still running.
+func (c *progressCallback) Close() error {
+	for key, bar := range c.bars {
+		if err := bar.Finish(); err != nil {
+			klog.ErrorS(err, "finish bar error")
+		}
+		delete(c.bars, key)
+	}
+
+	return nil
+}
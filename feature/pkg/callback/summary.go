@@ -0,0 +1,74 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package callback
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+// NewSummaryCallback only print the failed tasks and a recap of each host to w.
+func NewSummaryCallback(w io.Writer) Callback {
+	return &summaryCallback{w: w, recap: make(map[string]map[Status]int)}
+}
+
+type summaryCallback struct {
+	w io.Writer
+	// hosts which have result.
+	hosts []string
+	// recap is the count of each status. key is host.
+	recap map[string]map[Status]int
+}
+
+// OnEvent implements Callback.
+func (c *summaryCallback) OnEvent(event Event) {
+	switch event.Type {
+	case EventHostResult:
+		if _, ok := c.recap[event.Host]; !ok {
+			c.hosts = append(c.hosts, event.Host)
+			c.recap[event.Host] = make(map[Status]int)
+		}
+		c.recap[event.Host][event.Status]++
+		if event.Status == StatusFailed {
+			var roleLog string
+			if event.Role != "" {
+				roleLog = "[" + event.Role + "] "
+			}
+			fmt.Fprintf(c.w, "%s [%s] failed: %s%s: %s\n", event.Time.Format(time.TimeOnly+" MST"), event.Host, roleLog, event.Task, strings.TrimSpace(event.Stderr))
+		}
+	case EventPipelineEnd:
+		fmt.Fprintf(c.w, "%s [Pipeline %s] %s\n", event.Time.Format(time.TimeOnly+" MST"), event.Pipeline, event.Phase)
+		placeholder := 0
+		for _, h := range c.hosts {
+			placeholder = max(placeholder, len(h))
+		}
+		slices.Sort(c.hosts)
+		for _, h := range c.hosts {
+			r := c.recap[h]
+			fmt.Fprintf(c.w, "%-*s : success=%d changed=%d skip=%d ignored=%d failed=%d\n", placeholder, h,
+				r[StatusSuccess], r[StatusChanged], r[StatusSkip], r[StatusIgnored], r[StatusFailed])
+		}
+	}
+}
+
+// Close implements Callback.
+func (c *summaryCallback) Close() error {
+	return nil
+}
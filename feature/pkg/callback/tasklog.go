@@ -0,0 +1,312 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package callback
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	_const "github.com/kubesphere/kubekey/v4/pkg/const"
+)
+
+const (
+	// maxPipelineLogs is the max number of pipeline logs kept in a namespace. the oldest is removed when exceed.
+	maxPipelineLogs = 10
+	// maxRunLogs is the max number of run logs kept in a pipeline, a pipeline may run many times (e.g. cronJob).
+	maxRunLogs = 10
+	// runTimeFormat is the name of run log dir.
+	runTimeFormat = "20060102-150405"
+)
+
+// taskLogMeta is stored in the log dir of task. it's used to filter logs by task.
+type taskLogMeta struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Role string `json:"role,omitempty"`
+}
+
+// NewTaskLogCallback write the full output of each task in each host to dir, which is compressed by gzip.
+// the logs are stored as: dir/namespace/pipelineName/runTime/seq-taskID/host.log.gz
+func NewTaskLogCallback(dir string) Callback {
+	return &taskLogCallback{dir: dir, taskDirs: make(map[string]string)}
+}
+
+type taskLogCallback struct {
+	dir string
+	// runDir is the log dir of current run of pipeline.
+	runDir string
+	// taskDirs is the log dir of each task. key is TaskID.
+	taskDirs map[string]string
+}
+
+// OnEvent implements Callback.
+func (c *taskLogCallback) OnEvent(event Event) {
+	switch event.Type {
+	case EventPipelineStart:
+		runDir, err := createRunDir(filepath.Join(c.dir, event.Pipeline), event.Time)
+		if err != nil {
+			klog.ErrorS(err, "create pipeline log dir error", "pipeline", event.Pipeline)
+
+			return
+		}
+		c.runDir = runDir
+		c.taskDirs = make(map[string]string)
+		// the dir of current run and pipeline are the newest, they are never removed by rotation.
+		if err := rotatePipelineLogs(filepath.Dir(runDir), maxRunLogs); err != nil {
+			klog.ErrorS(err, "rotate run logs error", "pipeline", event.Pipeline)
+		}
+		if err := rotatePipelineLogs(filepath.Dir(filepath.Join(c.dir, event.Pipeline)), maxPipelineLogs); err != nil {
+			klog.ErrorS(err, "rotate pipeline logs error", "pipeline", event.Pipeline)
+		}
+	case EventTaskStart:
+		if _, ok := c.taskDirs[event.TaskID]; ok || c.runDir == "" {
+			// task retry
+			return
+		}
+		taskDir := filepath.Join(c.runDir, fmt.Sprintf("%04d-%s", len(c.taskDirs), event.TaskID))
+		c.taskDirs[event.TaskID] = taskDir
+		if err := writeTaskLogMeta(taskDir, taskLogMeta{ID: event.TaskID, Name: event.Task, Role: event.Role}); err != nil {
+			klog.ErrorS(err, "write task log metadata error", "task", event.TaskID)
+		}
+	case EventHostResult:
+		taskDir, ok := c.taskDirs[event.TaskID]
+		if !ok {
+			return
+		}
+		if err := appendHostLog(filepath.Join(taskDir, event.Host+".log.gz"), event); err != nil {
+			klog.ErrorS(err, "write task log error", "task", event.TaskID, "host", event.Host)
+		}
+	}
+}
+
+// Close implements Callback.
+func (c *taskLogCallback) Close() error {
+	return nil
+}
+
+// createRunDir creates the log dir of a run in pipelineDir, which is named by the start time of run.
+// the previous runs are kept.
+func createRunDir(pipelineDir string, start time.Time) (string, error) {
+	if start.IsZero() {
+		start = time.Now()
+	}
+	if err := os.MkdirAll(pipelineDir, os.ModePerm); err != nil {
+		return "", fmt.Errorf("create pipeline log dir %q error: %w", pipelineDir, err)
+	}
+	name := start.UTC().Format(runTimeFormat)
+	for i := 1; ; i++ {
+		runDir := filepath.Join(pipelineDir, name)
+		err := os.Mkdir(runDir, os.ModePerm)
+		if err == nil {
+			return runDir, nil
+		}
+		if !os.IsExist(err) {
+			return "", fmt.Errorf("create run log dir %q error: %w", runDir, err)
+		}
+		// the pipeline runs more than once in a second.
+		name = fmt.Sprintf("%s-%d", start.UTC().Format(runTimeFormat), i)
+	}
+}
+
+// rotatePipelineLogs remove the oldest pipeline logs in dir, only keep the newest keep pipeline logs.
+func rotatePipelineLogs(dir string, keep int) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return fmt.Errorf("read log dir %q error: %w", dir, err)
+	}
+	type pipelineLog struct {
+		name    string
+		modTime time.Time
+	}
+	var logs []pipelineLog
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return fmt.Errorf("get info of %q error: %w", entry.Name(), err)
+		}
+		logs = append(logs, pipelineLog{name: entry.Name(), modTime: info.ModTime()})
+	}
+	if len(logs) <= keep {
+		return nil
+	}
+	slices.SortFunc(logs, func(a, b pipelineLog) int {
+		return a.modTime.Compare(b.modTime)
+	})
+	for _, l := range logs[:len(logs)-keep] {
+		if err := os.RemoveAll(filepath.Join(dir, l.name)); err != nil {
+			return fmt.Errorf("remove pipeline log %q error: %w", l.name, err)
+		}
+	}
+
+	return nil
+}
+
+func writeTaskLogMeta(taskDir string, meta taskLogMeta) error {
+	if err := os.MkdirAll(taskDir, os.ModePerm); err != nil {
+		return fmt.Errorf("create task log dir %q error: %w", taskDir, err)
+	}
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return fmt.Errorf("marshal task log metadata error: %w", err)
+	}
+
+	return os.WriteFile(filepath.Join(taskDir, _const.LogTaskFile), data, os.ModePerm)
+}
+
+// appendHostLog append the result of task in host to file as a new gzip member.
+// task may retry, each retry is a new member in the file.
+func appendHostLog(file string, event Event) (err error) {
+	f, err := os.OpenFile(file, os.O_CREATE|os.O_APPEND|os.O_WRONLY, os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("open task log %q error: %w", file, err)
+	}
+	defer func() {
+		err = errors.Join(err, f.Close())
+	}()
+	gw := gzip.NewWriter(f)
+	fmt.Fprintf(gw, "==> %s status: %s\n", event.Time.Format(time.RFC3339), event.Status)
+	fmt.Fprintf(gw, "--- stdout\n%s\n", strings.TrimSuffix(event.Stdout, "\n"))
+	fmt.Fprintf(gw, "--- stderr\n%s\n", strings.TrimSuffix(event.Stderr, "\n"))
+
+	return gw.Close()
+}
+
+// ListRuns returns the runs of pipeline which have logs, from the oldest to the newest.
+func ListRuns(dir, namespace, pipeline string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(dir, namespace, pipeline))
+	if err != nil {
+		return nil, fmt.Errorf("read logs of pipeline %s/%s error: %w", namespace, pipeline, err)
+	}
+	var runs []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			runs = append(runs, entry.Name())
+		}
+	}
+	// the name of run is its start time, "-n" is appended when it runs more than once in a second.
+	slices.SortFunc(runs, func(a, b string) int {
+		if c := strings.Compare(a[:min(len(a), len(runTimeFormat))], b[:min(len(b), len(runTimeFormat))]); c != 0 {
+			return c
+		}
+		if len(a) != len(b) {
+			return len(a) - len(b)
+		}
+
+		return strings.Compare(a, b)
+	})
+
+	return runs, nil
+}
+
+// ReadTaskLogs write the task logs of a run of pipeline to w, in the order of task execution.
+// run is the latest run when it's empty. task and host are optional filters. task matches the name or id of task.
+func ReadTaskLogs(dir, namespace, pipeline, run, task, host string, w io.Writer) error {
+	if run == "" {
+		runs, err := ListRuns(dir, namespace, pipeline)
+		if err != nil {
+			return err
+		}
+		if len(runs) == 0 {
+			return fmt.Errorf("no logs found in pipeline %s/%s", namespace, pipeline)
+		}
+		run = runs[len(runs)-1]
+	}
+	pipelineDir := filepath.Join(dir, namespace, pipeline, run)
+	entries, err := os.ReadDir(pipelineDir)
+	if err != nil {
+		return fmt.Errorf("read logs of run %s in pipeline %s/%s error: %w", run, namespace, pipeline, err)
+	}
+	var found bool
+	// entries are sorted by name, which is prefixed by the sequence of task.
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		taskDir := filepath.Join(pipelineDir, entry.Name())
+		data, err := os.ReadFile(filepath.Join(taskDir, _const.LogTaskFile))
+		if err != nil {
+			return fmt.Errorf("read task log metadata of %q error: %w", entry.Name(), err)
+		}
+		var meta taskLogMeta
+		if err := json.Unmarshal(data, &meta); err != nil {
+			return fmt.Errorf("unmarshal task log metadata of %q error: %w", entry.Name(), err)
+		}
+		if task != "" && task != meta.Name && task != meta.ID {
+			continue
+		}
+		hostLogs, err := filepath.Glob(filepath.Join(taskDir, "*.log.gz"))
+		if err != nil {
+			return fmt.Errorf("find host logs of %q error: %w", entry.Name(), err)
+		}
+		for _, hostLog := range hostLogs {
+			h := strings.TrimSuffix(filepath.Base(hostLog), ".log.gz")
+			if host != "" && host != h {
+				continue
+			}
+			found = true
+			var roleLog string
+			if meta.Role != "" {
+				roleLog = "[" + meta.Role + "] "
+			}
+			fmt.Fprintf(w, "TASK %s%s | %s\n", roleLog, meta.Name, h)
+			if err := readHostLog(hostLog, w); err != nil {
+				return err
+			}
+		}
+	}
+	if !found {
+		return fmt.Errorf("no logs found in pipeline %s/%s", namespace, pipeline)
+	}
+
+	return nil
+}
+
+func readHostLog(file string, w io.Writer) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return fmt.Errorf("open task log %q error: %w", file, err)
+	}
+	defer f.Close()
+	// gzip reader read all the members in file by default.
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("read task log %q error: %w", file, err)
+	}
+	defer gr.Close()
+	if _, err := io.Copy(w, gr); err != nil {
+		return fmt.Errorf("read task log %q error: %w", file, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package callback
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaskLogCallback(t *testing.T) {
+	dir := t.TempDir()
+	cb := NewTaskLogCallback(dir)
+	for _, e := range testEvents {
+		cb.OnEvent(e)
+	}
+	// retry task1 in node2
+	cb.OnEvent(Event{Type: EventTaskStart, Pipeline: "default/test", TaskID: "task-1", Task: "task1", Hosts: []string{"node2"}})
+	cb.OnEvent(Event{Type: EventHostResult, Pipeline: "default/test", TaskID: "task-1", Task: "task1", Host: "node2", Status: StatusSuccess, Stdout: "retry"})
+	if err := cb.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	testcases := []struct {
+		name   string
+		task   string
+		host   string
+		except []string
+		err    bool
+	}{
+		{
+			name:   "all logs",
+			except: []string{"TASK task1 | node1", "changed", "TASK task1 | node2", "status: failed", "error", "status: success", "retry"},
+		},
+		{
+			name:   "filter by task id and host",
+			task:   "task-1",
+			host:   "node1",
+			except: []string{"TASK task1 | node1", "changed"},
+		},
+		{
+			name: "task not found",
+			task: "task2",
+			err:  true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			err := ReadTaskLogs(dir, "default", "test", "", tc.task, tc.host, buf)
+			if tc.err {
+				assert.Error(t, err)
+
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, s := range tc.except {
+				assert.Contains(t, buf.String(), s)
+			}
+			if tc.host != "" {
+				assert.NotContains(t, buf.String(), "| node2")
+			}
+		})
+	}
+}
+
+func TestTaskLogCallbackKeepRuns(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	for i, stdout := range []string{"first", "second", "third"} {
+		cb := NewTaskLogCallback(dir)
+		// the second and third run start in the same second.
+		cb.OnEvent(Event{Type: EventPipelineStart, Pipeline: "default/test", Time: start.Add(time.Duration(min(i, 1)) * time.Second)})
+		cb.OnEvent(Event{Type: EventTaskStart, Pipeline: "default/test", TaskID: "task-1", Task: "task1"})
+		cb.OnEvent(Event{Type: EventHostResult, Pipeline: "default/test", TaskID: "task-1", Task: "task1", Host: "node1", Status: StatusSuccess, Stdout: stdout})
+		if err := cb.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	runs, err := ListRuns(dir, "default", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"20240102-030405", "20240102-030406", "20240102-030406-1"}, runs)
+
+	buf := &bytes.Buffer{}
+	if err := ReadTaskLogs(dir, "default", "test", "", "", "", buf); err != nil {
+		t.Fatal(err)
+	}
+	assert.Contains(t, buf.String(), "third")
+	assert.NotContains(t, buf.String(), "first")
+
+	buf.Reset()
+	if err := ReadTaskLogs(dir, "default", "test", "20240102-030405", "", "", buf); err != nil {
+		t.Fatal(err)
+	}
+	assert.Contains(t, buf.String(), "first")
+}
+
+func TestRotatePipelineLogs(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	for i, name := range []string{"p1", "p2", "p3"} {
+		if err := os.MkdirAll(filepath.Join(dir, name), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		modTime := now.Add(time.Duration(i) * time.Minute)
+		if err := os.Chtimes(filepath.Join(dir, name), modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := rotatePipelineLogs(dir, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	assert.Equal(t, []string{"p2", "p3"}, names)
+}
@@ -170,7 +170,7 @@ func (c *sshConnector) FetchFile(_ context.Context, src string, dst io.Writer) e
 }
 
 // ExecuteCommand in remote host
-func (c *sshConnector) ExecuteCommand(_ context.Context, cmd string) ([]byte, error) {
+func (c *sshConnector) ExecuteCommand(ctx context.Context, cmd string) ([]byte, error) {
 	klog.V(5).InfoS("exec ssh command", "cmd", cmd, "host", c.Host)
 	// create ssh session
 	session, err := c.client.NewSession()
@@ -181,7 +181,26 @@ func (c *sshConnector) ExecuteCommand(_ context.Context, cmd string) ([]byte, er
 	}
 	defer session.Close()
 
-	return session.CombinedOutput(cmd)
+	type result struct {
+		output []byte
+		err    error
+	}
+	done := make(chan result, 1)
+	go func() {
+		output, err := session.CombinedOutput(cmd)
+		done <- result{output: output, err: err}
+	}()
+	select {
+	case r := <-done:
+		return r.output, r.err
+	case <-ctx.Done():
+		// kill the remote command when ctx is cancelled or timeout.
+		if err := session.Signal(ssh.SIGKILL); err != nil {
+			klog.V(4).ErrorS(err, "Failed to kill remote command", "cmd", cmd, "host", c.Host)
+		}
+
+		return nil, ctx.Err()
+	}
 }
 
 // HostInfo for GatherFacts
@@ -47,3 +47,8 @@ func RuntimeDirFromPipeline(obj kkcorev1.Pipeline) string {
 	return filepath.Join(GetRuntimeDir(), kkcorev1.SchemeGroupVersion.String(),
 		RuntimePipelineDir, obj.Namespace, obj.Name)
 }
+
+// GetLogDir returns the absolute path of the task log directory.
+func GetLogDir() string {
+	return filepath.Join(workDir, LogDir)
+}
@@ -21,17 +21,31 @@ workdir/
 |-- projects/
 |   |-- ansible-project1/
 |   |   |-- playbooks/
+|   |   |-- schema/
+|   |   |   |-- config.json
 |   |   |-- roles/
 |   |   |   |-- roleName/
 |   |   |   |   |-- tasks/
 |   |   |   |   |   |-- main.yml
 |   |   |   |   |-- defaults/
 |   |   |   |   |   |-- main.yml
+|   |   |   |   |-- vars/
+|   |   |   |   |   |-- main.yml
+|   |   |   |   |-- handlers/
+|   |   |   |   |   |-- main.yml
+|   |   |   |   |-- meta/
+|   |   |   |   |   |-- main.yml
+|   |   |   |   |   |-- argument_specs.yml
 |   |   |   |   |-- templates/
 |   |   |   |   |-- files/
 |   |
 |   |-- ansible-project2/
 |   |-- ...
+|   |-- sha256/
+|   |   |-- digest/
+|   |   |   |-- archive.tgz
+|   |   |   |-- archive.tgz.asc
+|   |   |   |-- project/
 |
 |-- runtime/
 |-- group/version/
@@ -51,9 +65,20 @@ workdir/
 |   |   |   |-- namespace/
 |   |   |   |   |-- inventory.yaml
 |
+|-- logs/
+|   |-- namespace/
+|   |   |-- pipelineName/
+|   |   |   |-- runTime/
+|   |   |   |   |-- seq-taskName/
+|   |   |   |   |   |-- task.json
+|   |   |   |   |   |-- hostname.log.gz
+|
 |-- kubekey/
 |-- artifact-path...
 |-- images
+|-- cache/
+|   |-- sha256/
+|   |   |-- digest
 |
 |-- kubernetes/
 
@@ -70,6 +95,12 @@ const ProjectDir = "projects"
 // ProjectPlaybooksDir is a fixed directory name under ansible-project. used to store executable playbook files.
 const ProjectPlaybooksDir = "playbooks"
 
+// ProjectSchemaDir is a fixed directory name under ansible-project. used to store json schema files.
+const ProjectSchemaDir = "schema"
+
+// ProjectSchemaConfigFile is a fixed file under schema. the spec of Config should match it when the file exists.
+const ProjectSchemaConfigFile = "config.json"
+
 // ProjectRolesDir is a fixed directory name under ansible-project. used to store roles which playbook need.
 const ProjectRolesDir = "roles"
 
@@ -87,6 +118,27 @@ const ProjectRolesDefaultsDir = "defaults"
 // ProjectRolesDefaultsMainFile is a fixed file under defaults. support *.yaml or *yml
 const ProjectRolesDefaultsMainFile = "main"
 
+// ProjectRolesVarsDir is a fixed directory name under roleName. it set variables to role, which override defaults.
+const ProjectRolesVarsDir = "vars"
+
+// ProjectRolesVarsMainFile is a fixed file under vars. support *.yaml or *yml
+const ProjectRolesVarsMainFile = "main"
+
+// ProjectRolesHandlersDir is a fixed directory name under roleName. used to store handlers which merged to play.
+const ProjectRolesHandlersDir = "handlers"
+
+// ProjectRolesHandlersMainFile is a fixed file under handlers. support *.yaml or *yml
+const ProjectRolesHandlersMainFile = "main"
+
+// ProjectRolesMetaDir is a fixed directory name under roleName. used to store metadata of role.
+const ProjectRolesMetaDir = "meta"
+
+// ProjectRolesMetaMainFile is a fixed file under meta. it defines dependencies of role. support *.yaml or *yml
+const ProjectRolesMetaMainFile = "main"
+
+// ProjectRolesMetaArgumentSpecsFile is a fixed file under meta. it defines arguments of role. support *.yaml or *yml
+const ProjectRolesMetaArgumentSpecsFile = "argument_specs"
+
 // ProjectRolesTemplateDir is a fixed directory name under roleName. used to store template which task need.
 const ProjectRolesTemplateDir = "templates"
 
@@ -119,6 +171,17 @@ const RuntimePipelineVariableDir = "variable"
 
 // inventory.yaml is the data of Inventory resource
 
+// LogDir is a fixed directory name under workdir, used to store the output of each task in each host.
+// it's not cleaned after the pipeline success.
+const LogDir = "logs"
+
+// LogTaskFile is the metadata of task in the log dir of task.
+const LogTaskFile = "task.json"
+
+// CacheDir is a fixed directory name under workdir, used to store downloaded files, which is named by
+// the algorithm and digest of the checksum. it's not in ArtifactDir, so it's not exported with the artifact.
+const CacheDir = "cache"
+
 // ArtifactDir is the default directory name under the working directory. It is used to store
 // files required when executing the kubekey command (such as: docker, etcd, image packages, etc.).
 // These files will be downloaded locally and distributed to remote nodes.
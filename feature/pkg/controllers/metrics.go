@@ -0,0 +1,250 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	kkcorev1 "github.com/kubesphere/kubekey/v4/pkg/apis/core/v1"
+)
+
+const (
+	metricsNamespace = "kubekey"
+	// observedPhaseAnnotation is the phase of pipeline which events and metrics have been recorded for.
+	observedPhaseAnnotation = "kubekey.kubesphere.io/observed-phase"
+)
+
+var (
+	pipelineDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "pipeline_duration_seconds",
+		Help:      "Duration of finished pipelines in seconds.",
+		Buckets:   prometheus.ExponentialBuckets(10, 2, 12),
+	}, []string{"phase"})
+
+	taskDuration = newTaskDurationCollector()
+
+	hostFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "host_failures_total",
+		Help:      "Total number of failed tasks per host.",
+	}, []string{"host"})
+
+	jobCreationLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "job_creation_latency_seconds",
+		Help:      "Latency between pipeline creation and its job (or cronJob) creation in seconds.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"kind"})
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(pipelineDuration, taskDuration, hostFailures, jobCreationLatency)
+}
+
+// taskDurationCollector reports the histogram of the duration of tasks in finished pipelines.
+// each task is observed by the executor when it finishes, and stored in the status of pipeline.
+// the histogram of pipeline is added when the pipeline finishes.
+type taskDurationCollector struct {
+	sync.Mutex
+	desc       *prometheus.Desc
+	histograms map[string]*kkcorev1.PipelineTaskDurationHistogram
+}
+
+func newTaskDurationCollector() *taskDurationCollector {
+	return &taskDurationCollector{
+		desc: prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "task_duration_seconds"),
+			"Duration of the tasks in finished pipelines in seconds.", []string{"phase"}, nil),
+		histograms: make(map[string]*kkcorev1.PipelineTaskDurationHistogram),
+	}
+}
+
+// add the task duration histogram of pipeline.
+func (c *taskDurationCollector) add(histograms map[string]kkcorev1.PipelineTaskDurationHistogram) {
+	c.Lock()
+	defer c.Unlock()
+
+	for phase, h := range histograms {
+		if len(h.Buckets) != len(kkcorev1.TaskDurationBuckets) {
+			// recorded by an executor with different buckets.
+			continue
+		}
+		total, ok := c.histograms[phase]
+		if !ok {
+			total = &kkcorev1.PipelineTaskDurationHistogram{Buckets: make([]int64, len(kkcorev1.TaskDurationBuckets))}
+			c.histograms[phase] = total
+		}
+		total.Count += h.Count
+		total.Sum.Duration += h.Sum.Duration
+		for i := range h.Buckets {
+			total.Buckets[i] += h.Buckets[i]
+		}
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *taskDurationCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+// Collect implements prometheus.Collector.
+func (c *taskDurationCollector) Collect(ch chan<- prometheus.Metric) {
+	c.Lock()
+	defer c.Unlock()
+
+	for phase, h := range c.histograms {
+		buckets := make(map[float64]uint64, len(h.Buckets))
+		for i, bound := range kkcorev1.TaskDurationBuckets {
+			buckets[bound] = uint64(h.Buckets[i])
+		}
+		ch <- prometheus.MustNewConstHistogram(c.desc, uint64(h.Count), h.Sum.Seconds(), buckets, phase)
+	}
+}
+
+// pipelineCollector reports the number of pipelines in each phase. pipelines are listed at scrape time.
+type pipelineCollector struct {
+	ctrlclient.Client
+	desc *prometheus.Desc
+}
+
+func newPipelineCollector(client ctrlclient.Client) *pipelineCollector {
+	return &pipelineCollector{
+		Client: client,
+		desc: prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "pipelines"),
+			"Number of pipelines by phase.", []string{"phase"}, nil),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *pipelineCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+// Collect implements prometheus.Collector.
+func (c *pipelineCollector) Collect(ch chan<- prometheus.Metric) {
+	pipelines := &kkcorev1.PipelineList{}
+	if err := c.Client.List(context.Background(), pipelines); err != nil {
+		klog.V(5).ErrorS(err, "list pipeline error")
+
+		return
+	}
+
+	counts := map[kkcorev1.PipelinePhase]float64{
+		kkcorev1.PipelinePhasePending:   0,
+		kkcorev1.PipelinePhaseRunning:   0,
+		kkcorev1.PipelinePhaseFailed:    0,
+		kkcorev1.PipelinePhaseSucceed:   0,
+		kkcorev1.PipelinePhaseCancelled: 0,
+	}
+	for _, p := range pipelines.Items {
+		counts[p.Status.Phase]++
+	}
+	for phase, count := range counts {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, count, string(phase))
+	}
+}
+
+// registerPipelineCollector registers pipelineCollector once. it's safe to call it more than once.
+func registerPipelineCollector(client ctrlclient.Client) error {
+	if err := ctrlmetrics.Registry.Register(newPipelineCollector(client)); err != nil {
+		if are := (prometheus.AlreadyRegisteredError{}); errors.As(err, &are) {
+			return nil
+		}
+
+		return fmt.Errorf("register pipeline metrics error: %w", err)
+	}
+
+	return nil
+}
+
+// observeJobCreation records the latency between pipeline creation and its job creation.
+func observeJobCreation(pipeline *kkcorev1.Pipeline, kind string) {
+	jobCreationLatency.WithLabelValues(kind).Observe(time.Since(pipeline.CreationTimestamp.Time).Seconds())
+}
+
+// recordPhaseTransition emits events and records metrics when the phase of pipeline changes.
+// the last recorded phase is stored in the annotation of pipeline, so each transition is recorded once
+// even if the controller restarts or the phase changes more than once between two reconciles.
+func (r *PipelineReconciler) recordPhaseTransition(ctx context.Context, pipeline *kkcorev1.Pipeline) error {
+	oldPhase := kkcorev1.PipelinePhase(pipeline.Annotations[observedPhaseAnnotation])
+	if oldPhase == pipeline.Status.Phase {
+		return nil
+	}
+
+	eventType := corev1.EventTypeNormal
+	if pipeline.Status.Phase == kkcorev1.PipelinePhaseFailed {
+		eventType = corev1.EventTypeWarning
+	}
+	if r.EventRecorder != nil {
+		r.EventRecorder.Eventf(pipeline, eventType, "PhaseChanged", "pipeline phase changed from %q to %q. %s",
+			oldPhase, pipeline.Status.Phase, pipeline.Status.Reason)
+	}
+
+	switch pipeline.Status.Phase {
+	case kkcorev1.PipelinePhaseFailed:
+		for _, detail := range pipeline.Status.FailedDetail {
+			hosts := make([]string, 0, len(detail.Hosts))
+			for _, h := range detail.Hosts {
+				hosts = append(hosts, h.Host)
+				hostFailures.WithLabelValues(h.Host).Inc()
+			}
+			if r.EventRecorder != nil {
+				r.EventRecorder.Eventf(pipeline, corev1.EventTypeWarning, "TaskFailed", "task %q failed on hosts: %s",
+					detail.Task, strings.Join(hosts, ","))
+			}
+		}
+		observePipelineDuration(pipeline)
+	case kkcorev1.PipelinePhaseSucceed, kkcorev1.PipelinePhaseCancelled:
+		observePipelineDuration(pipeline)
+	}
+
+	excepted := pipeline.DeepCopy()
+	metav1.SetMetaDataAnnotation(&pipeline.ObjectMeta, observedPhaseAnnotation, string(pipeline.Status.Phase))
+	if err := r.Client.Patch(ctx, pipeline, ctrlclient.MergeFrom(excepted)); err != nil {
+		klog.V(5).ErrorS(err, "record observed phase error", "pipeline", ctrlclient.ObjectKeyFromObject(pipeline))
+
+		return err
+	}
+
+	return nil
+}
+
+// observePipelineDuration records the duration of finished pipeline and its tasks.
+func observePipelineDuration(pipeline *kkcorev1.Pipeline) {
+	start, end := pipeline.CreationTimestamp.Time, time.Now()
+	if pipeline.Status.StartTimestamp != nil {
+		start = pipeline.Status.StartTimestamp.Time
+	}
+	if pipeline.Status.CompletionTimestamp != nil {
+		end = pipeline.Status.CompletionTimestamp.Time
+	}
+	pipelineDuration.WithLabelValues(string(pipeline.Status.Phase)).Observe(end.Sub(start).Seconds())
+
+	taskDuration.add(pipeline.Status.TaskDurationHistogram)
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kkcorev1 "github.com/kubesphere/kubekey/v4/pkg/apis/core/v1"
+	_const "github.com/kubesphere/kubekey/v4/pkg/const"
+)
+
+func TestRecordPhaseTransition(t *testing.T) {
+	start := metav1.NewTime(time.Now().Add(-time.Minute))
+	testcases := []struct {
+		name        string
+		oldPhase    kkcorev1.PipelinePhase
+		newStatus   kkcorev1.PipelineStatus
+		eventCount  int
+		hostFailure float64
+	}{
+		{
+			name:       "phase not changed",
+			oldPhase:   kkcorev1.PipelinePhaseRunning,
+			newStatus:  kkcorev1.PipelineStatus{Phase: kkcorev1.PipelinePhaseRunning},
+			eventCount: 0,
+		},
+		{
+			name:     "succeed",
+			oldPhase: kkcorev1.PipelinePhaseRunning,
+			newStatus: kkcorev1.PipelineStatus{
+				Phase:               kkcorev1.PipelinePhaseSucceed,
+				StartTimestamp:      &start,
+				CompletionTimestamp: ptr.To(metav1.Now()),
+			},
+			eventCount: 1,
+		},
+		{
+			name:     "failed",
+			oldPhase: kkcorev1.PipelinePhaseRunning,
+			newStatus: kkcorev1.PipelineStatus{
+				Phase: kkcorev1.PipelinePhaseFailed,
+				FailedDetail: []kkcorev1.PipelineFailedDetail{
+					{Task: "install", Hosts: []kkcorev1.PipelineFailedDetailHost{{Host: "node1"}}},
+				},
+				TaskDurations: []kkcorev1.PipelineTaskDuration{
+					{Task: "install", Phase: "Failed", Duration: metav1.Duration{Duration: time.Second}},
+				},
+			},
+			eventCount:  2,
+			hostFailure: 1,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			hostFailures.Reset()
+			pipeline := &kkcorev1.Pipeline{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default",
+					Annotations: map[string]string{observedPhaseAnnotation: string(tc.oldPhase)}},
+				Status: tc.newStatus,
+			}
+			client := fake.NewClientBuilder().WithScheme(_const.Scheme).WithObjects(pipeline.DeepCopy()).Build()
+			recorder := record.NewFakeRecorder(10)
+			r := &PipelineReconciler{Client: client, EventRecorder: recorder}
+
+			if err := r.recordPhaseTransition(context.TODO(), pipeline); err != nil {
+				t.Fatal(err)
+			}
+			assert.Len(t, recorder.Events, tc.eventCount)
+			assert.InDelta(t, tc.hostFailure, testutil.ToFloat64(hostFailures.WithLabelValues("node1")), 0)
+
+			// the transition is recorded once.
+			stored := &kkcorev1.Pipeline{}
+			if err := client.Get(context.TODO(), ctrlclient.ObjectKeyFromObject(pipeline), stored); err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, string(tc.newStatus.Phase), stored.Annotations[observedPhaseAnnotation])
+			if err := r.recordPhaseTransition(context.TODO(), stored); err != nil {
+				t.Fatal(err)
+			}
+			assert.Len(t, recorder.Events, tc.eventCount)
+		})
+	}
+}
+
+func TestTaskDurationCollector(t *testing.T) {
+	// more tasks than status.taskDurations keeps, all of them are counted.
+	histogram := kkcorev1.PipelineTaskDurationHistogram{}
+	for i := range 15 {
+		histogram.Observe(time.Duration(i) * time.Second)
+	}
+	c := newTaskDurationCollector()
+	c.add(map[string]kkcorev1.PipelineTaskDurationHistogram{"Success": histogram})
+	c.add(map[string]kkcorev1.PipelineTaskDurationHistogram{"Success": histogram})
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(c)
+	mfs, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, mfs, 1) && assert.Len(t, mfs[0].GetMetric(), 1) {
+		h := mfs[0].GetMetric()[0].GetHistogram()
+		assert.Equal(t, uint64(30), h.GetSampleCount())
+		assert.InDelta(t, 210, h.GetSampleSum(), 0)
+		// 0s and 1s are not greater than 1s.
+		assert.Equal(t, uint64(4), h.GetBucket()[1].GetCumulativeCount())
+	}
+}
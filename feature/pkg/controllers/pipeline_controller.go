@@ -18,7 +18,10 @@ package controllers
 
 import (
 	"context"
+	"fmt"
 	"os"
+	"slices"
+	"strings"
 
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -34,8 +37,11 @@ import (
 	ctrlcontroller "sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	ctrlfinalizer "sigs.k8s.io/controller-runtime/pkg/finalizer"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	kkcorev1 "github.com/kubesphere/kubekey/v4/pkg/apis/core/v1"
+	_const "github.com/kubesphere/kubekey/v4/pkg/const"
 )
 
 const (
@@ -44,6 +50,11 @@ const (
 	defaultExecutorImage  = "hub.kubesphere.com.cn/kubekey/executor:latest"
 	defaultPullPolicy     = "IfNotPresent"
 	defaultServiceAccount = "kk-executor"
+	// executorLogDir is the log dir of executor, the work dir of "kk pipeline" is /kubekey by default.
+	executorLogDir = "/kubekey/" + _const.LogDir
+	logVolumeName  = "kubekey-logs"
+	// pipelineFinalizer stops the jobs of pipeline and removes its runtime directory before the pipeline is deleted.
+	pipelineFinalizer = "kubekey.kubesphere.io/pipeline-cleanup"
 )
 
 // PipelineReconciler reconcile pipeline
@@ -73,7 +84,25 @@ func (r PipelineReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 	if pipeline.DeletionTimestamp != nil {
 		klog.V(5).InfoS("pipeline is deleting", "pipeline", ctrlclient.ObjectKeyFromObject(pipeline))
 
-		return ctrl.Result{}, nil
+		return r.dealDeletePipeline(ctx, pipeline)
+	}
+
+	if !controllerutil.ContainsFinalizer(pipeline, pipelineFinalizer) {
+		excepted := pipeline.DeepCopy()
+		controllerutil.AddFinalizer(pipeline, pipelineFinalizer)
+		if err := r.Client.Patch(ctx, pipeline, ctrlclient.MergeFrom(excepted)); err != nil {
+			klog.V(5).ErrorS(err, "add finalizer error", "pipeline", ctrlclient.ObjectKeyFromObject(pipeline))
+
+			return ctrl.Result{}, err
+		}
+	}
+
+	if err := r.recordPhaseTransition(ctx, pipeline); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if pipeline.Spec.Cancel {
+		return r.dealCancelPipeline(ctx, pipeline)
 	}
 
 	switch pipeline.Status.Phase {
@@ -87,6 +116,26 @@ func (r PipelineReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		}
 	case kkcorev1.PipelinePhasePending:
 		excepted := pipeline.DeepCopy()
+		// wait for the pipelines which depends on.
+		ready, reason, err := r.checkDependencies(ctx, pipeline)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		switch {
+		case reason != "":
+			pipeline.Status.Phase = kkcorev1.PipelinePhaseFailed
+			pipeline.Status.Reason = reason
+			if err := r.Client.Status().Patch(ctx, pipeline, ctrlclient.MergeFrom(excepted)); err != nil {
+				klog.V(5).ErrorS(err, "update pipeline error", "pipeline", ctrlclient.ObjectKeyFromObject(pipeline))
+
+				return ctrl.Result{}, err
+			}
+
+			return ctrl.Result{}, nil
+		case !ready:
+			// reconcile again when the dependencies change.
+			return ctrl.Result{}, nil
+		}
 		pipeline.Status.Phase = kkcorev1.PipelinePhaseRunning
 		if err := r.Client.Status().Patch(ctx, pipeline, ctrlclient.MergeFrom(excepted)); err != nil {
 			klog.V(5).ErrorS(err, "update pipeline error", "pipeline", ctrlclient.ObjectKeyFromObject(pipeline))
@@ -100,6 +149,192 @@ func (r PipelineReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		// do nothing
 	case kkcorev1.PipelinePhaseSucceed:
 		// do nothing
+	case kkcorev1.PipelinePhaseCancelled:
+		// do nothing
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// checkDependencies check the phase of pipelines in spec.dependsOn. it's ready when all of them are Succeed.
+// the reason is not empty when the pipeline should be Failed: any of them is Failed or Cancelled, or the dependencies are cyclic.
+func (r *PipelineReconciler) checkDependencies(ctx context.Context, pipeline *kkcorev1.Pipeline) (bool, string, error) {
+	if len(pipeline.Spec.DependsOn) == 0 {
+		return true, "", nil
+	}
+	if cycle, err := r.dependencyCycle(ctx, pipeline); err != nil {
+		return false, "", err
+	} else if len(cycle) != 0 {
+		return false, fmt.Sprintf("cyclic dependency: %s", strings.Join(cycle, " -> ")), nil
+	}
+
+	ready := true
+	for _, name := range pipeline.Spec.DependsOn {
+		dep := &kkcorev1.Pipeline{}
+		if err := r.Client.Get(ctx, ctrlclient.ObjectKey{Namespace: pipeline.Namespace, Name: name}, dep); err != nil {
+			if apierrors.IsNotFound(err) {
+				klog.V(5).InfoS("dependent pipeline not found", "pipeline", ctrlclient.ObjectKeyFromObject(pipeline), "dependency", name)
+				ready = false
+
+				continue
+			}
+
+			return false, "", err
+		}
+		switch dep.Status.Phase {
+		case kkcorev1.PipelinePhaseSucceed:
+		case kkcorev1.PipelinePhaseFailed, kkcorev1.PipelinePhaseCancelled:
+			return false, fmt.Sprintf("dependent pipeline %q is %s", name, dep.Status.Phase), nil
+		default:
+			ready = false
+		}
+	}
+
+	return ready, "", nil
+}
+
+// dependencyCycle returns the cyclic path of pipeline names start from pipeline. return nil if there is no cycle.
+func (r *PipelineReconciler) dependencyCycle(ctx context.Context, pipeline *kkcorev1.Pipeline) ([]string, error) {
+	visited := make(map[string]bool)
+	var visit func(name string, dependsOn []string, path []string) ([]string, error)
+	visit = func(name string, dependsOn []string, path []string) ([]string, error) {
+		path = append(path, name)
+		for _, depName := range dependsOn {
+			if depName == pipeline.Name {
+				return append(path, depName), nil
+			}
+			if visited[depName] {
+				continue
+			}
+			visited[depName] = true
+			dep := &kkcorev1.Pipeline{}
+			if err := r.Client.Get(ctx, ctrlclient.ObjectKey{Namespace: pipeline.Namespace, Name: depName}, dep); err != nil {
+				if apierrors.IsNotFound(err) {
+					continue
+				}
+
+				return nil, err
+			}
+			if cycle, err := visit(depName, dep.Spec.DependsOn, path); err != nil || cycle != nil {
+				return cycle, err
+			}
+		}
+
+		return nil, nil
+	}
+
+	return visit(pipeline.Name, pipeline.Spec.DependsOn, nil)
+}
+
+// dependentPipelines returns the pending pipelines which depend on obj.
+func (r *PipelineReconciler) dependentPipelines(ctx context.Context, obj ctrlclient.Object) []reconcile.Request {
+	pipelines := &kkcorev1.PipelineList{}
+	if err := r.Client.List(ctx, pipelines, ctrlclient.InNamespace(obj.GetNamespace())); err != nil {
+		klog.V(5).ErrorS(err, "list pipeline error", "namespace", obj.GetNamespace())
+
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, p := range pipelines.Items {
+		if p.Status.Phase == kkcorev1.PipelinePhasePending && slices.Contains(p.Spec.DependsOn, obj.GetName()) {
+			requests = append(requests, reconcile.Request{NamespacedName: ctrlclient.ObjectKeyFromObject(&p)})
+		}
+	}
+
+	return requests
+}
+
+// dealCancelPipeline when spec.cancel is set. the running job is cancelled by executor itself, which checks spec.cancel periodically.
+// the pipeline which has not run or run by cronJob is cancelled here.
+func (r *PipelineReconciler) dealCancelPipeline(ctx context.Context, pipeline *kkcorev1.Pipeline) (ctrl.Result, error) {
+	switch pipeline.Status.Phase {
+	case "", kkcorev1.PipelinePhasePending:
+		// job has not been created.
+	case kkcorev1.PipelinePhaseRunning:
+		if pipeline.Spec.JobSpec.Schedule == "" {
+			jobs := &batchv1.JobList{}
+			if err := r.Client.List(ctx, jobs, ctrlclient.InNamespace(pipeline.Namespace), ctrlclient.MatchingLabels{
+				jobLabel: pipeline.Name,
+			}); err != nil {
+				return ctrl.Result{}, err
+			}
+			if len(jobs.Items) != 0 {
+				// job is running. executor will cancel it.
+				return ctrl.Result{}, nil
+			}
+
+			break
+		}
+		// suspend cronJob. no more job will be created.
+		jobs := &batchv1.CronJobList{}
+		if err := r.Client.List(ctx, jobs, ctrlclient.InNamespace(pipeline.Namespace), ctrlclient.MatchingLabels{
+			jobLabel: pipeline.Name,
+		}); err != nil {
+			return ctrl.Result{}, err
+		}
+		for _, job := range jobs.Items {
+			cp := job.DeepCopy()
+			job.Spec.Suspend = ptr.To(true)
+			if err := r.Client.Patch(ctx, &job, ctrlclient.MergeFrom(cp)); err != nil {
+				klog.V(5).ErrorS(err, "suspend corn job error", "pipeline", ctrlclient.ObjectKeyFromObject(pipeline),
+					"cronJob", ctrlclient.ObjectKeyFromObject(&job))
+
+				return ctrl.Result{}, err
+			}
+		}
+	default:
+		// pipeline has finished.
+		return ctrl.Result{}, nil
+	}
+
+	excepted := pipeline.DeepCopy()
+	pipeline.Status.Phase = kkcorev1.PipelinePhaseCancelled
+	pipeline.Status.Reason = "pipeline is cancelled"
+	if err := r.Client.Status().Patch(ctx, pipeline, ctrlclient.MergeFrom(excepted)); err != nil {
+		klog.V(5).ErrorS(err, "update pipeline error", "pipeline", ctrlclient.ObjectKeyFromObject(pipeline))
+
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// dealDeletePipeline when the pipeline is deleting. the jobs and cronJobs of it are deleted at once, so the running
+// executor is stopped instead of running until the garbage collector deletes them. then the runtime directory is removed.
+func (r *PipelineReconciler) dealDeletePipeline(ctx context.Context, pipeline *kkcorev1.Pipeline) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(pipeline, pipelineFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	opts := []ctrlclient.DeleteAllOfOption{
+		ctrlclient.InNamespace(pipeline.Namespace),
+		ctrlclient.MatchingLabels{jobLabel: pipeline.Name},
+		ctrlclient.PropagationPolicy(metav1.DeletePropagationBackground),
+	}
+	if err := r.Client.DeleteAllOf(ctx, &batchv1.CronJob{}, opts...); err != nil && !apierrors.IsNotFound(err) {
+		klog.V(5).ErrorS(err, "delete cronJob error", "pipeline", ctrlclient.ObjectKeyFromObject(pipeline))
+
+		return ctrl.Result{}, err
+	}
+	if err := r.Client.DeleteAllOf(ctx, &batchv1.Job{}, opts...); err != nil && !apierrors.IsNotFound(err) {
+		klog.V(5).ErrorS(err, "delete job error", "pipeline", ctrlclient.ObjectKeyFromObject(pipeline))
+
+		return ctrl.Result{}, err
+	}
+	if _const.GetWorkDir() != "" {
+		if err := os.RemoveAll(_const.RuntimeDirFromPipeline(*pipeline)); err != nil {
+			klog.V(5).ErrorS(err, "remove runtime dir error", "pipeline", ctrlclient.ObjectKeyFromObject(pipeline))
+
+			return ctrl.Result{}, err
+		}
+	}
+
+	excepted := pipeline.DeepCopy()
+	controllerutil.RemoveFinalizer(pipeline, pipelineFinalizer)
+	if err := r.Client.Patch(ctx, pipeline, ctrlclient.MergeFrom(excepted)); err != nil {
+		klog.V(5).ErrorS(err, "remove finalizer error", "pipeline", ctrlclient.ObjectKeyFromObject(pipeline))
+
+		return ctrl.Result{}, err
 	}
 
 	return ctrl.Result{}, nil
@@ -141,6 +376,7 @@ func (r *PipelineReconciler) dealRunningPipeline(ctx context.Context, pipeline *
 		if err := r.Client.Create(ctx, job); err != nil {
 			return ctrl.Result{}, err
 		}
+		observeJobCreation(pipeline, "Job")
 	default: // pipeline will create cronJob
 		jobs := &batchv1.CronJobList{}
 		if err := r.Client.List(ctx, jobs, ctrlclient.InNamespace(pipeline.Namespace), ctrlclient.MatchingLabels{
@@ -191,6 +427,7 @@ func (r *PipelineReconciler) dealRunningPipeline(ctx context.Context, pipeline *
 		if err := r.Client.Create(ctx, cornJob); err != nil {
 			return ctrl.Result{}, err
 		}
+		observeJobCreation(pipeline, "CronJob")
 	}
 
 	return ctrl.Result{}, nil
@@ -281,7 +518,7 @@ func (r *PipelineReconciler) GenerateJobSpec(pipeline kkcorev1.Pipeline) batchv1
 			Spec: corev1.PodSpec{
 				ServiceAccountName: saName,
 				RestartPolicy:      "Never",
-				Volumes:            pipeline.Spec.JobSpec.Volumes,
+				Volumes:            slices.Clone(pipeline.Spec.JobSpec.Volumes),
 				Containers: []corev1.Container{
 					{
 						Name:            "executor",
@@ -291,22 +528,41 @@ func (r *PipelineReconciler) GenerateJobSpec(pipeline kkcorev1.Pipeline) batchv1
 						Args: []string{"pipeline",
 							"--name", pipeline.Name,
 							"--namespace", pipeline.Namespace},
-						VolumeMounts: pipeline.Spec.JobSpec.VolumeMounts,
+						VolumeMounts: slices.Clone(pipeline.Spec.JobSpec.VolumeMounts),
 					},
 				},
 			},
 		},
 	}
+	// store the task logs in the claim, so they are kept after the pod is deleted.
+	if pipeline.Spec.JobSpec.LogVolumeClaim != "" {
+		jobSpec.Template.Spec.Volumes = append(jobSpec.Template.Spec.Volumes, corev1.Volume{
+			Name: logVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: pipeline.Spec.JobSpec.LogVolumeClaim},
+			},
+		})
+		jobSpec.Template.Spec.Containers[0].VolumeMounts = append(jobSpec.Template.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+			Name:      logVolumeName,
+			MountPath: executorLogDir,
+		})
+	}
 
 	return jobSpec
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *PipelineReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := registerPipelineCollector(mgr.GetClient()); err != nil {
+		return err
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		WithOptions(ctrlcontroller.Options{
 			MaxConcurrentReconciles: r.MaxConcurrentReconciles,
 		}).
 		For(&kkcorev1.Pipeline{}).
+		// the pending pipelines wait for the pipelines which they depend on.
+		Watches(&kkcorev1.Pipeline{}, handler.EnqueueRequestsFromMapFunc(r.dependentPipelines)).
 		Complete(r)
 }
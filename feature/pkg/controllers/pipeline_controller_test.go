@@ -0,0 +1,205 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kkcorev1 "github.com/kubesphere/kubekey/v4/pkg/apis/core/v1"
+	_const "github.com/kubesphere/kubekey/v4/pkg/const"
+)
+
+func TestReconcileDependsOn(t *testing.T) {
+	newPipeline := func(name string, phase kkcorev1.PipelinePhase, dependsOn ...string) *kkcorev1.Pipeline {
+		return &kkcorev1.Pipeline{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       kkcorev1.PipelineSpec{Playbook: "playbooks/test.yaml", DependsOn: dependsOn},
+			Status:     kkcorev1.PipelineStatus{Phase: phase},
+		}
+	}
+
+	testcases := []struct {
+		name        string
+		pipelines   []ctrlclient.Object
+		exceptPhase kkcorev1.PipelinePhase
+	}{
+		{
+			name: "dependencies succeed",
+			pipelines: []ctrlclient.Object{
+				newPipeline("test", kkcorev1.PipelinePhasePending, "precheck", "init-os"),
+				newPipeline("precheck", kkcorev1.PipelinePhaseSucceed),
+				newPipeline("init-os", kkcorev1.PipelinePhaseSucceed, "precheck"),
+			},
+			exceptPhase: kkcorev1.PipelinePhaseRunning,
+		},
+		{
+			name: "dependency running",
+			pipelines: []ctrlclient.Object{
+				newPipeline("test", kkcorev1.PipelinePhasePending, "precheck", "init-os"),
+				newPipeline("precheck", kkcorev1.PipelinePhaseSucceed),
+				newPipeline("init-os", kkcorev1.PipelinePhaseRunning, "precheck"),
+			},
+			exceptPhase: kkcorev1.PipelinePhasePending,
+		},
+		{
+			name: "dependency not found",
+			pipelines: []ctrlclient.Object{
+				newPipeline("test", kkcorev1.PipelinePhasePending, "precheck"),
+			},
+			exceptPhase: kkcorev1.PipelinePhasePending,
+		},
+		{
+			name: "dependency failed",
+			pipelines: []ctrlclient.Object{
+				newPipeline("test", kkcorev1.PipelinePhasePending, "precheck", "init-os"),
+				newPipeline("precheck", kkcorev1.PipelinePhaseFailed),
+				newPipeline("init-os", kkcorev1.PipelinePhasePending, "precheck"),
+			},
+			exceptPhase: kkcorev1.PipelinePhaseFailed,
+		},
+		{
+			name: "cyclic dependency",
+			pipelines: []ctrlclient.Object{
+				newPipeline("test", kkcorev1.PipelinePhasePending, "init-os"),
+				newPipeline("init-os", kkcorev1.PipelinePhasePending, "precheck"),
+				newPipeline("precheck", kkcorev1.PipelinePhasePending, "test"),
+			},
+			exceptPhase: kkcorev1.PipelinePhaseFailed,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := fake.NewClientBuilder().WithScheme(_const.Scheme).
+				WithStatusSubresource(&kkcorev1.Pipeline{}).WithObjects(tc.pipelines...).Build()
+			r := PipelineReconciler{Scheme: _const.Scheme, Client: client}
+			key := ctrlclient.ObjectKey{Namespace: "default", Name: "test"}
+			if _, err := r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: key}); err != nil {
+				t.Fatal(err)
+			}
+			pipeline := &kkcorev1.Pipeline{}
+			if err := client.Get(context.TODO(), key, pipeline); err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tc.exceptPhase, pipeline.Status.Phase)
+		})
+	}
+}
+
+func TestDependentPipelines(t *testing.T) {
+	client := fake.NewClientBuilder().WithScheme(_const.Scheme).WithObjects(
+		&kkcorev1.Pipeline{
+			ObjectMeta: metav1.ObjectMeta{Name: "init-os", Namespace: "default"},
+			Spec:       kkcorev1.PipelineSpec{DependsOn: []string{"precheck"}},
+			Status:     kkcorev1.PipelineStatus{Phase: kkcorev1.PipelinePhasePending},
+		},
+		&kkcorev1.Pipeline{
+			ObjectMeta: metav1.ObjectMeta{Name: "create-cluster", Namespace: "default"},
+			Spec:       kkcorev1.PipelineSpec{DependsOn: []string{"init-os"}},
+			Status:     kkcorev1.PipelineStatus{Phase: kkcorev1.PipelinePhasePending},
+		},
+	).Build()
+	r := PipelineReconciler{Client: client}
+
+	requests := r.dependentPipelines(context.TODO(), &kkcorev1.Pipeline{ObjectMeta: metav1.ObjectMeta{Name: "precheck", Namespace: "default"}})
+	if len(requests) != 1 {
+		t.Fatalf("except 1 request but got %d", len(requests))
+	}
+	assert.Equal(t, "init-os", requests[0].Name)
+}
+
+func TestReconcileDeletePipeline(t *testing.T) {
+	now := metav1.Now()
+	client := fake.NewClientBuilder().WithScheme(_const.Scheme).WithObjects(
+		&kkcorev1.Pipeline{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default",
+				DeletionTimestamp: &now, Finalizers: []string{pipelineFinalizer}},
+			Spec:   kkcorev1.PipelineSpec{Playbook: "playbooks/test.yaml"},
+			Status: kkcorev1.PipelineStatus{Phase: kkcorev1.PipelinePhaseRunning},
+		},
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "test-abcde", Namespace: "default", Labels: map[string]string{jobLabel: "test"}}},
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "other-abcde", Namespace: "default", Labels: map[string]string{jobLabel: "other"}}},
+	).Build()
+	r := PipelineReconciler{Scheme: _const.Scheme, Client: client}
+	key := ctrlclient.ObjectKey{Namespace: "default", Name: "test"}
+	if _, err := r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: key}); err != nil {
+		t.Fatal(err)
+	}
+
+	jobs := &batchv1.JobList{}
+	if err := client.List(context.TODO(), jobs, ctrlclient.InNamespace("default")); err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, jobs.Items, 1) {
+		assert.Equal(t, "other-abcde", jobs.Items[0].Name)
+	}
+	// the pipeline is removed after the finalizer is removed.
+	assert.True(t, apierrors.IsNotFound(client.Get(context.TODO(), key, &kkcorev1.Pipeline{})))
+}
+
+func TestReconcileAddFinalizer(t *testing.T) {
+	client := fake.NewClientBuilder().WithScheme(_const.Scheme).WithStatusSubresource(&kkcorev1.Pipeline{}).WithObjects(
+		&kkcorev1.Pipeline{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+			Spec:       kkcorev1.PipelineSpec{Playbook: "playbooks/test.yaml"},
+		},
+	).Build()
+	r := PipelineReconciler{Scheme: _const.Scheme, Client: client}
+	key := ctrlclient.ObjectKey{Namespace: "default", Name: "test"}
+	if _, err := r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: key}); err != nil {
+		t.Fatal(err)
+	}
+
+	pipeline := &kkcorev1.Pipeline{}
+	if err := client.Get(context.TODO(), key, pipeline); err != nil {
+		t.Fatal(err)
+	}
+	assert.Contains(t, pipeline.Finalizers, pipelineFinalizer)
+	assert.Equal(t, kkcorev1.PipelinePhasePending, pipeline.Status.Phase)
+}
+
+func TestGenerateJobSpecLogVolumeClaim(t *testing.T) {
+	pipeline := kkcorev1.Pipeline{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec: kkcorev1.PipelineSpec{JobSpec: kkcorev1.PipelineJobSpec{
+			LogVolumeClaim: "kubekey-logs",
+			VolumeMounts:   []corev1.VolumeMount{{Name: "work", MountPath: "/kubekey/artifact"}},
+		}},
+	}
+	spec := (&PipelineReconciler{}).GenerateJobSpec(pipeline)
+
+	assert.Equal(t, []corev1.Volume{{
+		Name:         logVolumeName,
+		VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "kubekey-logs"}},
+	}}, spec.Template.Spec.Volumes)
+	assert.Equal(t, []corev1.VolumeMount{
+		{Name: "work", MountPath: "/kubekey/artifact"},
+		{Name: logVolumeName, MountPath: "/kubekey/logs"},
+	}, spec.Template.Spec.Containers[0].VolumeMounts)
+	// the spec of pipeline is not changed.
+	assert.Len(t, pipeline.Spec.JobSpec.VolumeMounts, 1)
+}
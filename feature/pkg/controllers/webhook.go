@@ -0,0 +1,153 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	kkcorev1 "github.com/kubesphere/kubekey/v4/pkg/apis/core/v1"
+	"github.com/kubesphere/kubekey/v4/pkg/validation"
+)
+
+// SetupWebhookWithManager register validating webhook for Pipeline, Inventory and Config.
+func SetupWebhookWithManager(mgr ctrl.Manager) error {
+	if err := ctrl.NewWebhookManagedBy(mgr).
+		For(&kkcorev1.Pipeline{}).
+		WithValidator(&PipelineValidator{Client: mgr.GetClient()}).
+		Complete(); err != nil {
+		return err
+	}
+	if err := ctrl.NewWebhookManagedBy(mgr).
+		For(&kkcorev1.Inventory{}).
+		WithValidator(&InventoryValidator{}).
+		Complete(); err != nil {
+		return err
+	}
+
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&kkcorev1.Config{}).
+		WithValidator(&ConfigValidator{}).
+		Complete()
+}
+
+// PipelineValidator validate pipeline, and the config referenced by pipeline matches the json schema in project.
+type PipelineValidator struct {
+	ctrlclient.Client
+}
+
+// ValidateCreate pipeline
+func (v PipelineValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	pipeline, ok := obj.(*kkcorev1.Pipeline)
+	if !ok {
+		return nil, fmt.Errorf("expected a Pipeline but got a %T", obj)
+	}
+	allErrs := validation.ValidatePipeline(pipeline)
+	if len(allErrs) == 0 && pipeline.Spec.ConfigRef != nil {
+		namespace := pipeline.Spec.ConfigRef.Namespace
+		if namespace == "" {
+			namespace = pipeline.Namespace
+		}
+		config := &kkcorev1.Config{}
+		if err := v.Client.Get(ctx, ctrlclient.ObjectKey{Namespace: namespace, Name: pipeline.Spec.ConfigRef.Name}, config); err != nil {
+			return nil, fmt.Errorf("get config %s/%s error: %w", namespace, pipeline.Spec.ConfigRef.Name, err)
+		}
+		allErrs = append(allErrs, validation.ValidatePipelineConfig(ctx, pipeline, config)...)
+	}
+
+	return nil, toInvalid("Pipeline", pipeline.Name, allErrs)
+}
+
+// ValidateUpdate pipeline. the spec of pipeline is immutable, except spec.cancel which can be set to cancel the pipeline.
+func (v PipelineValidator) ValidateUpdate(_ context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldPipeline, ok := oldObj.(*kkcorev1.Pipeline)
+	if !ok {
+		return nil, fmt.Errorf("expected a Pipeline but got a %T", oldObj)
+	}
+	newPipeline, ok := newObj.(*kkcorev1.Pipeline)
+	if !ok {
+		return nil, fmt.Errorf("expected a Pipeline but got a %T", newObj)
+	}
+	return nil, toInvalid("Pipeline", newPipeline.Name, validation.ValidatePipelineUpdate(newPipeline, oldPipeline))
+}
+
+// ValidateDelete always pass
+func (v PipelineValidator) ValidateDelete(context.Context, runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// InventoryValidator validate hosts and groups of inventory.
+type InventoryValidator struct{}
+
+// ValidateCreate inventory
+func (v InventoryValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	inventory, ok := obj.(*kkcorev1.Inventory)
+	if !ok {
+		return nil, fmt.Errorf("expected a Inventory but got a %T", obj)
+	}
+
+	return nil, toInvalid("Inventory", inventory.Name, validation.ValidateInventory(inventory))
+}
+
+// ValidateUpdate inventory
+func (v InventoryValidator) ValidateUpdate(ctx context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	return v.ValidateCreate(ctx, newObj)
+}
+
+// ValidateDelete always pass
+func (v InventoryValidator) ValidateDelete(context.Context, runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// ConfigValidator validate spec of config.
+type ConfigValidator struct{}
+
+// ValidateCreate config
+func (v ConfigValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	config, ok := obj.(*kkcorev1.Config)
+	if !ok {
+		return nil, fmt.Errorf("expected a Config but got a %T", obj)
+	}
+
+	return nil, toInvalid("Config", config.Name, validation.ValidateConfig(config))
+}
+
+// ValidateUpdate config
+func (v ConfigValidator) ValidateUpdate(ctx context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	return v.ValidateCreate(ctx, newObj)
+}
+
+// ValidateDelete always pass
+func (v ConfigValidator) ValidateDelete(context.Context, runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// toInvalid convert field errors to an invalid error. return nil if there is no error.
+func toInvalid(kind, name string, allErrs field.ErrorList) error {
+	if len(allErrs) == 0 {
+		return nil
+	}
+
+	return apierrors.NewInvalid(kkcorev1.SchemeGroupVersion.WithKind(kind).GroupKind(), name, allErrs)
+}
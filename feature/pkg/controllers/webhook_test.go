@@ -0,0 +1,91 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kkcorev1 "github.com/kubesphere/kubekey/v4/pkg/apis/core/v1"
+)
+
+func TestPipelineValidateUpdate(t *testing.T) {
+	newPipeline := func(mutate func(spec *kkcorev1.PipelineSpec)) *kkcorev1.Pipeline {
+		pipeline := &kkcorev1.Pipeline{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+			Spec:       kkcorev1.PipelineSpec{Playbook: "playbooks/test.yaml", Tags: []string{"a"}},
+		}
+		if mutate != nil {
+			mutate(&pipeline.Spec)
+		}
+
+		return pipeline
+	}
+
+	testcases := []struct {
+		name    string
+		oldObj  *kkcorev1.Pipeline
+		newObj  *kkcorev1.Pipeline
+		invalid bool
+	}{
+		{
+			name:   "unchanged",
+			oldObj: newPipeline(nil),
+			newObj: newPipeline(nil),
+		},
+		{
+			name:   "cancel",
+			oldObj: newPipeline(nil),
+			newObj: newPipeline(func(spec *kkcorev1.PipelineSpec) { spec.Cancel = true }),
+		},
+		{
+			name:    "resume cancelled",
+			oldObj:  newPipeline(func(spec *kkcorev1.PipelineSpec) { spec.Cancel = true }),
+			newObj:  newPipeline(nil),
+			invalid: true,
+		},
+		{
+			name:    "change playbook",
+			oldObj:  newPipeline(nil),
+			newObj:  newPipeline(func(spec *kkcorev1.PipelineSpec) { spec.Playbook = "playbooks/other.yaml" }),
+			invalid: true,
+		},
+		{
+			name:   "cancel and change tags",
+			oldObj: newPipeline(nil),
+			newObj: newPipeline(func(spec *kkcorev1.PipelineSpec) {
+				spec.Cancel = true
+				spec.Tags = []string{"b"}
+			}),
+			invalid: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := PipelineValidator{}.ValidateUpdate(context.TODO(), tc.oldObj, tc.newObj)
+			if tc.invalid {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
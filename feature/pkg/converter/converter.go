@@ -50,6 +50,7 @@ func MarshalBlock(role string, hosts []string, when []string, block kkprojectv1.
 			Hosts:       hosts,
 			IgnoreError: block.IgnoreErrors,
 			Retries:     block.Retries,
+			Timeout:     block.Timeout,
 			When:        when,
 			FailedWhen:  block.FailedWhen.Data,
 			Register:    block.Register,
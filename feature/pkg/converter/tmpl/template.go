@@ -71,6 +71,22 @@ func ParseString(ctx map[string]any, input string) (string, error) {
 	return strings.TrimPrefix(strings.TrimSuffix(result.String(), "\n"), "\n"), nil
 }
 
+// Check the syntax of template string without executing it.
+// when isBool is true, input is a condition which may not be wrapped by "{{ }}", the same as ParseBool.
+func Check(input string, isBool bool) error {
+	if isBool && !IsTmplSyntax(input) {
+		input = "{{ " + input + " }}"
+	}
+	if !IsTmplSyntax(input) {
+		return nil
+	}
+	if _, err := internal.Template.Parse(input); err != nil {
+		return fmt.Errorf("failed to parse template '%s': %w", input, err)
+	}
+
+	return nil
+}
+
 // IsTmplSyntax Check if the string conforms to the template syntax.
 func IsTmplSyntax(s string) bool {
 	return strings.Contains(s, "{{") && strings.Contains(s, "}}")
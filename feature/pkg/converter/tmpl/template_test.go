@@ -642,3 +642,48 @@ func TestParseCustomFunction(t *testing.T) {
 		})
 	}
 }
+
+func TestCheck(t *testing.T) {
+	testcases := []struct {
+		name   string
+		input  string
+		isBool bool
+		hasErr bool
+	}{
+		{
+			name:  "plain string",
+			input: "hello (",
+		},
+		{
+			name:  "valid template",
+			input: "{{ .foo | default \"a\" }}",
+		},
+		{
+			name:   "valid condition without braces",
+			input:  ".foo | eq \"a\"",
+			isBool: true,
+		},
+		{
+			name:   "invalid condition without braces",
+			input:  ".foo | eq \"a\")",
+			isBool: true,
+			hasErr: true,
+		},
+		{
+			name:   "undefined function",
+			input:  "{{ .foo | notExist }}",
+			hasErr: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Check(tc.input, tc.isBool)
+			if tc.hasErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
+	"reflect"
 	"slices"
 
 	"k8s.io/apimachinery/pkg/runtime"
@@ -14,7 +16,9 @@ import (
 
 	kkcorev1 "github.com/kubesphere/kubekey/v4/pkg/apis/core/v1"
 	kkprojectv1 "github.com/kubesphere/kubekey/v4/pkg/apis/project/v1"
+	_const "github.com/kubesphere/kubekey/v4/pkg/const"
 	"github.com/kubesphere/kubekey/v4/pkg/converter"
+	"github.com/kubesphere/kubekey/v4/pkg/converter/tmpl"
 	"github.com/kubesphere/kubekey/v4/pkg/modules"
 	"github.com/kubesphere/kubekey/v4/pkg/variable"
 )
@@ -54,14 +58,25 @@ func (e blockExecutor) Exec(ctx context.Context) error {
 		}
 
 		switch {
-		case len(block.Block) != 0:
-			if err := e.dealBlock(ctx, hosts, ignoreErrors, when, tags, block); err != nil {
+		case block.IsInclude():
+			blockCtx, cancel := withTimeout(ctx, block.Timeout, fmt.Sprintf("block %q", block.Name))
+			err := e.dealInclude(blockCtx, hosts, ignoreErrors, when, tags, block)
+			cancel()
+			if err != nil {
+				klog.V(5).ErrorS(err, "deal include error", "block", block.Name, "pipeline", ctrlclient.ObjectKeyFromObject(e.pipeline))
+
+				return err
+			}
+		case len(block.Block) != 0, block.ImportTasks != "", block.ImportRole != nil:
+			// import tasks and import role has converted to blocks.
+			blockCtx, cancel := withTimeout(ctx, block.Timeout, fmt.Sprintf("block %q", block.Name))
+			err := e.dealBlock(blockCtx, hosts, ignoreErrors, when, tags, block)
+			cancel()
+			if err != nil {
 				klog.V(5).ErrorS(err, "deal block error", "block", block.Name, "pipeline", ctrlclient.ObjectKeyFromObject(e.pipeline))
 
 				return err
 			}
-		case block.IncludeTasks != "":
-			// do nothing. include tasks has converted to blocks.
 		default:
 			if err := e.dealTask(ctx, hosts, when, block); err != nil {
 				klog.V(5).ErrorS(err, "deal task error", "block", block.Name, "pipeline", ctrlclient.ObjectKeyFromObject(e.pipeline))
@@ -119,6 +134,10 @@ func (e blockExecutor) dealWhen(when kkprojectv1.When) []string {
 // If always id defined, execute it.
 func (e blockExecutor) dealBlock(ctx context.Context, hosts []string, ignoreErrors *bool, when []string, tags kkprojectv1.Taggable, block kkprojectv1.Block) error {
 	var errs error
+	// the blocks of import_role belong to the imported role.
+	if block.ImportRole != nil {
+		e.role = block.ImportRole.Name
+	}
 	// exec block
 	if err := (blockExecutor{
 		option:       e.option,
@@ -166,6 +185,165 @@ func (e blockExecutor) dealBlock(ctx context.Context, hosts []string, ignoreErro
 	return errs
 }
 
+// includeGroup is the hosts which include the same target (tasks file or role) with the same loop item.
+type includeGroup struct {
+	target string
+	item   any
+	hosts  []string
+}
+
+// dealInclude "include_tasks" and "include_role" argument in block. they are evaluated in each host at execution time.
+// hosts which include the same target with the same loop item run together, in the order of loop items.
+func (e blockExecutor) dealInclude(ctx context.Context, hosts []string, ignoreErrors *bool, when []string, tags kkprojectv1.Taggable, block kkprojectv1.Block) error {
+	groups, err := e.groupInclude(hosts, when, block)
+	if err != nil {
+		return err
+	}
+	for _, g := range groups {
+		// set item to runtime variable
+		if err := e.variable.Merge(variable.MergeRuntimeVariable(map[string]any{
+			_const.VariableItem: g.item,
+		}, g.hosts...)); err != nil {
+			return fmt.Errorf("set loop item to variable error: %w", err)
+		}
+		if block.IncludeRole != nil {
+			err = e.execIncludeRole(ctx, ignoreErrors, tags, block, g)
+		} else {
+			err = e.execIncludeTasks(ctx, ignoreErrors, tags, g)
+		}
+		if err != nil {
+			return err
+		}
+		// delete item
+		if err := e.variable.Merge(variable.MergeRuntimeVariable(map[string]any{
+			_const.VariableItem: nil,
+		}, g.hosts...)); err != nil {
+			return fmt.Errorf("clean loop item to variable error: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// groupInclude check when condition, expand loop and parse the target for each host. then group hosts by target and item.
+func (e blockExecutor) groupInclude(hosts []string, when []string, block kkprojectv1.Block) ([]*includeGroup, error) {
+	target := block.IncludeTasks
+	if block.IncludeRole != nil {
+		target = block.IncludeRole.Name
+	}
+	var loop runtime.RawExtension
+	if block.Loop != nil {
+		data, err := json.Marshal(block.Loop)
+		if err != nil {
+			return nil, fmt.Errorf("marshal loop error: %w", err)
+		}
+		loop.Raw = data
+	}
+
+	hostTargets := make(map[string][]includeGroup)
+	for _, h := range hosts {
+		ha, err := e.variable.Get(variable.GetAllVariable(h))
+		if err != nil {
+			return nil, fmt.Errorf("get variable of host %s error: %w", h, err)
+		}
+		had, ok := ha.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("variable of host %s is not a map", h)
+		}
+		if len(when) != 0 {
+			ok, err := tmpl.ParseBool(had, when)
+			if err != nil {
+				return nil, fmt.Errorf("parse when condition of host %s error: %w", h, err)
+			}
+			if !ok {
+				continue
+			}
+		}
+		items := []any{nil}
+		if loop.Raw != nil {
+			items = variable.Extension2Slice(had, loop)
+		}
+		for _, item := range items {
+			vars := maps.Clone(had)
+			vars[_const.VariableItem] = item
+			t, err := tmpl.ParseString(vars, target)
+			if err != nil {
+				return nil, fmt.Errorf("parse %q of host %s error: %w", target, h, err)
+			}
+			hostTargets[h] = append(hostTargets[h], includeGroup{target: t, item: item})
+		}
+	}
+
+	var groups []*includeGroup
+	for i := 0; ; i++ {
+		start, found := len(groups), false
+		for _, h := range hosts {
+			if len(hostTargets[h]) <= i {
+				continue
+			}
+			found = true
+			ht := hostTargets[h][i]
+			if j := slices.IndexFunc(groups[start:], func(g *includeGroup) bool {
+				return g.target == ht.target && reflect.DeepEqual(g.item, ht.item)
+			}); j != -1 {
+				groups[start+j].hosts = append(groups[start+j].hosts, h)
+
+				continue
+			}
+			groups = append(groups, &includeGroup{target: ht.target, item: ht.item, hosts: []string{h}})
+		}
+		if !found {
+			return groups, nil
+		}
+	}
+}
+
+// execIncludeTasks load blocks from the tasks file and execute them.
+func (e blockExecutor) execIncludeTasks(ctx context.Context, ignoreErrors *bool, tags kkprojectv1.Taggable, g *includeGroup) error {
+	blocks, err := e.project.MarshalBlock(g.target, e.role)
+	if err != nil {
+		return fmt.Errorf("load include_tasks %s error: %w", g.target, err)
+	}
+
+	return blockExecutor{
+		option:       e.option,
+		hosts:        g.hosts,
+		ignoreErrors: ignoreErrors,
+		blocks:       blocks,
+		role:         e.role,
+		tags:         tags,
+	}.Exec(ctx)
+}
+
+// execIncludeRole load the role with its dependencies and execute them.
+func (e blockExecutor) execIncludeRole(ctx context.Context, ignoreErrors *bool, tags kkprojectv1.Taggable, block kkprojectv1.Block, g *includeGroup) error {
+	roles, err := e.project.MarshalRole(kkprojectv1.Role{RoleInfo: kkprojectv1.RoleInfo{
+		Base: kkprojectv1.Base{Vars: block.Vars},
+		Role: g.target,
+	}}, block.IncludeRole.TasksFrom)
+	if err != nil {
+		return fmt.Errorf("load include_role %s error: %w", g.target, err)
+	}
+	for _, role := range roles {
+		if err := e.variable.Merge(variable.MergeRuntimeVariable(role.Vars, g.hosts...)); err != nil {
+			return fmt.Errorf("merge variable error: %w", err)
+		}
+		if err := (blockExecutor{
+			option:       e.option,
+			hosts:        g.hosts,
+			ignoreErrors: ignoreErrors,
+			blocks:       role.Block,
+			role:         role.Role,
+			when:         role.When.Data,
+			tags:         kkprojectv1.JoinTag(role.Taggable, tags),
+		}.Exec(ctx)); err != nil {
+			return fmt.Errorf("execute include_role %s error: %w", role.Role, err)
+		}
+	}
+
+	return nil
+}
+
 // dealTask "block" argument is not defined in block.
 func (e blockExecutor) dealTask(ctx context.Context, hosts []string, when []string, block kkprojectv1.Block) error {
 	task := converter.MarshalBlock(e.role, hosts, when, block)
@@ -204,6 +382,14 @@ func (e blockExecutor) dealTask(ctx context.Context, hosts []string, when []stri
 
 		return err
 	}
+	// notify handler in the hosts which task has changed.
+	for _, handler := range block.Notify.Data {
+		for _, hr := range task.Status.HostResults {
+			if modules.IsChanged(task.Spec.Module.Name, hr.Stdout, hr.StdErr) {
+				e.notify(handler, hr.Host)
+			}
+		}
+	}
 
 	return nil
 }
@@ -131,3 +131,52 @@ func TestBlockExecutor_DealWhen(t *testing.T) {
 		})
 	}
 }
+
+func TestBlockExecutor_GroupInclude(t *testing.T) {
+	testcases := []struct {
+		name   string
+		when   []string
+		block  kkprojectv1.Block
+		except []*includeGroup
+	}{
+		{
+			name: "include tasks",
+			block: kkprojectv1.Block{
+				BlockInclude: kkprojectv1.BlockInclude{IncludeTasks: "a.yaml"},
+			},
+			except: []*includeGroup{{target: "a.yaml", hosts: []string{"localhost"}}},
+		},
+		{
+			name: "include tasks with loop",
+			block: kkprojectv1.Block{
+				BlockInclude: kkprojectv1.BlockInclude{IncludeTasks: "{{ .item }}.yaml"},
+				Task:         kkprojectv1.Task{Loop: []any{"a", "b"}},
+			},
+			except: []*includeGroup{
+				{target: "a.yaml", item: "a", hosts: []string{"localhost"}},
+				{target: "b.yaml", item: "b", hosts: []string{"localhost"}},
+			},
+		},
+		{
+			name: "include role with false condition",
+			when: []string{"false"},
+			block: kkprojectv1.Block{
+				BlockInclude: kkprojectv1.BlockInclude{IncludeRole: &kkprojectv1.RoleRef{Name: "role1"}},
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			o, err := newTestOption()
+			if err != nil {
+				t.Fatal(err)
+			}
+			groups, err := blockExecutor{option: o}.groupInclude([]string{"localhost"}, tc.when, tc.block)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tc.except, groups)
+		})
+	}
+}
@@ -2,11 +2,15 @@ package executor
 
 import (
 	"context"
-	"io"
+	"fmt"
+	"slices"
+	"time"
 
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 
 	kkcorev1 "github.com/kubesphere/kubekey/v4/pkg/apis/core/v1"
+	"github.com/kubesphere/kubekey/v4/pkg/callback"
+	"github.com/kubesphere/kubekey/v4/pkg/project"
 	"github.com/kubesphere/kubekey/v4/pkg/variable"
 )
 
@@ -21,6 +25,45 @@ type option struct {
 
 	pipeline *kkcorev1.Pipeline
 	variable variable.Variable
-	// commandLine log output. default os.stdout
-	logOutput io.Writer
+	// callback receives the events of pipeline execution.
+	callback callback.Callback
+	// play is the name of running play.
+	play string
+	// project of pipeline. used to load the included tasks and roles at execution time.
+	project project.Project
+	// notified handlers. key is the name of handler, value is the hosts which notify it.
+	notified map[string][]string
+}
+
+// event fill the common fields of event and send it to callback.
+func (o *option) event(event callback.Event) {
+	if o.callback == nil {
+		return
+	}
+	event.Time = time.Now()
+	event.Pipeline = ctrlclient.ObjectKeyFromObject(o.pipeline).String()
+	event.Play = o.play
+	o.callback.OnEvent(event)
+}
+
+// notify the handler in hosts. the handler will run in the end of play.
+func (o *option) notify(handler string, hosts ...string) {
+	if o.notified == nil {
+		o.notified = make(map[string][]string)
+	}
+	for _, h := range hosts {
+		if !slices.Contains(o.notified[handler], h) {
+			o.notified[handler] = append(o.notified[handler], h)
+		}
+	}
+}
+
+// withTimeout return a context which is done after timeout seconds, the cause of context is "name timeout after timeout seconds".
+// if timeout is not positive, the context is only done when parent done.
+func withTimeout(ctx context.Context, timeout int, name string) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeoutCause(ctx, time.Duration(timeout)*time.Second, fmt.Errorf("%s timeout after %ds", name, timeout))
 }
@@ -11,6 +11,7 @@ import (
 
 	kkcorev1 "github.com/kubesphere/kubekey/v4/pkg/apis/core/v1"
 	kkcorev1alpha1 "github.com/kubesphere/kubekey/v4/pkg/apis/core/v1alpha1"
+	"github.com/kubesphere/kubekey/v4/pkg/callback"
 	_const "github.com/kubesphere/kubekey/v4/pkg/const"
 	"github.com/kubesphere/kubekey/v4/pkg/variable"
 	"github.com/kubesphere/kubekey/v4/pkg/variable/source"
@@ -39,7 +40,7 @@ func newTestOption() (*option, error) {
 			},
 			Status: kkcorev1.PipelineStatus{},
 		},
-		logOutput: os.Stdout,
+		callback: callback.NewProgressCallback(os.Stdout),
 	}
 
 	if err := o.client.Create(context.TODO(), &kkcorev1.Inventory{
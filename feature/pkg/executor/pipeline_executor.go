@@ -18,25 +18,29 @@ package executor
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
+	"slices"
 
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/klog/v2"
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 
 	kkcorev1 "github.com/kubesphere/kubekey/v4/pkg/apis/core/v1"
 	kkprojectv1 "github.com/kubesphere/kubekey/v4/pkg/apis/project/v1"
+	"github.com/kubesphere/kubekey/v4/pkg/callback"
 	"github.com/kubesphere/kubekey/v4/pkg/connector"
 	_const "github.com/kubesphere/kubekey/v4/pkg/const"
 	"github.com/kubesphere/kubekey/v4/pkg/converter"
 	"github.com/kubesphere/kubekey/v4/pkg/project"
+	"github.com/kubesphere/kubekey/v4/pkg/validation"
 	"github.com/kubesphere/kubekey/v4/pkg/variable"
 	"github.com/kubesphere/kubekey/v4/pkg/variable/source"
 )
 
 // NewPipelineExecutor return a new pipelineExecutor
-func NewPipelineExecutor(ctx context.Context, client ctrlclient.Client, pipeline *kkcorev1.Pipeline, logOutput io.Writer) Executor {
+func NewPipelineExecutor(ctx context.Context, client ctrlclient.Client, pipeline *kkcorev1.Pipeline, cb callback.Callback) Executor {
 	// get variable
 	v, err := variable.New(ctx, client, *pipeline, source.FileSource)
 	if err != nil {
@@ -47,10 +51,10 @@ func NewPipelineExecutor(ctx context.Context, client ctrlclient.Client, pipeline
 
 	return &pipelineExecutor{
 		option: &option{
-			client:    client,
-			pipeline:  pipeline,
-			variable:  v,
-			logOutput: logOutput,
+			client:   client,
+			pipeline: pipeline,
+			variable: v,
+			callback: cb,
 		},
 	}
 }
@@ -67,6 +71,10 @@ func (e pipelineExecutor) Exec(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("deal project error: %w", err)
 	}
+	e.project = pj
+	if err := e.validateConfig(ctx); err != nil {
+		return err
+	}
 
 	// convert to transfer.Playbook struct
 	pb, err := pj.MarshalPlaybook()
@@ -87,6 +95,8 @@ func (e pipelineExecutor) Exec(ctx context.Context) error {
 
 			continue
 		}
+		e.play = play.Name
+		e.event(callback.Event{Type: callback.EventPlayStart, Hosts: hosts})
 		// when gather_fact is set. get host's information from remote.
 		if err := e.dealGatherFacts(ctx, play.GatherFacts, hosts); err != nil {
 			return fmt.Errorf("deal gather_facts argument error: %w", err)
@@ -98,16 +108,80 @@ func (e pipelineExecutor) Exec(ctx context.Context) error {
 		}
 		e.dealRunOnce(play.RunOnce, hosts, &batchHosts)
 		// exec pipeline in each BatchHosts
-		if err := e.execBatchHosts(ctx, play, batchHosts); err != nil {
-			return fmt.Errorf("exec batch hosts error: %v", err)
+		playCtx, cancel := withTimeout(ctx, play.Timeout, fmt.Sprintf("play %q", play.Name))
+		err := e.execBatchHosts(playCtx, play, batchHosts)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("exec batch hosts error: %w", err)
+		}
+	}
+
+	return e.dealOutputs()
+}
+
+// validateConfig check the config referenced by pipeline matches the json schema in project.
+// it's checked at submission as well, but the config may be updated before the pipeline is executed.
+func (e pipelineExecutor) validateConfig(ctx context.Context) error {
+	if e.pipeline.Spec.ConfigRef == nil {
+		return nil
+	}
+	config := &kkcorev1.Config{}
+	if err := e.client.Get(ctx, ctrlclient.ObjectKey{Namespace: e.pipeline.Spec.ConfigRef.Namespace, Name: e.pipeline.Spec.ConfigRef.Name}, config); err != nil {
+		return fmt.Errorf("get config %s/%s error: %w", e.pipeline.Spec.ConfigRef.Namespace, e.pipeline.Spec.ConfigRef.Name, err)
+	}
+	if errs := validation.ValidateProjectConfig(e.project, config); len(errs) != 0 {
+		return fmt.Errorf("config %s/%s is invalid: %w", config.Namespace, config.Name, errs.ToAggregate())
+	}
+
+	return nil
+}
+
+// dealOutputs export the variables defined in spec.outputs of each host to status.outputs.
+// the downstream pipelines which depend on this pipeline will merge them to runtime variables.
+func (e pipelineExecutor) dealOutputs() error {
+	if len(e.pipeline.Spec.Outputs) == 0 {
+		return nil
+	}
+	ahn, err := e.variable.Get(variable.GetHostnames([]string{_const.VariableGroupsAll}))
+	if err != nil {
+		return fmt.Errorf("get all hosts error: %w", err)
+	}
+	hosts, ok := ahn.([]string)
+	if !ok {
+		return errors.New("get all hosts error: hosts should be []string")
+	}
+
+	outputs := make(map[string]any)
+	for _, h := range hosts {
+		vars, err := e.variable.Get(variable.GetAllVariable(h))
+		if err != nil {
+			return fmt.Errorf("get variable of host %q error: %w", h, err)
+		}
+		vd, ok := vars.(map[string]any)
+		if !ok {
+			return fmt.Errorf("variable of host %q should be map", h)
+		}
+		hostOutputs := make(map[string]any)
+		for _, name := range e.pipeline.Spec.Outputs {
+			if val, ok := vd[name]; ok {
+				hostOutputs[name] = val
+			}
 		}
+		if len(hostOutputs) != 0 {
+			outputs[h] = hostOutputs
+		}
+	}
+	data, err := json.Marshal(outputs)
+	if err != nil {
+		return fmt.Errorf("marshal outputs error: %w", err)
 	}
+	e.pipeline.Status.Outputs = runtime.RawExtension{Raw: data}
 
 	return nil
 }
 
 // execBatchHosts executor block in play order by: "pre_tasks" > "roles" > "tasks" > "post_tasks"
-func (e pipelineExecutor) execBatchHosts(ctx context.Context, play kkprojectv1.Play, batchHosts [][]string) any {
+func (e pipelineExecutor) execBatchHosts(ctx context.Context, play kkprojectv1.Play, batchHosts [][]string) error {
 	// generate and execute task.
 	for _, serials := range batchHosts {
 		// each batch hosts should not be empty.
@@ -173,6 +247,56 @@ func (e pipelineExecutor) execBatchHosts(ctx context.Context, play kkprojectv1.P
 		}.Exec(ctx)); err != nil {
 			return fmt.Errorf("execute post-tasks error: %w", err)
 		}
+		// run notified handlers
+		if err := e.execHandlers(ctx, play, serials); err != nil {
+			return fmt.Errorf("execute handlers error: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// execHandlers run the handlers which notified in serials hosts. handlers run in defined order.
+// the handlers defined in play run first, then the handlers defined in roles.
+// each handler only run once in a host, even if it's notified by multiple tasks.
+func (e pipelineExecutor) execHandlers(ctx context.Context, play kkprojectv1.Play, serials []string) error {
+	type handler struct {
+		role  string
+		block kkprojectv1.Block
+	}
+	handlers := make([]handler, 0, len(play.Handlers))
+	for _, h := range play.Handlers {
+		handlers = append(handlers, handler{block: h})
+	}
+	for _, role := range play.Roles {
+		for _, h := range role.Handlers {
+			handlers = append(handlers, handler{role: role.Role, block: h})
+		}
+	}
+
+	for _, h := range handlers {
+		var hosts []string
+		for _, host := range serials {
+			if slices.Contains(e.notified[h.block.Name], host) {
+				hosts = append(hosts, host)
+			}
+		}
+		if len(hosts) == 0 {
+			continue
+		}
+		e.notified[h.block.Name] = slices.DeleteFunc(e.notified[h.block.Name], func(host string) bool {
+			return slices.Contains(hosts, host)
+		})
+		if err := (blockExecutor{
+			option:       e.option,
+			hosts:        hosts,
+			ignoreErrors: play.IgnoreErrors,
+			blocks:       []kkprojectv1.Block{h.block},
+			role:         h.role,
+			tags:         play.Taggable,
+		}.Exec(ctx)); err != nil {
+			return fmt.Errorf("execute handler %q error: %w", h.block.Name, err)
+		}
 	}
 
 	return nil
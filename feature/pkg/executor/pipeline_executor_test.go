@@ -1,9 +1,18 @@
 package executor
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kkcorev1 "github.com/kubesphere/kubekey/v4/pkg/apis/core/v1"
+	"github.com/kubesphere/kubekey/v4/pkg/variable"
+	"github.com/kubesphere/kubekey/v4/pkg/variable/source"
 )
 
 func TestPipelineExecutor_DealRunOnce(t *testing.T) {
@@ -36,3 +45,59 @@ func TestPipelineExecutor_DealRunOnce(t *testing.T) {
 		})
 	}
 }
+
+func TestPipelineExecutor_DealOutputs(t *testing.T) {
+	o, err := newTestOption()
+	if err != nil {
+		t.Fatal(err)
+	}
+	inventory := &kkcorev1.Inventory{}
+	if err := o.client.Get(context.TODO(), ctrlclient.ObjectKey{Namespace: corev1.NamespaceDefault, Name: "test"}, inventory); err != nil {
+		t.Fatal(err)
+	}
+	inventory.Spec.Hosts = map[string]runtime.RawExtension{"node1": {}}
+	if err := o.client.Update(context.TODO(), inventory); err != nil {
+		t.Fatal(err)
+	}
+	o.pipeline.Spec.Outputs = []string{"cluster_token", "not_exist"}
+	if o.variable, err = variable.New(context.TODO(), o.client, *o.pipeline, source.MemorySource); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.variable.Merge(variable.MergeRuntimeVariable(map[string]any{"cluster_token": "abc", "other": "x"}, "node1")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := (pipelineExecutor{option: o}).dealOutputs(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, map[string]any{"node1": map[string]any{"cluster_token": "abc"}}, variable.Extension2Variables(o.pipeline.Status.Outputs))
+
+	// the downstream pipeline get the outputs from runtime variables.
+	if err := o.client.Create(context.TODO(), o.pipeline); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.client.Status().Update(context.TODO(), o.pipeline); err != nil {
+		t.Fatal(err)
+	}
+	downstream := &kkcorev1.Pipeline{
+		ObjectMeta: metav1.ObjectMeta{Name: "downstream", Namespace: corev1.NamespaceDefault},
+		Spec: kkcorev1.PipelineSpec{
+			InventoryRef: o.pipeline.Spec.InventoryRef,
+			ConfigRef:    o.pipeline.Spec.ConfigRef,
+			DependsOn:    []string{o.pipeline.Name},
+		},
+	}
+	v, err := variable.New(context.TODO(), o.client, *downstream, source.MemorySource)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vars, err := v.Get(variable.GetAllVariable("node1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	vd, ok := vars.(map[string]any)
+	if !ok {
+		t.Fatal("variable should be map")
+	}
+	assert.Equal(t, "abc", vd["cluster_token"])
+}
@@ -1,13 +1,15 @@
 package executor
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
-	"os"
+	"slices"
 	"strings"
 	"time"
 
-	"github.com/schollz/progressbar/v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/json"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/klog/v2"
@@ -15,12 +17,29 @@ import (
 
 	kkcorev1 "github.com/kubesphere/kubekey/v4/pkg/apis/core/v1"
 	kkcorev1alpha1 "github.com/kubesphere/kubekey/v4/pkg/apis/core/v1alpha1"
+	"github.com/kubesphere/kubekey/v4/pkg/callback"
 	_const "github.com/kubesphere/kubekey/v4/pkg/const"
 	"github.com/kubesphere/kubekey/v4/pkg/converter/tmpl"
 	"github.com/kubesphere/kubekey/v4/pkg/modules"
 	"github.com/kubesphere/kubekey/v4/pkg/variable"
 )
 
+// maxTaskDurations is the max number of tasks recorded in the status of pipeline, only the slowest are kept.
+const maxTaskDurations = 10
+
+// slowestTasks adds td to durations, which is sorted from the slowest, and keeps at most maxTaskDurations of them.
+func slowestTasks(durations []kkcorev1.PipelineTaskDuration, td kkcorev1.PipelineTaskDuration) []kkcorev1.PipelineTaskDuration {
+	i, _ := slices.BinarySearchFunc(durations, td, func(a, b kkcorev1.PipelineTaskDuration) int {
+		return cmp.Compare(b.Duration.Duration, a.Duration.Duration)
+	})
+	if i >= maxTaskDurations {
+		return durations
+	}
+	durations = slices.Insert(durations, i, td)
+
+	return durations[:min(len(durations), maxTaskDurations)]
+}
+
 type taskExecutor struct {
 	*option
 	task *kkcorev1alpha1.Task
@@ -28,13 +47,31 @@ type taskExecutor struct {
 
 // Exec and store Task
 func (e taskExecutor) Exec(ctx context.Context) error {
+	// the pipeline is cancelled or timeout. should not run new task.
+	if ctx.Err() != nil {
+		return fmt.Errorf("task %s is not run: %w", e.task.Spec.Name, context.Cause(ctx))
+	}
 	// create task
 	if err := e.client.Create(ctx, e.task); err != nil {
 		klog.V(5).ErrorS(err, "create task error", "task", ctrlclient.ObjectKeyFromObject(e.task), "pipeline", ctrlclient.ObjectKeyFromObject(e.pipeline))
 
 		return err
 	}
+	start := time.Now()
 	defer func() {
+		duration := time.Since(start)
+		e.pipeline.Status.TaskDurations = slowestTasks(e.pipeline.Status.TaskDurations, kkcorev1.PipelineTaskDuration{
+			Task:     e.task.Spec.Name,
+			Phase:    string(e.task.Status.Phase),
+			Duration: metav1.Duration{Duration: duration},
+		})
+		// every task is observed, the histogram is exported by the controller when the pipeline finishes.
+		if e.pipeline.Status.TaskDurationHistogram == nil {
+			e.pipeline.Status.TaskDurationHistogram = make(map[string]kkcorev1.PipelineTaskDurationHistogram)
+		}
+		histogram := e.pipeline.Status.TaskDurationHistogram[string(e.task.Status.Phase)]
+		histogram.Observe(duration)
+		e.pipeline.Status.TaskDurationHistogram[string(e.task.Status.Phase)] = histogram
 		e.pipeline.Status.TaskResult.Total++
 		switch e.task.Status.Phase {
 		case kkcorev1alpha1.TaskPhaseSuccess:
@@ -47,24 +84,31 @@ func (e taskExecutor) Exec(ctx context.Context) error {
 	}()
 
 	for !e.task.IsComplete() {
-		var roleLog string
-		if e.task.Annotations[kkcorev1alpha1.TaskAnnotationRole] != "" {
-			roleLog = "[" + e.task.Annotations[kkcorev1alpha1.TaskAnnotationRole] + "] "
-		}
 		klog.V(5).InfoS("begin run task", "task", ctrlclient.ObjectKeyFromObject(e.task))
-		fmt.Fprintf(e.logOutput, "%s %s%s\n", time.Now().Format(time.TimeOnly+" MST"), roleLog, e.task.Spec.Name)
+		e.event(callback.Event{
+			Type:   callback.EventTaskStart,
+			Role:   e.task.Annotations[kkcorev1alpha1.TaskAnnotationRole],
+			TaskID: e.task.Name,
+			Task:   e.task.Spec.Name,
+			Hosts:  e.task.Spec.Hosts,
+		})
 		// exec task
 		e.task.Status.Phase = kkcorev1alpha1.TaskPhaseRunning
 		if err := e.client.Status().Update(ctx, e.task); err != nil {
 			klog.V(5).ErrorS(err, "update task status error", "task", ctrlclient.ObjectKeyFromObject(e.task), "pipeline", ctrlclient.ObjectKeyFromObject(e.pipeline))
 		}
 		e.execTask(ctx)
-		if err := e.client.Status().Update(ctx, e.task); err != nil {
+		// the ctx may be cancelled. status should always be updated.
+		if err := e.client.Status().Update(context.WithoutCancel(ctx), e.task); err != nil {
 			klog.V(5).ErrorS(err, "update task status error", "task", ctrlclient.ObjectKeyFromObject(e.task), "pipeline", ctrlclient.ObjectKeyFromObject(e.pipeline))
 
 			return err
 		}
 	}
+	// exit when pipeline is cancelled
+	if e.task.IsCancelled() {
+		return fmt.Errorf("task %s is cancelled: %w", e.task.Spec.Name, context.Cause(ctx))
+	}
 	// exit when task run failed
 	if e.task.IsFailed() {
 		var hostReason []kkcorev1.PipelineFailedDetailHost
@@ -96,6 +140,12 @@ func (e taskExecutor) execTask(ctx context.Context) {
 		wg.StartWithContext(ctx, e.execTaskHost(i, h))
 	}
 	wg.Wait()
+	// the running task is stopped by cancel.
+	if errors.Is(ctx.Err(), context.Canceled) {
+		e.task.Status.Phase = kkcorev1alpha1.TaskPhaseCancelled
+
+		return
+	}
 	// host result for task
 	e.task.Status.Phase = kkcorev1alpha1.TaskPhaseSuccess
 	for _, data := range e.task.Status.HostResults {
@@ -114,9 +164,15 @@ func (e taskExecutor) execTask(ctx context.Context) {
 // execTaskHost deal module in each host parallel.
 func (e taskExecutor) execTaskHost(i int, h string) func(ctx context.Context) {
 	return func(ctx context.Context) {
+		ctx, cancel := withTimeout(ctx, e.task.Spec.Timeout, "task")
+		defer cancel()
 		// task result
 		var stdout, stderr string
 		defer func() {
+			// the task is timeout or cancelled in host.
+			if ctx.Err() != nil {
+				stderr = strings.TrimSuffix(context.Cause(ctx).Error()+": "+stderr, ": ")
+			}
 			if err := e.dealRegister(stdout, stderr, h); err != nil {
 				stderr = err.Error()
 			}
@@ -131,10 +187,17 @@ func (e taskExecutor) execTaskHost(i int, h string) func(ctx context.Context) {
 				Stdout: stdout,
 				StdErr: stderr,
 			}
+			e.event(callback.Event{
+				Type:   callback.EventHostResult,
+				Role:   e.task.Annotations[kkcorev1alpha1.TaskAnnotationRole],
+				TaskID: e.task.Name,
+				Task:   e.task.Spec.Name,
+				Host:   h,
+				Status: e.hostStatus(stdout, stderr),
+				Stdout: stdout,
+				Stderr: stderr,
+			})
 		}()
-		// task log
-		deferFunc := e.execTaskHostLogs(ctx, h, &stdout, &stderr)
-		defer deferFunc()
 		// task execute
 		ha, err := e.variable.Get(variable.GetAllVariable(h))
 		if err != nil {
@@ -154,6 +217,9 @@ func (e taskExecutor) execTaskHost(i int, h string) func(ctx context.Context) {
 		// execute module in loop with loop item.
 		// if loop is empty. execute once, and the item is null
 		for _, item := range e.dealLoop(had) {
+			if ctx.Err() != nil {
+				return
+			}
 			// set item to runtime variable
 			if err := e.variable.Merge(variable.MergeRuntimeVariable(map[string]any{
 				_const.VariableItem: item,
@@ -175,60 +241,21 @@ func (e taskExecutor) execTaskHost(i int, h string) func(ctx context.Context) {
 	}
 }
 
-// execTaskHostLogs logs for each host
-func (e taskExecutor) execTaskHostLogs(ctx context.Context, h string, stdout, stderr *string) func() {
-	// placeholder format task log
-	var placeholder string
-	if hostNameMaxLen, err := e.variable.Get(variable.GetHostMaxLength()); err == nil {
-		if hl, ok := hostNameMaxLen.(int); ok {
-			placeholder = strings.Repeat(" ", hl-len(h))
-		}
-	}
-	// progress bar for task
-	var bar = progressbar.NewOptions(-1,
-		progressbar.OptionSetWriter(e.logOutput),
-		progressbar.OptionSpinnerCustom([]string{"            "}),
-		progressbar.OptionEnableColorCodes(true),
-		progressbar.OptionSetDescription(fmt.Sprintf("[\033[36m%s\033[0m]%s \033[36mrunning\033[0m", h, placeholder)),
-		progressbar.OptionOnCompletion(func() {
-			if _, err := os.Stdout.WriteString("\n"); err != nil {
-				klog.ErrorS(err, "failed to write output", "host", h)
-			}
-		}),
-	)
-	// run progress
-	go func() {
-		err := wait.PollUntilContextCancel(ctx, 100*time.Millisecond, true, func(context.Context) (bool, error) {
-			if bar.IsFinished() {
-				return true, nil
-			}
-			if err := bar.Add(1); err != nil {
-				return false, err
-			}
-
-			return false, nil
-		})
-		if err != nil {
-			klog.ErrorS(err, "failed to wait for task run to finish", "host", h)
+// hostStatus convert the result of task in host to callback.Status.
+func (e taskExecutor) hostStatus(stdout, stderr string) callback.Status {
+	switch {
+	case stderr != "":
+		if e.task.Spec.IgnoreError != nil && *e.task.Spec.IgnoreError {
+			return callback.StatusIgnored
 		}
-	}()
 
-	return func() {
-		switch {
-		case *stderr != "":
-			if e.task.Spec.IgnoreError != nil && *e.task.Spec.IgnoreError { // ignore
-				bar.Describe(fmt.Sprintf("[\033[36m%s\033[0m]%s \033[34mignore \033[0m", h, placeholder))
-			} else { // failed
-				bar.Describe(fmt.Sprintf("[\033[36m%s\033[0m]%s \033[31mfailed \033[0m", h, placeholder))
-			}
-		case *stdout == modules.StdoutSkip: // skip
-			bar.Describe(fmt.Sprintf("[\033[36m%s\033[0m]%s \033[34mskip   \033[0m", h, placeholder))
-		default: //success
-			bar.Describe(fmt.Sprintf("[\033[36m%s\033[0m]%s \033[34msuccess\033[0m", h, placeholder))
-		}
-		if err := bar.Finish(); err != nil {
-			klog.ErrorS(err, "finish bar error")
-		}
+		return callback.StatusFailed
+	case stdout == modules.StdoutSkip:
+		return callback.StatusSkip
+	case modules.IsChanged(e.task.Spec.Module.Name, stdout, stderr):
+		return callback.StatusChanged
+	default:
+		return callback.StatusSuccess
 	}
 }
 
@@ -1,13 +1,20 @@
 package executor
 
 import (
+	"cmp"
 	"context"
+	"errors"
+	"fmt"
+	"slices"
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 
+	kkcorev1 "github.com/kubesphere/kubekey/v4/pkg/apis/core/v1"
 	kkcorev1alpha1 "github.com/kubesphere/kubekey/v4/pkg/apis/core/v1alpha1"
 )
 
@@ -69,3 +76,53 @@ func TestTaskExecutor(t *testing.T) {
 		})
 	}
 }
+
+func TestTaskExecutor_Cancel(t *testing.T) {
+	o, err := newTestOption()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancelCause(context.TODO())
+	cancel(errors.New("pipeline is cancelled"))
+
+	task := &kkcorev1alpha1.Task{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: corev1.NamespaceDefault,
+		},
+		Spec: kkcorev1alpha1.TaskSpec{
+			Name:  "test",
+			Hosts: []string{"node1"},
+			Module: kkcorev1alpha1.Module{
+				Name: "debug",
+				Args: runtime.RawExtension{Raw: []byte(`{"msg":"hello"}`)},
+			},
+		},
+	}
+	// task should not run when pipeline is cancelled.
+	err = taskExecutor{option: o, task: task}.Exec(ctx)
+	assert.ErrorContains(t, err, "pipeline is cancelled")
+	assert.Empty(t, task.Status.Phase)
+
+	// running task should be cancelled.
+	taskExecutor{option: o, task: task}.execTask(ctx)
+	assert.Equal(t, kkcorev1alpha1.TaskPhaseCancelled, task.Status.Phase)
+	assert.True(t, task.IsComplete())
+	assert.Equal(t, "pipeline is cancelled", task.Status.HostResults[0].StdErr)
+}
+
+func TestSlowestTasks(t *testing.T) {
+	var durations []kkcorev1.PipelineTaskDuration
+	for i := 1; i <= maxTaskDurations+5; i++ {
+		durations = slowestTasks(durations, kkcorev1.PipelineTaskDuration{
+			Task:     fmt.Sprintf("task%d", i),
+			Duration: metav1.Duration{Duration: time.Duration(i%7) * time.Second},
+		})
+	}
+
+	assert.Len(t, durations, maxTaskDurations)
+	assert.Equal(t, 6*time.Second, durations[0].Duration.Duration)
+	assert.True(t, slices.IsSortedFunc(durations, func(a, b kkcorev1.PipelineTaskDuration) int {
+		return cmp.Compare(b.Duration.Duration, a.Duration.Duration)
+	}))
+}
@@ -0,0 +1,370 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	kkprojectv1 "github.com/kubesphere/kubekey/v4/pkg/apis/project/v1"
+	"github.com/kubesphere/kubekey/v4/pkg/converter/tmpl"
+	"github.com/kubesphere/kubekey/v4/pkg/modules"
+	"github.com/kubesphere/kubekey/v4/pkg/project"
+)
+
+// Issue is a problem found in playbook.
+type Issue struct {
+	Play    string
+	Role    string
+	Task    string
+	Message string
+}
+
+// String of issue. format is: play[/role][/task]: message
+func (i Issue) String() string {
+	location := []string{i.Play}
+	if i.Role != "" {
+		location = append(location, i.Role)
+	}
+	if i.Task != "" {
+		location = append(location, i.Task)
+	}
+
+	return fmt.Sprintf("%s: %s", strings.Join(location, "/"), i.Message)
+}
+
+// Task is a task which will be executed in playbook.
+type Task struct {
+	Play   string
+	Role   string
+	Name   string
+	Module string
+	// Tags of task, include the tags inherited from play, role and block.
+	Tags []string
+}
+
+// Result of Lint.
+type Result struct {
+	// Issues found in playbook. empty when the playbook is valid.
+	Issues []Issue
+	// Tasks in playbook by execute order. the tasks in dynamic include with template target are not contained.
+	Tasks []Task
+}
+
+// Tags return all tags defined in tasks. sorted and unique.
+func (r Result) Tags() []string {
+	var tags []string
+	for _, t := range r.Tasks {
+		for _, tag := range t.Tags {
+			if !slices.Contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	sort.Strings(tags)
+
+	return tags
+}
+
+type linter struct {
+	project project.Project
+	result  Result
+	// play and role of the blocks which is checking.
+	play string
+	role string
+	// included is the include targets which has checked. avoid recursive include.
+	included map[string]bool
+}
+
+// Lint check the playbook without connecting to any host.
+// it checks every task maps to a registered module with valid args, the syntax of templates,
+// and the files/templates/include_tasks/include_role used by tasks can be found in project.
+func Lint(pj project.Project, pb *kkprojectv1.Playbook) Result {
+	l := &linter{project: pj, included: make(map[string]bool)}
+	for i, play := range pb.Play {
+		l.play = play.Name
+		if l.play == "" {
+			l.play = fmt.Sprintf("play[%d]", i)
+		}
+		l.role = ""
+		l.checkVars("", play.Vars)
+		// the same order as execute: "pre_tasks" > "roles" > "tasks" > "post_tasks"
+		l.checkBlocks(play.PreTasks, play.Taggable, false)
+		for _, role := range play.Roles {
+			l.checkRole(role, play.Taggable)
+		}
+		l.role = ""
+		l.checkBlocks(play.Tasks, play.Taggable, false)
+		l.checkBlocks(play.PostTasks, play.Taggable, false)
+		l.checkBlocks(play.Handlers, play.Taggable, true)
+	}
+
+	return l.result
+}
+
+func (l *linter) issue(task string, format string, args ...any) {
+	l.result.Issues = append(l.result.Issues, Issue{Play: l.play, Role: l.role, Task: task, Message: fmt.Sprintf(format, args...)})
+}
+
+// checkRole check the blocks and handlers of role.
+func (l *linter) checkRole(role kkprojectv1.Role, tags kkprojectv1.Taggable) {
+	l.role = role.Role
+	l.checkVars("", role.Vars)
+	l.checkWhen("", "when", role.When.Data)
+	tags = kkprojectv1.JoinTag(role.Taggable, tags)
+	l.checkBlocks(role.Block, tags, false)
+	l.checkBlocks(role.Handlers, tags, true)
+}
+
+// checkBlocks check blocks recursively. tasks in handlers are checked but not listed.
+func (l *linter) checkBlocks(blocks []kkprojectv1.Block, parentTags kkprojectv1.Taggable, handler bool) {
+	for _, block := range blocks {
+		tags := kkprojectv1.JoinTag(block.Taggable, parentTags)
+		l.checkString(block.Name, "name", block.Name)
+		l.checkVars(block.Name, block.Vars)
+		l.checkWhen(block.Name, "when", block.When.Data)
+		l.checkWhen(block.Name, "changed_when", block.ChangedWhen.Data)
+		l.checkWhen(block.Name, "failed_when", block.FailedWhen.Data)
+		l.checkWhen(block.Name, "until", block.Until.Data)
+		if s, ok := block.Loop.(string); ok {
+			l.checkString(block.Name, "loop", s)
+		}
+
+		switch {
+		case block.IsInclude():
+			l.checkInclude(block, tags, handler)
+		case len(block.Block) != 0, block.ImportTasks != "", block.ImportRole != nil:
+			// import tasks and import role has converted to blocks.
+			role := l.role
+			if block.ImportRole != nil {
+				l.role = block.ImportRole.Name
+			}
+			l.checkBlocks(block.Block, tags, handler)
+			l.checkBlocks(block.Rescue, tags, handler)
+			l.checkBlocks(block.Always, tags, handler)
+			l.role = role
+		default:
+			l.checkTask(block, tags, handler)
+		}
+	}
+}
+
+// checkInclude load the target of "include_tasks" and "include_role" and check them.
+// the target which contains template syntax is evaluated at execution time, only its syntax is checked.
+func (l *linter) checkInclude(block kkprojectv1.Block, tags kkprojectv1.Taggable, handler bool) {
+	target := block.IncludeTasks
+	if block.IncludeRole != nil {
+		target = block.IncludeRole.Name
+	}
+	if tmpl.IsTmplSyntax(target) {
+		l.checkString(block.Name, "include", target)
+
+		return
+	}
+	key := l.role + "/" + target
+	if block.IncludeRole != nil {
+		key = "role:" + target + "/" + block.IncludeRole.TasksFrom
+	}
+	if l.included[key] {
+		return
+	}
+	l.included[key] = true
+
+	if block.IncludeRole == nil {
+		blocks, err := l.project.MarshalBlock(target, l.role)
+		if err != nil {
+			l.issue(block.Name, "load include_tasks %q error: %v", target, err)
+
+			return
+		}
+		l.checkBlocks(blocks, tags, handler)
+
+		return
+	}
+
+	roles, err := l.project.MarshalRole(kkprojectv1.Role{RoleInfo: kkprojectv1.RoleInfo{
+		Base: kkprojectv1.Base{Vars: block.Vars},
+		Role: target,
+	}}, block.IncludeRole.TasksFrom)
+	if err != nil {
+		l.issue(block.Name, "load include_role %q error: %v", target, err)
+
+		return
+	}
+	parent := l.role
+	for _, role := range roles {
+		l.checkRole(role, tags)
+	}
+	l.role = parent
+}
+
+// checkTask check the module and args of task, the same as how task is converted at execution time.
+func (l *linter) checkTask(block kkprojectv1.Block, tags kkprojectv1.Taggable, handler bool) {
+	var module string
+	for _, n := range sortedKeys(block.UnknownField) {
+		if modules.FindModule(n) == nil {
+			l.issue(block.Name, "unknown field %q. it's neither a task keyword nor a registered module", n)
+
+			continue
+		}
+		if module != "" {
+			l.issue(block.Name, "multiple modules %q and %q in task", module, n)
+
+			continue
+		}
+		module = n
+	}
+	name := block.Name
+	if name == "" {
+		name = module
+	}
+	if module == "" {
+		l.issue(block.Name, "no module/action detected in task")
+
+		return
+	}
+	if !handler {
+		l.result.Tasks = append(l.result.Tasks, Task{Play: l.play, Role: l.role, Name: name, Module: module, Tags: tags.Tags})
+	}
+
+	data, err := json.Marshal(block.UnknownField[module])
+	if err != nil {
+		l.issue(name, "marshal args of module %q error: %v", module, err)
+
+		return
+	}
+	if err := modules.ValidateArgs(module, runtime.RawExtension{Raw: data}); err != nil {
+		l.issue(name, "%v", err)
+	}
+	l.checkValue(name, module, block.UnknownField[module])
+
+	args, ok := block.UnknownField[module].(map[string]any)
+	if !ok {
+		return
+	}
+	src, ok := args["src"].(string)
+	if !ok || src == "" || tmpl.IsTmplSyntax(src) || filepath.IsAbs(src) {
+		// the src is rendered or read from local path at execution time.
+		return
+	}
+	switch module {
+	case "template":
+		l.checkTemplateFile(name, src)
+	case "copy":
+		if _, err := l.project.Stat(src, project.GetFileOption{IsFile: true, Role: l.role}); err != nil {
+			l.issue(name, "cannot find file %q in project", src)
+		}
+	}
+}
+
+// checkTemplateFile check the template file (or all files in the template dir) can be found and parsed.
+func (l *linter) checkTemplateFile(task, src string) {
+	option := project.GetFileOption{IsTemplate: true, Role: l.role}
+	info, err := l.project.Stat(src, option)
+	if err != nil {
+		l.issue(task, "cannot find template %q in project", src)
+
+		return
+	}
+	if !info.IsDir() {
+		l.checkTemplateContent(task, src, option)
+
+		return
+	}
+	if err := l.project.WalkDir(src, option, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		// the path from WalkDir is the full path, read it as default project file.
+		l.checkTemplateContent(task, path, project.GetFileOption{Role: l.role})
+
+		return nil
+	}); err != nil {
+		l.issue(task, "walk template dir %q error: %v", src, err)
+	}
+}
+
+func (l *linter) checkTemplateContent(task, path string, option project.GetFileOption) {
+	data, err := l.project.ReadFile(path, option)
+	if err != nil {
+		l.issue(task, "read template %q error: %v", path, err)
+
+		return
+	}
+	if err := tmpl.Check(string(data), false); err != nil {
+		// the error wrapped by Check contains the whole content of file. only show the parse error.
+		l.issue(task, "template %q: %v", path, errors.Unwrap(err))
+	}
+}
+
+// checkWhen check the syntax of conditions.
+func (l *linter) checkWhen(task, field string, conditions []string) {
+	for _, c := range conditions {
+		if err := tmpl.Check(c, true); err != nil {
+			l.issue(task, "%s: %v", field, err)
+		}
+	}
+}
+
+// checkString check the syntax of string which may contain template.
+func (l *linter) checkString(task, field, s string) {
+	if err := tmpl.Check(s, false); err != nil {
+		l.issue(task, "%s: %v", field, err)
+	}
+}
+
+// checkVars check the syntax of all string in vars.
+func (l *linter) checkVars(task string, vars map[string]any) {
+	for _, k := range sortedKeys(vars) {
+		l.checkValue(task, "vars."+k, vars[k])
+	}
+}
+
+// checkValue check the syntax of all string in value recursively.
+func (l *linter) checkValue(task, field string, v any) {
+	switch val := v.(type) {
+	case string:
+		l.checkString(task, field, val)
+	case map[string]any:
+		for _, k := range sortedKeys(val) {
+			l.checkValue(task, field+"."+k, val[k])
+		}
+	case []any:
+		for i, sv := range val {
+			l.checkValue(task, fmt.Sprintf("%s[%d]", field, i), sv)
+		}
+	}
+}
+
+// sortedKeys of map. make the order of issues stable.
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	kkcorev1 "github.com/kubesphere/kubekey/v4/pkg/apis/core/v1"
+	"github.com/kubesphere/kubekey/v4/pkg/project"
+)
+
+func TestLint(t *testing.T) {
+	testcases := []struct {
+		name         string
+		playbook     string
+		exceptIssues []string
+		exceptTasks  []Task
+		exceptTags   []string
+	}{
+		{
+			name:     "valid",
+			playbook: "valid.yaml",
+			exceptTasks: []Task{
+				{Play: "play[0]", Role: "demo", Name: "generate config", Module: "template", Tags: []string{"config", "demo"}},
+				{Play: "play[0]", Role: "demo", Name: "echo other", Module: "command", Tags: []string{"demo"}},
+				{Play: "play[0]", Name: "print hello", Module: "debug", Tags: []string{"demo"}},
+			},
+			exceptTags: []string{"config", "demo"},
+		},
+		{
+			name:     "invalid",
+			playbook: "invalid.yaml",
+			exceptIssues: []string{
+				`play[0]/unknown module: unknown field "apt". it's neither a task keyword nor a registered module`,
+				`play[0]/unknown module: no module/action detected in task`,
+				`play[0]/bad when: when: failed to parse template '{{ .foo | eq "a") }}': template: kubekey:1: unexpected right paren`,
+				`play[0]/missing dest: unknown "desc" in args of module "template"`,
+				`play[0]/missing template: cannot find template "missing.config" in project`,
+				`play[0]/bad template: template "bad.config": template: kubekey:1: unexpected right paren`,
+				`play[0]/missing include: load include_tasks "missing.yaml" error: read file missing.yaml failed: open missing.yaml: no such file or directory`,
+			},
+			exceptTasks: []Task{
+				{Play: "play[0]", Name: "bad when", Module: "command"},
+				{Play: "play[0]", Name: "missing dest", Module: "template"},
+				{Play: "play[0]", Name: "missing template", Module: "template"},
+				{Play: "play[0]", Name: "bad template", Module: "template"},
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			playbook, err := filepath.Abs(filepath.Join("testdata", "playbooks", tc.playbook))
+			if err != nil {
+				t.Fatal(err)
+			}
+			pj, err := project.New(context.TODO(), kkcorev1.Pipeline{Spec: kkcorev1.PipelineSpec{Playbook: playbook}}, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			pb, err := pj.MarshalPlaybook()
+			if err != nil {
+				t.Fatal(err)
+			}
+			result := Lint(pj, pb)
+			issues := make([]string, 0, len(result.Issues))
+			for _, i := range result.Issues {
+				issues = append(issues, i.String())
+			}
+			assert.ElementsMatch(t, tc.exceptIssues, issues)
+			assert.Equal(t, tc.exceptTasks, result.Tasks)
+			assert.Equal(t, tc.exceptTags, result.Tags())
+		})
+	}
+}
+
+// TestLintBuiltin lints every playbook of the builtin project.
+func TestLintBuiltin(t *testing.T) {
+	playbooks, err := filepath.Glob(filepath.Join("..", "..", "builtin", "playbooks", "*.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NotEmpty(t, playbooks)
+
+	for _, playbook := range playbooks {
+		t.Run(filepath.Base(playbook), func(t *testing.T) {
+			playbook, err := filepath.Abs(playbook)
+			if err != nil {
+				t.Fatal(err)
+			}
+			pj, err := project.New(context.TODO(), kkcorev1.Pipeline{Spec: kkcorev1.PipelineSpec{Playbook: playbook}}, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			pb, err := pj.MarshalPlaybook()
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, issue := range Lint(pj, pb).Issues {
+				t.Error(issue.String())
+			}
+		})
+	}
+}
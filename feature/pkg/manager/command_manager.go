@@ -18,19 +18,27 @@ package manager
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"time"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 
 	kkcorev1 "github.com/kubesphere/kubekey/v4/pkg/apis/core/v1"
+	"github.com/kubesphere/kubekey/v4/pkg/callback"
 	_const "github.com/kubesphere/kubekey/v4/pkg/const"
 	"github.com/kubesphere/kubekey/v4/pkg/executor"
 )
 
+// cancelCheckPeriod is the period to check whether the pipeline is cancelled.
+const cancelCheckPeriod = 5 * time.Second
+
 type commandManager struct {
 	*kkcorev1.Pipeline
 	*kkcorev1.Config
@@ -39,31 +47,44 @@ type commandManager struct {
 	ctrlclient.Client
 
 	logOutput io.Writer
+	// callbacks is the specs of callback. see callback.New
+	callbacks []string
 }
 
 // Run command Manager. print log and run pipeline executor.
 func (m *commandManager) Run(ctx context.Context) error {
-	fmt.Fprint(m.logOutput, `
-
- _   __      _          _   __           
-| | / /     | |        | | / /           
-| |/ / _   _| |__   ___| |/ /  ___ _   _ 
-|    \| | | | '_ \ / _ \    \ / _ \ | | |
-| |\  \ |_| | |_) |  __/ |\  \  __/ |_| |
-\_| \_/\__,_|_.__/ \___\_| \_/\___|\__, |
-                                    __/ |
-                                   |___/
-
-`)
-	fmt.Fprintf(m.logOutput, "%s [Pipeline %s] start\n", time.Now().Format(time.TimeOnly+" MST"), ctrlclient.ObjectKeyFromObject(m.Pipeline))
+	cb, err := callback.New(m.callbacks, m.logOutput)
+	if err != nil {
+		return fmt.Errorf("create callback error: %w", err)
+	}
+	// the full output of tasks is always stored in log dir. it can be read by "kk logs".
+	cb = callback.Join(cb, callback.NewTaskLogCallback(_const.GetLogDir()))
+	defer func() {
+		if err := cb.Close(); err != nil {
+			klog.ErrorS(err, "close callback error", "pipeline", ctrlclient.ObjectKeyFromObject(m.Pipeline))
+		}
+	}()
+	cb.OnEvent(callback.Event{
+		Type:     callback.EventPipelineStart,
+		Pipeline: ctrlclient.ObjectKeyFromObject(m.Pipeline).String(),
+	})
 	cp := m.Pipeline.DeepCopy()
+	m.Pipeline.Status.StartTimestamp = ptr.To(metav1.Now())
+	// pipeline of cronJob may run many times. only keep the durations of this run.
+	m.Pipeline.Status.TaskDurations = nil
+	m.Pipeline.Status.TaskDurationHistogram = nil
 	defer func() {
-		fmt.Fprintf(m.logOutput, "%s [Pipeline %s] finish. total: %v,success: %v,ignored: %v,failed: %v\n", time.Now().Format(time.TimeOnly+" MST"), ctrlclient.ObjectKeyFromObject(m.Pipeline),
-			m.Pipeline.Status.TaskResult.Total, m.Pipeline.Status.TaskResult.Success, m.Pipeline.Status.TaskResult.Ignored, m.Pipeline.Status.TaskResult.Failed)
+		m.Pipeline.Status.CompletionTimestamp = ptr.To(metav1.Now())
+		cb.OnEvent(callback.Event{
+			Type:       callback.EventPipelineEnd,
+			Pipeline:   ctrlclient.ObjectKeyFromObject(m.Pipeline).String(),
+			Phase:      m.Pipeline.Status.Phase,
+			TaskResult: m.Pipeline.Status.TaskResult.DeepCopy(),
+		})
 		go func() {
 			if !m.Pipeline.Spec.Debug && m.Pipeline.Status.Phase == kkcorev1.PipelinePhaseSucceed {
 				<-ctx.Done()
-				fmt.Fprintf(m.logOutput, "%s [Pipeline %s] clean runtime directory\n", time.Now().Format(time.TimeOnly+" MST"), ctrlclient.ObjectKeyFromObject(m.Pipeline))
+				klog.InfoS("clean runtime directory", "pipeline", ctrlclient.ObjectKeyFromObject(m.Pipeline))
 				// clean runtime directory
 				if err := os.RemoveAll(_const.GetRuntimeDir()); err != nil {
 					klog.ErrorS(err, "clean runtime directory error", "pipeline", ctrlclient.ObjectKeyFromObject(m.Pipeline), "runtime_dir", _const.GetRuntimeDir())
@@ -74,20 +95,54 @@ func (m *commandManager) Run(ctx context.Context) error {
 		if m.Pipeline.Spec.JobSpec.Schedule != "" {
 			m.Pipeline.Status.Phase = kkcorev1.PipelinePhaseRunning
 		}
-		// update pipeline status
-		if err := m.Client.Status().Patch(ctx, m.Pipeline, ctrlclient.MergeFrom(cp)); err != nil {
+		// update pipeline status. ctx may be cancelled by signal.
+		if err := m.Client.Status().Patch(context.WithoutCancel(ctx), m.Pipeline, ctrlclient.MergeFrom(cp)); err != nil {
 			klog.ErrorS(err, "update pipeline error", "pipeline", ctrlclient.ObjectKeyFromObject(m.Pipeline))
 		}
 	}()
 
+	// execCtx is cancelled when pipeline is cancelled, or reach the timeout of pipeline.
+	execCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	if m.Pipeline.Spec.Timeout != nil && m.Pipeline.Spec.Timeout.Duration > 0 {
+		var cancelTimeout context.CancelFunc
+		execCtx, cancelTimeout = context.WithTimeoutCause(execCtx, m.Pipeline.Spec.Timeout.Duration,
+			fmt.Errorf("pipeline timeout after %s", m.Pipeline.Spec.Timeout.Duration))
+		defer cancelTimeout()
+	}
+	go m.watchCancel(execCtx, cancel)
+
 	m.Pipeline.Status.Phase = kkcorev1.PipelinePhaseSucceed
-	if err := executor.NewPipelineExecutor(ctx, m.Client, m.Pipeline, m.logOutput).Exec(ctx); err != nil {
+	if err := executor.NewPipelineExecutor(execCtx, m.Client, m.Pipeline, cb).Exec(execCtx); err != nil {
 		klog.ErrorS(err, "executor tasks error", "pipeline", ctrlclient.ObjectKeyFromObject(m.Pipeline))
 		m.Pipeline.Status.Phase = kkcorev1.PipelinePhaseFailed
 		m.Pipeline.Status.Reason = err.Error()
+		switch {
+		case errors.Is(execCtx.Err(), context.Canceled):
+			m.Pipeline.Status.Phase = kkcorev1.PipelinePhaseCancelled
+			m.Pipeline.Status.Reason = context.Cause(execCtx).Error()
+		case execCtx.Err() != nil: // timeout
+			m.Pipeline.Status.Reason = context.Cause(execCtx).Error()
+		}
 
 		return err
 	}
 
 	return nil
 }
+
+// watchCancel cancel the execution when spec.cancel of pipeline is set.
+func (m *commandManager) watchCancel(ctx context.Context, cancel context.CancelCauseFunc) {
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		pipeline := &kkcorev1.Pipeline{}
+		if err := m.Client.Get(ctx, ctrlclient.ObjectKeyFromObject(m.Pipeline), pipeline); err != nil {
+			klog.V(5).ErrorS(err, "get pipeline error", "pipeline", ctrlclient.ObjectKeyFromObject(m.Pipeline))
+
+			return
+		}
+		if pipeline.Spec.Cancel {
+			klog.InfoS("pipeline is cancelled", "pipeline", ctrlclient.ObjectKeyFromObject(m.Pipeline))
+			cancel(errors.New("pipeline is cancelled"))
+		}
+	}, cancelCheckPeriod)
+}
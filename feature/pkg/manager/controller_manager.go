@@ -23,6 +23,8 @@ import (
 	"k8s.io/client-go/rest"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	_const "github.com/kubesphere/kubekey/v4/pkg/const"
 	"github.com/kubesphere/kubekey/v4/pkg/controllers"
@@ -32,6 +34,9 @@ import (
 type controllerManager struct {
 	MaxConcurrentReconciles int
 	LeaderElection          bool
+	Webhook                 bool
+	WebhookPort             int
+	MetricsBindAddress      string
 }
 
 // Run controllerManager, run controller in kubernetes
@@ -51,6 +56,12 @@ func (c controllerManager) Run(ctx context.Context) error {
 		Scheme:           _const.Scheme,
 		LeaderElection:   c.LeaderElection,
 		LeaderElectionID: "controller-leader-election-kk",
+		Metrics: metricsserver.Options{
+			BindAddress: c.MetricsBindAddress,
+		},
+		WebhookServer: webhook.NewServer(webhook.Options{
+			Port: c.WebhookPort,
+		}),
 	})
 	if err != nil {
 		return fmt.Errorf("could not create controller manager: %w", err)
@@ -66,6 +77,13 @@ func (c controllerManager) Run(ctx context.Context) error {
 
 		return err
 	}
+	if c.Webhook {
+		if err := controllers.SetupWebhookWithManager(mgr); err != nil {
+			klog.ErrorS(err, "create webhook error")
+
+			return err
+		}
+	}
 
 	return mgr.Start(ctx)
 }
@@ -38,6 +38,8 @@ type CommandManagerOptions struct {
 	*kkcorev1.Inventory
 
 	ctrlclient.Client
+	// Callbacks receive the events of pipeline. format: name[=path]. default is progress.
+	Callbacks []string
 }
 
 // NewCommandManager return a new commandManager
@@ -48,6 +50,7 @@ func NewCommandManager(o CommandManagerOptions) Manager {
 		Inventory: o.Inventory,
 		Client:    o.Client,
 		logOutput: os.Stdout,
+		callbacks: o.Callbacks,
 	}
 }
 
@@ -55,6 +58,9 @@ func NewCommandManager(o CommandManagerOptions) Manager {
 type ControllerManagerOptions struct {
 	MaxConcurrentReconciles int
 	LeaderElection          bool
+	Webhook                 bool
+	WebhookPort             int
+	MetricsBindAddress      string
 }
 
 // NewControllerManager return a new controllerManager
@@ -62,5 +68,8 @@ func NewControllerManager(o ControllerManagerOptions) Manager {
 	return &controllerManager{
 		MaxConcurrentReconciles: o.MaxConcurrentReconciles,
 		LeaderElection:          o.LeaderElection,
+		Webhook:                 o.Webhook,
+		WebhookPort:             o.WebhookPort,
+		MetricsBindAddress:      o.MetricsBindAddress,
 	}
 }
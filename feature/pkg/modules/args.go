@@ -0,0 +1,119 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package modules
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/kubesphere/kubekey/v4/pkg/converter/tmpl"
+)
+
+// argSpec is the args which module accepted.
+type argSpec struct {
+	required []string
+	optional []string
+}
+
+// argSpecOf parse the argSpec from the "arg" tag of the module's args struct.
+// the tag is the key of args, followed by ",required" if the key is required.
+// fields without the tag are not read from args directly.
+func argSpecOf(args any) argSpec {
+	var spec argSpec
+	t := reflect.TypeOf(args)
+	for i := range t.NumField() {
+		tag, ok := t.Field(i).Tag.Lookup("arg")
+		if !ok {
+			continue
+		}
+		if key, found := strings.CutSuffix(tag, ",required"); found {
+			spec.required = append(spec.required, key)
+		} else {
+			spec.optional = append(spec.optional, key)
+		}
+	}
+
+	return spec
+}
+
+// moduleArgs of the modules which args is a map with fixed keys.
+// modules not in it (such as "command" or "set_fact") accept any args.
+var moduleArgs = map[string]argSpec{
+	"assert":                 argSpecOf(assertArgs{}),
+	"blockinfile":            argSpecOf(blockInFileArgs{}),
+	"copy":                   argSpecOf(copyArgs{}),
+	"debug":                  argSpecOf(debugArgs{}),
+	"fetch":                  argSpecOf(fetchArgs{}),
+	"file":                   argSpecOf(fileArgs{}),
+	"gen_cert":               argSpecOf(genCertArgs{}),
+	"get_url":                argSpecOf(getURLArgs{}),
+	"image":                  argSpecOf(imageArgs{}),
+	"lineinfile":             argSpecOf(lineInFileArgs{}),
+	"mount":                  argSpecOf(mountArgs{}),
+	"service":                argSpecOf(serviceArgs{}),
+	"sysctl":                 argSpecOf(sysctlArgs{}),
+	"template":               argSpecOf(templateArgs{}),
+	"unarchive":              argSpecOf(unarchiveArgs{}),
+	"validate_argument_spec": argSpecOf(validateArgumentSpecArgs{}),
+	"wait_for":               argSpecOf(waitForArgs{}),
+}
+
+// ValidateArgs check the module is registered and its args without executing it.
+// the value of args is not checked, because it may be rendered by variables at execution time.
+func ValidateArgs(moduleName string, raw runtime.RawExtension) error {
+	if FindModule(moduleName) == nil {
+		return fmt.Errorf("module %q is not registered", moduleName)
+	}
+	spec, ok := moduleArgs[moduleName]
+	if !ok {
+		return nil
+	}
+
+	var args map[string]any
+	if err := json.Unmarshal(raw.Raw, &args); err != nil {
+		var s string
+		if err := json.Unmarshal(raw.Raw, &s); err == nil && tmpl.IsTmplSyntax(s) {
+			// the args is rendered at execution time.
+			return nil
+		}
+
+		return fmt.Errorf("args of module %q should be map", moduleName)
+	}
+	keys := make([]string, 0, len(args))
+	for key := range args {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if !slices.Contains(spec.required, key) && !slices.Contains(spec.optional, key) {
+			return fmt.Errorf("unknown %q in args of module %q", key, moduleName)
+		}
+	}
+	for _, key := range spec.required {
+		if _, ok := args[key]; !ok {
+			return fmt.Errorf("%q is required in args of module %q", key, moduleName)
+		}
+	}
+
+	return nil
+}
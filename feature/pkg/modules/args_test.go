@@ -0,0 +1,94 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package modules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestValidateArgs(t *testing.T) {
+	testcases := []struct {
+		name   string
+		module string
+		args   string
+		hasErr bool
+	}{
+		{
+			name:   "valid template",
+			module: "template",
+			args:   `{"src": "a.config", "dest": "/etc/a.config", "mode": 420}`,
+		},
+		{
+			name:   "unknown arg",
+			module: "template",
+			args:   `{"src": "a.config", "desc": "/etc/a.config"}`,
+			hasErr: true,
+		},
+		{
+			name:   "missing required arg",
+			module: "copy",
+			args:   `{"src": "a.config"}`,
+			hasErr: true,
+		},
+		{
+			name:   "args is not map",
+			module: "copy",
+			args:   `"a.config"`,
+			hasErr: true,
+		},
+		{
+			name:   "args is template",
+			module: "copy",
+			args:   `"{{ .copy_args }}"`,
+		},
+		{
+			name:   "any args",
+			module: "command",
+			args:   `"echo hello"`,
+		},
+		{
+			name:   "not registered",
+			module: "apt",
+			args:   `{"name": "curl"}`,
+			hasErr: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateArgs(tc.module, runtime.RawExtension{Raw: []byte(tc.args)})
+			if tc.hasErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestArgSpecOf(t *testing.T) {
+	assert.Equal(t, argSpec{
+		required: []string{"url", "dest"},
+		optional: []string{"checksum", "mode", "validate_certs", "delegate_to"},
+	}, argSpecOf(getURLArgs{}))
+	assert.Equal(t, argSpec{
+		optional: []string{"var", "msg"},
+	}, argSpecOf(debugArgs{}))
+}
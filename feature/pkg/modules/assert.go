@@ -30,10 +30,10 @@ import (
 )
 
 type assertArgs struct {
-	that       []string
-	successMsg string
-	failMsg    string // high priority than msg
-	msg        string
+	that       []string `arg:"that,required"`
+	successMsg string   `arg:"success_msg"`
+	failMsg    string   `arg:"fail_msg"` // high priority than msg
+	msg        string   `arg:"msg"`
 }
 
 func newAssertArgs(_ context.Context, raw runtime.RawExtension, vars map[string]any) (*assertArgs, error) {
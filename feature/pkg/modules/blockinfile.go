@@ -0,0 +1,180 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package modules
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kubesphere/kubekey/v4/pkg/variable"
+)
+
+// defaultBlockMarker is the marker line of block. "{mark}" will be replaced by marker_begin or marker_end.
+const defaultBlockMarker = "# {mark} KUBEKEY MANAGED BLOCK"
+
+type blockInFileArgs struct {
+	path         string `arg:"path,required"`
+	block        string `arg:"block"`
+	marker       string `arg:"marker"`
+	markerBegin  string `arg:"marker_begin"`
+	markerEnd    string `arg:"marker_end"`
+	state        string `arg:"state"`
+	insertAfter  string `arg:"insertafter"`
+	insertBefore string `arg:"insertbefore"`
+	create       bool   `arg:"create"`
+}
+
+func newBlockInFileArgs(_ context.Context, raw runtime.RawExtension, vars map[string]any) (*blockInFileArgs, error) {
+	var err error
+	ba := &blockInFileArgs{}
+	args := variable.Extension2Variables(raw)
+	ba.path, err = variable.StringVar(vars, args, "path")
+	if err != nil {
+		return nil, errors.New("\"path\" in args should be string")
+	}
+	ba.block, _ = variable.StringVar(vars, args, "block")
+	ba.marker, _ = variable.StringVar(vars, args, "marker")
+	if ba.marker == "" {
+		ba.marker = defaultBlockMarker
+	}
+	ba.markerBegin, _ = variable.StringVar(vars, args, "marker_begin")
+	if ba.markerBegin == "" {
+		ba.markerBegin = "BEGIN"
+	}
+	ba.markerEnd, _ = variable.StringVar(vars, args, "marker_end")
+	if ba.markerEnd == "" {
+		ba.markerEnd = "END"
+	}
+	ba.state, _ = variable.StringVar(vars, args, "state")
+	if ba.state == "" {
+		ba.state = lineStatePresent
+	}
+	ba.insertAfter, _ = variable.StringVar(vars, args, "insertafter")
+	ba.insertBefore, _ = variable.StringVar(vars, args, "insertbefore")
+	if create, err := variable.BoolVar(vars, args, "create"); err == nil {
+		ba.create = *create
+	}
+
+	if ba.state != lineStatePresent && ba.state != lineStateAbsent {
+		return nil, fmt.Errorf("\"state\" should be one of %q, %q", lineStatePresent, lineStateAbsent)
+	}
+	if !strings.Contains(ba.marker, "{mark}") {
+		return nil, errors.New("\"marker\" should contain \"{mark}\"")
+	}
+	if ba.insertAfter != "" && ba.insertBefore != "" {
+		return nil, errors.New("\"insertafter\" and \"insertbefore\" cannot be set at the same time")
+	}
+
+	return ba, nil
+}
+
+// ModuleBlockInFile deal "blockinfile" module
+func ModuleBlockInFile(ctx context.Context, options ExecOptions) (string, string) {
+	// get host variable
+	ha, err := options.getAllVariables()
+	if err != nil {
+		return "", err.Error()
+	}
+
+	ba, err := newBlockInFileArgs(ctx, options.Args, ha)
+	if err != nil {
+		klog.V(4).ErrorS(err, "get blockinfile args error", "task", ctrlclient.ObjectKeyFromObject(&options.Task))
+
+		return "", err.Error()
+	}
+
+	// get connector
+	conn, err := getConnector(ctx, options.Host, ha)
+	if err != nil {
+		return "", fmt.Sprintf("get connector error: %v", err)
+	}
+	defer conn.Close(ctx)
+
+	content, mode, exist, err := readRemoteFile(ctx, conn, ba.path)
+	if err != nil {
+		return "", err.Error()
+	}
+	if !exist {
+		if ba.state == lineStateAbsent {
+			// nothing to remove
+			return StdoutSuccess, ""
+		}
+		if !ba.create {
+			return "", fmt.Sprintf("file %s is not exist", ba.path)
+		}
+	}
+	result, err := ba.apply(content)
+	if err != nil {
+		return "", err.Error()
+	}
+	if bytes.Equal(content, result) {
+		return StdoutSuccess, ""
+	}
+	if err := conn.PutFile(ctx, result, ba.path, mode); err != nil {
+		return "", fmt.Sprintf("write file %s error: %v", ba.path, err)
+	}
+
+	return StdoutChanged, ""
+}
+
+// apply blockinfile to the file content and return the new content.
+func (ba blockInFileArgs) apply(content []byte) ([]byte, error) {
+	lines := splitLines(content)
+	begin := strings.ReplaceAll(ba.marker, "{mark}", ba.markerBegin)
+	end := strings.ReplaceAll(ba.marker, "{mark}", ba.markerEnd)
+	// find the exist block
+	beginIndex, endIndex := -1, -1
+	for i, l := range lines {
+		if l == begin && beginIndex == -1 {
+			beginIndex = i
+		}
+		if l == end && beginIndex != -1 && i > beginIndex {
+			endIndex = i
+
+			break
+		}
+	}
+
+	var block []string
+	if ba.state == lineStatePresent && ba.block != "" {
+		block = append([]string{begin}, splitLines([]byte(ba.block))...)
+		block = append(block, end)
+	}
+
+	if beginIndex != -1 && endIndex != -1 {
+		lines = slices.Replace(lines, beginIndex, endIndex+1, block...)
+
+		return joinLines(lines), nil
+	}
+	if len(block) == 0 {
+		return joinLines(lines), nil
+	}
+	index, err := insertIndex(lines, ba.insertAfter, ba.insertBefore)
+	if err != nil {
+		return nil, err
+	}
+
+	return joinLines(slices.Insert(lines, index, block...)), nil
+}
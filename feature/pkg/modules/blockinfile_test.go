@@ -0,0 +1,70 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package modules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlockInFileApply(t *testing.T) {
+	args := blockInFileArgs{marker: defaultBlockMarker, markerBegin: "BEGIN", markerEnd: "END", state: lineStatePresent}
+	testcases := []struct {
+		name    string
+		block   string
+		state   string
+		content string
+		except  string
+	}{
+		{
+			name:    "insert block",
+			block:   "10.0.0.1 node1\n10.0.0.2 node2",
+			state:   lineStatePresent,
+			content: "127.0.0.1 localhost\n",
+			except:  "127.0.0.1 localhost\n# BEGIN KUBEKEY MANAGED BLOCK\n10.0.0.1 node1\n10.0.0.2 node2\n# END KUBEKEY MANAGED BLOCK\n",
+		},
+		{
+			name:    "replace block",
+			block:   "10.0.0.3 node3",
+			state:   lineStatePresent,
+			content: "# BEGIN KUBEKEY MANAGED BLOCK\n10.0.0.1 node1\n# END KUBEKEY MANAGED BLOCK\n127.0.0.1 localhost\n",
+			except:  "# BEGIN KUBEKEY MANAGED BLOCK\n10.0.0.3 node3\n# END KUBEKEY MANAGED BLOCK\n127.0.0.1 localhost\n",
+		},
+		{
+			name:    "remove block",
+			state:   lineStateAbsent,
+			content: "# BEGIN KUBEKEY MANAGED BLOCK\n10.0.0.1 node1\n# END KUBEKEY MANAGED BLOCK\n127.0.0.1 localhost\n",
+			except:  "127.0.0.1 localhost\n",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ba := args
+			ba.block = tc.block
+			ba.state = tc.state
+			result, err := ba.apply([]byte(tc.content))
+			assert.NoError(t, err)
+			assert.Equal(t, tc.except, string(result))
+			// apply twice should not change the content.
+			again, err := ba.apply(result)
+			assert.NoError(t, err)
+			assert.Equal(t, string(result), string(again))
+		})
+	}
+}
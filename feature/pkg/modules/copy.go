@@ -37,10 +37,10 @@ import (
 )
 
 type copyArgs struct {
-	src     string
-	content string
-	dest    string
-	mode    *int
+	src     string `arg:"src"`
+	content string `arg:"content"`
+	dest    string `arg:"dest,required"`
+	mode    *int   `arg:"mode"`
 }
 
 func newCopyArgs(_ context.Context, raw runtime.RawExtension, vars map[string]any) (*copyArgs, error) {
@@ -92,6 +92,7 @@ func ModuleCopy(ctx context.Context, options ExecOptions) (string, string) {
 
 // copySrc copy src file to dest
 func (ca copyArgs) copySrc(ctx context.Context, options ExecOptions, conn connector.Connector) (string, string) {
+	var changed bool
 	if filepath.IsAbs(ca.src) { // if src is absolute path. find it in local path
 		fileInfo, err := os.Stat(ca.src)
 		if err != nil {
@@ -99,11 +100,11 @@ func (ca copyArgs) copySrc(ctx context.Context, options ExecOptions, conn connec
 		}
 
 		if fileInfo.IsDir() { // src is dir
-			if err := ca.absDir(ctx, conn); err != nil {
+			if changed, err = ca.absDir(ctx, conn); err != nil {
 				return "", fmt.Sprintf("sync copy absolute dir error %s", err)
 			}
 		} else { // src is file
-			if err := ca.absFile(ctx, fileInfo.Mode(), conn); err != nil {
+			if changed, err = ca.absFile(ctx, fileInfo.Mode(), conn); err != nil {
 				return "", fmt.Sprintf("sync copy absolute dir error %s", err)
 			}
 		}
@@ -119,15 +120,18 @@ func (ca copyArgs) copySrc(ctx context.Context, options ExecOptions, conn connec
 		}
 
 		if fileInfo.IsDir() {
-			if err := ca.relDir(ctx, pj, options.Task.Annotations[kkcorev1alpha1.TaskAnnotationRole], conn); err != nil {
+			if changed, err = ca.relDir(ctx, pj, options.Task.Annotations[kkcorev1alpha1.TaskAnnotationRole], conn); err != nil {
 				return "", fmt.Sprintf("sync copy relative dir error %s", err)
 			}
 		} else {
-			if err := ca.relFile(ctx, pj, options.Task.Annotations[kkcorev1alpha1.TaskAnnotationRole], fileInfo.Mode(), conn); err != nil {
+			if changed, err = ca.relFile(ctx, pj, options.Task.Annotations[kkcorev1alpha1.TaskAnnotationRole], fileInfo.Mode(), conn); err != nil {
 				return "", fmt.Sprintf("sync copy relative dir error %s", err)
 			}
 		}
 	}
+	if changed {
+		return StdoutChanged, ""
+	}
 
 	return StdoutSuccess, ""
 }
@@ -142,18 +146,22 @@ func (ca copyArgs) copyContent(ctx context.Context, mode fs.FileMode, conn conne
 		mode = os.FileMode(*ca.mode)
 	}
 
-	if err := conn.PutFile(ctx, []byte(ca.content), ca.dest, mode); err != nil {
+	changed, err := putFileIfChanged(ctx, conn, []byte(ca.content), ca.dest, mode)
+	if err != nil {
 		return "", fmt.Sprintf("copy file error: %v", err)
 	}
+	if changed {
+		return StdoutChanged, ""
+	}
 
 	return StdoutSuccess, ""
 }
 
 // relFile when copy.src is relative dir, get all files from project, and copy to remote.
-func (ca copyArgs) relFile(ctx context.Context, pj project.Project, role string, mode fs.FileMode, conn connector.Connector) any {
+func (ca copyArgs) relFile(ctx context.Context, pj project.Project, role string, mode fs.FileMode, conn connector.Connector) (bool, error) {
 	data, err := pj.ReadFile(ca.src, project.GetFileOption{IsFile: true, Role: role})
 	if err != nil {
-		return fmt.Errorf("read file error: %w", err)
+		return false, fmt.Errorf("read file error: %w", err)
 	}
 
 	dest := ca.dest
@@ -165,15 +173,17 @@ func (ca copyArgs) relFile(ctx context.Context, pj project.Project, role string,
 		mode = os.FileMode(*ca.mode)
 	}
 
-	if err := conn.PutFile(ctx, data, dest, mode); err != nil {
-		return fmt.Errorf("copy file error: %w", err)
+	changed, err := putFileIfChanged(ctx, conn, data, dest, mode)
+	if err != nil {
+		return false, fmt.Errorf("copy file error: %w", err)
 	}
 
-	return nil
+	return changed, nil
 }
 
 // relDir when copy.src is relative dir, get all files from project, and copy to remote.
-func (ca copyArgs) relDir(ctx context.Context, pj project.Project, role string, conn connector.Connector) error {
+func (ca copyArgs) relDir(ctx context.Context, pj project.Project, role string, conn connector.Connector) (bool, error) {
+	var changed bool
 	if err := pj.WalkDir(ca.src, project.GetFileOption{IsFile: true, Role: role}, func(path string, d fs.DirEntry, err error) error {
 		if d.IsDir() { // only copy file
 			return nil
@@ -206,23 +216,25 @@ func (ca copyArgs) relDir(ctx context.Context, pj project.Project, role string,
 			dest = filepath.Join(ca.dest, rel)
 		}
 
-		if err := conn.PutFile(ctx, data, dest, mode); err != nil {
+		fileChanged, err := putFileIfChanged(ctx, conn, data, dest, mode)
+		if err != nil {
 			return fmt.Errorf("copy file error: %w", err)
 		}
+		changed = changed || fileChanged
 
 		return nil
 	}); err != nil {
-		return err
+		return false, err
 	}
 
-	return nil
+	return changed, nil
 }
 
 // absFile when copy.src is absolute file, get file from os, and copy to remote.
-func (ca copyArgs) absFile(ctx context.Context, mode fs.FileMode, conn connector.Connector) error {
+func (ca copyArgs) absFile(ctx context.Context, mode fs.FileMode, conn connector.Connector) (bool, error) {
 	data, err := os.ReadFile(ca.src)
 	if err != nil {
-		return fmt.Errorf("read file error: %w", err)
+		return false, fmt.Errorf("read file error: %w", err)
 	}
 
 	dest := ca.dest
@@ -234,15 +246,17 @@ func (ca copyArgs) absFile(ctx context.Context, mode fs.FileMode, conn connector
 		mode = os.FileMode(*ca.mode)
 	}
 
-	if err := conn.PutFile(ctx, data, dest, mode); err != nil {
-		return fmt.Errorf("copy file error: %w", err)
+	changed, err := putFileIfChanged(ctx, conn, data, dest, mode)
+	if err != nil {
+		return false, fmt.Errorf("copy file error: %w", err)
 	}
 
-	return nil
+	return changed, nil
 }
 
 // absDir when copy.src is absolute dir, get all files from os, and copy to remote.
-func (ca copyArgs) absDir(ctx context.Context, conn connector.Connector) error {
+func (ca copyArgs) absDir(ctx context.Context, conn connector.Connector) (bool, error) {
+	var changed bool
 	if err := filepath.WalkDir(ca.src, func(path string, d fs.DirEntry, err error) error {
 		if d.IsDir() { // only copy file
 			return nil
@@ -276,14 +290,16 @@ func (ca copyArgs) absDir(ctx context.Context, conn connector.Connector) error {
 			dest = filepath.Join(ca.dest, rel)
 		}
 
-		if err := conn.PutFile(ctx, data, dest, mode); err != nil {
+		fileChanged, err := putFileIfChanged(ctx, conn, data, dest, mode)
+		if err != nil {
 			return fmt.Errorf("copy file error: %w", err)
 		}
+		changed = changed || fileChanged
 
 		return nil
 	}); err != nil {
-		return err
+		return false, err
 	}
 
-	return nil
+	return changed, nil
 }
@@ -18,6 +18,8 @@ package modules
 
 import (
 	"context"
+	"crypto/sha256"
+	"fmt"
 	"testing"
 	"time"
 
@@ -87,6 +89,22 @@ func TestCopy(t *testing.T) {
 			ctxFunc: func() context.Context {
 				return context.WithValue(context.Background(), ConnKey, successConnector)
 			},
+			exceptStdout: StdoutChanged,
+		},
+		{
+			name: "copy unchanged",
+			opt: ExecOptions{
+				Args: runtime.RawExtension{
+					Raw: []byte(`{"content": "hello world", "dest": "/etc/test.txt", "mode": 420}`),
+				},
+				Host:     "local",
+				Variable: &testVariable{},
+			},
+			ctxFunc: func() context.Context {
+				return context.WithValue(context.Background(), ConnKey, &testConnector{
+					output: []byte(fmt.Sprintf("644\n%x  /etc/test.txt", sha256.Sum256([]byte("hello world")))),
+				})
+			},
 			exceptStdout: StdoutSuccess,
 		},
 		{
@@ -24,6 +24,12 @@ import (
 	"github.com/kubesphere/kubekey/v4/pkg/variable"
 )
 
+type debugArgs struct {
+	// varName is the variable to print. high priority than msg
+	varName string `arg:"var"`
+	msg     string `arg:"msg"`
+}
+
 // ModuleDebug deal "debug" module
 func ModuleDebug(_ context.Context, options ExecOptions) (string, string) {
 	// get host variable
@@ -32,10 +38,11 @@ func ModuleDebug(_ context.Context, options ExecOptions) (string, string) {
 		return "", err.Error()
 	}
 
+	da := &debugArgs{}
 	args := variable.Extension2Variables(options.Args)
 	// var is defined. return the value of var
-	if varParam, err := variable.StringVar(ha, args, "var"); err == nil {
-		result, err := tmpl.ParseString(ha, fmt.Sprintf("{{ %s }}", varParam))
+	if da.varName, err = variable.StringVar(ha, args, "var"); err == nil {
+		result, err := tmpl.ParseString(ha, fmt.Sprintf("{{ %s }}", da.varName))
 		if err != nil {
 			return "", fmt.Sprintf("failed to parse var: %v", err)
 		}
@@ -43,8 +50,8 @@ func ModuleDebug(_ context.Context, options ExecOptions) (string, string) {
 		return result, ""
 	}
 	// msg is defined. return the actual msg
-	if msgParam, err := variable.StringVar(ha, args, "msg"); err == nil {
-		return msgParam, ""
+	if da.msg, err = variable.StringVar(ha, args, "msg"); err == nil {
+		return da.msg, ""
 	}
 
 	return "", "unknown args for debug. only support var or msg"
@@ -27,6 +27,11 @@ import (
 	"github.com/kubesphere/kubekey/v4/pkg/variable"
 )
 
+type fetchArgs struct {
+	src  string `arg:"src,required"`
+	dest string `arg:"dest,required"`
+}
+
 // ModuleFetch deal fetch module
 func ModuleFetch(ctx context.Context, options ExecOptions) (string, string) {
 	// get host variable
@@ -36,11 +41,12 @@ func ModuleFetch(ctx context.Context, options ExecOptions) (string, string) {
 	}
 	// check args
 	args := variable.Extension2Variables(options.Args)
-	srcParam, err := variable.StringVar(ha, args, "src")
+	fa := &fetchArgs{}
+	fa.src, err = variable.StringVar(ha, args, "src")
 	if err != nil {
 		return "", "\"src\" in args should be string"
 	}
-	destParam, err := variable.StringVar(ha, args, "dest")
+	fa.dest, err = variable.StringVar(ha, args, "dest")
 	if err != nil {
 		return "", "\"dest\" in args should be string"
 	}
@@ -53,13 +59,13 @@ func ModuleFetch(ctx context.Context, options ExecOptions) (string, string) {
 	defer conn.Close(ctx)
 
 	// fetch file
-	if _, err := os.Stat(filepath.Dir(destParam)); os.IsNotExist(err) {
-		if err := os.MkdirAll(filepath.Dir(destParam), os.ModePerm); err != nil {
+	if _, err := os.Stat(filepath.Dir(fa.dest)); os.IsNotExist(err) {
+		if err := os.MkdirAll(filepath.Dir(fa.dest), os.ModePerm); err != nil {
 			return "", fmt.Sprintf("failed to create dest dir: %v", err)
 		}
 	}
 
-	destFile, err := os.Create(destParam)
+	destFile, err := os.Create(fa.dest)
 	if err != nil {
 		klog.V(4).ErrorS(err, "failed to create dest file")
 
@@ -67,7 +73,7 @@ func ModuleFetch(ctx context.Context, options ExecOptions) (string, string) {
 	}
 	defer destFile.Close()
 
-	if err := conn.FetchFile(ctx, srcParam, destFile); err != nil {
+	if err := conn.FetchFile(ctx, fa.src, destFile); err != nil {
 		return "", fmt.Sprintf("failed to fetch file: %v", err)
 	}
 
@@ -0,0 +1,344 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package modules
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kubesphere/kubekey/v4/pkg/connector"
+	"github.com/kubesphere/kubekey/v4/pkg/variable"
+)
+
+// state of file
+const (
+	fileStateFile      = "file"
+	fileStateDirectory = "directory"
+	fileStateAbsent    = "absent"
+	fileStateLink      = "link"
+	fileStateTouch     = "touch"
+)
+
+// defaultFileMode is used when create a new file in remote host.
+const defaultFileMode fs.FileMode = 0644
+
+type fileArgs struct {
+	path    string `arg:"path,required"`
+	state   string `arg:"state"`
+	src     string `arg:"src"`
+	owner   string `arg:"owner"`
+	group   string `arg:"group"`
+	mode    *int   `arg:"mode"`
+	recurse bool   `arg:"recurse"`
+}
+
+func newFileArgs(_ context.Context, raw runtime.RawExtension, vars map[string]any) (*fileArgs, error) {
+	var err error
+	fa := &fileArgs{}
+	args := variable.Extension2Variables(raw)
+	fa.path, err = variable.StringVar(vars, args, "path")
+	if err != nil {
+		return nil, errors.New("\"path\" in args should be string")
+	}
+	fa.state, _ = variable.StringVar(vars, args, "state")
+	if fa.state == "" {
+		fa.state = fileStateFile
+	}
+	fa.src, _ = variable.StringVar(vars, args, "src")
+	fa.owner, _ = variable.StringVar(vars, args, "owner")
+	fa.group, _ = variable.StringVar(vars, args, "group")
+	fa.mode, _ = variable.IntVar(vars, args, "mode")
+	if recurse, err := variable.BoolVar(vars, args, "recurse"); err == nil {
+		fa.recurse = *recurse
+	}
+
+	switch fa.state {
+	case fileStateFile, fileStateDirectory, fileStateAbsent, fileStateTouch:
+	case fileStateLink:
+		if fa.src == "" {
+			return nil, errors.New("\"src\" is required when state is link")
+		}
+	default:
+		return nil, fmt.Errorf("\"state\" should be one of %q, %q, %q, %q, %q", fileStateFile, fileStateDirectory, fileStateAbsent, fileStateLink, fileStateTouch)
+	}
+	if fa.recurse && fa.state != fileStateDirectory {
+		return nil, errors.New("\"recurse\" is only supported when state is directory")
+	}
+
+	return fa, nil
+}
+
+// ModuleFile deal "file" module
+func ModuleFile(ctx context.Context, options ExecOptions) (string, string) {
+	// get host variable
+	ha, err := options.getAllVariables()
+	if err != nil {
+		return "", err.Error()
+	}
+
+	fa, err := newFileArgs(ctx, options.Args, ha)
+	if err != nil {
+		klog.V(4).ErrorS(err, "get file args error", "task", ctrlclient.ObjectKeyFromObject(&options.Task))
+
+		return "", err.Error()
+	}
+
+	// get connector
+	conn, err := getConnector(ctx, options.Host, ha)
+	if err != nil {
+		return "", fmt.Sprintf("get connector error: %v", err)
+	}
+	defer conn.Close(ctx)
+
+	changed, err := fa.syncState(ctx, conn)
+	if err != nil {
+		return "", err.Error()
+	}
+	if fa.state != fileStateAbsent {
+		attrChanged, err := fa.syncAttributes(ctx, conn)
+		if err != nil {
+			return "", err.Error()
+		}
+		changed = changed || attrChanged
+	}
+
+	if changed {
+		return StdoutChanged, ""
+	}
+
+	return StdoutSuccess, ""
+}
+
+// syncState make the path in remote host match the state.
+func (fa fileArgs) syncState(ctx context.Context, conn connector.Connector) (bool, error) {
+	fi, err := statRemoteFile(ctx, conn, fa.path)
+	if err != nil {
+		return false, err
+	}
+
+	switch fa.state {
+	case fileStateAbsent:
+		if fi == nil {
+			return false, nil
+		}
+		if _, err := conn.ExecuteCommand(ctx, "rm -rf "+fa.path); err != nil {
+			return false, fmt.Errorf("remove %s error: %w", fa.path, err)
+		}
+
+		return true, nil
+	case fileStateDirectory:
+		if fi != nil {
+			if fi.fileType != "directory" {
+				return false, fmt.Errorf("%s is exist and is not a directory", fa.path)
+			}
+
+			return false, nil
+		}
+		if _, err := conn.ExecuteCommand(ctx, "mkdir -p "+fa.path); err != nil {
+			return false, fmt.Errorf("create directory %s error: %w", fa.path, err)
+		}
+
+		return true, nil
+	case fileStateTouch:
+		if fi != nil {
+			return false, nil
+		}
+		if _, err := conn.ExecuteCommand(ctx, "touch "+fa.path); err != nil {
+			return false, fmt.Errorf("touch %s error: %w", fa.path, err)
+		}
+
+		return true, nil
+	case fileStateLink:
+		if fi != nil && fi.fileType == "symbolic link" {
+			target, err := conn.ExecuteCommand(ctx, "readlink "+fa.path)
+			if err == nil && strings.TrimSpace(string(target)) == fa.src {
+				return false, nil
+			}
+		}
+		if _, err := conn.ExecuteCommand(ctx, fmt.Sprintf("ln -sfn %s %s", fa.src, fa.path)); err != nil {
+			return false, fmt.Errorf("link %s to %s error: %w", fa.path, fa.src, err)
+		}
+
+		return true, nil
+	default: // fileStateFile
+		if fi == nil {
+			return false, fmt.Errorf("file %s is not exist", fa.path)
+		}
+
+		return false, nil
+	}
+}
+
+// syncAttributes make the owner, group and mode of path in remote host match the args.
+func (fa fileArgs) syncAttributes(ctx context.Context, conn connector.Connector) (bool, error) {
+	if fa.owner == "" && fa.group == "" && fa.mode == nil {
+		return false, nil
+	}
+	var recurse string
+	if fa.recurse {
+		recurse = "-R "
+	}
+	// change the owner of symbolic link itself, not the target.
+	chownFlag := recurse
+	if fa.state == fileStateLink {
+		chownFlag = "-h "
+	}
+
+	var changed bool
+	if fa.owner != "" || fa.group != "" {
+		chown := fa.owner
+		if fa.group != "" {
+			chown += ":" + fa.group
+		}
+		// find prints nothing when all files match the expected owner and group.
+		var conditions []string
+		if fa.owner != "" {
+			conditions = append(conditions, "! -user "+fa.owner)
+		}
+		if fa.group != "" {
+			conditions = append(conditions, "! -group "+fa.group)
+		}
+		if ok, err := fa.findMismatch(ctx, conn, strings.Join(conditions, " -o ")); err != nil {
+			return changed, err
+		} else if ok {
+			if _, err := conn.ExecuteCommand(ctx, fmt.Sprintf("chown %s%s %s", chownFlag, chown, fa.path)); err != nil {
+				return changed, fmt.Errorf("chown %s error: %w", fa.path, err)
+			}
+			changed = true
+		}
+	}
+	// symbolic link has no permission of itself.
+	if fa.mode != nil && fa.state != fileStateLink {
+		mode := strconv.FormatInt(int64(*fa.mode), 8)
+		if ok, err := fa.findMismatch(ctx, conn, "! -perm "+mode); err != nil {
+			return changed, err
+		} else if ok {
+			if _, err := conn.ExecuteCommand(ctx, fmt.Sprintf("chmod %s%s %s", recurse, mode, fa.path)); err != nil {
+				return changed, fmt.Errorf("chmod %s error: %w", fa.path, err)
+			}
+			changed = true
+		}
+	}
+
+	return changed, nil
+}
+
+// findMismatch check if any file in path (or path itself when not recurse) matches the conditions.
+func (fa fileArgs) findMismatch(ctx context.Context, conn connector.Connector, conditions string) (bool, error) {
+	maxDepth := "-maxdepth 0 "
+	if fa.recurse {
+		maxDepth = ""
+	}
+	output, err := conn.ExecuteCommand(ctx, fmt.Sprintf("find %s %s\\( %s \\) -print -quit", fa.path, maxDepth, conditions))
+	if err != nil {
+		return false, fmt.Errorf("check attributes of %s error: %w", fa.path, err)
+	}
+
+	return len(bytes.TrimSpace(output)) != 0, nil
+}
+
+// remoteFileInfo is the file information in remote host.
+type remoteFileInfo struct {
+	// fileType is the "%F" of stat. such as: "regular file", "directory", "symbolic link".
+	fileType string
+	mode     fs.FileMode
+}
+
+// statRemoteFile get the file information in remote host. return nil if the file is not exist.
+func statRemoteFile(ctx context.Context, conn connector.Connector, path string) (*remoteFileInfo, error) {
+	if _, err := conn.ExecuteCommand(ctx, fmt.Sprintf("test -e %s -o -L %s", path, path)); err != nil {
+		return nil, nil
+	}
+	output, err := conn.ExecuteCommand(ctx, "stat -c '%a|%F' "+path)
+	if err != nil {
+		return nil, fmt.Errorf("stat %s error: %w", path, err)
+	}
+	mode, fileType, ok := strings.Cut(strings.TrimSpace(string(output)), "|")
+	if !ok {
+		return nil, fmt.Errorf("unexpected stat output of %s: %s", path, output)
+	}
+	perm, err := strconv.ParseUint(mode, 8, 32)
+	if err != nil {
+		return nil, fmt.Errorf("parse mode of %s error: %w", path, err)
+	}
+
+	return &remoteFileInfo{fileType: fileType, mode: fs.FileMode(perm)}, nil
+}
+
+// readRemoteFile read the file content and mode in remote host. exist is false if the file is not exist.
+func readRemoteFile(ctx context.Context, conn connector.Connector, path string) ([]byte, fs.FileMode, bool, error) {
+	fi, err := statRemoteFile(ctx, conn, path)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	if fi == nil {
+		return nil, defaultFileMode, false, nil
+	}
+
+	var content bytes.Buffer
+	if err := conn.FetchFile(ctx, path, &content); err != nil {
+		return nil, 0, true, fmt.Errorf("read file %s error: %w", path, err)
+	}
+
+	return content.Bytes(), fi.mode, true, nil
+}
+
+// putFileIfChanged put data to dest in remote host, unless dest has the same content and mode already.
+// return true if dest is written.
+func putFileIfChanged(ctx context.Context, conn connector.Connector, data []byte, dest string, mode fs.FileMode) (bool, error) {
+	output, err := conn.ExecuteCommand(ctx, fmt.Sprintf("stat -c '%%a' %s && sha256sum %s", dest, dest))
+	if err == nil {
+		fields := strings.Fields(string(output))
+		if len(fields) >= 2 && fields[0] == strconv.FormatUint(uint64(mode.Perm()), 8) &&
+			fields[1] == fmt.Sprintf("%x", sha256.Sum256(data)) {
+			return false, nil
+		}
+	}
+	if err := conn.PutFile(ctx, data, dest, mode); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// splitLines split file content to lines. the last empty line is dropped.
+func splitLines(content []byte) []string {
+	if len(content) == 0 {
+		return nil
+	}
+
+	return strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+}
+
+// joinLines join lines to file content which end with "\n".
+func joinLines(lines []string) []byte {
+	if len(lines) == 0 {
+		return nil
+	}
+
+	return []byte(strings.Join(lines, "\n") + "\n")
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package modules
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestFile(t *testing.T) {
+	testcases := []struct {
+		name         string
+		opt          ExecOptions
+		ctxFunc      func() context.Context
+		exceptStdout string
+		exceptStderr string
+	}{
+		{
+			name: "path is empty",
+			opt: ExecOptions{
+				Args:     runtime.RawExtension{},
+				Host:     "local",
+				Variable: &testVariable{},
+			},
+			ctxFunc: func() context.Context {
+				return context.WithValue(context.Background(), ConnKey, successConnector)
+			},
+			exceptStderr: "\"path\" in args should be string",
+		},
+		{
+			name: "link without src",
+			opt: ExecOptions{
+				Args: runtime.RawExtension{
+					Raw: []byte(`{"path": "/usr/local/bin/kubectl", "state": "link"}`),
+				},
+				Host:     "local",
+				Variable: &testVariable{},
+			},
+			ctxFunc: func() context.Context {
+				return context.WithValue(context.Background(), ConnKey, successConnector)
+			},
+			exceptStderr: "\"src\" is required when state is link",
+		},
+		{
+			name: "recurse with file",
+			opt: ExecOptions{
+				Args: runtime.RawExtension{
+					Raw: []byte(`{"path": "/etc/kubernetes", "recurse": true}`),
+				},
+				Host:     "local",
+				Variable: &testVariable{},
+			},
+			ctxFunc: func() context.Context {
+				return context.WithValue(context.Background(), ConnKey, successConnector)
+			},
+			exceptStderr: "\"recurse\" is only supported when state is directory",
+		},
+		{
+			name: "file is not exist",
+			opt: ExecOptions{
+				Args: runtime.RawExtension{
+					Raw: []byte(`{"path": "/etc/kubernetes/admin.conf"}`),
+				},
+				Host:     "local",
+				Variable: &testVariable{},
+			},
+			ctxFunc: func() context.Context {
+				return context.WithValue(context.Background(), ConnKey, failedConnector)
+			},
+			exceptStderr: "file /etc/kubernetes/admin.conf is not exist",
+		},
+		{
+			name: "absent file which is not exist",
+			opt: ExecOptions{
+				Args: runtime.RawExtension{
+					Raw: []byte(`{"path": "/tmp/kubekey", "state": "absent"}`),
+				},
+				Host:     "local",
+				Variable: &testVariable{},
+			},
+			ctxFunc: func() context.Context {
+				return context.WithValue(context.Background(), ConnKey, failedConnector)
+			},
+			exceptStdout: StdoutSuccess,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(tc.ctxFunc(), time.Second*5)
+			defer cancel()
+
+			acStdout, acStderr := ModuleFile(ctx, tc.opt)
+			assert.Equal(t, tc.exceptStdout, acStdout)
+			assert.Equal(t, tc.exceptStderr, acStderr)
+		})
+	}
+}
@@ -48,14 +48,14 @@ var defaultAltName = &cgutilcert.AltNames{
 }
 
 type genCertArgs struct {
-	rootKey  string
-	rootCert string
-	date     time.Duration
-	policy   string
-	sans     []string
-	cn       string
-	outKey   string
-	outCert  string
+	rootKey  string        `arg:"root_key"`
+	rootCert string        `arg:"root_cert"`
+	date     time.Duration `arg:"date"`
+	policy   string        `arg:"policy"`
+	sans     []string      `arg:"sans"`
+	cn       string        `arg:"cn,required"`
+	outKey   string        `arg:"out_key,required"`
+	outCert  string        `arg:"out_cert,required"`
 }
 
 // signedCertificate generate certificate signed by root certificate
@@ -0,0 +1,378 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package modules
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kubesphere/kubekey/v4/pkg/connector"
+	_const "github.com/kubesphere/kubekey/v4/pkg/const"
+	"github.com/kubesphere/kubekey/v4/pkg/variable"
+)
+
+// supported algorithm of checksum
+const (
+	checksumSHA256 = "sha256"
+	checksumSHA512 = "sha512"
+)
+
+// partialSuffix is the suffix of file which is downloading.
+const partialSuffix = ".part"
+
+type getURLArgs struct {
+	url  string `arg:"url,required"`
+	dest string `arg:"dest,required"`
+	// algorithm and digest is parsed from checksum. such as: sha256:xxx
+	algorithm     string `arg:"checksum"`
+	digest        string
+	mode          *int `arg:"mode"`
+	validateCerts bool `arg:"validate_certs"`
+	// delegateTo is the host to download the file. only support localhost now.
+	delegateTo string `arg:"delegate_to"`
+}
+
+func newGetURLArgs(_ context.Context, raw runtime.RawExtension, vars map[string]any) (*getURLArgs, error) {
+	var err error
+	ga := &getURLArgs{validateCerts: true}
+	args := variable.Extension2Variables(raw)
+	ga.url, err = variable.StringVar(vars, args, "url")
+	ga.url = strings.TrimSpace(ga.url)
+	if err != nil || ga.url == "" {
+		return nil, errors.New("\"url\" in args should be string")
+	}
+	u, err := url.Parse(ga.url)
+	if err != nil {
+		return nil, fmt.Errorf("\"url\" is invalid: %w", err)
+	}
+	ga.dest, err = variable.StringVar(vars, args, "dest")
+	if err != nil || ga.dest == "" {
+		return nil, errors.New("\"dest\" in args should be string")
+	}
+	// download to the directory with the file name in url.
+	if strings.HasSuffix(ga.dest, "/") {
+		ga.dest = path.Join(ga.dest, path.Base(u.Path))
+	}
+	if checksum, _ := variable.StringVar(vars, args, "checksum"); checksum != "" {
+		var ok bool
+		ga.algorithm, ga.digest, ok = strings.Cut(checksum, ":")
+		if !ok || (ga.algorithm != checksumSHA256 && ga.algorithm != checksumSHA512) {
+			return nil, fmt.Errorf("\"checksum\" should be format as \"%s:<digest>\" or \"%s:<digest>\"", checksumSHA256, checksumSHA512)
+		}
+		ga.digest = strings.ToLower(ga.digest)
+	}
+	ga.mode, _ = variable.IntVar(vars, args, "mode")
+	if validateCerts, err := variable.BoolVar(vars, args, "validate_certs"); err == nil {
+		ga.validateCerts = *validateCerts
+	}
+	ga.delegateTo, _ = variable.StringVar(vars, args, "delegate_to")
+	if ga.delegateTo != "" && ga.delegateTo != _const.VariableLocalHost {
+		return nil, fmt.Errorf("\"delegate_to\" only support %q", _const.VariableLocalHost)
+	}
+
+	return ga, nil
+}
+
+// ModuleGetURL deal "get_url" module
+func ModuleGetURL(ctx context.Context, options ExecOptions) (string, string) {
+	// get host variable
+	ha, err := options.getAllVariables()
+	if err != nil {
+		return "", err.Error()
+	}
+
+	ga, err := newGetURLArgs(ctx, options.Args, ha)
+	if err != nil {
+		klog.V(4).ErrorS(err, "get get_url args error", "task", ctrlclient.ObjectKeyFromObject(&options.Task))
+
+		return "", err.Error()
+	}
+
+	// get connector
+	conn, err := getConnector(ctx, options.Host, ha)
+	if err != nil {
+		return "", fmt.Sprintf("get connector error: %v", err)
+	}
+	defer conn.Close(ctx)
+
+	// skip when dest is exist and match the checksum.
+	if ok, err := ga.destValid(ctx, conn); err != nil {
+		return "", err.Error()
+	} else if ok {
+		return StdoutSuccess, ""
+	}
+
+	switch {
+	case ga.delegateTo != "":
+		err = ga.downloadLocal(ctx, conn)
+	case ga.cached():
+		// the file has been downloaded by other host. copy it to remote host instead of downloading again.
+		err = ga.putFile(ctx, conn, ga.cacheFile())
+	default:
+		err = ga.downloadRemote(ctx, conn)
+	}
+	if err != nil {
+		return "", err.Error()
+	}
+
+	return StdoutChanged, ""
+}
+
+// destValid check if dest is exist in remote host, and its checksum is matched.
+func (ga getURLArgs) destValid(ctx context.Context, conn connector.Connector) (bool, error) {
+	fi, err := statRemoteFile(ctx, conn, ga.dest)
+	if err != nil || fi == nil {
+		return false, err
+	}
+	if fi.fileType == "directory" {
+		return false, fmt.Errorf("dest %s is a directory", ga.dest)
+	}
+	if ga.digest == "" {
+		return true, nil
+	}
+
+	return ga.remoteChecksum(ctx, conn, ga.dest) == nil, nil
+}
+
+// remoteChecksum verify the checksum of file in remote host.
+func (ga getURLArgs) remoteChecksum(ctx context.Context, conn connector.Connector, file string) error {
+	output, err := conn.ExecuteCommand(ctx, fmt.Sprintf("%ssum %s", ga.algorithm, file))
+	if err != nil {
+		return fmt.Errorf("get %s of %s error: %w", ga.algorithm, file, err)
+	}
+	if fields := strings.Fields(string(output)); len(fields) == 0 || !strings.EqualFold(fields[0], ga.digest) {
+		return fmt.Errorf("%s of %s is mismatch, except %s but got %s", ga.algorithm, file, ga.digest, strings.TrimSpace(string(output)))
+	}
+
+	return nil
+}
+
+// downloadRemote download the file by curl in remote host. the partial file is kept for resuming.
+func (ga getURLArgs) downloadRemote(ctx context.Context, conn connector.Connector) error {
+	partial := ga.dest + partialSuffix
+	insecure := ""
+	if !ga.validateCerts {
+		insecure = "-k "
+	}
+	if _, err := conn.ExecuteCommand(ctx, fmt.Sprintf("mkdir -p %s && curl -fsSL %s-C - -o %s %q",
+		filepath.Dir(ga.dest), insecure, partial, ga.url)); err != nil {
+		return fmt.Errorf("download %s error: %w", ga.url, err)
+	}
+	if ga.digest != "" {
+		if err := ga.remoteChecksum(ctx, conn, partial); err != nil {
+			// the partial file is broken, download from the beginning next time.
+			_, _ = conn.ExecuteCommand(ctx, "rm -f "+partial)
+
+			return err
+		}
+	}
+	cmd := fmt.Sprintf("mv -f %s %s", partial, ga.dest)
+	if ga.mode != nil {
+		cmd += fmt.Sprintf(" && chmod %o %s", *ga.mode, ga.dest)
+	}
+	if _, err := conn.ExecuteCommand(ctx, cmd); err != nil {
+		return fmt.Errorf("move %s to %s error: %w", partial, ga.dest, err)
+	}
+
+	return nil
+}
+
+// cacheFile returns the local cache file of url. it's empty when the checksum is not defined.
+func (ga getURLArgs) cacheFile() string {
+	if ga.digest == "" {
+		return ""
+	}
+
+	return filepath.Join(_const.GetWorkDir(), _const.CacheDir, ga.algorithm, ga.digest)
+}
+
+// cached returns true if the url has been downloaded in local cache and match the checksum.
+func (ga getURLArgs) cached() bool {
+	file := ga.cacheFile()
+	if file == "" {
+		return false
+	}
+	if _, err := os.Stat(file); err != nil {
+		return false
+	}
+
+	return ga.localChecksum(file) == nil
+}
+
+// downloadLocal download the file in local, and copy it to remote host.
+// the file is cached by checksum in work_dir, so that it can be reused by other hosts.
+func (ga getURLArgs) downloadLocal(ctx context.Context, conn connector.Connector) error {
+	file := ga.cacheFile()
+	if file == "" {
+		// not cache the file which has no checksum.
+		tmpDir, err := os.MkdirTemp("", "kubekey-get-url-")
+		if err != nil {
+			return fmt.Errorf("create temp dir error: %w", err)
+		}
+		defer os.RemoveAll(tmpDir)
+		file = filepath.Join(tmpDir, path.Base(ga.dest))
+	}
+
+	if err := ga.fetch(ctx, file); err != nil {
+		return err
+	}
+
+	return ga.putFile(ctx, conn, file)
+}
+
+// putFile copy the local file to dest of remote host.
+func (ga getURLArgs) putFile(ctx context.Context, conn connector.Connector, file string) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("read file %s error: %w", file, err)
+	}
+	mode := defaultFileMode
+	if ga.mode != nil {
+		mode = fs.FileMode(*ga.mode)
+	}
+	if err := conn.PutFile(ctx, data, ga.dest, mode); err != nil {
+		return fmt.Errorf("copy %s to %s error: %w", file, ga.dest, err)
+	}
+
+	return nil
+}
+
+// fetch download url to the local file. It's skipped when file is exist and match the checksum.
+// The partial file is resumed by range request if the server supported.
+func (ga getURLArgs) fetch(ctx context.Context, file string) error {
+	if _, err := os.Stat(file); err == nil {
+		if err := ga.localChecksum(file); err == nil {
+			klog.V(4).InfoS("use cached file", "url", ga.url, "file", file)
+
+			return nil
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(file), os.ModePerm); err != nil {
+		return fmt.Errorf("create dir %s error: %w", filepath.Dir(file), err)
+	}
+
+	partial := file + partialSuffix
+	f, err := os.OpenFile(partial, os.O_CREATE|os.O_WRONLY, defaultFileMode)
+	if err != nil {
+		return fmt.Errorf("open file %s error: %w", partial, err)
+	}
+	defer f.Close()
+	offset, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return fmt.Errorf("seek file %s error: %w", partial, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ga.url, http.NoBody)
+	if err != nil {
+		return fmt.Errorf("create request of %s error: %w", ga.url, err)
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	client := &http.Client{
+		Transport: &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: !ga.validateCerts,
+			},
+		},
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("download %s error: %w", ga.url, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		klog.V(4).InfoS("resume download", "url", ga.url, "offset", offset)
+	case http.StatusRequestedRangeNotSatisfiable:
+		// the partial file has been downloaded completely.
+	case http.StatusOK:
+		// the server not support range request, download from the beginning.
+		if err := f.Truncate(0); err != nil {
+			return fmt.Errorf("truncate file %s error: %w", partial, err)
+		}
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return fmt.Errorf("seek file %s error: %w", partial, err)
+		}
+	default:
+		return fmt.Errorf("download %s error: http code is %d", ga.url, resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusRequestedRangeNotSatisfiable {
+		if _, err := io.Copy(f, resp.Body); err != nil {
+			return fmt.Errorf("download %s error: %w", ga.url, err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("close file %s error: %w", partial, err)
+	}
+
+	if err := ga.localChecksum(partial); err != nil {
+		// the partial file is broken, download from the beginning next time.
+		_ = os.Remove(partial)
+
+		return err
+	}
+
+	return os.Rename(partial, file)
+}
+
+// localChecksum verify the checksum of local file.
+func (ga getURLArgs) localChecksum(file string) error {
+	if ga.digest == "" {
+		return nil
+	}
+	var h hash.Hash
+	switch ga.algorithm {
+	case checksumSHA512:
+		h = sha512.New()
+	default:
+		h = sha256.New()
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return fmt.Errorf("open file %s error: %w", file, err)
+	}
+	defer f.Close()
+	if _, err := io.Copy(h, f); err != nil {
+		return fmt.Errorf("read file %s error: %w", file, err)
+	}
+	if actual := hex.EncodeToString(h.Sum(nil)); actual != ga.digest {
+		return fmt.Errorf("%s of %s is mismatch, except %s but got %s", ga.algorithm, ga.url, ga.digest, actual)
+	}
+
+	return nil
+}
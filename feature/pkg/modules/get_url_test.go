@@ -0,0 +1,159 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package modules
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	_const "github.com/kubesphere/kubekey/v4/pkg/const"
+)
+
+func TestGetURLArgs(t *testing.T) {
+	testcases := []struct {
+		name         string
+		args         string
+		exceptDest   string
+		exceptErrMsg string
+	}{
+		{
+			name:         "url is empty",
+			args:         `{"dest": "/tmp/"}`,
+			exceptErrMsg: "\"url\" in args should be string",
+		},
+		{
+			name:         "dest is empty",
+			args:         `{"url": "https://dl.k8s.io/release/v1.30.0/bin/linux/amd64/kubeadm"}`,
+			exceptErrMsg: "\"dest\" in args should be string",
+		},
+		{
+			name:         "unsupported checksum",
+			args:         `{"url": "https://dl.k8s.io/release/v1.30.0/bin/linux/amd64/kubeadm", "dest": "/tmp/", "checksum": "md5:abc"}`,
+			exceptErrMsg: "\"checksum\" should be format as \"sha256:<digest>\" or \"sha512:<digest>\"",
+		},
+		{
+			name:       "dest is directory",
+			args:       `{"url": "https://dl.k8s.io/release/v1.30.0/bin/linux/amd64/kubeadm", "dest": "/tmp/", "checksum": "sha256:ABC"}`,
+			exceptDest: "/tmp/kubeadm",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ga, err := newGetURLArgs(context.Background(), runtime.RawExtension{Raw: []byte(tc.args)}, nil)
+			if tc.exceptErrMsg != "" {
+				assert.EqualError(t, err, tc.exceptErrMsg)
+
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tc.exceptDest, ga.dest)
+		})
+	}
+}
+
+func TestGetURLFetch(t *testing.T) {
+	content := strings.Repeat("kubekey", 1024)
+	sum := sha256.Sum256([]byte(content))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if rng := r.Header.Get("Range"); rng != "" {
+			offset, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(rng, "bytes="), "-"))
+			if err != nil || offset > len(content) {
+				w.WriteHeader(http.StatusBadRequest)
+
+				return
+			}
+			w.WriteHeader(http.StatusPartialContent)
+			_, _ = w.Write([]byte(content[offset:]))
+
+			return
+		}
+		_, _ = w.Write([]byte(content))
+	}))
+	defer server.Close()
+
+	testcases := []struct {
+		name         string
+		digest       string
+		partial      string
+		exceptErrMsg string
+	}{
+		{
+			name:   "download",
+			digest: hex.EncodeToString(sum[:]),
+		},
+		{
+			name:    "resume download",
+			digest:  hex.EncodeToString(sum[:]),
+			partial: content[:100],
+		},
+		{
+			name:         "checksum mismatch",
+			digest:       "abc",
+			exceptErrMsg: "sha256 of " + server.URL + " is mismatch",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "kubeadm")
+			if tc.partial != "" {
+				if err := os.WriteFile(file+partialSuffix, []byte(tc.partial), defaultFileMode); err != nil {
+					t.Fatal(err)
+				}
+			}
+			ga := getURLArgs{url: server.URL, algorithm: checksumSHA256, digest: tc.digest}
+			err := ga.fetch(context.Background(), file)
+			if tc.exceptErrMsg != "" {
+				assert.ErrorContains(t, err, tc.exceptErrMsg)
+				assert.NoFileExists(t, file+partialSuffix)
+
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, content, string(data))
+		})
+	}
+}
+
+func TestGetURLCacheFile(t *testing.T) {
+	// the cache is not in artifact dir, so it's not exported with the artifact.
+	assert.Equal(t, filepath.Join(_const.GetWorkDir(), _const.CacheDir, checksumSHA256, "abc"),
+		getURLArgs{algorithm: checksumSHA256, digest: "abc"}.cacheFile())
+	// the file without checksum is not cached.
+	assert.Empty(t, getURLArgs{}.cacheFile())
+	assert.False(t, getURLArgs{}.cached())
+}
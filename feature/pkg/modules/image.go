@@ -44,8 +44,8 @@ import (
 )
 
 type imageArgs struct {
-	pull *imagePullArgs
-	push *imagePushArgs
+	pull *imagePullArgs `arg:"pull"`
+	push *imagePushArgs `arg:"push"`
 }
 
 type imagePullArgs struct {
@@ -0,0 +1,217 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package modules
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kubesphere/kubekey/v4/pkg/variable"
+)
+
+// state for lineinfile, blockinfile and sysctl
+const (
+	lineStatePresent = "present"
+	lineStateAbsent  = "absent"
+)
+
+// special position for insertafter and insertbefore
+const (
+	insertEOF = "EOF"
+	insertBOF = "BOF"
+)
+
+type lineInFileArgs struct {
+	path         string         `arg:"path,required"`
+	line         string         `arg:"line"`
+	regexp       *regexp.Regexp `arg:"regexp"`
+	state        string         `arg:"state"`
+	insertAfter  string         `arg:"insertafter"`
+	insertBefore string         `arg:"insertbefore"`
+	create       bool           `arg:"create"`
+}
+
+func newLineInFileArgs(_ context.Context, raw runtime.RawExtension, vars map[string]any) (*lineInFileArgs, error) {
+	var err error
+	la := &lineInFileArgs{}
+	args := variable.Extension2Variables(raw)
+	la.path, err = variable.StringVar(vars, args, "path")
+	if err != nil {
+		return nil, errors.New("\"path\" in args should be string")
+	}
+	la.line, _ = variable.StringVar(vars, args, "line")
+	if exp, _ := variable.StringVar(vars, args, "regexp"); exp != "" {
+		la.regexp, err = regexp.Compile(exp)
+		if err != nil {
+			return nil, fmt.Errorf("\"regexp\" is invalid: %w", err)
+		}
+	}
+	la.state, _ = variable.StringVar(vars, args, "state")
+	if la.state == "" {
+		la.state = lineStatePresent
+	}
+	la.insertAfter, _ = variable.StringVar(vars, args, "insertafter")
+	la.insertBefore, _ = variable.StringVar(vars, args, "insertbefore")
+	if create, err := variable.BoolVar(vars, args, "create"); err == nil {
+		la.create = *create
+	}
+
+	switch la.state {
+	case lineStatePresent:
+		if la.line == "" {
+			return nil, errors.New("\"line\" is required when state is present")
+		}
+	case lineStateAbsent:
+		if la.line == "" && la.regexp == nil {
+			return nil, errors.New("either \"line\" or \"regexp\" must be provided when state is absent")
+		}
+	default:
+		return nil, fmt.Errorf("\"state\" should be one of %q, %q", lineStatePresent, lineStateAbsent)
+	}
+	if la.insertAfter != "" && la.insertBefore != "" {
+		return nil, errors.New("\"insertafter\" and \"insertbefore\" cannot be set at the same time")
+	}
+
+	return la, nil
+}
+
+// ModuleLineInFile deal "lineinfile" module
+func ModuleLineInFile(ctx context.Context, options ExecOptions) (string, string) {
+	// get host variable
+	ha, err := options.getAllVariables()
+	if err != nil {
+		return "", err.Error()
+	}
+
+	la, err := newLineInFileArgs(ctx, options.Args, ha)
+	if err != nil {
+		klog.V(4).ErrorS(err, "get lineinfile args error", "task", ctrlclient.ObjectKeyFromObject(&options.Task))
+
+		return "", err.Error()
+	}
+
+	// get connector
+	conn, err := getConnector(ctx, options.Host, ha)
+	if err != nil {
+		return "", fmt.Sprintf("get connector error: %v", err)
+	}
+	defer conn.Close(ctx)
+
+	content, mode, exist, err := readRemoteFile(ctx, conn, la.path)
+	if err != nil {
+		return "", err.Error()
+	}
+	if !exist {
+		if la.state == lineStateAbsent {
+			// nothing to remove
+			return StdoutSuccess, ""
+		}
+		if !la.create {
+			return "", fmt.Sprintf("file %s is not exist", la.path)
+		}
+	}
+	result, err := la.apply(content)
+	if err != nil {
+		return "", err.Error()
+	}
+	if bytes.Equal(content, result) {
+		return StdoutSuccess, ""
+	}
+	if err := conn.PutFile(ctx, result, la.path, mode); err != nil {
+		return "", fmt.Sprintf("write file %s error: %v", la.path, err)
+	}
+
+	return StdoutChanged, ""
+}
+
+// apply lineinfile to the file content and return the new content.
+func (la lineInFileArgs) apply(content []byte) ([]byte, error) {
+	lines := splitLines(content)
+	match := func(l string) bool {
+		if la.regexp != nil {
+			return la.regexp.MatchString(l)
+		}
+
+		return l == la.line
+	}
+
+	if la.state == lineStateAbsent {
+		lines = slices.DeleteFunc(lines, match)
+
+		return joinLines(lines), nil
+	}
+	// replace the last matched line.
+	if la.regexp != nil {
+		for i := len(lines) - 1; i >= 0; i-- {
+			if la.regexp.MatchString(lines[i]) {
+				lines[i] = la.line
+
+				return joinLines(lines), nil
+			}
+		}
+	}
+	if slices.Contains(lines, la.line) {
+		return joinLines(lines), nil
+	}
+	// insert the line.
+	index, err := insertIndex(lines, la.insertAfter, la.insertBefore)
+	if err != nil {
+		return nil, err
+	}
+
+	return joinLines(slices.Insert(lines, index, la.line)), nil
+}
+
+// insertIndex get the index of lines to insert new content.
+// insertAfter will insert after the last matched line, insertBefore will insert before the first matched line.
+// If nothing matched, insert at the end of lines.
+func insertIndex(lines []string, insertAfter, insertBefore string) (int, error) {
+	switch {
+	case insertBefore == insertBOF:
+		return 0, nil
+	case insertBefore != "":
+		exp, err := regexp.Compile(insertBefore)
+		if err != nil {
+			return 0, fmt.Errorf("\"insertbefore\" is invalid: %w", err)
+		}
+		for i, l := range lines {
+			if exp.MatchString(l) {
+				return i, nil
+			}
+		}
+	case insertAfter != "" && insertAfter != insertEOF:
+		exp, err := regexp.Compile(insertAfter)
+		if err != nil {
+			return 0, fmt.Errorf("\"insertafter\" is invalid: %w", err)
+		}
+		for i := len(lines) - 1; i >= 0; i-- {
+			if exp.MatchString(lines[i]) {
+				return i + 1, nil
+			}
+		}
+	}
+
+	return len(lines), nil
+}
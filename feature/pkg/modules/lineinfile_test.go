@@ -0,0 +1,84 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package modules
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLineInFileApply(t *testing.T) {
+	testcases := []struct {
+		name    string
+		args    lineInFileArgs
+		content string
+		except  string
+	}{
+		{
+			name:    "append line",
+			args:    lineInFileArgs{line: "127.0.0.1 localhost", state: lineStatePresent},
+			content: "::1 localhost\n",
+			except:  "::1 localhost\n127.0.0.1 localhost\n",
+		},
+		{
+			name:    "line is exist",
+			args:    lineInFileArgs{line: "127.0.0.1 localhost", state: lineStatePresent},
+			content: "127.0.0.1 localhost\n::1 localhost\n",
+			except:  "127.0.0.1 localhost\n::1 localhost\n",
+		},
+		{
+			name:    "replace the last matched line",
+			args:    lineInFileArgs{line: "127.0.1.1 node1", regexp: regexp.MustCompile("^127.0.1.1"), state: lineStatePresent},
+			content: "127.0.1.1 a\n127.0.1.1 b\n",
+			except:  "127.0.1.1 a\n127.0.1.1 node1\n",
+		},
+		{
+			name:    "insert before",
+			args:    lineInFileArgs{line: "first", state: lineStatePresent, insertBefore: insertBOF},
+			content: "second\n",
+			except:  "first\nsecond\n",
+		},
+		{
+			name:    "insert after",
+			args:    lineInFileArgs{line: "b", state: lineStatePresent, insertAfter: "^a"},
+			content: "a\nc\n",
+			except:  "a\nb\nc\n",
+		},
+		{
+			name:    "create file",
+			args:    lineInFileArgs{line: "a", state: lineStatePresent},
+			content: "",
+			except:  "a\n",
+		},
+		{
+			name:    "remove lines",
+			args:    lineInFileArgs{regexp: regexp.MustCompile("swap"), state: lineStateAbsent},
+			content: "/dev/sda1 / ext4 defaults 0 0\n/swapfile none swap sw 0 0\n",
+			except:  "/dev/sda1 / ext4 defaults 0 0\n",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := tc.args.apply([]byte(tc.content))
+			assert.NoError(t, err)
+			assert.Equal(t, tc.except, string(result))
+		})
+	}
+}
@@ -36,6 +36,8 @@ const (
 	// StdoutSuccess message for common module
 	StdoutSuccess = "success"
 	StdoutSkip    = "skip"
+	// StdoutChanged message for idempotent module which has changed the host
+	StdoutChanged = "changed"
 
 	// StdoutTrue for bool module
 	StdoutTrue = "True"
@@ -43,6 +45,22 @@ const (
 	StdoutFalse = "False"
 )
 
+// alwaysChangedModules can't tell whether the host is changed, such as the command they run.
+// they are treated as changed when succeed.
+var alwaysChangedModules = map[string]bool{
+	"command": true,
+	"shell":   true,
+}
+
+// IsChanged returns true if the module has changed the host by the result of it.
+func IsChanged(moduleName, stdout, stderr string) bool {
+	if stderr != "" || stdout == StdoutSkip {
+		return false
+	}
+
+	return stdout == StdoutChanged || alwaysChangedModules[moduleName]
+}
+
 // ModuleExecFunc exec module
 type ModuleExecFunc func(ctx context.Context, options ExecOptions) (stdout string, stderr string)
 
@@ -103,6 +121,16 @@ func init() {
 	utilruntime.Must(RegisterModule("set_fact", ModuleSetFact))
 	utilruntime.Must(RegisterModule("gen_cert", ModuleGenCert))
 	utilruntime.Must(RegisterModule("image", ModuleImage))
+	utilruntime.Must(RegisterModule("service", ModuleService))
+	utilruntime.Must(RegisterModule("file", ModuleFile))
+	utilruntime.Must(RegisterModule("lineinfile", ModuleLineInFile))
+	utilruntime.Must(RegisterModule("blockinfile", ModuleBlockInFile))
+	utilruntime.Must(RegisterModule("sysctl", ModuleSysctl))
+	utilruntime.Must(RegisterModule("mount", ModuleMount))
+	utilruntime.Must(RegisterModule("wait_for", ModuleWaitFor))
+	utilruntime.Must(RegisterModule("get_url", ModuleGetURL))
+	utilruntime.Must(RegisterModule("unarchive", ModuleUnarchive))
+	utilruntime.Must(RegisterModule("validate_argument_spec", ModuleValidateArgumentSpec))
 }
 
 // ConnKey for connector which store in context
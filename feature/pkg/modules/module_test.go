@@ -21,6 +21,9 @@ import (
 	"errors"
 	"io"
 	"io/fs"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
 
 	"github.com/kubesphere/kubekey/v4/pkg/variable"
 )
@@ -86,3 +89,25 @@ func (t testConnector) FetchFile(context.Context, string, io.Writer) error {
 func (t testConnector) ExecuteCommand(context.Context, string) ([]byte, error) {
 	return t.output, t.commandErr
 }
+
+func TestIsChanged(t *testing.T) {
+	testcases := []struct {
+		name    string
+		module  string
+		stdout  string
+		stderr  string
+		changed bool
+	}{
+		{name: "idempotent module changed", module: "copy", stdout: StdoutChanged, changed: true},
+		{name: "idempotent module unchanged", module: "copy", stdout: StdoutSuccess},
+		{name: "command succeed", module: "command", stdout: "hello world", changed: true},
+		{name: "command failed", module: "command", stdout: "hello world", stderr: "exit status 1"},
+		{name: "command skipped", module: "shell", stdout: StdoutSkip},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.changed, IsChanged(tc.module, tc.stdout, tc.stderr))
+		})
+	}
+}
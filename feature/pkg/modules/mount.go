@@ -0,0 +1,223 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package modules
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kubesphere/kubekey/v4/pkg/connector"
+	"github.com/kubesphere/kubekey/v4/pkg/variable"
+)
+
+// state of mount
+const (
+	// mountStateMounted add entry to fstab and mount it.
+	mountStateMounted = "mounted"
+	// mountStatePresent only add entry to fstab.
+	mountStatePresent = "present"
+	// mountStateUnmounted only unmount it, the entry in fstab is kept.
+	mountStateUnmounted = "unmounted"
+	// mountStateAbsent remove entry from fstab and unmount it.
+	mountStateAbsent = "absent"
+)
+
+// defaultFstab is the file to persist mount entries.
+const defaultFstab = "/etc/fstab"
+
+type mountArgs struct {
+	path   string `arg:"path,required"`
+	src    string `arg:"src"`
+	fstype string `arg:"fstype"`
+	opts   string `arg:"opts"`
+	dump   string `arg:"dump"`
+	passno string `arg:"passno"`
+	state  string `arg:"state"`
+	fstab  string `arg:"fstab"`
+}
+
+func newMountArgs(_ context.Context, raw runtime.RawExtension, vars map[string]any) (*mountArgs, error) {
+	var err error
+	ma := &mountArgs{}
+	args := variable.Extension2Variables(raw)
+	ma.path, err = variable.StringVar(vars, args, "path")
+	if err != nil {
+		return nil, errors.New("\"path\" in args should be string")
+	}
+	if ma.path != "/" {
+		ma.path = strings.TrimSuffix(ma.path, "/")
+	}
+	ma.src, _ = variable.StringVar(vars, args, "src")
+	ma.fstype, _ = variable.StringVar(vars, args, "fstype")
+	ma.opts, _ = variable.StringVar(vars, args, "opts")
+	if ma.opts == "" {
+		ma.opts = "defaults"
+	}
+	ma.dump = "0"
+	if dump, err := variable.IntVar(vars, args, "dump"); err == nil {
+		ma.dump = fmt.Sprintf("%d", *dump)
+	}
+	ma.passno = "0"
+	if passno, err := variable.IntVar(vars, args, "passno"); err == nil {
+		ma.passno = fmt.Sprintf("%d", *passno)
+	}
+	ma.state, _ = variable.StringVar(vars, args, "state")
+	if ma.state == "" {
+		ma.state = mountStateMounted
+	}
+	ma.fstab, _ = variable.StringVar(vars, args, "fstab")
+	if ma.fstab == "" {
+		ma.fstab = defaultFstab
+	}
+
+	switch ma.state {
+	case mountStateMounted, mountStatePresent:
+		if ma.src == "" || ma.fstype == "" {
+			return nil, fmt.Errorf("\"src\" and \"fstype\" is required when state is %s", ma.state)
+		}
+	case mountStateUnmounted, mountStateAbsent:
+	default:
+		return nil, fmt.Errorf("\"state\" should be one of %q, %q, %q, %q", mountStateMounted, mountStatePresent, mountStateUnmounted, mountStateAbsent)
+	}
+
+	return ma, nil
+}
+
+// ModuleMount deal "mount" module
+func ModuleMount(ctx context.Context, options ExecOptions) (string, string) {
+	// get host variable
+	ha, err := options.getAllVariables()
+	if err != nil {
+		return "", err.Error()
+	}
+
+	ma, err := newMountArgs(ctx, options.Args, ha)
+	if err != nil {
+		klog.V(4).ErrorS(err, "get mount args error", "task", ctrlclient.ObjectKeyFromObject(&options.Task))
+
+		return "", err.Error()
+	}
+
+	// get connector
+	conn, err := getConnector(ctx, options.Host, ha)
+	if err != nil {
+		return "", fmt.Sprintf("get connector error: %v", err)
+	}
+	defer conn.Close(ctx)
+
+	var changed bool
+	// sync fstab entry
+	if ma.state != mountStateUnmounted {
+		content, mode, _, err := readRemoteFile(ctx, conn, ma.fstab)
+		if err != nil {
+			return "", err.Error()
+		}
+		result := ma.apply(content)
+		if !bytes.Equal(content, result) {
+			if err := conn.PutFile(ctx, result, ma.fstab, mode); err != nil {
+				return "", fmt.Sprintf("write file %s error: %v", ma.fstab, err)
+			}
+			changed = true
+		}
+	}
+	// sync mount point
+	if ma.state != mountStatePresent {
+		mountChanged, err := ma.syncMount(ctx, conn, changed)
+		if err != nil {
+			return "", err.Error()
+		}
+		changed = changed || mountChanged
+	}
+
+	if changed {
+		return StdoutChanged, ""
+	}
+
+	return StdoutSuccess, ""
+}
+
+// apply the mount entry to the fstab content and return the new content.
+func (ma mountArgs) apply(content []byte) []byte {
+	lines := splitLines(content)
+	match := func(l string) bool {
+		fields := strings.Fields(l)
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+			return false
+		}
+
+		return fields[1] == ma.path || strings.TrimSuffix(fields[1], "/") == ma.path
+	}
+
+	index := slices.IndexFunc(lines, match)
+	if ma.state == mountStateAbsent {
+		return joinLines(slices.DeleteFunc(lines, match))
+	}
+
+	entry := strings.Join([]string{ma.src, ma.path, ma.fstype, ma.opts, ma.dump, ma.passno}, " ")
+	if index == -1 {
+		return joinLines(append(lines, entry))
+	}
+	// keep the origin format if the entry is not changed.
+	if slices.Equal(strings.Fields(lines[index]), strings.Fields(entry)) {
+		return joinLines(lines)
+	}
+	lines[index] = entry
+
+	return joinLines(lines)
+}
+
+// syncMount mount or unmount the path. remount it when the fstab entry has changed.
+func (ma mountArgs) syncMount(ctx context.Context, conn connector.Connector, entryChanged bool) (bool, error) {
+	_, err := conn.ExecuteCommand(ctx, "mountpoint -q "+ma.path)
+	mounted := err == nil
+
+	switch ma.state {
+	case mountStateMounted:
+		if !mounted {
+			if _, err := conn.ExecuteCommand(ctx, fmt.Sprintf("mkdir -p %s && mount %s", ma.path, ma.path)); err != nil {
+				return false, fmt.Errorf("mount %s error: %w", ma.path, err)
+			}
+
+			return true, nil
+		}
+		if entryChanged {
+			if _, err := conn.ExecuteCommand(ctx, "mount -o remount "+ma.path); err != nil {
+				return false, fmt.Errorf("remount %s error: %w", ma.path, err)
+			}
+
+			return true, nil
+		}
+	case mountStateUnmounted, mountStateAbsent:
+		if mounted {
+			if _, err := conn.ExecuteCommand(ctx, "umount "+ma.path); err != nil {
+				return false, fmt.Errorf("umount %s error: %w", ma.path, err)
+			}
+
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
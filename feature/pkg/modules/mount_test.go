@@ -0,0 +1,63 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package modules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMountApply(t *testing.T) {
+	testcases := []struct {
+		name    string
+		args    mountArgs
+		content string
+		except  string
+	}{
+		{
+			name:    "add entry",
+			args:    mountArgs{path: "/mnt/nfs", src: "10.0.0.1:/share", fstype: "nfs", opts: "defaults", dump: "0", passno: "0", state: mountStateMounted},
+			content: "/dev/sda1 / ext4 defaults 0 1\n",
+			except:  "/dev/sda1 / ext4 defaults 0 1\n10.0.0.1:/share /mnt/nfs nfs defaults 0 0\n",
+		},
+		{
+			name:    "entry is not changed",
+			args:    mountArgs{path: "/mnt/nfs", src: "10.0.0.1:/share", fstype: "nfs", opts: "defaults", dump: "0", passno: "0", state: mountStatePresent},
+			content: "10.0.0.1:/share\t/mnt/nfs\tnfs\tdefaults\t0\t0\n",
+			except:  "10.0.0.1:/share\t/mnt/nfs\tnfs\tdefaults\t0\t0\n",
+		},
+		{
+			name:    "update entry",
+			args:    mountArgs{path: "/mnt/nfs", src: "10.0.0.2:/share", fstype: "nfs", opts: "ro", dump: "0", passno: "0", state: mountStateMounted},
+			content: "10.0.0.1:/share /mnt/nfs nfs defaults 0 0\n",
+			except:  "10.0.0.2:/share /mnt/nfs nfs ro 0 0\n",
+		},
+		{
+			name:    "remove entry",
+			args:    mountArgs{path: "/mnt/nfs", state: mountStateAbsent},
+			content: "# /mnt/nfs\n10.0.0.1:/share /mnt/nfs nfs defaults 0 0\n",
+			except:  "# /mnt/nfs\n",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.except, string(tc.args.apply([]byte(tc.content))))
+		})
+	}
+}
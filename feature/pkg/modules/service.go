@@ -0,0 +1,312 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package modules
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kubesphere/kubekey/v4/pkg/connector"
+	"github.com/kubesphere/kubekey/v4/pkg/variable"
+)
+
+// state of service
+const (
+	serviceStateStarted   = "started"
+	serviceStateStopped   = "stopped"
+	serviceStateRestarted = "restarted"
+	serviceStateReloaded  = "reloaded"
+)
+
+// defaultServiceOverrideName is the drop-in file name when "override_name" is not set.
+const defaultServiceOverrideName = "override"
+
+type serviceArgs struct {
+	name         string `arg:"name,required"`
+	state        string `arg:"state"`
+	enabled      *bool  `arg:"enabled"`
+	daemonReload bool   `arg:"daemon_reload"`
+	override     string `arg:"override"`
+	overrideName string `arg:"override_name"`
+}
+
+func newServiceArgs(_ context.Context, raw runtime.RawExtension, vars map[string]any) (*serviceArgs, error) {
+	sa := &serviceArgs{}
+	args := variable.Extension2Variables(raw)
+	sa.name, _ = variable.StringVar(vars, args, "name")
+	sa.state, _ = variable.StringVar(vars, args, "state")
+	sa.enabled, _ = variable.BoolVar(vars, args, "enabled")
+	if daemonReload, err := variable.BoolVar(vars, args, "daemon_reload"); err == nil {
+		sa.daemonReload = *daemonReload
+	}
+	sa.override, _ = variable.StringVar(vars, args, "override")
+	sa.overrideName, _ = variable.StringVar(vars, args, "override_name")
+	if sa.overrideName == "" {
+		sa.overrideName = defaultServiceOverrideName
+	}
+
+	switch sa.state {
+	case "", serviceStateStarted, serviceStateStopped, serviceStateRestarted, serviceStateReloaded:
+	default:
+		return nil, fmt.Errorf("\"state\" should be one of %q, %q, %q, %q", serviceStateStarted, serviceStateStopped, serviceStateRestarted, serviceStateReloaded)
+	}
+	if sa.name == "" && (sa.state != "" || sa.enabled != nil || sa.override != "") {
+		return nil, errors.New("\"name\" in args should be string")
+	}
+	if sa.name == "" && !sa.daemonReload {
+		return nil, errors.New("either \"name\" or \"daemon_reload\" must be provided")
+	}
+
+	return sa, nil
+}
+
+// unit returns the systemd unit name. the ".service" suffix is added when no unit type is specified.
+func (sa serviceArgs) unit() string {
+	if filepath.Ext(sa.name) == "" {
+		return sa.name + ".service"
+	}
+
+	return sa.name
+}
+
+// ModuleService deal "service" module
+func ModuleService(ctx context.Context, options ExecOptions) (string, string) {
+	// get host variable
+	ha, err := options.getAllVariables()
+	if err != nil {
+		return "", err.Error()
+	}
+
+	sa, err := newServiceArgs(ctx, options.Args, ha)
+	if err != nil {
+		klog.V(4).ErrorS(err, "get service args error", "task", ctrlclient.ObjectKeyFromObject(&options.Task))
+
+		return "", err.Error()
+	}
+
+	// get connector
+	conn, err := getConnector(ctx, options.Host, ha)
+	if err != nil {
+		return "", fmt.Sprintf("get connector error: %v", err)
+	}
+	defer conn.Close(ctx)
+
+	var changed bool
+	if isSystemd(ctx, conn) {
+		changed, err = sa.systemd(ctx, conn)
+	} else {
+		klog.V(4).InfoS("systemd is not found, fallback to service command", "host", options.Host)
+		changed, err = sa.sysvinit(ctx, conn)
+	}
+	if err != nil {
+		return "", err.Error()
+	}
+
+	if changed {
+		return StdoutChanged, ""
+	}
+
+	return StdoutSuccess, ""
+}
+
+// isSystemd check if the host is booted with systemd.
+func isSystemd(ctx context.Context, conn connector.Connector) bool {
+	_, err := conn.ExecuteCommand(ctx, "test -d /run/systemd/system && command -v systemctl")
+
+	return err == nil
+}
+
+// systemd manage service by systemctl.
+func (sa serviceArgs) systemd(ctx context.Context, conn connector.Connector) (bool, error) {
+	var changed bool
+	// sync drop-in override file. systemd should reload after the file changed.
+	if sa.override != "" {
+		overrideChanged, err := sa.syncOverride(ctx, conn)
+		if err != nil {
+			return changed, err
+		}
+		if overrideChanged {
+			changed = true
+			sa.daemonReload = true
+		}
+	}
+	if sa.daemonReload {
+		if _, err := conn.ExecuteCommand(ctx, "systemctl daemon-reload"); err != nil {
+			return changed, fmt.Errorf("daemon-reload error: %w", err)
+		}
+	}
+	if sa.name == "" {
+		return changed, nil
+	}
+	// enable or disable service
+	if sa.enabled != nil {
+		// "is-enabled" exits with non-zero when the unit is disabled. the output is what we need.
+		output, _ := conn.ExecuteCommand(ctx, "systemctl is-enabled "+sa.unit())
+		isEnabled := strings.TrimSpace(string(output)) == "enabled"
+		if isEnabled != *sa.enabled {
+			action := "disable"
+			if *sa.enabled {
+				action = "enable"
+			}
+			if _, err := conn.ExecuteCommand(ctx, fmt.Sprintf("systemctl %s %s", action, sa.unit())); err != nil {
+				return changed, fmt.Errorf("%s service %s error: %w", action, sa.name, err)
+			}
+			changed = true
+		}
+	}
+	// change service state
+	if sa.state != "" {
+		output, _ := conn.ExecuteCommand(ctx, "systemctl is-active "+sa.unit())
+		action := serviceAction(sa.state, strings.TrimSpace(string(output)) == "active")
+		if action != "" {
+			if _, err := conn.ExecuteCommand(ctx, fmt.Sprintf("systemctl %s %s", action, sa.unit())); err != nil {
+				return changed, fmt.Errorf("%s service %s error: %w", action, sa.name, err)
+			}
+			changed = true
+		}
+	}
+
+	return changed, nil
+}
+
+// syncOverride write drop-in file to /etc/systemd/system/<unit>.d/<override_name>.conf when its content is different.
+func (sa serviceArgs) syncOverride(ctx context.Context, conn connector.Connector) (bool, error) {
+	dest := filepath.Join("/etc/systemd/system", sa.unit()+".d", sa.overrideName+".conf")
+	content := []byte(sa.override)
+	if !bytes.HasSuffix(content, []byte("\n")) {
+		content = append(content, '\n')
+	}
+
+	var old bytes.Buffer
+	if err := conn.FetchFile(ctx, dest, &old); err == nil && bytes.Equal(old.Bytes(), content) {
+		return false, nil
+	}
+	if err := conn.PutFile(ctx, content, dest, 0644); err != nil {
+		return false, fmt.Errorf("write override file %s error: %w", dest, err)
+	}
+
+	return true, nil
+}
+
+// sysvinit manage service by "service" command when systemd is not exist.
+func (sa serviceArgs) sysvinit(ctx context.Context, conn connector.Connector) (bool, error) {
+	if sa.override != "" {
+		return false, errors.New("\"override\" is only supported by systemd")
+	}
+	if sa.daemonReload {
+		klog.V(4).InfoS("systemd is not found, skip daemon_reload", "service", sa.name)
+	}
+	if sa.name == "" {
+		return false, nil
+	}
+
+	var changed bool
+	if sa.enabled != nil {
+		enableChanged, err := sa.sysvinitEnable(ctx, conn)
+		if err != nil {
+			return changed, err
+		}
+		changed = enableChanged
+	}
+	if sa.state != "" {
+		_, err := conn.ExecuteCommand(ctx, fmt.Sprintf("service %s status", sa.name))
+		action := serviceAction(sa.state, err == nil)
+		if action != "" {
+			if _, err := conn.ExecuteCommand(ctx, fmt.Sprintf("service %s %s", sa.name, action)); err != nil {
+				return changed, fmt.Errorf("%s service %s error: %w", action, sa.name, err)
+			}
+			changed = true
+		}
+	}
+
+	return changed, nil
+}
+
+// sysvinitEnable enable or disable service by chkconfig or update-rc.d.
+func (sa serviceArgs) sysvinitEnable(ctx context.Context, conn connector.Connector) (bool, error) {
+	switch {
+	case commandExist(ctx, conn, "chkconfig"):
+		_, err := conn.ExecuteCommand(ctx, "chkconfig "+sa.name)
+		if (err == nil) == *sa.enabled {
+			return false, nil
+		}
+		action := "off"
+		if *sa.enabled {
+			action = "on"
+		}
+		if _, err := conn.ExecuteCommand(ctx, fmt.Sprintf("chkconfig %s %s", sa.name, action)); err != nil {
+			return false, fmt.Errorf("chkconfig service %s error: %w", sa.name, err)
+		}
+
+		return true, nil
+	case commandExist(ctx, conn, "update-rc.d"):
+		_, err := conn.ExecuteCommand(ctx, fmt.Sprintf("ls /etc/rc?.d/S??%s", sa.name))
+		if (err == nil) == *sa.enabled {
+			return false, nil
+		}
+		action := "disable"
+		if *sa.enabled {
+			action = "enable"
+		}
+		if _, err := conn.ExecuteCommand(ctx, fmt.Sprintf("update-rc.d %s %s", sa.name, action)); err != nil {
+			return false, fmt.Errorf("update-rc.d service %s error: %w", sa.name, err)
+		}
+
+		return true, nil
+	default:
+		return false, errors.New("neither systemctl, chkconfig nor update-rc.d is found to manage \"enabled\"")
+	}
+}
+
+// serviceAction returns the command action to reach the expected state. return empty if nothing to do.
+func serviceAction(state string, active bool) string {
+	switch state {
+	case serviceStateStarted:
+		if !active {
+			return "start"
+		}
+	case serviceStateStopped:
+		if active {
+			return "stop"
+		}
+	case serviceStateRestarted:
+		return "restart"
+	case serviceStateReloaded:
+		if !active {
+			return "start"
+		}
+
+		return "reload"
+	}
+
+	return ""
+}
+
+// commandExist check if the command is exist in remote host.
+func commandExist(ctx context.Context, conn connector.Connector, cmd string) bool {
+	_, err := conn.ExecuteCommand(ctx, "command -v "+cmd)
+
+	return err == nil
+}
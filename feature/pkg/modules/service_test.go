@@ -0,0 +1,166 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package modules
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestService(t *testing.T) {
+	testcases := []struct {
+		name         string
+		opt          ExecOptions
+		ctxFunc      func() context.Context
+		exceptStdout string
+		exceptStderr string
+	}{
+		{
+			name: "name and daemon_reload is empty",
+			opt: ExecOptions{
+				Args:     runtime.RawExtension{},
+				Host:     "local",
+				Variable: &testVariable{},
+			},
+			ctxFunc: func() context.Context {
+				return context.WithValue(context.Background(), ConnKey, successConnector)
+			},
+			exceptStderr: "either \"name\" or \"daemon_reload\" must be provided",
+		},
+		{
+			name: "state without name",
+			opt: ExecOptions{
+				Args: runtime.RawExtension{
+					Raw: []byte(`{"state": "started", "daemon_reload": true}`),
+				},
+				Host:     "local",
+				Variable: &testVariable{},
+			},
+			ctxFunc: func() context.Context {
+				return context.WithValue(context.Background(), ConnKey, successConnector)
+			},
+			exceptStderr: "\"name\" in args should be string",
+		},
+		{
+			name: "unknown state",
+			opt: ExecOptions{
+				Args: runtime.RawExtension{
+					Raw: []byte(`{"name": "kubelet", "state": "running"}`),
+				},
+				Host:     "local",
+				Variable: &testVariable{},
+			},
+			ctxFunc: func() context.Context {
+				return context.WithValue(context.Background(), ConnKey, successConnector)
+			},
+			exceptStderr: "\"state\" should be one of \"started\", \"stopped\", \"restarted\", \"reloaded\"",
+		},
+		{
+			name: "daemon_reload only",
+			opt: ExecOptions{
+				Args: runtime.RawExtension{
+					Raw: []byte(`{"daemon_reload": true}`),
+				},
+				Host:     "local",
+				Variable: &testVariable{},
+			},
+			ctxFunc: func() context.Context {
+				return context.WithValue(context.Background(), ConnKey, successConnector)
+			},
+			exceptStdout: StdoutSuccess,
+		},
+		{
+			name: "start inactive service",
+			opt: ExecOptions{
+				Args: runtime.RawExtension{
+					Raw: []byte(`{"name": "kubelet", "state": "started"}`),
+				},
+				Host:     "local",
+				Variable: &testVariable{},
+			},
+			ctxFunc: func() context.Context {
+				return context.WithValue(context.Background(), ConnKey, successConnector)
+			},
+			exceptStdout: StdoutChanged,
+		},
+		{
+			name: "start active service",
+			opt: ExecOptions{
+				Args: runtime.RawExtension{
+					Raw: []byte(`{"name": "kubelet", "state": "started"}`),
+				},
+				Host:     "local",
+				Variable: &testVariable{},
+			},
+			ctxFunc: func() context.Context {
+				return context.WithValue(context.Background(), ConnKey, &testConnector{output: []byte("active\n")})
+			},
+			exceptStdout: StdoutSuccess,
+		},
+		{
+			name: "override without systemd",
+			opt: ExecOptions{
+				Args: runtime.RawExtension{
+					Raw: []byte(`{"name": "kubelet", "override": "[Service]\nRestart=always"}`),
+				},
+				Host:     "local",
+				Variable: &testVariable{},
+			},
+			ctxFunc: func() context.Context {
+				return context.WithValue(context.Background(), ConnKey, failedConnector)
+			},
+			exceptStderr: "\"override\" is only supported by systemd",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(tc.ctxFunc(), time.Second*5)
+			defer cancel()
+
+			acStdout, acStderr := ModuleService(ctx, tc.opt)
+			assert.Equal(t, tc.exceptStdout, acStdout)
+			assert.Equal(t, tc.exceptStderr, acStderr)
+		})
+	}
+}
+
+func TestServiceAction(t *testing.T) {
+	testcases := []struct {
+		state  string
+		active bool
+		except string
+	}{
+		{state: serviceStateStarted, active: false, except: "start"},
+		{state: serviceStateStarted, active: true, except: ""},
+		{state: serviceStateStopped, active: true, except: "stop"},
+		{state: serviceStateStopped, active: false, except: ""},
+		{state: serviceStateRestarted, active: true, except: "restart"},
+		{state: serviceStateReloaded, active: false, except: "start"},
+		{state: serviceStateReloaded, active: true, except: "reload"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.state, func(t *testing.T) {
+			assert.Equal(t, tc.except, serviceAction(tc.state, tc.active))
+		})
+	}
+}
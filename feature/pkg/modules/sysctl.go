@@ -0,0 +1,170 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package modules
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kubesphere/kubekey/v4/pkg/connector"
+	"github.com/kubesphere/kubekey/v4/pkg/variable"
+)
+
+// defaultSysctlFile is the file to persist sysctl key.
+const defaultSysctlFile = "/etc/sysctl.conf"
+
+type sysctlArgs struct {
+	name       string `arg:"name,required"`
+	value      string `arg:"value"`
+	state      string `arg:"state"`
+	sysctlFile string `arg:"sysctl_file"`
+	reload     bool   `arg:"reload"`
+}
+
+func newSysctlArgs(_ context.Context, raw runtime.RawExtension, vars map[string]any) (*sysctlArgs, error) {
+	var err error
+	sa := &sysctlArgs{reload: true}
+	args := variable.Extension2Variables(raw)
+	sa.name, err = variable.StringVar(vars, args, "name")
+	if err != nil {
+		return nil, errors.New("\"name\" in args should be string")
+	}
+	// value maybe number in yaml
+	if value, err := variable.StringVar(vars, args, "value"); err == nil {
+		sa.value = value
+	} else if value, err := variable.IntVar(vars, args, "value"); err == nil {
+		sa.value = fmt.Sprintf("%d", *value)
+	}
+	sa.state, _ = variable.StringVar(vars, args, "state")
+	if sa.state == "" {
+		sa.state = lineStatePresent
+	}
+	sa.sysctlFile, _ = variable.StringVar(vars, args, "sysctl_file")
+	if sa.sysctlFile == "" {
+		sa.sysctlFile = defaultSysctlFile
+	}
+	if reload, err := variable.BoolVar(vars, args, "reload"); err == nil {
+		sa.reload = *reload
+	}
+
+	switch sa.state {
+	case lineStatePresent:
+		if sa.value == "" {
+			return nil, errors.New("\"value\" is required when state is present")
+		}
+	case lineStateAbsent:
+	default:
+		return nil, fmt.Errorf("\"state\" should be one of %q, %q", lineStatePresent, lineStateAbsent)
+	}
+
+	return sa, nil
+}
+
+// ModuleSysctl deal "sysctl" module
+func ModuleSysctl(ctx context.Context, options ExecOptions) (string, string) {
+	// get host variable
+	ha, err := options.getAllVariables()
+	if err != nil {
+		return "", err.Error()
+	}
+
+	sa, err := newSysctlArgs(ctx, options.Args, ha)
+	if err != nil {
+		klog.V(4).ErrorS(err, "get sysctl args error", "task", ctrlclient.ObjectKeyFromObject(&options.Task))
+
+		return "", err.Error()
+	}
+
+	// get connector
+	conn, err := getConnector(ctx, options.Host, ha)
+	if err != nil {
+		return "", fmt.Sprintf("get connector error: %v", err)
+	}
+	defer conn.Close(ctx)
+
+	// persist key to sysctl file
+	content, mode, _, err := readRemoteFile(ctx, conn, sa.sysctlFile)
+	if err != nil {
+		return "", err.Error()
+	}
+	result := sa.apply(content)
+	changed := !bytes.Equal(content, result)
+	if changed {
+		if err := conn.PutFile(ctx, result, sa.sysctlFile, mode); err != nil {
+			return "", fmt.Sprintf("write file %s error: %v", sa.sysctlFile, err)
+		}
+	}
+	// apply value to kernel
+	if sa.state == lineStatePresent && sa.reload {
+		liveChanged, err := sa.applyLive(ctx, conn)
+		if err != nil {
+			return "", err.Error()
+		}
+		changed = changed || liveChanged
+	}
+
+	if changed {
+		return StdoutChanged, ""
+	}
+
+	return StdoutSuccess, ""
+}
+
+// apply sysctl key to the file content and return the new content.
+// The key is kept only once in file, which has been set to the value.
+func (sa sysctlArgs) apply(content []byte) []byte {
+	lines := splitLines(content)
+	exp := regexp.MustCompile(`^\s*` + regexp.QuoteMeta(sa.name) + `\s*=`)
+	line := fmt.Sprintf("%s = %s", sa.name, sa.value)
+
+	index := slices.IndexFunc(lines, exp.MatchString)
+	lines = slices.DeleteFunc(lines, exp.MatchString)
+	if sa.state == lineStatePresent {
+		if index == -1 {
+			index = len(lines)
+		}
+		lines = slices.Insert(lines, index, line)
+	}
+
+	return joinLines(lines)
+}
+
+// applyLive set the sysctl value to kernel when it is different with current value.
+func (sa sysctlArgs) applyLive(ctx context.Context, conn connector.Connector) (bool, error) {
+	current, err := conn.ExecuteCommand(ctx, "sysctl -n "+sa.name)
+	if err != nil {
+		return false, fmt.Errorf("get sysctl %s error: %w", sa.name, err)
+	}
+	// multi values is split by tab in sysctl output.
+	if strings.Join(strings.Fields(string(current)), " ") == strings.Join(strings.Fields(sa.value), " ") {
+		return false, nil
+	}
+	if _, err := conn.ExecuteCommand(ctx, fmt.Sprintf("sysctl -w %s=%q", sa.name, sa.value)); err != nil {
+		return false, fmt.Errorf("set sysctl %s error: %w", sa.name, err)
+	}
+
+	return true, nil
+}
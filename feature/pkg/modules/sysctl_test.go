@@ -0,0 +1,57 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package modules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSysctlApply(t *testing.T) {
+	testcases := []struct {
+		name    string
+		args    sysctlArgs
+		content string
+		except  string
+	}{
+		{
+			name:    "append key",
+			args:    sysctlArgs{name: "net.ipv4.ip_forward", value: "1", state: lineStatePresent},
+			content: "vm.swappiness = 0\n",
+			except:  "vm.swappiness = 0\nnet.ipv4.ip_forward = 1\n",
+		},
+		{
+			name:    "replace and deduplicate key",
+			args:    sysctlArgs{name: "net.ipv4.ip_forward", value: "1", state: lineStatePresent},
+			content: "net.ipv4.ip_forward=0\nvm.swappiness = 0\nnet.ipv4.ip_forward = 1\n",
+			except:  "net.ipv4.ip_forward = 1\nvm.swappiness = 0\n",
+		},
+		{
+			name:    "remove key",
+			args:    sysctlArgs{name: "net.ipv4.ip_forward", state: lineStateAbsent},
+			content: "net.ipv4.ip_forward = 1\nvm.swappiness = 0\n",
+			except:  "vm.swappiness = 0\n",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.except, string(tc.args.apply([]byte(tc.content))))
+		})
+	}
+}
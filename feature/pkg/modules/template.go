@@ -38,9 +38,9 @@ import (
 )
 
 type templateArgs struct {
-	src  string
-	dest string
-	mode *int
+	src  string `arg:"src,required"`
+	dest string `arg:"dest,required"`
+	mode *int   `arg:"mode"`
 }
 
 func newTemplateArgs(_ context.Context, raw runtime.RawExtension, vars map[string]any) (*templateArgs, error) {
@@ -88,6 +88,7 @@ func ModuleTemplate(ctx context.Context, options ExecOptions) (string, string) {
 	}
 	defer conn.Close(ctx)
 
+	var changed bool
 	if filepath.IsAbs(ta.src) {
 		fileInfo, err := os.Stat(ta.src)
 		if err != nil {
@@ -95,11 +96,11 @@ func ModuleTemplate(ctx context.Context, options ExecOptions) (string, string) {
 		}
 
 		if fileInfo.IsDir() { // src is dir
-			if err := ta.absDir(ctx, conn, ha); err != nil {
+			if changed, err = ta.absDir(ctx, conn, ha); err != nil {
 				return "", fmt.Sprintf("sync template absolute dir error %s", err)
 			}
 		} else { // src is file
-			if err := ta.absFile(ctx, fileInfo.Mode(), conn, ha); err != nil {
+			if changed, err = ta.absFile(ctx, fileInfo.Mode(), conn, ha); err != nil {
 				return "", fmt.Sprintf("sync template absolute file error %s", err)
 			}
 		}
@@ -115,29 +116,32 @@ func ModuleTemplate(ctx context.Context, options ExecOptions) (string, string) {
 		}
 
 		if fileInfo.IsDir() {
-			if err := ta.relDir(ctx, pj, options.Task.Annotations[kkcorev1alpha1.TaskAnnotationRole], conn, ha); err != nil {
+			if changed, err = ta.relDir(ctx, pj, options.Task.Annotations[kkcorev1alpha1.TaskAnnotationRole], conn, ha); err != nil {
 				return "", fmt.Sprintf("sync template relative dir error: %s", err)
 			}
 		} else {
-			if err := ta.relFile(ctx, pj, options.Task.Annotations[kkcorev1alpha1.TaskAnnotationRole], fileInfo.Mode(), conn, ha); err != nil {
+			if changed, err = ta.relFile(ctx, pj, options.Task.Annotations[kkcorev1alpha1.TaskAnnotationRole], fileInfo.Mode(), conn, ha); err != nil {
 				return "", fmt.Sprintf("sync template relative dir error: %s", err)
 			}
 		}
 	}
+	if changed {
+		return StdoutChanged, ""
+	}
 
 	return StdoutSuccess, ""
 }
 
 // relFile when template.src is relative file, get file from project, parse it, and copy to remote.
-func (ta templateArgs) relFile(ctx context.Context, pj project.Project, role string, mode fs.FileMode, conn connector.Connector, vars map[string]any) any {
+func (ta templateArgs) relFile(ctx context.Context, pj project.Project, role string, mode fs.FileMode, conn connector.Connector, vars map[string]any) (bool, error) {
 	data, err := pj.ReadFile(ta.src, project.GetFileOption{IsTemplate: true, Role: role})
 	if err != nil {
-		return fmt.Errorf("read file error: %w", err)
+		return false, fmt.Errorf("read file error: %w", err)
 	}
 
 	result, err := tmpl.ParseString(vars, string(data))
 	if err != nil {
-		return fmt.Errorf("parse file error: %w", err)
+		return false, fmt.Errorf("parse file error: %w", err)
 	}
 
 	dest := ta.dest
@@ -149,15 +153,17 @@ func (ta templateArgs) relFile(ctx context.Context, pj project.Project, role str
 		mode = os.FileMode(*ta.mode)
 	}
 
-	if err := conn.PutFile(ctx, []byte(result), dest, mode); err != nil {
-		return fmt.Errorf("copy file error: %w", err)
+	changed, err := putFileIfChanged(ctx, conn, []byte(result), dest, mode)
+	if err != nil {
+		return false, fmt.Errorf("copy file error: %w", err)
 	}
 
-	return nil
+	return changed, nil
 }
 
 // relDir when template.src is relative dir, get all files from project, parse it, and copy to remote.
-func (ta templateArgs) relDir(ctx context.Context, pj project.Project, role string, conn connector.Connector, vars map[string]any) error {
+func (ta templateArgs) relDir(ctx context.Context, pj project.Project, role string, conn connector.Connector, vars map[string]any) (bool, error) {
+	var changed bool
 	if err := pj.WalkDir(ta.src, project.GetFileOption{IsTemplate: true, Role: role}, func(path string, d fs.DirEntry, err error) error {
 		if d.IsDir() { // only copy file
 			return nil
@@ -194,28 +200,30 @@ func (ta templateArgs) relDir(ctx context.Context, pj project.Project, role stri
 			dest = filepath.Join(ta.dest, rel)
 		}
 
-		if err := conn.PutFile(ctx, []byte(result), dest, mode); err != nil {
+		fileChanged, err := putFileIfChanged(ctx, conn, []byte(result), dest, mode)
+		if err != nil {
 			return fmt.Errorf("copy file error: %w", err)
 		}
+		changed = changed || fileChanged
 
 		return nil
 	}); err != nil {
-		return err
+		return false, err
 	}
 
-	return nil
+	return changed, nil
 }
 
 // absFile when template.src is absolute file, get file by os, parse it, and copy to remote.
-func (ta templateArgs) absFile(ctx context.Context, mode fs.FileMode, conn connector.Connector, vars map[string]any) error {
+func (ta templateArgs) absFile(ctx context.Context, mode fs.FileMode, conn connector.Connector, vars map[string]any) (bool, error) {
 	data, err := os.ReadFile(ta.src)
 	if err != nil {
-		return fmt.Errorf("read file error: %w", err)
+		return false, fmt.Errorf("read file error: %w", err)
 	}
 
 	result, err := tmpl.ParseString(vars, string(data))
 	if err != nil {
-		return fmt.Errorf("parse file error: %w", err)
+		return false, fmt.Errorf("parse file error: %w", err)
 	}
 
 	dest := ta.dest
@@ -227,15 +235,17 @@ func (ta templateArgs) absFile(ctx context.Context, mode fs.FileMode, conn conne
 		mode = os.FileMode(*ta.mode)
 	}
 
-	if err := conn.PutFile(ctx, []byte(result), dest, mode); err != nil {
-		return fmt.Errorf("copy file error: %w", err)
+	changed, err := putFileIfChanged(ctx, conn, []byte(result), dest, mode)
+	if err != nil {
+		return false, fmt.Errorf("copy file error: %w", err)
 	}
 
-	return nil
+	return changed, nil
 }
 
 // absDir when template.src is absolute dir, get all files by os, parse it, and copy to remote.
-func (ta templateArgs) absDir(ctx context.Context, conn connector.Connector, vars map[string]any) error {
+func (ta templateArgs) absDir(ctx context.Context, conn connector.Connector, vars map[string]any) (bool, error) {
+	var changed bool
 	if err := filepath.WalkDir(ta.src, func(path string, d fs.DirEntry, err error) error {
 		if d.IsDir() { // only copy file
 			return nil
@@ -272,14 +282,16 @@ func (ta templateArgs) absDir(ctx context.Context, conn connector.Connector, var
 			dest = filepath.Join(ta.dest, rel)
 		}
 
-		if err := conn.PutFile(ctx, []byte(result), dest, mode); err != nil {
+		fileChanged, err := putFileIfChanged(ctx, conn, []byte(result), dest, mode)
+		if err != nil {
 			return fmt.Errorf("copy file error: %w", err)
 		}
+		changed = changed || fileChanged
 
 		return nil
 	}); err != nil {
-		return err
+		return false, err
 	}
 
-	return nil
+	return changed, nil
 }
@@ -0,0 +1,120 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package modules
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kubesphere/kubekey/v4/pkg/variable"
+)
+
+type unarchiveArgs struct {
+	src             string `arg:"src,required"`
+	dest            string `arg:"dest,required"`
+	creates         string `arg:"creates"`
+	stripComponents *int   `arg:"strip_components"`
+}
+
+func newUnarchiveArgs(_ context.Context, raw runtime.RawExtension, vars map[string]any) (*unarchiveArgs, error) {
+	var err error
+	ua := &unarchiveArgs{}
+	args := variable.Extension2Variables(raw)
+	ua.src, err = variable.StringVar(vars, args, "src")
+	if err != nil || ua.src == "" {
+		return nil, errors.New("\"src\" in args should be string")
+	}
+	ua.dest, err = variable.StringVar(vars, args, "dest")
+	if err != nil || ua.dest == "" {
+		return nil, errors.New("\"dest\" in args should be string")
+	}
+	ua.creates, _ = variable.StringVar(vars, args, "creates")
+	ua.stripComponents, _ = variable.IntVar(vars, args, "strip_components")
+
+	if _, err := ua.command(); err != nil {
+		return nil, err
+	}
+
+	return ua, nil
+}
+
+// ModuleUnarchive deal "unarchive" module
+func ModuleUnarchive(ctx context.Context, options ExecOptions) (string, string) {
+	// get host variable
+	ha, err := options.getAllVariables()
+	if err != nil {
+		return "", err.Error()
+	}
+
+	ua, err := newUnarchiveArgs(ctx, options.Args, ha)
+	if err != nil {
+		klog.V(4).ErrorS(err, "get unarchive args error", "task", ctrlclient.ObjectKeyFromObject(&options.Task))
+
+		return "", err.Error()
+	}
+
+	// get connector
+	conn, err := getConnector(ctx, options.Host, ha)
+	if err != nil {
+		return "", fmt.Sprintf("get connector error: %v", err)
+	}
+	defer conn.Close(ctx)
+
+	// the archive has been extracted when creates is exist.
+	if ua.creates != "" {
+		if fi, err := statRemoteFile(ctx, conn, ua.creates); err != nil {
+			return "", err.Error()
+		} else if fi != nil {
+			return StdoutSuccess, ""
+		}
+	}
+	cmd, _ := ua.command()
+	if output, err := conn.ExecuteCommand(ctx, fmt.Sprintf("mkdir -p %s && %s", ua.dest, cmd)); err != nil {
+		return "", fmt.Sprintf("extract %s to %s error: %v, %s", ua.src, ua.dest, err, strings.TrimSpace(string(output)))
+	}
+
+	return StdoutChanged, ""
+}
+
+// command get the command to extract src by the extension of src.
+func (ua unarchiveArgs) command() (string, error) {
+	var strip string
+	if ua.stripComponents != nil {
+		strip = fmt.Sprintf(" --strip-components=%d", *ua.stripComponents)
+	}
+
+	switch {
+	case strings.HasSuffix(ua.src, ".tar.gz"), strings.HasSuffix(ua.src, ".tgz"):
+		return fmt.Sprintf("tar -zxf %s -C %s%s", ua.src, ua.dest, strip), nil
+	case strings.HasSuffix(ua.src, ".tar"):
+		return fmt.Sprintf("tar -xf %s -C %s%s", ua.src, ua.dest, strip), nil
+	case strings.HasSuffix(ua.src, ".zip"):
+		if ua.stripComponents != nil {
+			return "", errors.New("\"strip_components\" is not supported for zip")
+		}
+
+		return fmt.Sprintf("unzip -o -q %s -d %s", ua.src, ua.dest), nil
+	default:
+		return "", fmt.Errorf("unsupported archive %s, only support .tar.gz, .tgz, .tar and .zip", ua.src)
+	}
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package modules
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+)
+
+func TestUnarchive(t *testing.T) {
+	testcases := []struct {
+		name         string
+		opt          ExecOptions
+		ctxFunc      func() context.Context
+		exceptStdout string
+		exceptStderr string
+	}{
+		{
+			name: "src is empty",
+			opt: ExecOptions{
+				Args: runtime.RawExtension{
+					Raw: []byte(`{"dest": "/tmp/etcd"}`),
+				},
+				Host:     "local",
+				Variable: &testVariable{},
+			},
+			ctxFunc: func() context.Context {
+				return context.WithValue(context.Background(), ConnKey, successConnector)
+			},
+			exceptStderr: "\"src\" in args should be string",
+		},
+		{
+			name: "creates is exist",
+			opt: ExecOptions{
+				Args: runtime.RawExtension{
+					Raw: []byte(`{"src": "/tmp/etcd.tar.gz", "dest": "/tmp/etcd", "creates": "/tmp/etcd/etcd"}`),
+				},
+				Host:     "local",
+				Variable: &testVariable{},
+			},
+			ctxFunc: func() context.Context {
+				// the output of stat
+				return context.WithValue(context.Background(), ConnKey, &testConnector{output: []byte("755|regular file")})
+			},
+			exceptStdout: StdoutSuccess,
+		},
+		{
+			name: "extract success",
+			opt: ExecOptions{
+				Args: runtime.RawExtension{
+					Raw: []byte(`{"src": "/tmp/etcd.tar.gz", "dest": "/tmp/etcd"}`),
+				},
+				Host:     "local",
+				Variable: &testVariable{},
+			},
+			ctxFunc: func() context.Context {
+				return context.WithValue(context.Background(), ConnKey, successConnector)
+			},
+			exceptStdout: StdoutChanged,
+		},
+		{
+			name: "extract failed",
+			opt: ExecOptions{
+				Args: runtime.RawExtension{
+					Raw: []byte(`{"src": "/tmp/etcd.tar.gz", "dest": "/tmp/etcd", "creates": "/tmp/etcd/etcd"}`),
+				},
+				Host:     "local",
+				Variable: &testVariable{},
+			},
+			ctxFunc: func() context.Context {
+				// creates is not exist, and the extract command failed.
+				return context.WithValue(context.Background(), ConnKey, failedConnector)
+			},
+			exceptStderr: "extract /tmp/etcd.tar.gz to /tmp/etcd error: failed, ",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(tc.ctxFunc(), time.Second*5)
+			defer cancel()
+			acStdout, acStderr := ModuleUnarchive(ctx, tc.opt)
+			assert.Equal(t, tc.exceptStdout, acStdout)
+			assert.Equal(t, tc.exceptStderr, acStderr)
+		})
+	}
+}
+
+func TestUnarchiveCommand(t *testing.T) {
+	testcases := []struct {
+		name         string
+		args         unarchiveArgs
+		except       string
+		exceptErrMsg string
+	}{
+		{
+			name:   "tar.gz",
+			args:   unarchiveArgs{src: "/tmp/etcd.tar.gz", dest: "/tmp/etcd", stripComponents: ptr.To(1)},
+			except: "tar -zxf /tmp/etcd.tar.gz -C /tmp/etcd --strip-components=1",
+		},
+		{
+			name:   "zip",
+			args:   unarchiveArgs{src: "/tmp/helm.zip", dest: "/tmp/helm"},
+			except: "unzip -o -q /tmp/helm.zip -d /tmp/helm",
+		},
+		{
+			name:         "unsupported",
+			args:         unarchiveArgs{src: "/tmp/helm.rar", dest: "/tmp/helm"},
+			exceptErrMsg: "unsupported archive /tmp/helm.rar, only support .tar.gz, .tgz, .tar and .zip",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			cmd, err := tc.args.command()
+			if tc.exceptErrMsg != "" {
+				assert.EqualError(t, err, tc.exceptErrMsg)
+
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tc.except, cmd)
+		})
+	}
+}
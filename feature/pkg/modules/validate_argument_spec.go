@@ -0,0 +1,206 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package modules
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kkprojectv1 "github.com/kubesphere/kubekey/v4/pkg/apis/project/v1"
+	"github.com/kubesphere/kubekey/v4/pkg/variable"
+)
+
+type validateArgumentSpecArgs struct {
+	argumentSpec map[string]kkprojectv1.RoleArgumentOption `arg:"argument_spec,required"`
+	// providedArguments is the arguments to validate. default is all variables of host.
+	providedArguments map[string]any `arg:"provided_arguments"`
+}
+
+func newValidateArgumentSpecArgs(_ context.Context, raw runtime.RawExtension, vars map[string]any) (*validateArgumentSpecArgs, error) {
+	va := &validateArgumentSpecArgs{providedArguments: vars}
+	args := variable.Extension2Variables(raw)
+	spec, ok := args["argument_spec"]
+	if !ok {
+		return nil, errors.New("\"argument_spec\" in args should be map")
+	}
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return nil, fmt.Errorf("\"argument_spec\" in args is invalid: %w", err)
+	}
+	if err := json.Unmarshal(data, &va.argumentSpec); err != nil {
+		return nil, fmt.Errorf("\"argument_spec\" in args is invalid: %w", err)
+	}
+	if provided, ok := args["provided_arguments"]; ok {
+		pa, ok := provided.(map[string]any)
+		if !ok {
+			return nil, errors.New("\"provided_arguments\" in args should be map")
+		}
+		va.providedArguments = pa
+	}
+
+	return va, nil
+}
+
+// ModuleValidateArgumentSpec deal "validate_argument_spec" module
+func ModuleValidateArgumentSpec(ctx context.Context, options ExecOptions) (string, string) {
+	// get host variable
+	ha, err := options.getAllVariables()
+	if err != nil {
+		return "", err.Error()
+	}
+
+	va, err := newValidateArgumentSpecArgs(ctx, options.Args, ha)
+	if err != nil {
+		klog.V(4).ErrorS(err, "get validate_argument_spec args error", "task", ctrlclient.ObjectKeyFromObject(&options.Task))
+
+		return "", err.Error()
+	}
+
+	if errs := validateArguments(va.argumentSpec, va.providedArguments, ""); len(errs) != 0 {
+		return "", "validation of arguments failed: " + strings.Join(errs, "; ")
+	}
+
+	return StdoutSuccess, ""
+}
+
+// validateArguments check the provided arguments match the spec. return all error messages.
+func validateArguments(spec map[string]kkprojectv1.RoleArgumentOption, provided map[string]any, prefix string) []string {
+	names := make([]string, 0, len(spec))
+	for name := range spec {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []string
+	for _, name := range names {
+		option := spec[name]
+		val, ok := provided[name]
+		if !ok || val == nil {
+			if option.Required {
+				errs = append(errs, fmt.Sprintf("missing required argument: %s%s", prefix, name))
+			}
+
+			continue
+		}
+		errs = append(errs, validateArgument(option, val, prefix+name)...)
+	}
+
+	return errs
+}
+
+// validateArgument check the type, choices and sub options of an argument.
+func validateArgument(option kkprojectv1.RoleArgumentOption, val any, name string) []string {
+	if err := checkArgumentType(option.Type, val); err != nil {
+		return []string{fmt.Sprintf("argument %s: %v", name, err)}
+	}
+	var errs []string
+	if len(option.Choices) != 0 {
+		values := []any{val}
+		if list, ok := val.([]any); ok {
+			values = list
+		}
+		for _, v := range values {
+			if !slices.ContainsFunc(option.Choices, func(c any) bool { return fmt.Sprint(c) == fmt.Sprint(v) }) {
+				errs = append(errs, fmt.Sprintf("argument %s: value %v is not one of %v", name, v, option.Choices))
+			}
+		}
+	}
+	switch v := val.(type) {
+	case map[string]any:
+		if len(option.Options) != 0 {
+			errs = append(errs, validateArguments(option.Options, v, name+".")...)
+		}
+	case []any:
+		for i, e := range v {
+			elem := kkprojectv1.RoleArgumentOption{Type: option.Elements, Options: option.Options}
+			if option.Elements == "" {
+				elem.Type = "raw"
+			}
+			errs = append(errs, validateArgument(elem, e, fmt.Sprintf("%s[%d]", name, i))...)
+		}
+	}
+
+	return errs
+}
+
+// checkArgumentType check the value matches the type. string value which can be converted to the type is allowed,
+// because the variable may be defined by template.
+func checkArgumentType(typ string, val any) error {
+	switch typ {
+	case "", "str", "path":
+		if _, ok := val.(string); !ok {
+			return fmt.Errorf("value %v should be string", val)
+		}
+	case "int":
+		switch v := val.(type) {
+		case int, int64:
+		case float64:
+			if v != float64(int64(v)) {
+				return fmt.Errorf("value %v should be int", val)
+			}
+		case string:
+			if _, err := strconv.Atoi(v); err != nil {
+				return fmt.Errorf("value %v should be int", val)
+			}
+		default:
+			return fmt.Errorf("value %v should be int", val)
+		}
+	case "float":
+		switch v := val.(type) {
+		case int, int64, float64:
+		case string:
+			if _, err := strconv.ParseFloat(v, 64); err != nil {
+				return fmt.Errorf("value %v should be float", val)
+			}
+		default:
+			return fmt.Errorf("value %v should be float", val)
+		}
+	case "bool":
+		switch v := val.(type) {
+		case bool:
+		case string:
+			if _, err := strconv.ParseBool(v); err != nil {
+				return fmt.Errorf("value %v should be bool", val)
+			}
+		default:
+			return fmt.Errorf("value %v should be bool", val)
+		}
+	case "list":
+		if _, ok := val.([]any); !ok {
+			return fmt.Errorf("value %v should be list", val)
+		}
+	case "dict":
+		if _, ok := val.(map[string]any); !ok {
+			return fmt.Errorf("value %v should be dict", val)
+		}
+	case "raw":
+	default:
+		return fmt.Errorf("unsupported type %q", typ)
+	}
+
+	return nil
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package modules
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestValidateArgumentSpec(t *testing.T) {
+	testcases := []struct {
+		name         string
+		opt          ExecOptions
+		exceptStdout string
+		exceptStderr string
+	}{
+		{
+			name: "non-argument_spec",
+			opt: ExecOptions{
+				Host:     "local",
+				Variable: &testVariable{},
+				Args:     runtime.RawExtension{},
+			},
+			exceptStderr: "\"argument_spec\" in args should be map",
+		},
+		{
+			name: "success",
+			opt: ExecOptions{
+				Host: "local",
+				Args: runtime.RawExtension{
+					Raw: []byte(`{"argument_spec": {"version": {"type": "str", "required": true}, "port": {"type": "int"}, "cri": {"type": "dict", "options": {"manager": {"choices": ["docker", "containerd"]}}}}}`),
+				},
+				Variable: &testVariable{
+					value: map[string]any{
+						"version": "v1.23.15",
+						"port":    float64(6443),
+						"cri":     map[string]any{"manager": "containerd"},
+					},
+				},
+			},
+			exceptStdout: StdoutSuccess,
+		},
+		{
+			name: "missing required",
+			opt: ExecOptions{
+				Host: "local",
+				Args: runtime.RawExtension{
+					Raw: []byte(`{"argument_spec": {"version": {"type": "str", "required": true}}}`),
+				},
+				Variable: &testVariable{},
+			},
+			exceptStderr: "validation of arguments failed: missing required argument: version",
+		},
+		{
+			name: "invalid type and choices",
+			opt: ExecOptions{
+				Host: "local",
+				Args: runtime.RawExtension{
+					Raw: []byte(`{"argument_spec": {"port": {"type": "int"}, "cri": {"type": "dict", "options": {"manager": {"choices": ["docker", "containerd"]}}}}}`),
+				},
+				Variable: &testVariable{
+					value: map[string]any{
+						"port": "abc",
+						"cri":  map[string]any{"manager": "crio"},
+					},
+				},
+			},
+			exceptStderr: "validation of arguments failed: argument cri.manager: value crio is not one of [docker containerd]; argument port: value abc should be int",
+		},
+		{
+			name: "provided_arguments",
+			opt: ExecOptions{
+				Host: "local",
+				Args: runtime.RawExtension{
+					Raw: []byte(`{"argument_spec": {"hosts": {"type": "list", "elements": "str"}}, "provided_arguments": {"hosts": ["node1", 1]}}`),
+				},
+				Variable: &testVariable{},
+			},
+			exceptStderr: "validation of arguments failed: argument hosts[1]: value 1 should be string",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+			defer cancel()
+			stdout, stderr := ModuleValidateArgumentSpec(ctx, tc.opt)
+			assert.Equal(t, tc.exceptStdout, stdout)
+			assert.Equal(t, tc.exceptStderr, stderr)
+		})
+	}
+}
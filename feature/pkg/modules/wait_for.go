@@ -0,0 +1,275 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package modules
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kubesphere/kubekey/v4/pkg/connector"
+	_const "github.com/kubesphere/kubekey/v4/pkg/const"
+	"github.com/kubesphere/kubekey/v4/pkg/variable"
+)
+
+// state of wait_for
+const (
+	// waitStateStarted wait for port is open, path is exist or url return the status code.
+	waitStateStarted = "started"
+	// waitStatePresent is alias of waitStateStarted.
+	waitStatePresent = "present"
+	// waitStateStopped wait for port is closed, path is not exist or url not return the status code.
+	waitStateStopped = "stopped"
+	// waitStateAbsent is alias of waitStateStopped.
+	waitStateAbsent = "absent"
+)
+
+// default value of wait_for
+const (
+	defaultWaitHost       = "127.0.0.1"
+	defaultWaitTimeout    = 300 * time.Second
+	defaultWaitSleep      = time.Second
+	defaultWaitStatusCode = 200
+)
+
+type waitForArgs struct {
+	host          string `arg:"host"`
+	port          *int   `arg:"port"`
+	path          string `arg:"path"`
+	searchRegex   string `arg:"search_regex"`
+	url           string `arg:"url"`
+	statusCode    int    `arg:"status_code"`
+	ca            string `arg:"ca"`
+	validateCerts bool   `arg:"validate_certs"`
+	// started is true when state is started or present.
+	started bool          `arg:"state"`
+	timeout time.Duration `arg:"timeout"`
+	delay   time.Duration `arg:"delay"`
+	sleep   time.Duration `arg:"sleep"`
+	// delegateTo is the host to run the check. only support localhost now.
+	delegateTo string `arg:"delegate_to"`
+}
+
+func newWaitForArgs(_ context.Context, raw runtime.RawExtension, vars map[string]any) (*waitForArgs, error) {
+	var err error
+	wa := &waitForArgs{
+		host:          defaultWaitHost,
+		statusCode:    defaultWaitStatusCode,
+		validateCerts: true,
+		started:       true,
+		timeout:       defaultWaitTimeout,
+		sleep:         defaultWaitSleep,
+	}
+	args := variable.Extension2Variables(raw)
+	if host, _ := variable.StringVar(vars, args, "host"); host != "" {
+		wa.host = host
+	}
+	wa.port, _ = variable.IntVar(vars, args, "port")
+	wa.path, _ = variable.StringVar(vars, args, "path")
+	wa.searchRegex, _ = variable.StringVar(vars, args, "search_regex")
+	wa.url, _ = variable.StringVar(vars, args, "url")
+	if statusCode, err := variable.IntVar(vars, args, "status_code"); err == nil {
+		wa.statusCode = *statusCode
+	}
+	wa.ca, _ = variable.StringVar(vars, args, "ca")
+	if validateCerts, err := variable.BoolVar(vars, args, "validate_certs"); err == nil {
+		wa.validateCerts = *validateCerts
+	}
+	switch state, _ := variable.StringVar(vars, args, "state"); state {
+	case "", waitStateStarted, waitStatePresent:
+	case waitStateStopped, waitStateAbsent:
+		wa.started = false
+	default:
+		return nil, fmt.Errorf("\"state\" should be one of %q, %q, %q, %q", waitStateStarted, waitStatePresent, waitStateStopped, waitStateAbsent)
+	}
+	for key, d := range map[string]*time.Duration{"timeout": &wa.timeout, "delay": &wa.delay, "sleep": &wa.sleep} {
+		if _, ok := args[key]; !ok {
+			continue
+		}
+		if *d, err = variable.DurationVar(vars, args, key); err != nil {
+			return nil, fmt.Errorf("\"%s\" in args should be duration: %w", key, err)
+		}
+	}
+	wa.delegateTo, _ = variable.StringVar(vars, args, "delegate_to")
+
+	var targets int
+	for _, set := range []bool{wa.port != nil, wa.path != "", wa.url != ""} {
+		if set {
+			targets++
+		}
+	}
+	if targets != 1 {
+		return nil, errors.New("exactly one of \"port\", \"path\" or \"url\" should be provided")
+	}
+	if wa.searchRegex != "" && wa.path == "" {
+		return nil, errors.New("\"search_regex\" is only supported with \"path\"")
+	}
+	if wa.delegateTo != "" && wa.delegateTo != _const.VariableLocalHost {
+		return nil, fmt.Errorf("\"delegate_to\" only support %q", _const.VariableLocalHost)
+	}
+	if wa.sleep <= 0 {
+		return nil, errors.New("\"sleep\" should be greater than 0")
+	}
+
+	return wa, nil
+}
+
+// ModuleWaitFor deal "wait_for" module
+func ModuleWaitFor(ctx context.Context, options ExecOptions) (string, string) {
+	// get host variable
+	ha, err := options.getAllVariables()
+	if err != nil {
+		return "", err.Error()
+	}
+
+	wa, err := newWaitForArgs(ctx, options.Args, ha)
+	if err != nil {
+		klog.V(4).ErrorS(err, "get wait_for args error", "task", ctrlclient.ObjectKeyFromObject(&options.Task))
+
+		return "", err.Error()
+	}
+
+	// get connector. check from the kubekey running host when delegate to localhost.
+	host := options.Host
+	if wa.delegateTo != "" {
+		host = wa.delegateTo
+		ha = make(map[string]any)
+	}
+	conn, err := getConnector(ctx, host, ha)
+	if err != nil {
+		return "", fmt.Sprintf("get connector error: %v", err)
+	}
+	defer conn.Close(ctx)
+
+	if err := wa.wait(ctx, conn); err != nil {
+		return "", err.Error()
+	}
+
+	return StdoutSuccess, ""
+}
+
+// wait check the condition every sleep duration until it matches the state or timeout.
+func (wa waitForArgs) wait(ctx context.Context, conn connector.Connector) error {
+	ctx, cancel := context.WithTimeout(ctx, wa.delay+wa.timeout)
+	defer cancel()
+
+	if wa.delay > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wa.delay):
+		}
+	}
+
+	ticker := time.NewTicker(wa.sleep)
+	defer ticker.Stop()
+	for {
+		ok, detail := wa.check(ctx, conn)
+		if ok == wa.started {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timeout waiting for %s after %s: %s", wa.target(), wa.timeout, detail)
+		case <-ticker.C:
+		}
+	}
+}
+
+// check the condition once. return true if the port is open, path is exist or url return the status code.
+// detail describes the current result, which is used in timeout message.
+func (wa waitForArgs) check(ctx context.Context, conn connector.Connector) (bool, string) {
+	switch {
+	case wa.port != nil:
+		// /dev/tcp is supported by bash, and the connector runs command by sh.
+		output, err := conn.ExecuteCommand(ctx, fmt.Sprintf("timeout 1 bash -c 'cat < /dev/null > /dev/tcp/%s/%d'", wa.host, *wa.port))
+		if err != nil {
+			return false, fmt.Sprintf("port is closed: %s", strings.TrimSpace(string(output)))
+		}
+
+		return true, "port is open"
+	case wa.path != "":
+		if _, err := conn.ExecuteCommand(ctx, "test -e "+shellQuote(wa.path)); err != nil {
+			return false, "path is not exist"
+		}
+		if wa.searchRegex == "" {
+			return true, "path is exist"
+		}
+		if _, err := conn.ExecuteCommand(ctx, wa.searchCommand()); err != nil {
+			return false, "search_regex is not found"
+		}
+
+		return true, "search_regex is found"
+	default:
+		output, err := conn.ExecuteCommand(ctx, wa.curlCommand())
+		if err != nil {
+			return false, fmt.Sprintf("request error: %s", strings.TrimSpace(string(output)))
+		}
+		code, err := strconv.Atoi(strings.TrimSpace(string(output)))
+		if err != nil {
+			return false, fmt.Sprintf("unexpected status code: %s", strings.TrimSpace(string(output)))
+		}
+		if code != wa.statusCode {
+			return false, fmt.Sprintf("status code is %d", code)
+		}
+
+		return true, fmt.Sprintf("status code is %d", code)
+	}
+}
+
+// curlCommand get the command to request url, which only output the status code.
+func (wa waitForArgs) curlCommand() string {
+	cmd := []string{"curl", "-s", "-o", "/dev/null", "-w", "'%{http_code}'", "--max-time", "5"}
+	if !wa.validateCerts {
+		cmd = append(cmd, "-k")
+	}
+	if wa.ca != "" {
+		cmd = append(cmd, "--cacert", shellQuote(wa.ca))
+	}
+
+	return strings.Join(append(cmd, shellQuote(wa.url)), " ")
+}
+
+// searchCommand get the command to search the search_regex in path.
+func (wa waitForArgs) searchCommand() string {
+	return fmt.Sprintf("grep -Eq %s %s", shellQuote(wa.searchRegex), shellQuote(wa.path))
+}
+
+// shellQuote quote s with single quotes, so that nothing in it is expanded by shell.
+// the single quote in s is closed, escaped and reopened.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// target describes what is waiting for.
+func (wa waitForArgs) target() string {
+	switch {
+	case wa.port != nil:
+		return fmt.Sprintf("port %s:%d", wa.host, *wa.port)
+	case wa.path != "":
+		return "path " + wa.path
+	default:
+		return "url " + wa.url
+	}
+}
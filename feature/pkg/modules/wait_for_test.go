@@ -0,0 +1,184 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package modules
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestWaitFor(t *testing.T) {
+	testcases := []struct {
+		name         string
+		opt          ExecOptions
+		ctxFunc      func() context.Context
+		exceptStdout string
+		exceptStderr string
+	}{
+		{
+			name: "target is empty",
+			opt: ExecOptions{
+				Args:     runtime.RawExtension{},
+				Host:     "local",
+				Variable: &testVariable{},
+			},
+			ctxFunc: func() context.Context {
+				return context.WithValue(context.Background(), ConnKey, successConnector)
+			},
+			exceptStderr: "exactly one of \"port\", \"path\" or \"url\" should be provided",
+		},
+		{
+			name: "more than one target",
+			opt: ExecOptions{
+				Args: runtime.RawExtension{
+					Raw: []byte(`{"port": 6443, "path": "/etc/kubernetes/admin.conf"}`),
+				},
+				Host:     "local",
+				Variable: &testVariable{},
+			},
+			ctxFunc: func() context.Context {
+				return context.WithValue(context.Background(), ConnKey, successConnector)
+			},
+			exceptStderr: "exactly one of \"port\", \"path\" or \"url\" should be provided",
+		},
+		{
+			name: "invalid timeout",
+			opt: ExecOptions{
+				Args: runtime.RawExtension{
+					Raw: []byte(`{"port": 6443, "timeout": "ten"}`),
+				},
+				Host:     "local",
+				Variable: &testVariable{},
+			},
+			ctxFunc: func() context.Context {
+				return context.WithValue(context.Background(), ConnKey, successConnector)
+			},
+			exceptStderr: "\"timeout\" in args should be duration: time: invalid duration \"ten\"",
+		},
+		{
+			name: "unsupported delegate_to",
+			opt: ExecOptions{
+				Args: runtime.RawExtension{
+					Raw: []byte(`{"port": 6443, "delegate_to": "node1"}`),
+				},
+				Host:     "local",
+				Variable: &testVariable{},
+			},
+			ctxFunc: func() context.Context {
+				return context.WithValue(context.Background(), ConnKey, successConnector)
+			},
+			exceptStderr: "\"delegate_to\" only support \"localhost\"",
+		},
+		{
+			name: "port is open",
+			opt: ExecOptions{
+				Args: runtime.RawExtension{
+					Raw: []byte(`{"port": 6443}`),
+				},
+				Host:     "local",
+				Variable: &testVariable{},
+			},
+			ctxFunc: func() context.Context {
+				return context.WithValue(context.Background(), ConnKey, successConnector)
+			},
+			exceptStdout: StdoutSuccess,
+		},
+		{
+			name: "port is closed",
+			opt: ExecOptions{
+				Args: runtime.RawExtension{
+					Raw: []byte(`{"port": 6443, "state": "stopped"}`),
+				},
+				Host:     "local",
+				Variable: &testVariable{},
+			},
+			ctxFunc: func() context.Context {
+				return context.WithValue(context.Background(), ConnKey, failedConnector)
+			},
+			exceptStdout: StdoutSuccess,
+		},
+		{
+			name: "path timeout",
+			opt: ExecOptions{
+				Args: runtime.RawExtension{
+					Raw: []byte(`{"path": "/etc/kubernetes/admin.conf", "timeout": "20ms", "sleep": "10ms"}`),
+				},
+				Host:     "local",
+				Variable: &testVariable{},
+			},
+			ctxFunc: func() context.Context {
+				return context.WithValue(context.Background(), ConnKey, failedConnector)
+			},
+			exceptStderr: "timeout waiting for path /etc/kubernetes/admin.conf after 20ms: path is not exist",
+		},
+		{
+			name: "url return status code",
+			opt: ExecOptions{
+				Args: runtime.RawExtension{
+					Raw: []byte(`{"url": "https://127.0.0.1:6443/healthz", "validate_certs": false}`),
+				},
+				Host:     "local",
+				Variable: &testVariable{},
+			},
+			ctxFunc: func() context.Context {
+				return context.WithValue(context.Background(), ConnKey, &testConnector{output: []byte("200")})
+			},
+			exceptStdout: StdoutSuccess,
+		},
+		{
+			name: "url return unexpected status code",
+			opt: ExecOptions{
+				Args: runtime.RawExtension{
+					Raw: []byte(`{"url": "https://127.0.0.1:6443/healthz", "timeout": "20ms", "sleep": "10ms"}`),
+				},
+				Host:     "local",
+				Variable: &testVariable{},
+			},
+			ctxFunc: func() context.Context {
+				return context.WithValue(context.Background(), ConnKey, &testConnector{output: []byte("503")})
+			},
+			exceptStderr: "timeout waiting for url https://127.0.0.1:6443/healthz after 20ms: status code is 503",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(tc.ctxFunc(), time.Second*5)
+			defer cancel()
+			acStdout, acStderr := ModuleWaitFor(ctx, tc.opt)
+			assert.Equal(t, tc.exceptStdout, acStdout)
+			assert.Equal(t, tc.exceptStderr, acStderr)
+		})
+	}
+}
+
+func TestWaitForCurlCommand(t *testing.T) {
+	wa := waitForArgs{url: "https://127.0.0.1:6443/healthz", ca: "/etc/kubernetes/pki/ca.crt", validateCerts: true}
+	assert.Equal(t, `curl -s -o /dev/null -w '%{http_code}' --max-time 5 --cacert '/etc/kubernetes/pki/ca.crt' 'https://127.0.0.1:6443/healthz'`, wa.curlCommand())
+
+	wa = waitForArgs{url: "https://127.0.0.1/$(id)?a='b'", validateCerts: true}
+	assert.Equal(t, `curl -s -o /dev/null -w '%{http_code}' --max-time 5 'https://127.0.0.1/$(id)?a='\''b'\'''`, wa.curlCommand())
+}
+
+func TestWaitForSearchCommand(t *testing.T) {
+	wa := waitForArgs{path: "/var/log/my app.log", searchRegex: "ready `id` $HOME 'ok'"}
+	assert.Equal(t, "grep -Eq 'ready `id` $HOME '\\''ok'\\''' '/var/log/my app.log'", wa.searchCommand())
+}
@@ -0,0 +1,493 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package project
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"k8s.io/klog/v2"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/registry/remote"
+	"oras.land/oras-go/v2/registry/remote/auth"
+	"oras.land/oras-go/v2/registry/remote/credentials"
+
+	kkcorev1 "github.com/kubesphere/kubekey/v4/pkg/apis/core/v1"
+	_const "github.com/kubesphere/kubekey/v4/pkg/const"
+)
+
+const (
+	// ociPrefix is the prefix of project address which stored in oci registry.
+	ociPrefix = "oci://"
+	// ArtifactType of project in oci registry.
+	ArtifactType = "application/vnd.kubesphere.kubekey.project.v1"
+	// LayerMediaType of project in oci registry. the layer is the tar.gz of project dir.
+	LayerMediaType = "application/vnd.kubesphere.kubekey.project.layer.v1.tar+gzip"
+	// SignatureMediaType of project in oci registry. the layer is the armored OpenPGP detached signature of project layer.
+	SignatureMediaType = "application/vnd.kubesphere.kubekey.project.signature.v1+pgp"
+)
+
+// IsOCIProject the project address starts with "oci://".
+func IsOCIProject(addr string) bool {
+	return strings.HasPrefix(addr, ociPrefix)
+}
+
+// IsTarballProject the project address is a http(s) url of tar.gz file.
+func IsTarballProject(addr string) bool {
+	return (strings.HasPrefix(addr, "https://") || strings.HasPrefix(addr, "http://")) &&
+		(strings.HasSuffix(addr, ".tgz") || strings.HasSuffix(addr, ".tar.gz"))
+}
+
+// newArchiveProject download project archive from oci registry or http(s) url, and extract it to project dir.
+// the archive is cached by its digest, the extracted project is used as local project.
+func newArchiveProject(ctx context.Context, pipeline kkcorev1.Pipeline, update bool) (Project, error) {
+	if pipeline.Spec.Playbook == "" || pipeline.Spec.Project.Addr == "" {
+		return nil, errors.New("playbook and project.addr should not be empty")
+	}
+	if filepath.IsAbs(pipeline.Spec.Playbook) {
+		return nil, errors.New("playbook should be relative path base on project.addr")
+	}
+
+	projectDir, err := fetchArchive(ctx, pipeline.Spec.Project, filepath.Join(_const.GetWorkDir(), _const.ProjectDir), update)
+	if err != nil {
+		return nil, fmt.Errorf("fetch project %s error: %w", pipeline.Spec.Project.Addr, err)
+	}
+	pipeline.Spec.Project.Addr = projectDir
+
+	return newLocalProject(pipeline)
+}
+
+// fetchArchive returns the extracted project dir of archive. the archive is stored as:
+// baseDir/sha256/<hex>/archive.tgz, its signature archive.tgz.asc and the extracted project dir.
+// the digest is the manifest digest for oci project, or the sha256 of tarball. when the digest is known before
+// download (project.checksum is set, or resolved from the oci tag), the cached archive is used.
+// the signature is verified every time when project.publicKey is set.
+func fetchArchive(ctx context.Context, project kkcorev1.PipelineProject, baseDir string, update bool) (string, error) {
+	var repo *remote.Repository
+	digest := project.Checksum
+	if IsOCIProject(project.Addr) {
+		var err error
+		if repo, err = NewRepository(project.Addr, project.InsecureSkipTLS, project.Token); err != nil {
+			return "", err
+		}
+		if digest == "" {
+			desc, err := oras.Resolve(ctx, repo, repo.Reference.Reference, oras.DefaultResolveOptions)
+			if err != nil {
+				return "", fmt.Errorf("resolve %s error: %w", project.Addr, err)
+			}
+			digest = desc.Digest.String()
+		}
+	}
+
+	var data, signature []byte
+	if digest != "" && !update {
+		data, signature = readArchiveCache(archiveCacheDir(baseDir, digest))
+	}
+	if data == nil {
+		var err error
+		if repo != nil {
+			data, signature, err = pullOCIArchive(ctx, repo, digest)
+		} else {
+			data, signature, err = downloadArchive(ctx, project)
+		}
+		if err != nil {
+			return "", err
+		}
+		if digest == "" {
+			sum := sha256.Sum256(data)
+			digest = "sha256:" + hex.EncodeToString(sum[:])
+		}
+		if project.PublicKey != "" {
+			if err := verifySignature(project.PublicKey, data, signature); err != nil {
+				return "", err
+			}
+		}
+		if err := writeArchiveCache(archiveCacheDir(baseDir, digest), data, signature); err != nil {
+			return "", err
+		}
+	} else if project.PublicKey != "" {
+		if err := verifySignature(project.PublicKey, data, signature); err != nil {
+			return "", err
+		}
+	}
+
+	return filepath.Join(archiveCacheDir(baseDir, digest), archiveProjectDir), nil
+}
+
+const (
+	// archiveFile is the archive of project in cache dir.
+	archiveFile = "archive.tgz"
+	// archiveProjectDir is the extracted project in cache dir.
+	archiveProjectDir = "project"
+)
+
+// maxExtractSize is the max size of all files extracted from an archive.
+// the checksum and signature of archive are optional, so the size is limited to avoid decompression bomb.
+var maxExtractSize int64 = 1 << 30
+
+// archiveCacheDir is the cache dir of the archive with digest, such as baseDir/sha256/<hex>.
+func archiveCacheDir(baseDir, digest string) string {
+	algorithm, encoded, _ := strings.Cut(digest, ":")
+
+	return filepath.Join(baseDir, algorithm, encoded)
+}
+
+// readArchiveCache returns the archive and its signature in cache dir. data is nil when the cache is not exist.
+func readArchiveCache(dir string) ([]byte, []byte) {
+	if _, err := os.Stat(filepath.Join(dir, archiveProjectDir)); err != nil {
+		return nil, nil
+	}
+	data, err := os.ReadFile(filepath.Join(dir, archiveFile))
+	if err != nil {
+		return nil, nil
+	}
+	signature, err := os.ReadFile(filepath.Join(dir, archiveFile+SignatureSuffix))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	return data, signature
+}
+
+// writeArchiveCache store the archive, its signature and the extracted project to cache dir.
+// the old cache dir is replaced when all of them are written.
+func writeArchiveCache(dir string, data, signature []byte) error {
+	if err := os.MkdirAll(filepath.Dir(dir), os.ModePerm); err != nil {
+		return err
+	}
+	tmpDir, err := os.MkdirTemp(filepath.Dir(dir), "."+filepath.Base(dir)+"-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if err := os.WriteFile(filepath.Join(tmpDir, archiveFile), data, os.ModePerm); err != nil {
+		return err
+	}
+	if signature != nil {
+		if err := os.WriteFile(filepath.Join(tmpDir, archiveFile+SignatureSuffix), signature, os.ModePerm); err != nil {
+			return err
+		}
+	}
+	if err := extractArchive(data, filepath.Join(tmpDir, archiveProjectDir)); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpDir, dir)
+}
+
+// pullOCIArchive get the project layer and the signature layer from the manifest with digest in oci registry.
+// the signature is nil when the manifest has no signature layer.
+func pullOCIArchive(ctx context.Context, repo *remote.Repository, digest string) ([]byte, []byte, error) {
+	desc, manifestData, err := oras.FetchBytes(ctx, repo, digest, oras.DefaultFetchBytesOptions)
+	if err != nil {
+		return nil, nil, fmt.Errorf("fetch manifest error: %w", err)
+	}
+	if desc.Digest.String() != digest {
+		return nil, nil, fmt.Errorf("manifest digest %s does not match checksum %s", desc.Digest, digest)
+	}
+	manifest := ocispec.Manifest{}
+	if err := json.Unmarshal(manifestData, &manifest); err != nil {
+		return nil, nil, fmt.Errorf("unmarshal manifest error: %w", err)
+	}
+	var data, signature []byte
+	for _, layer := range manifest.Layers {
+		// the content is verified by the digest of layer.
+		switch layer.MediaType {
+		case LayerMediaType:
+			if data, err = content.FetchAll(ctx, repo, layer); err != nil {
+				return nil, nil, fmt.Errorf("fetch project layer error: %w", err)
+			}
+		case SignatureMediaType:
+			if signature, err = content.FetchAll(ctx, repo, layer); err != nil {
+				return nil, nil, fmt.Errorf("fetch signature layer error: %w", err)
+			}
+		}
+	}
+	if data == nil {
+		return nil, nil, fmt.Errorf("cannot find layer with media type %s", LayerMediaType)
+	}
+
+	return data, signature, nil
+}
+
+// downloadArchive get the project tarball and its signature (addr + ".asc") from http(s) url.
+// the sha256 of tarball should match project.checksum when it's set. the signature is nil when it's not found.
+func downloadArchive(ctx context.Context, project kkcorev1.PipelineProject) ([]byte, []byte, error) {
+	client := &http.Client{Transport: &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: project.InsecureSkipTLS,
+		},
+	}}
+	data, err := download(ctx, client, project.Addr, project.Token)
+	if err != nil {
+		return nil, nil, err
+	}
+	if project.Checksum != "" {
+		sum := sha256.Sum256(data)
+		if strings.TrimPrefix(project.Checksum, "sha256:") != hex.EncodeToString(sum[:]) {
+			return nil, nil, fmt.Errorf("sha256 %s does not match checksum %s", hex.EncodeToString(sum[:]), project.Checksum)
+		}
+	}
+	signature, err := download(ctx, client, project.Addr+SignatureSuffix, project.Token)
+	if err != nil {
+		klog.V(4).InfoS("signature of project is not found", "addr", project.Addr, "error", err)
+		signature = nil
+	}
+
+	return data, signature, nil
+}
+
+func download(ctx context.Context, client *http.Client, addr, token string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, addr, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+// extractArchive extract tar.gz data to dir. the old dir is replaced when extract success.
+// if the archive only contains one dir without playbooks dir, the content of the dir is the project.
+func extractArchive(data []byte, dir string) error {
+	if err := os.MkdirAll(filepath.Dir(dir), os.ModePerm); err != nil {
+		return err
+	}
+	tmpDir, err := os.MkdirTemp(filepath.Dir(dir), "."+filepath.Base(dir)+"-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	gr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer gr.Close()
+	tr := tar.NewReader(gr)
+	remaining := maxExtractSize
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		name := path.Clean(hdr.Name)
+		if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return fmt.Errorf("invalid file path %q in archive", hdr.Name)
+		}
+		target := filepath.Join(tmpDir, filepath.FromSlash(name))
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, os.ModePerm); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), os.ModePerm); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, fs.FileMode(hdr.Mode).Perm())
+			if err != nil {
+				return err
+			}
+			n, err := io.CopyN(f, tr, remaining+1)
+			if err != nil && !errors.Is(err, io.EOF) {
+				f.Close()
+
+				return err
+			}
+			if n > remaining {
+				f.Close()
+
+				return fmt.Errorf("the extracted size of archive exceeds the limit %d bytes", maxExtractSize)
+			}
+			remaining -= n
+			if err := f.Close(); err != nil {
+				return err
+			}
+		default:
+			klog.V(4).InfoS("skip unsupported file in archive", "file", hdr.Name, "type", hdr.Typeflag)
+		}
+	}
+
+	root := tmpDir
+	if entries, err := os.ReadDir(tmpDir); err == nil && len(entries) == 1 && entries[0].IsDir() && entries[0].Name() != _const.ProjectPlaybooksDir {
+		root = filepath.Join(tmpDir, entries[0].Name())
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+
+	return os.Rename(root, dir)
+}
+
+// Archive write the tar.gz of project dir to w. the ".git" dir is skipped.
+func Archive(dir string, w io.Writer) error {
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+	if err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil || rel == "." {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() && !info.IsDir() {
+			// only dir and regular file are archived.
+			return nil
+		}
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(rel)
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+
+		return err
+	}); err != nil {
+		return fmt.Errorf("archive project %s error: %w", dir, err)
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+
+	return gw.Close()
+}
+
+// Push the project dir to oci registry as an artifact. return the digest of manifest.
+// the project layer is signed by signKey when it's set, the signature is pushed as another layer.
+func Push(ctx context.Context, dir, addr string, insecureSkipTLS bool, token, signKey string) (string, error) {
+	repo, err := NewRepository(addr, insecureSkipTLS, token)
+	if err != nil {
+		return "", err
+	}
+	if repo.Reference.Reference == "" {
+		return "", fmt.Errorf("tag of %s should not be empty", addr)
+	}
+	buf := &bytes.Buffer{}
+	if err := Archive(dir, buf); err != nil {
+		return "", err
+	}
+	layer := content.NewDescriptorFromBytes(LayerMediaType, buf.Bytes())
+	layer.Annotations = map[string]string{ocispec.AnnotationTitle: filepath.Base(dir) + ".tgz"}
+	if err := repo.Push(ctx, layer, bytes.NewReader(buf.Bytes())); err != nil {
+		return "", fmt.Errorf("push project layer error: %w", err)
+	}
+	layers := []ocispec.Descriptor{layer}
+	if signKey != "" {
+		signature, err := Sign(signKey, buf.Bytes())
+		if err != nil {
+			return "", err
+		}
+		signatureLayer := content.NewDescriptorFromBytes(SignatureMediaType, signature)
+		if err := repo.Push(ctx, signatureLayer, bytes.NewReader(signature)); err != nil {
+			return "", fmt.Errorf("push signature layer error: %w", err)
+		}
+		layers = append(layers, signatureLayer)
+	}
+	desc, err := oras.PackManifest(ctx, repo, oras.PackManifestVersion1_1, ArtifactType, oras.PackManifestOptions{
+		Layers: layers,
+	})
+	if err != nil {
+		return "", fmt.Errorf("push project manifest error: %w", err)
+	}
+	if err := repo.Tag(ctx, desc, repo.Reference.Reference); err != nil {
+		return "", fmt.Errorf("tag project manifest error: %w", err)
+	}
+
+	return desc.Digest.String(), nil
+}
+
+// NewRepository for oci project address. the credential is token when it's set, or from docker config.
+func NewRepository(addr string, insecureSkipTLS bool, token string) (*remote.Repository, error) {
+	repo, err := remote.NewRepository(strings.TrimPrefix(addr, ociPrefix))
+	if err != nil {
+		return nil, fmt.Errorf("invalid oci address %s: %w", addr, err)
+	}
+	client := &auth.Client{
+		Client: &http.Client{Transport: &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: insecureSkipTLS,
+			},
+		}},
+		Cache: auth.NewCache(),
+	}
+	if token != "" {
+		client.Credential = auth.StaticCredential(repo.Reference.Registry, auth.Credential{AccessToken: token})
+	} else if store, err := credentials.NewStoreFromDocker(credentials.StoreOptions{}); err == nil {
+		client.Credential = credentials.Credential(store)
+	} else {
+		klog.V(4).ErrorS(err, "load docker credentials error", "addr", addr)
+	}
+	repo.Client = client
+
+	return repo, nil
+}
@@ -0,0 +1,300 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package project
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/openpgp"       //nolint:staticcheck
+	"golang.org/x/crypto/openpgp/armor" //nolint:staticcheck
+
+	kkcorev1 "github.com/kubesphere/kubekey/v4/pkg/apis/core/v1"
+)
+
+func TestDownloadArchive(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := Archive("testdata", buf); err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(buf.Bytes())
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+		_, _ = w.Write(buf.Bytes())
+	}))
+	defer server.Close()
+
+	testcases := []struct {
+		name     string
+		token    string
+		checksum string
+		hasErr   bool
+	}{
+		{
+			name:     "valid checksum",
+			token:    "token",
+			checksum: "sha256:" + hex.EncodeToString(sum[:]),
+		},
+		{
+			name:  "without checksum",
+			token: "token",
+		},
+		{
+			name:     "checksum mismatch",
+			token:    "token",
+			checksum: "sha256:0000",
+			hasErr:   true,
+		},
+		{
+			name:   "unauthorized",
+			hasErr: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, _, err := downloadArchive(context.TODO(), kkcorev1.PipelineProject{
+				Addr:     server.URL + "/project.tgz",
+				Token:    tc.token,
+				Checksum: tc.checksum,
+			})
+			if tc.hasErr {
+				assert.Error(t, err)
+
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			dir := filepath.Join(t.TempDir(), "project")
+			if err := extractArchive(data, dir); err != nil {
+				t.Fatal(err)
+			}
+			assert.FileExists(t, filepath.Join(dir, "playbooks", "playbook1.yaml"))
+		})
+	}
+}
+
+func TestFetchArchive(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := Archive("testdata", buf); err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(buf.Bytes())
+	checksum := "sha256:" + hex.EncodeToString(sum[:])
+	privateKey, publicKey := newTestKey(t)
+	signature, err := Sign(privateKey, buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, otherPublicKey := newTestKey(t)
+
+	var downloads int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/signed.tgz", "/unsigned.tgz":
+			downloads++
+			_, _ = w.Write(buf.Bytes())
+		case "/signed.tgz" + SignatureSuffix:
+			_, _ = w.Write(signature)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	testcases := []struct {
+		name      string
+		file      string
+		checksum  string
+		publicKey string
+		downloads int
+		hasErr    bool
+	}{
+		{
+			name:      "signed",
+			file:      "signed.tgz",
+			publicKey: publicKey,
+			downloads: 1,
+		},
+		{
+			name:      "cached by checksum",
+			file:      "signed.tgz",
+			checksum:  checksum,
+			publicKey: publicKey,
+			downloads: 0,
+		},
+		{
+			name:      "signed by other key",
+			file:      "signed.tgz",
+			publicKey: otherPublicKey,
+			downloads: 1,
+			hasErr:    true,
+		},
+		{
+			name:      "signature not found",
+			file:      "unsigned.tgz",
+			publicKey: publicKey,
+			downloads: 1,
+			hasErr:    true,
+		},
+	}
+
+	baseDir := t.TempDir()
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			downloads = 0
+			dir, err := fetchArchive(context.TODO(), kkcorev1.PipelineProject{
+				Addr:      server.URL + "/" + tc.file,
+				Checksum:  tc.checksum,
+				PublicKey: tc.publicKey,
+			}, baseDir, false)
+			assert.Equal(t, tc.downloads, downloads)
+			if tc.hasErr {
+				assert.Error(t, err)
+
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, filepath.Join(baseDir, "sha256", hex.EncodeToString(sum[:]), archiveProjectDir), dir)
+			assert.FileExists(t, filepath.Join(dir, "playbooks", "playbook1.yaml"))
+		})
+	}
+}
+
+// newTestKey returns the armored private key file and the armored public key.
+func newTestKey(t *testing.T) (string, string) {
+	t.Helper()
+	entity, err := openpgp.NewEntity("test", "", "test@kubesphere.io", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	private := &bytes.Buffer{}
+	w, err := armor.Encode(private, openpgp.PrivateKeyType, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := entity.SerializePrivate(w, nil); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	public := &bytes.Buffer{}
+	if w, err = armor.Encode(public, openpgp.PublicKeyType, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := entity.Serialize(w); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+
+	keyFile := filepath.Join(t.TempDir(), "private.asc")
+	if err := os.WriteFile(keyFile, private.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return keyFile, public.String()
+}
+
+func TestExtractArchive(t *testing.T) {
+	testcases := []struct {
+		name   string
+		files  []string
+		limit  int64
+		except string
+		hasErr bool
+	}{
+		{
+			name:   "project in root",
+			files:  []string{"playbooks/site.yaml"},
+			except: "playbooks/site.yaml",
+		},
+		{
+			name:   "project in single dir",
+			files:  []string{"project/playbooks/site.yaml"},
+			except: "playbooks/site.yaml",
+		},
+		{
+			name:   "path traversal",
+			files:  []string{"../playbooks/site.yaml"},
+			hasErr: true,
+		},
+		{
+			name:   "exceed extract size",
+			files:  []string{"playbooks/site.yaml", "playbooks/other.yaml"},
+			limit:  3,
+			hasErr: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			gw := gzip.NewWriter(buf)
+			tw := tar.NewWriter(gw)
+			for _, f := range tc.files {
+				if err := tw.WriteHeader(&tar.Header{Name: f, Mode: 0o644, Size: 2, Typeflag: tar.TypeReg}); err != nil {
+					t.Fatal(err)
+				}
+				if _, err := tw.Write([]byte("{}")); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := tw.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if err := gw.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			if tc.limit != 0 {
+				defer func(limit int64) { maxExtractSize = limit }(maxExtractSize)
+				maxExtractSize = tc.limit
+			}
+			dir := filepath.Join(t.TempDir(), "project")
+			err := extractArchive(buf.Bytes(), dir)
+			if tc.hasErr {
+				assert.Error(t, err)
+
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := os.ReadFile(filepath.Join(dir, tc.except))
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, "{}", string(data))
+		})
+	}
+}
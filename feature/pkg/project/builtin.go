@@ -89,6 +89,16 @@ func (p builtinProject) MarshalPlaybook() (*kkprojectv1.Playbook, error) {
 	return marshalPlaybook(p.FS, p.playbook)
 }
 
+// MarshalBlock project tasks file to blocks.
+func (p builtinProject) MarshalBlock(file string, role string) ([]kkprojectv1.Block, error) {
+	return marshalBlock(p.FS, p.playbook, file, role)
+}
+
+// MarshalRole project role to roles with dependencies.
+func (p builtinProject) MarshalRole(role kkprojectv1.Role, tasksFrom string) ([]kkprojectv1.Role, error) {
+	return marshalRole(p.FS, p.playbook, role, tasksFrom)
+}
+
 // Stat role/file/template file or dir in project
 func (p builtinProject) Stat(path string, option GetFileOption) (os.FileInfo, error) {
 	return fs.Stat(p.FS, p.getFilePath(path, option))
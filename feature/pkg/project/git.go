@@ -163,6 +163,16 @@ func (p gitProject) MarshalPlaybook() (*kkprojectv1.Playbook, error) {
 	return marshalPlaybook(os.DirFS(p.projectDir), p.Pipeline.Spec.Playbook)
 }
 
+// MarshalBlock project tasks file to blocks.
+func (p gitProject) MarshalBlock(file string, role string) ([]kkprojectv1.Block, error) {
+	return marshalBlock(os.DirFS(p.projectDir), p.Pipeline.Spec.Playbook, file, role)
+}
+
+// MarshalRole project role to roles with dependencies.
+func (p gitProject) MarshalRole(role kkprojectv1.Role, tasksFrom string) ([]kkprojectv1.Role, error) {
+	return marshalRole(os.DirFS(p.projectDir), p.Pipeline.Spec.Playbook, role, tasksFrom)
+}
+
 // Stat role/file/template file or dir in project
 func (p gitProject) Stat(path string, option GetFileOption) (os.FileInfo, error) {
 	return os.Stat(p.getFilePath(path, option))
@@ -17,10 +17,15 @@ limitations under the License.
 package project
 
 import (
+	"encoding/json"
 	"fmt"
 	"io/fs"
+	"maps"
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 
@@ -39,9 +44,9 @@ func marshalPlaybook(baseFS fs.FS, pbPath string) (*kkprojectv1.Playbook, error)
 	if err := convertRoles(baseFS, pbPath, pb); err != nil {
 		return nil, fmt.Errorf("convert roles failed: %w", err)
 	}
-	// convertIncludeTasks
-	if err := convertIncludeTasks(baseFS, pbPath, pb); err != nil {
-		return nil, fmt.Errorf("convert include tasks failed: %w", err)
+	// convertImports
+	if err := convertImports(baseFS, pbPath, pb); err != nil {
+		return nil, fmt.Errorf("convert import tasks failed: %w", err)
 	}
 	// validate playbook
 	if err := pb.Validate(); err != nil {
@@ -71,11 +76,6 @@ func loadPlaybook(baseFS fs.FS, pbPath string, pb *kkprojectv1.Playbook) error {
 		if err := dealVarsFiles(p, baseFS, pbPath); err != nil {
 			return fmt.Errorf("load vars_files failed: %w", err)
 		}
-		// fill block in roles
-		if err := dealRoles(p, baseFS, pbPath); err != nil {
-			return fmt.Errorf("load roles failed: %w", err)
-		}
-
 		pb.Play = append(pb.Play, p)
 	}
 
@@ -127,120 +127,244 @@ func dealVarsFiles(p kkprojectv1.Play, baseFS fs.FS, pbPath string) error {
 	return nil
 }
 
-// dealRoles "roles" argument in play
-func dealRoles(p kkprojectv1.Play, baseFS fs.FS, pbPath string) error {
-	for i, r := range p.Roles {
-		roleBase := getRoleBaseFromPlaybook(baseFS, pbPath, r.Role)
-		if roleBase == "" {
-			return fmt.Errorf("cannot found Role %s", r.Role)
+// convertRoles convert roleName to block. the dependencies of role are resolved and placed before the role.
+func convertRoles(baseFS fs.FS, pbPath string, pb *kkprojectv1.Playbook) error {
+	for i, p := range pb.Play {
+		rr := &roleResolver{baseFS: baseFS, pbPath: pbPath}
+		for _, r := range p.Roles {
+			if err := rr.resolve(r, "", nil); err != nil {
+				return err
+			}
 		}
+		p.Roles = rr.roles
+		pb.Play[i] = p
+	}
 
-		mainTask := getYamlFile(baseFS, filepath.Join(roleBase, _const.ProjectRolesTasksDir, _const.ProjectRolesTasksMainFile))
-		if mainTask == "" {
-			return fmt.Errorf("cannot found main task for Role %s", r.Role)
-		}
+	return nil
+}
 
-		rdata, err := fs.ReadFile(baseFS, mainTask)
-		if err != nil {
-			return fmt.Errorf("read file %s failed: %w", mainTask, err)
+// roleResolver resolve roles and their dependencies in a play.
+type roleResolver struct {
+	baseFS fs.FS
+	pbPath string
+	// roles which have resolved, sorted by execution order.
+	roles []kkprojectv1.Role
+	// inlineVars is the vars defined in playbook for each resolved role. used to check duplicate.
+	inlineVars []map[string]any
+}
+
+// resolve role and its dependencies. tasksFrom is the tasks file of role, default is main.
+// chain is the roles which depend on the role, used to detect cyclic dependencies.
+func (rr *roleResolver) resolve(r kkprojectv1.Role, tasksFrom string, chain []string) error {
+	if slices.Contains(chain, r.Role) {
+		return fmt.Errorf("cyclic dependency of role: %s", strings.Join(append(chain, r.Role), " -> "))
+	}
+	roleBase := getRoleBaseFromPlaybook(rr.baseFS, rr.pbPath, r.Role)
+	if roleBase == "" {
+		return fmt.Errorf("cannot found Role %s", r.Role)
+	}
+	meta, err := convertRoleMeta(rr.baseFS, roleBase)
+	if err != nil {
+		return fmt.Errorf("convert role %s meta failed: %w", r.Role, err)
+	}
+	for _, dep := range meta.Dependencies {
+		// dependency inherits when and tags from the role which depends on it.
+		dep.When.Data = append(slices.Clone(r.When.Data), dep.When.Data...)
+		dep.Taggable = kkprojectv1.JoinTag(dep.Taggable, r.Taggable)
+		if err := rr.resolve(dep, "", append(chain, r.Role)); err != nil {
+			return err
 		}
-		var blocks []kkprojectv1.Block
-		if err := yaml.Unmarshal(rdata, &blocks); err != nil {
-			return fmt.Errorf("unmarshal yaml file: %s failed: %w", filepath.Join(filepath.Dir(pbPath), mainTask), err)
+	}
+	// the role with same vars only run once in a play, unless allow_duplicates is set.
+	if !meta.AllowDuplicates && rr.resolved(r) {
+		return nil
+	}
+	inlineVars := r.Vars
+	if r.Block, err = convertRoleBlocks(rr.baseFS, roleBase, tasksFrom, len(meta.Dependencies) != 0); err != nil {
+		return fmt.Errorf("convert role %s tasks failed: %w", r.Role, err)
+	}
+	argumentSpec, err := convertRoleArgumentSpecs(rr.baseFS, roleBase)
+	if err != nil {
+		return fmt.Errorf("convert role %s argument_specs failed: %w", r.Role, err)
+	}
+	if argumentSpec != nil {
+		r.Block = append([]kkprojectv1.Block{*argumentSpec}, r.Block...)
+	}
+	if r.Vars, err = convertRoleVars(rr.baseFS, roleBase, r.Vars); err != nil {
+		return fmt.Errorf("convert role %s vars failed: %w", r.Role, err)
+	}
+	// handlers of role are merged once, even if the role runs multiple times.
+	if !slices.ContainsFunc(rr.roles, func(rl kkprojectv1.Role) bool { return rl.Role == r.Role }) {
+		if r.Handlers, err = convertRoleHandlers(rr.baseFS, roleBase); err != nil {
+			return fmt.Errorf("convert role %s handlers failed: %w", r.Role, err)
 		}
-		p.Roles[i].Block = blocks
 	}
+	rr.roles = append(rr.roles, r)
+	rr.inlineVars = append(rr.inlineVars, inlineVars)
 
 	return nil
 }
 
-// convertRoles convert roleName to block
-func convertRoles(baseFS fs.FS, pbPath string, pb *kkprojectv1.Playbook) error {
-	for i, p := range pb.Play {
-		for i, r := range p.Roles {
-			roleBase := getRoleBaseFromPlaybook(baseFS, pbPath, r.Role)
-			if roleBase == "" {
-				return fmt.Errorf("cannot found Role %s", r.Role)
-			}
-
-			var err error
-			if p.Roles[i].Block, err = convertRoleBlocks(baseFS, pbPath, roleBase); err != nil {
-				return fmt.Errorf("convert role %s  tasks failed: %w", r.Role, err)
-			}
-
-			if p.Roles[i].Vars, err = convertRoleVars(baseFS, roleBase, p.Roles[i].Vars); err != nil {
-				return fmt.Errorf("convert role %s defaults failed: %w", r.Role, err)
-			}
+// resolved check if the role with the same vars has been resolved.
+func (rr *roleResolver) resolved(r kkprojectv1.Role) bool {
+	for i, rl := range rr.roles {
+		if rl.Role == r.Role && reflect.DeepEqual(rr.inlineVars[i], r.Vars) {
+			return true
 		}
-		pb.Play[i] = p
 	}
 
-	return nil
+	return false
 }
 
+// convertRoleVars merge variables of role. the priority is: vars in playbook > vars/main.yaml > defaults/main.yaml
 func convertRoleVars(baseFS fs.FS, roleBase string, roleVars map[string]any) (map[string]any, error) {
-	// load defaults (optional)
-	mainDefault := getYamlFile(baseFS, filepath.Join(roleBase, _const.ProjectRolesDefaultsDir, _const.ProjectRolesDefaultsMainFile))
-	if mainDefault != "" {
-		mainData, err := fs.ReadFile(baseFS, mainDefault)
+	vars := make(map[string]any)
+	for _, file := range []string{
+		getYamlFile(baseFS, filepath.Join(roleBase, _const.ProjectRolesDefaultsDir, _const.ProjectRolesDefaultsMainFile)),
+		getYamlFile(baseFS, filepath.Join(roleBase, _const.ProjectRolesVarsDir, _const.ProjectRolesVarsMainFile)),
+	} {
+		// the file is optional
+		if file == "" {
+			continue
+		}
+		data, err := fs.ReadFile(baseFS, file)
 		if err != nil {
-			return nil, fmt.Errorf("read defaults variable file %s failed: %w", mainDefault, err)
+			return nil, fmt.Errorf("read variable file %s failed: %w", file, err)
 		}
-
-		var vars map[string]any
+		var fileVars map[string]any
 		var node yaml.Node // marshal file on defined order
-		if err := yaml.Unmarshal(mainData, &node); err != nil {
-			return nil, fmt.Errorf("unmarshal defaults variable yaml file: %s failed: %w", mainDefault, err)
+		if err := yaml.Unmarshal(data, &node); err != nil {
+			return nil, fmt.Errorf("unmarshal variable yaml file: %s failed: %w", file, err)
 		}
-		if err := node.Decode(&vars); err != nil {
-			return nil, fmt.Errorf("decode defaults variable yaml file: %s failed: %w", mainDefault, err)
+		if err := node.Decode(&fileVars); err != nil {
+			return nil, fmt.Errorf("decode variable yaml file: %s failed: %w", file, err)
 		}
-
-		return combineMaps(roleVars, vars)
+		maps.Copy(vars, fileVars)
+	}
+	maps.Copy(vars, roleVars)
+	if len(vars) == 0 {
+		return roleVars, nil
 	}
 
-	return roleVars, nil
+	return vars, nil
 }
 
-// convertRoleBlocks roles/task/main.yaml to []kkprojectv1.Block
-func convertRoleBlocks(baseFS fs.FS, pbPath string, roleBase string) ([]kkprojectv1.Block, error) {
-	mainTask := getYamlFile(baseFS, filepath.Join(roleBase, _const.ProjectRolesTasksDir, _const.ProjectRolesTasksMainFile))
-	if mainTask == "" {
-		return nil, fmt.Errorf("cannot found main task for Role %s", roleBase)
+// convertRoleBlocks roles/task/main.yaml (or tasksFrom file) to []kkprojectv1.Block
+// the main task is optional when role only has dependencies.
+func convertRoleBlocks(baseFS fs.FS, roleBase string, tasksFrom string, optional bool) ([]kkprojectv1.Block, error) {
+	if tasksFrom == "" {
+		tasksFrom = _const.ProjectRolesTasksMainFile
 	}
+	taskFile := filepath.Join(roleBase, _const.ProjectRolesTasksDir, tasksFrom)
+	if ext := filepath.Ext(tasksFrom); ext != ".yaml" && ext != ".yml" {
+		taskFile = getYamlFile(baseFS, taskFile)
+	}
+	if taskFile == "" {
+		if optional {
+			return nil, nil
+		}
 
-	rdata, err := fs.ReadFile(baseFS, mainTask)
+		return nil, fmt.Errorf("cannot found task %s for Role %s", tasksFrom, roleBase)
+	}
+
+	return loadBlocks(baseFS, taskFile)
+}
+
+// loadBlocks from a tasks file.
+func loadBlocks(baseFS fs.FS, file string) ([]kkprojectv1.Block, error) {
+	data, err := fs.ReadFile(baseFS, file)
 	if err != nil {
-		return nil, fmt.Errorf("read file %s failed: %w", mainTask, err)
+		return nil, fmt.Errorf("read file %s failed: %w", file, err)
 	}
 	var blocks []kkprojectv1.Block
-	if err := yaml.Unmarshal(rdata, &blocks); err != nil {
-		return nil, fmt.Errorf("unmarshal yaml file: %s failed: %w", filepath.Join(filepath.Dir(pbPath), mainTask), err)
+	if err := yaml.Unmarshal(data, &blocks); err != nil {
+		return nil, fmt.Errorf("unmarshal yaml file: %s failed: %w", file, err)
 	}
 
 	return blocks, nil
 }
 
-// convertIncludeTasks from file to blocks
-func convertIncludeTasks(baseFS fs.FS, pbPath string, pb *kkprojectv1.Playbook) error {
-	var pbBase = filepath.Dir(filepath.Dir(pbPath))
-	for _, play := range pb.Play {
-		if err := fileToBlock(baseFS, pbBase, play.PreTasks); err != nil {
-			return fmt.Errorf("convert pre_tasks file %s failed: %w", pbPath, err)
-		}
+// convertRoleHandlers roles/handlers/main.yaml to []kkprojectv1.Block
+func convertRoleHandlers(baseFS fs.FS, roleBase string) ([]kkprojectv1.Block, error) {
+	mainHandler := getYamlFile(baseFS, filepath.Join(roleBase, _const.ProjectRolesHandlersDir, _const.ProjectRolesHandlersMainFile))
+	if mainHandler == "" {
+		return nil, nil
+	}
 
-		if err := fileToBlock(baseFS, pbBase, play.Tasks); err != nil {
-			return fmt.Errorf("convert tasks file %s failed: %w", pbPath, err)
-		}
+	return loadBlocks(baseFS, mainHandler)
+}
+
+// convertRoleMeta roles/meta/main.yaml to kkprojectv1.RoleMeta
+func convertRoleMeta(baseFS fs.FS, roleBase string) (kkprojectv1.RoleMeta, error) {
+	var meta kkprojectv1.RoleMeta
+	mainMeta := getYamlFile(baseFS, filepath.Join(roleBase, _const.ProjectRolesMetaDir, _const.ProjectRolesMetaMainFile))
+	if mainMeta == "" {
+		return meta, nil
+	}
+	data, err := fs.ReadFile(baseFS, mainMeta)
+	if err != nil {
+		return meta, fmt.Errorf("read file %s failed: %w", mainMeta, err)
+	}
+	if err := yaml.Unmarshal(data, &meta); err != nil {
+		return meta, fmt.Errorf("unmarshal yaml file: %s failed: %w", mainMeta, err)
+	}
+
+	return meta, nil
+}
 
-		if err := fileToBlock(baseFS, pbBase, play.PostTasks); err != nil {
-			return fmt.Errorf("convert post_tasks file %s failed: %w", pbPath, err)
+// convertRoleArgumentSpecs roles/meta/argument_specs.yaml to a task which validate the arguments of role.
+// the task is always run before the tasks of role. return nil if argument_specs is not defined.
+func convertRoleArgumentSpecs(baseFS fs.FS, roleBase string) (*kkprojectv1.Block, error) {
+	file := getYamlFile(baseFS, filepath.Join(roleBase, _const.ProjectRolesMetaDir, _const.ProjectRolesMetaArgumentSpecsFile))
+	if file == "" {
+		return nil, nil
+	}
+	data, err := fs.ReadFile(baseFS, file)
+	if err != nil {
+		return nil, fmt.Errorf("read file %s failed: %w", file, err)
+	}
+	var specs kkprojectv1.RoleArgumentSpecs
+	if err := yaml.Unmarshal(data, &specs); err != nil {
+		return nil, fmt.Errorf("unmarshal yaml file: %s failed: %w", file, err)
+	}
+	spec, ok := specs.ArgumentSpecs[_const.ProjectRolesTasksMainFile]
+	if !ok || len(spec.Options) == 0 {
+		return nil, nil
+	}
+	// convert to map. so that it can be used as module args.
+	jsonData, err := json.Marshal(spec.Options)
+	if err != nil {
+		return nil, fmt.Errorf("marshal argument_specs %s failed: %w", file, err)
+	}
+	var options map[string]any
+	if err := json.Unmarshal(jsonData, &options); err != nil {
+		return nil, fmt.Errorf("unmarshal argument_specs %s failed: %w", file, err)
+	}
+
+	return &kkprojectv1.Block{
+		BlockBase: kkprojectv1.BlockBase{
+			Base:     kkprojectv1.Base{Name: fmt.Sprintf("Validating arguments against arg spec %q", _const.ProjectRolesTasksMainFile)},
+			Taggable: kkprojectv1.Taggable{Tags: []string{kkprojectv1.AlwaysTag}},
+		},
+		Task: kkprojectv1.Task{UnknownField: map[string]any{
+			"validate_argument_spec": map[string]any{"argument_spec": options},
+		}},
+	}, nil
+}
+
+// convertImports convert "import_tasks" and "import_role" to blocks.
+func convertImports(baseFS fs.FS, pbPath string, pb *kkprojectv1.Playbook) error {
+	var pbBase = filepath.Dir(filepath.Dir(pbPath))
+	for _, play := range pb.Play {
+		for _, blocks := range [][]kkprojectv1.Block{play.PreTasks, play.Tasks, play.PostTasks, play.Handlers} {
+			if err := fileToBlock(baseFS, pbPath, pbBase, blocks); err != nil {
+				return fmt.Errorf("convert tasks file %s failed: %w", pbPath, err)
+			}
 		}
 
 		for _, r := range play.Roles {
-			roleBase := getRoleBaseFromPlaybook(baseFS, pbPath, r.Role)
-			if err := fileToBlock(baseFS, filepath.Join(roleBase, _const.ProjectRolesTasksDir), r.Block); err != nil {
-				return fmt.Errorf("convert role %s failed: %w", filepath.Join(pbPath, r.Role), err)
+			if err := convertRoleImports(baseFS, pbPath, r); err != nil {
+				return err
 			}
 		}
 	}
@@ -248,38 +372,107 @@ func convertIncludeTasks(baseFS fs.FS, pbPath string, pb *kkprojectv1.Playbook)
 	return nil
 }
 
-func fileToBlock(baseFS fs.FS, baseDir string, blocks []kkprojectv1.Block) error {
+// convertRoleImports convert "import_tasks" and "import_role" in tasks and handlers of role.
+func convertRoleImports(baseFS fs.FS, pbPath string, r kkprojectv1.Role) error {
+	roleBase := getRoleBaseFromPlaybook(baseFS, pbPath, r.Role)
+	if err := fileToBlock(baseFS, pbPath, filepath.Join(roleBase, _const.ProjectRolesTasksDir), r.Block); err != nil {
+		return fmt.Errorf("convert role %s failed: %w", filepath.Join(pbPath, r.Role), err)
+	}
+	if err := fileToBlock(baseFS, pbPath, filepath.Join(roleBase, _const.ProjectRolesHandlersDir), r.Handlers); err != nil {
+		return fmt.Errorf("convert role %s handlers failed: %w", filepath.Join(pbPath, r.Role), err)
+	}
+
+	return nil
+}
+
+// fileToBlock convert "import_tasks" and "import_role" to block. the file of "import_tasks" is relative to baseDir.
+// "include_tasks" and "include_role" are kept, which are loaded at execution time.
+func fileToBlock(baseFS fs.FS, pbPath string, baseDir string, blocks []kkprojectv1.Block) error {
 	for i, b := range blocks {
-		if b.IncludeTasks != "" {
-			data, err := fs.ReadFile(baseFS, filepath.Join(baseDir, b.IncludeTasks))
+		switch {
+		case b.ImportTasks != "":
+			bs, err := loadBlocks(baseFS, filepath.Join(baseDir, b.ImportTasks))
 			if err != nil {
-				return fmt.Errorf("read includeTask file %s failed: %w", filepath.Join(baseDir, b.IncludeTasks), err)
+				return fmt.Errorf("load import_tasks failed: %w", err)
 			}
-			var bs []kkprojectv1.Block
-			if err := yaml.Unmarshal(data, &bs); err != nil {
-				return fmt.Errorf("unmarshal includeTask file %s failed: %w", filepath.Join(baseDir, b.IncludeTasks), err)
+			b.Block = bs
+			blocks[i] = b
+		case b.ImportRole != nil:
+			roleBase := getRoleBaseFromPlaybook(baseFS, pbPath, b.ImportRole.Name)
+			if roleBase == "" {
+				return fmt.Errorf("cannot found Role %s", b.ImportRole.Name)
+			}
+			bs, err := convertRoleBlocks(baseFS, roleBase, b.ImportRole.TasksFrom, false)
+			if err != nil {
+				return fmt.Errorf("convert import_role %s tasks failed: %w", b.ImportRole.Name, err)
+			}
+			if b.Vars, err = convertRoleVars(baseFS, roleBase, b.Vars); err != nil {
+				return fmt.Errorf("convert import_role %s vars failed: %w", b.ImportRole.Name, err)
 			}
-
 			b.Block = bs
 			blocks[i] = b
+			// the file in role is relative to the tasks dir of role.
+			if err := fileToBlock(baseFS, pbPath, filepath.Join(roleBase, _const.ProjectRolesTasksDir), b.Block); err != nil {
+				return fmt.Errorf("convert import_role %s failed: %w", b.ImportRole.Name, err)
+			}
+
+			continue
 		}
 
-		if err := fileToBlock(baseFS, baseDir, b.Block); err != nil {
-			return fmt.Errorf("convert block file %s failed: %w", filepath.Join(baseDir, b.IncludeTasks), err)
+		if err := fileToBlock(baseFS, pbPath, baseDir, b.Block); err != nil {
+			return fmt.Errorf("convert block file %s failed: %w", filepath.Join(baseDir, b.ImportTasks), err)
 		}
 
-		if err := fileToBlock(baseFS, baseDir, b.Rescue); err != nil {
-			return fmt.Errorf("convert rescue file %s failed: %w", filepath.Join(baseDir, b.IncludeTasks), err)
+		if err := fileToBlock(baseFS, pbPath, baseDir, b.Rescue); err != nil {
+			return fmt.Errorf("convert rescue file %s failed: %w", filepath.Join(baseDir, b.ImportTasks), err)
 		}
 
-		if err := fileToBlock(baseFS, baseDir, b.Always); err != nil {
-			return fmt.Errorf("convert always file %s failed: %w", filepath.Join(baseDir, b.IncludeTasks), err)
+		if err := fileToBlock(baseFS, pbPath, baseDir, b.Always); err != nil {
+			return fmt.Errorf("convert always file %s failed: %w", filepath.Join(baseDir, b.ImportTasks), err)
 		}
 	}
 
 	return nil
 }
 
+// marshalBlock load blocks from tasks file, which is used by "include_tasks" at execution time.
+// the file is relative to the tasks dir of role if role is set, otherwise relative to project.
+func marshalBlock(baseFS fs.FS, pbPath string, file string, role string) ([]kkprojectv1.Block, error) {
+	baseDir := filepath.Dir(filepath.Dir(pbPath))
+	if role != "" {
+		roleBase := getRoleBaseFromPlaybook(baseFS, pbPath, role)
+		if roleBase == "" {
+			return nil, fmt.Errorf("cannot found Role %s", role)
+		}
+		baseDir = filepath.Join(roleBase, _const.ProjectRolesTasksDir)
+	}
+	blocks, err := loadBlocks(baseFS, filepath.Join(baseDir, file))
+	if err != nil {
+		return nil, err
+	}
+	if err := fileToBlock(baseFS, pbPath, baseDir, blocks); err != nil {
+		return nil, fmt.Errorf("convert tasks file %s failed: %w", file, err)
+	}
+
+	return blocks, nil
+}
+
+// marshalRole load role and its dependencies, which is used by "include_role" at execution time.
+// the dependencies are placed before the role.
+func marshalRole(baseFS fs.FS, pbPath string, role kkprojectv1.Role, tasksFrom string) ([]kkprojectv1.Role, error) {
+	rr := &roleResolver{baseFS: baseFS, pbPath: pbPath}
+	if err := rr.resolve(role, tasksFrom, nil); err != nil {
+		return nil, err
+	}
+	for _, r := range rr.roles {
+		if err := convertRoleImports(baseFS, pbPath, r); err != nil {
+			return nil, err
+		}
+	}
+
+	return rr.roles, nil
+}
+
 // getPlaybookBaseFromPlaybook find import_playbook path base on the current_playbook
 // find from project/playbooks/playbook if exists.
 // find from current_playbook/playbooks/playbook if exists.
@@ -235,6 +235,127 @@ func TestMarshalPlaybook(t *testing.T) {
 	}
 }
 
+func TestMarshalPlaybookWithRoleMeta(t *testing.T) {
+	debugBlock := func(name string) kkprojectv1.Block {
+		return kkprojectv1.Block{
+			BlockBase: kkprojectv1.BlockBase{Base: kkprojectv1.Base{Name: name}},
+			Task: kkprojectv1.Task{UnknownField: map[string]any{
+				"debug": map[string]any{
+					"msg": "echo \"hello world\"",
+				},
+			}},
+		}
+	}
+	metaRoleBlock := debugBlock("meta_role | block1")
+	metaRoleBlock.Notify = kkprojectv1.Notify{Data: []string{"meta_role | handler1"}}
+
+	testcases := []struct {
+		name   string
+		file   string
+		except *kkprojectv1.Playbook
+		err    bool
+	}{
+		{
+			name: "dependencies, vars, handlers and argument_specs",
+			file: "playbooks/role_meta.yaml",
+			except: &kkprojectv1.Playbook{Play: []kkprojectv1.Play{
+				{
+					Base:     kkprojectv1.Base{Name: "role_meta"},
+					PlayHost: kkprojectv1.PlayHost{Hosts: []string{"localhost"}},
+					Roles: []kkprojectv1.Role{
+						{RoleInfo: kkprojectv1.RoleInfo{
+							Role:  "role1",
+							Block: []kkprojectv1.Block{debugBlock("role1 | block1")},
+						}},
+						{RoleInfo: kkprojectv1.RoleInfo{
+							Role:     "dep_role",
+							Taggable: kkprojectv1.Taggable{Tags: []string{"dep_role"}},
+							Block:    []kkprojectv1.Block{debugBlock("dep_role | block1")},
+						}},
+						{RoleInfo: kkprojectv1.RoleInfo{
+							Base: kkprojectv1.Base{Vars: map[string]any{"a": "default", "b": "vars", "c": "d"}},
+							Role: "meta_role",
+							Block: []kkprojectv1.Block{
+								{
+									BlockBase: kkprojectv1.BlockBase{
+										Base:     kkprojectv1.Base{Name: "Validating arguments against arg spec \"main\""},
+										Taggable: kkprojectv1.Taggable{Tags: []string{"always"}},
+									},
+									Task: kkprojectv1.Task{UnknownField: map[string]any{
+										"validate_argument_spec": map[string]any{"argument_spec": map[string]any{
+											"a": map[string]any{"type": "str", "required": true},
+										}},
+									}},
+								},
+								metaRoleBlock,
+							},
+							Handlers: []kkprojectv1.Block{debugBlock("meta_role | handler1")},
+						}},
+					},
+				},
+			}},
+		},
+		{
+			name: "cyclic dependencies",
+			file: "playbooks/role_cycle.yaml",
+			err:  true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			pb, err := marshalPlaybook(os.DirFS("testdata"), tc.file)
+			if tc.err {
+				assert.Error(t, err)
+
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tc.except, pb)
+		})
+	}
+}
+
+func TestMarshalPlaybookWithImport(t *testing.T) {
+	otherBlock := kkprojectv1.Block{
+		BlockBase: kkprojectv1.BlockBase{Base: kkprojectv1.Base{Name: "dep_role | other"}},
+		Task: kkprojectv1.Task{UnknownField: map[string]any{
+			"debug": map[string]any{
+				"msg": "echo \"hello world\"",
+			},
+		}},
+	}
+
+	pb, err := marshalPlaybook(os.DirFS("testdata"), "playbooks/role_import.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, &kkprojectv1.Playbook{Play: []kkprojectv1.Play{
+		{
+			Base:     kkprojectv1.Base{Name: "role_import"},
+			PlayHost: kkprojectv1.PlayHost{Hosts: []string{"localhost"}},
+			Tasks: []kkprojectv1.Block{
+				{
+					BlockInclude: kkprojectv1.BlockInclude{ImportRole: &kkprojectv1.RoleRef{Name: "dep_role", TasksFrom: "other"}},
+					BlockInfo:    kkprojectv1.BlockInfo{Block: []kkprojectv1.Block{otherBlock}},
+				},
+				{
+					BlockInclude: kkprojectv1.BlockInclude{IncludeTasks: "{{ .file }}"},
+				},
+			},
+		},
+	}}, pb)
+
+	// include_tasks is loaded at execution time.
+	blocks, err := marshalBlock(os.DirFS("testdata"), "playbooks/role_import.yaml", "other.yaml", "dep_role")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []kkprojectv1.Block{otherBlock}, blocks)
+}
+
 func TestCombineMaps(t *testing.T) {
 	testcases := []struct {
 		name   string
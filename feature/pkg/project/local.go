@@ -105,6 +105,16 @@ func (p localProject) MarshalPlaybook() (*kkprojectv1.Playbook, error) {
 	return marshalPlaybook(os.DirFS(p.projectDir), p.playbook)
 }
 
+// MarshalBlock project tasks file to blocks.
+func (p localProject) MarshalBlock(file string, role string) ([]kkprojectv1.Block, error) {
+	return marshalBlock(os.DirFS(p.projectDir), p.playbook, file, role)
+}
+
+// MarshalRole project role to roles with dependencies.
+func (p localProject) MarshalRole(role kkprojectv1.Role, tasksFrom string) ([]kkprojectv1.Role, error) {
+	return marshalRole(os.DirFS(p.projectDir), p.playbook, role, tasksFrom)
+}
+
 // Stat role/file/template file or dir in project
 func (p localProject) Stat(path string, option GetFileOption) (os.FileInfo, error) {
 	return os.Stat(p.getFilePath(path, option))
@@ -32,6 +32,10 @@ var builtinProjectFunc func(kkcorev1.Pipeline) (Project, error)
 // get project file should base on it
 type Project interface {
 	MarshalPlaybook() (*kkprojectv1.Playbook, error)
+	// MarshalBlock load blocks from tasks file. used by "include_tasks" at execution time.
+	MarshalBlock(file string, role string) ([]kkprojectv1.Block, error)
+	// MarshalRole load role and its dependencies. used by "include_role" at execution time.
+	MarshalRole(role kkprojectv1.Role, tasksFrom string) ([]kkprojectv1.Role, error)
 	Stat(path string, option GetFileOption) (os.FileInfo, error)
 	WalkDir(path string, option GetFileOption, f fs.WalkDirFunc) error
 	ReadFile(path string, option GetFileOption) ([]byte, error)
@@ -46,10 +50,15 @@ type GetFileOption struct {
 }
 
 // New project.
+// If project address is oci or tarball format. newArchiveProject
 // If project address is git format. newGitProject
 // If pipeline has BuiltinsProjectAnnotation. builtinProjectFunc
 // Default newLocalProject
 func New(ctx context.Context, pipeline kkcorev1.Pipeline, update bool) (Project, error) {
+	if IsOCIProject(pipeline.Spec.Project.Addr) || IsTarballProject(pipeline.Spec.Project.Addr) {
+		return newArchiveProject(ctx, pipeline, update)
+	}
+
 	if strings.HasPrefix(pipeline.Spec.Project.Addr, "https://") ||
 		strings.HasPrefix(pipeline.Spec.Project.Addr, "http://") ||
 		strings.HasPrefix(pipeline.Spec.Project.Addr, "git@") {
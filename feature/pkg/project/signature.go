@@ -0,0 +1,80 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package project
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/openpgp" //nolint:staticcheck // the same signature format as the artifact of kk v3.
+)
+
+// SignatureSuffix is the suffix of the armored detached signature of tarball project, e.g. project.tgz.asc.
+const SignatureSuffix = ".asc"
+
+// Sign returns the armored detached signature of data. the keyFile is an armored OpenPGP private key,
+// it must not be protected by a passphrase.
+func Sign(keyFile string, data []byte) ([]byte, error) {
+	key, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("read key %s error: %w", keyFile, err)
+	}
+	keyring, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(key))
+	if err != nil {
+		return nil, fmt.Errorf("read key %s error: %w", keyFile, err)
+	}
+	var signer *openpgp.Entity
+	for _, entity := range keyring {
+		if entity.PrivateKey != nil {
+			signer = entity
+
+			break
+		}
+	}
+	if signer == nil {
+		return nil, fmt.Errorf("no private key found in %s", keyFile)
+	}
+	if signer.PrivateKey.Encrypted {
+		return nil, fmt.Errorf("private key in %s is protected by a passphrase", keyFile)
+	}
+
+	signature := &bytes.Buffer{}
+	if err := openpgp.ArmoredDetachSign(signature, signer, bytes.NewReader(data), nil); err != nil {
+		return nil, fmt.Errorf("sign with %s error: %w", keyFile, err)
+	}
+
+	return signature.Bytes(), nil
+}
+
+// verifySignature verifies the armored detached signature of data with the armored OpenPGP public key.
+func verifySignature(publicKey string, data, signature []byte) error {
+	if len(signature) == 0 {
+		return errors.New("signature is not found")
+	}
+	keyring, err := openpgp.ReadArmoredKeyRing(strings.NewReader(publicKey))
+	if err != nil {
+		return fmt.Errorf("read public key error: %w", err)
+	}
+	if _, err := openpgp.CheckArmoredDetachedSignature(keyring, bytes.NewReader(data), bytes.NewReader(signature)); err != nil {
+		return fmt.Errorf("verify signature error: %w", err)
+	}
+
+	return nil
+}
@@ -18,8 +18,16 @@ package proxy
 
 import (
 	"context"
+	"fmt"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apiserver/pkg/admission"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+	apirest "k8s.io/apiserver/pkg/registry/rest"
+
+	kkcorev1 "github.com/kubesphere/kubekey/v4/pkg/apis/core/v1"
+	"github.com/kubesphere/kubekey/v4/pkg/validation"
 )
 
 func newAlwaysAdmit() admission.Interface {
@@ -46,3 +54,47 @@ func (a admit) Handles(admission.Operation) bool {
 var _ admission.MutationInterface = admit{}
 
 var _ admission.ValidationInterface = admit{}
+
+// newPipelineAdmit validate the config referenced by pipeline matches the json schema in project.
+func newPipelineAdmit(configGetter apirest.Getter) admission.Interface {
+	return &pipelineAdmit{configGetter: configGetter}
+}
+
+type pipelineAdmit struct {
+	configGetter apirest.Getter
+}
+
+// Validate the config referenced by pipeline when create.
+func (a pipelineAdmit) Validate(ctx context.Context, attr admission.Attributes, _ admission.ObjectInterfaces) error {
+	if attr.GetSubresource() != "" {
+		return nil
+	}
+	pipeline, ok := attr.GetObject().(*kkcorev1.Pipeline)
+	if !ok || pipeline.Spec.ConfigRef == nil {
+		return nil
+	}
+	namespace := pipeline.Spec.ConfigRef.Namespace
+	if namespace == "" {
+		namespace = attr.GetNamespace()
+	}
+	obj, err := a.configGetter.Get(apirequest.WithNamespace(ctx, namespace), pipeline.Spec.ConfigRef.Name, &metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("get config %s/%s error: %w", namespace, pipeline.Spec.ConfigRef.Name, err)
+	}
+	config, ok := obj.(*kkcorev1.Config)
+	if !ok {
+		return fmt.Errorf("the object of %s/%s is not Config", namespace, pipeline.Spec.ConfigRef.Name)
+	}
+	if errs := validation.ValidatePipelineConfig(ctx, pipeline, config); len(errs) != 0 {
+		return apierrors.NewInvalid(attr.GetKind().GroupKind(), attr.GetName(), errs)
+	}
+
+	return nil
+}
+
+// Handles only create
+func (a pipelineAdmit) Handles(operation admission.Operation) bool {
+	return operation == admission.Create
+}
+
+var _ admission.ValidationInterface = pipelineAdmit{}
@@ -17,11 +17,14 @@ limitations under the License.
 package internal
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"reflect"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -43,18 +46,22 @@ const (
 	deleteTagSuffix = "-deleted"
 	// the file type of resource will store local.
 	yamlSuffix = ".yaml"
+	// resourceVersionFile stores the latest resourceVersion of the resource. it's also the lock file when write resource.
+	resourceVersionFile = ".resourceversion"
 )
 
-func newFileStorage(prefix string, resource schema.GroupResource, codec runtime.Codec, newFunc func() runtime.Object) (apistorage.Interface, factory.DestroyFunc) {
+func newFileStorage(prefix string, resource schema.GroupResource, codec runtime.Codec, newFunc func() runtime.Object, fsync bool) (apistorage.Interface, factory.DestroyFunc) {
 	return &fileStorage{
-			prefix:    prefix,
-			versioner: apistorage.APIObjectVersioner{},
-			resource:  resource,
-			codec:     codec,
-			newFunc:   newFunc,
-		}, func() {
-			// do nothing
-		}
+		prefix:    prefix,
+		versioner: apistorage.APIObjectVersioner{},
+		resource:  resource,
+		codec:     codec,
+		newFunc:   newFunc,
+		fsync:     fsync,
+		mu:        &sync.Mutex{},
+	}, func() {
+		// do nothing
+	}
 }
 
 type fileStorage struct {
@@ -64,6 +71,10 @@ type fileStorage struct {
 	resource  schema.GroupResource
 
 	newFunc func() runtime.Object
+	// fsync flush the resource file to disk after each write.
+	fsync bool
+	// mu serializes writes in process. the file lock of resourceVersionFile serializes writes between processes.
+	mu *sync.Mutex
 }
 
 var _ apistorage.Interface = &fileStorage{}
@@ -75,14 +86,6 @@ func (s fileStorage) Versioner() apistorage.Versioner {
 
 // Create local resource files.
 func (s fileStorage) Create(_ context.Context, key string, obj, out runtime.Object, _ uint64) error {
-	// set resourceVersion to obj
-	metaObj, err := meta.Accessor(obj)
-	if err != nil {
-		klog.V(6).ErrorS(err, "failed to get meta object", "path", filepath.Dir(key))
-
-		return err
-	}
-	metaObj.SetResourceVersion("1")
 	// create file to local disk
 	if _, err := os.Stat(filepath.Dir(key)); err != nil {
 		if !os.IsNotExist(err) {
@@ -97,29 +100,45 @@ func (s fileStorage) Create(_ context.Context, key string, obj, out runtime.Obje
 		}
 	}
 
-	data, err := runtime.Encode(s.codec, obj)
-	if err != nil {
-		klog.V(6).ErrorS(err, "failed to encode resource file", "path", key)
+	return s.withLock(func(current uint64) (uint64, error) {
+		if _, err := os.Stat(key + yamlSuffix); err == nil {
+			return 0, apistorage.NewKeyExistsError(key, 0)
+		} else if !os.IsNotExist(err) {
+			klog.V(6).ErrorS(err, "failed to check resource file", "path", key)
 
-		return err
-	}
-	// render to out
-	if out != nil {
-		err = decode(s.codec, data, out)
+			return 0, err
+		}
+		// set resourceVersion to obj
+		rv := current + 1
+		if err := s.versioner.UpdateObject(obj, rv); err != nil {
+			klog.V(6).ErrorS(err, "failed to set resource version", "path", key)
+
+			return 0, err
+		}
+
+		data, err := runtime.Encode(s.codec, obj)
 		if err != nil {
-			klog.V(6).ErrorS(err, "failed to decode resource file", "path", key)
+			klog.V(6).ErrorS(err, "failed to encode resource file", "path", key)
 
-			return err
+			return 0, err
 		}
-	}
-	// render to file
-	if err := os.WriteFile(key+yamlSuffix, data, os.ModePerm); err != nil {
-		klog.V(6).ErrorS(err, "failed to create resource file", "path", key)
+		// render to file
+		if err := s.writeFile(key+yamlSuffix, data); err != nil {
+			klog.V(6).ErrorS(err, "failed to create resource file", "path", key)
 
-		return err
-	}
+			return 0, err
+		}
+		// render to out
+		if out != nil {
+			if err := decode(s.codec, data, out); err != nil {
+				klog.V(6).ErrorS(err, "failed to decode resource file", "path", key)
 
-	return nil
+				return 0, err
+			}
+		}
+
+		return rv, nil
+	})
 }
 
 // Delete local resource files.
@@ -148,13 +167,15 @@ func (s fileStorage) Delete(ctx context.Context, key string, out runtime.Object,
 
 	// delete object
 	// rename file to trigger watcher
-	if err := os.Rename(key+yamlSuffix, key+yamlSuffix+deleteTagSuffix); err != nil {
-		klog.V(6).ErrorS(err, "failed to rename resource file", "path", key)
+	return s.withLock(func(current uint64) (uint64, error) {
+		if err := os.Rename(key+yamlSuffix, key+yamlSuffix+deleteTagSuffix); err != nil {
+			klog.V(6).ErrorS(err, "failed to rename resource file", "path", key)
 
-		return err
-	}
+			return 0, err
+		}
 
-	return nil
+		return current, nil
+	})
 }
 
 // Watch local resource files.
@@ -163,9 +184,16 @@ func (s fileStorage) Watch(_ context.Context, key string, _ apistorage.ListOptio
 }
 
 // Get local resource files.
-func (s fileStorage) Get(_ context.Context, key string, _ apistorage.GetOptions, out runtime.Object) error {
+func (s fileStorage) Get(_ context.Context, key string, opts apistorage.GetOptions, out runtime.Object) error {
 	data, err := os.ReadFile(key + yamlSuffix)
 	if err != nil {
+		if os.IsNotExist(err) {
+			if opts.IgnoreNotFound {
+				return runtime.SetZeroValue(out)
+			}
+
+			return apistorage.NewKeyNotFoundError(key, 0)
+		}
 		klog.V(6).ErrorS(err, "failed to read resource file", "path", key)
 
 		return err
@@ -398,76 +426,251 @@ func (s fileStorage) handleResult(listObj runtime.Object, v reflect.Value, lastK
 			return err
 		}
 
-		return s.versioner.UpdateList(listObj, 1, next, nil)
+		return s.versioner.UpdateList(listObj, s.resourceVersion(), next, nil)
 	}
 
 	// If no more results, return the final list without continuation.
-	return s.versioner.UpdateList(listObj, 1, "", nil)
+	return s.versioner.UpdateList(listObj, s.resourceVersion(), "", nil)
 }
 
 // GuaranteedUpdate local resource file.
-func (s fileStorage) GuaranteedUpdate(ctx context.Context, key string, destination runtime.Object, ignoreNotFound bool, preconditions *apistorage.Preconditions, tryUpdate apistorage.UpdateFunc, cachedExistingObject runtime.Object) error {
-	var oldObj runtime.Object
-	if cachedExistingObject != nil {
-		oldObj = cachedExistingObject
-	} else {
-		oldObj = s.newFunc()
+// the existing object is always read from disk under lock, so tryUpdate could detect the conflict of resourceVersion.
+func (s fileStorage) GuaranteedUpdate(ctx context.Context, key string, destination runtime.Object, ignoreNotFound bool, preconditions *apistorage.Preconditions, tryUpdate apistorage.UpdateFunc, _ runtime.Object) error {
+	return s.withLock(func(current uint64) (uint64, error) {
+		oldObj := s.newFunc()
 		if err := s.Get(ctx, key, apistorage.GetOptions{IgnoreNotFound: ignoreNotFound}, oldObj); err != nil {
 			klog.V(6).ErrorS(err, "failed to get resource", "path", key)
 
-			return err
+			return 0, err
 		}
+		if err := preconditions.Check(key, oldObj); err != nil {
+			klog.V(6).ErrorS(err, "failed to check preconditions", "path", key)
+
+			return 0, err
+		}
+		oldVersion, err := s.versioner.ObjectResourceVersion(oldObj)
+		if err != nil {
+			klog.V(6).ErrorS(err, "failed to parse resource version", "path", key)
+
+			return 0, err
+		}
+		out, _, err := tryUpdate(oldObj, apistorage.ResponseMeta{ResourceVersion: oldVersion})
+		if err != nil {
+			klog.V(6).ErrorS(err, "failed to try update", "path", key)
+
+			return 0, err
+		}
+		// nothing changed. keep the resource file.
+		if oldVersion != 0 {
+			if err := s.versioner.UpdateObject(out, oldVersion); err != nil {
+				klog.V(6).ErrorS(err, "failed to set resource version", "path", key)
+
+				return 0, err
+			}
+			if unchanged, err := s.unchanged(key, out); err != nil {
+				return 0, err
+			} else if unchanged {
+				return current, s.render(key, out, destination)
+			}
+		}
+		// set resourceVersion to obj
+		rv := max(current, oldVersion) + 1
+		if err := s.versioner.UpdateObject(out, rv); err != nil {
+			klog.V(6).ErrorS(err, "failed to set resource version", "path", key)
+
+			return 0, err
+		}
+		data, err := runtime.Encode(s.codec, out)
+		if err != nil {
+			klog.V(6).ErrorS(err, "failed to encode resource file", "path", key)
+
+			return 0, err
+		}
+		// render to file
+		if err := s.writeFile(key+yamlSuffix, data); err != nil {
+			klog.V(6).ErrorS(err, "failed to update resource file", "path", key)
+
+			return 0, err
+		}
+
+		return rv, s.render(key, out, destination)
+	})
+}
+
+// unchanged check if the encoded obj is same as the resource file.
+func (s fileStorage) unchanged(key string, obj runtime.Object) (bool, error) {
+	data, err := runtime.Encode(s.codec, obj)
+	if err != nil {
+		klog.V(6).ErrorS(err, "failed to encode resource file", "path", key)
+
+		return false, err
 	}
-	if err := preconditions.Check(key, oldObj); err != nil {
-		klog.V(6).ErrorS(err, "failed to check preconditions", "path", key)
+	old, err := os.ReadFile(key + yamlSuffix)
+	if err != nil {
+		klog.V(6).ErrorS(err, "failed to read resource file", "path", key)
 
-		return err
+		return false, err
 	}
-	// set resourceVersion to obj
-	metaObj, err := meta.Accessor(oldObj)
+
+	return bytes.Equal(data, old), nil
+}
+
+// render obj to destination.
+func (s fileStorage) render(key string, obj, destination runtime.Object) error {
+	if destination == nil {
+		return nil
+	}
+	data, err := runtime.Encode(s.codec, obj)
 	if err != nil {
-		klog.V(6).ErrorS(err, "failed to get meta object", "path", filepath.Dir(key))
+		klog.V(6).ErrorS(err, "failed to encode resource file", "path", key)
 
 		return err
 	}
-	oldVersion, err := s.versioner.ParseResourceVersion(metaObj.GetResourceVersion())
-	if err != nil {
-		klog.V(6).ErrorS(err, "failed to parse resource version", "resourceVersion", metaObj.GetResourceVersion())
+	if err := decode(s.codec, data, destination); err != nil {
+		klog.V(6).ErrorS(err, "failed to decode resource file", "path", key)
 
 		return err
 	}
-	out, _, err := tryUpdate(oldObj, apistorage.ResponseMeta{ResourceVersion: oldVersion + 1})
+
+	return nil
+}
+
+// withLock runs fn under the write lock of the resource. fn receives the latest resourceVersion of the resource,
+// and returns the resourceVersion after writing. the returned resourceVersion is stored when fn succeeds.
+func (s fileStorage) withLock(fn func(current uint64) (uint64, error)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(s.prefix, os.ModePerm); err != nil {
+		klog.V(6).ErrorS(err, "failed to create dir", "path", s.prefix)
+
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(s.prefix, resourceVersionFile), os.O_RDWR|os.O_CREATE, 0o644)
 	if err != nil {
-		klog.V(6).ErrorS(err, "failed to try update", "path", key)
+		klog.V(6).ErrorS(err, "failed to open resource version file", "path", s.prefix)
+
+		return err
+	}
+	defer f.Close()
+	// the lock is released when the file is closed.
+	if err := lockFile(f); err != nil {
+		klog.V(6).ErrorS(err, "failed to lock resource version file", "path", s.prefix)
 
 		return err
 	}
 
-	data, err := runtime.Encode(s.codec, out)
+	data, err := io.ReadAll(f)
 	if err != nil {
-		klog.V(6).ErrorS(err, "failed to encode resource file", "path", key)
+		klog.V(6).ErrorS(err, "failed to read resource version file", "path", s.prefix)
 
 		return err
 	}
-	// render to destination
-	if destination != nil {
-		err = decode(s.codec, data, destination)
-		if err != nil {
-			klog.V(6).ErrorS(err, "failed to decode resource file", "path", key)
+	current, err := parseResourceVersion(data)
+	if err != nil {
+		return err
+	}
 
-			return err
+	rv, err := fn(current)
+	if err != nil {
+		return err
+	}
+	if rv == current {
+		return nil
+	}
+	if err := f.Truncate(0); err != nil {
+		return fmt.Errorf("truncate resource version file error: %w", err)
+	}
+	if _, err := f.WriteAt([]byte(strconv.FormatUint(rv, 10)), 0); err != nil {
+		return fmt.Errorf("write resource version file error: %w", err)
+	}
+	if s.fsync {
+		if err := f.Sync(); err != nil {
+			return fmt.Errorf("sync resource version file error: %w", err)
 		}
 	}
-	// render to file
-	if err := os.WriteFile(key+yamlSuffix, data, os.ModePerm); err != nil {
-		klog.V(6).ErrorS(err, "failed to create resource file", "path", key)
 
-		return err
+	return nil
+}
+
+// resourceVersion returns the latest resourceVersion of the resource.
+func (s fileStorage) resourceVersion() uint64 {
+	data, err := os.ReadFile(filepath.Join(s.prefix, resourceVersionFile))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			klog.V(6).ErrorS(err, "failed to read resource version file", "path", s.prefix)
+		}
+
+		return 1
+	}
+	rv, err := parseResourceVersion(data)
+	if err != nil || rv == 0 {
+		return 1
+	}
+
+	return rv
+}
+
+// writeFile writes data to a temp file and renames it to path. the reader never sees a partial written file.
+func (s fileStorage) writeFile(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("create temp file error: %w", err)
+	}
+	// remove temp file when fail to rename.
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+
+		return fmt.Errorf("write temp file error: %w", err)
+	}
+	if s.fsync {
+		if err := tmp.Sync(); err != nil {
+			tmp.Close()
+
+			return fmt.Errorf("sync temp file error: %w", err)
+		}
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close temp file error: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("chmod temp file error: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("rename temp file error: %w", err)
+	}
+	if s.fsync {
+		// sync the dir to persist the rename.
+		d, err := os.Open(dir)
+		if err != nil {
+			return fmt.Errorf("open dir error: %w", err)
+		}
+		defer d.Close()
+		if err := d.Sync(); err != nil {
+			return fmt.Errorf("sync dir error: %w", err)
+		}
 	}
 
 	return nil
 }
 
+// parseResourceVersion from the content of resourceVersionFile. empty content is 0.
+func parseResourceVersion(data []byte) (uint64, error) {
+	str := strings.TrimSpace(string(data))
+	if str == "" {
+		return 0, nil
+	}
+	rv, err := strconv.ParseUint(str, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid resource version %q: %w", str, err)
+	}
+
+	return rv, nil
+}
+
 // Count local resource file
 func (s fileStorage) Count(key string) (int64, error) {
 	// countByNSDir count the crd files by namespace dir.
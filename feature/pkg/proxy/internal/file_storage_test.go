@@ -0,0 +1,123 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"context"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	apistorage "k8s.io/apiserver/pkg/storage"
+
+	kkcorev1 "github.com/kubesphere/kubekey/v4/pkg/apis/core/v1"
+)
+
+func newTestStorage(t *testing.T) (*fileStorage, string) {
+	t.Helper()
+	prefix := t.TempDir()
+	s, _ := newFileStorage(prefix, schema.GroupResource{Group: kkcorev1.SchemeGroupVersion.Group, Resource: "configs"},
+		newYamlCodec(kkcorev1.SchemeGroupVersion), func() runtime.Object { return &kkcorev1.Config{} }, true)
+	fs, ok := s.(*fileStorage)
+	if !ok {
+		t.Fatal("storage is not fileStorage")
+	}
+
+	return fs, filepath.Join(prefix, "default", "test")
+}
+
+func TestFileStorageCreate(t *testing.T) {
+	s, key := newTestStorage(t)
+	ctx := context.Background()
+
+	out := &kkcorev1.Config{}
+	if err := s.Create(ctx, key, &kkcorev1.Config{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"}}, out, 0); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "1", out.ResourceVersion)
+
+	err := s.Create(ctx, key, &kkcorev1.Config{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"}}, nil, 0)
+	assert.True(t, apistorage.IsExist(err))
+
+	err = s.Get(ctx, filepath.Join(filepath.Dir(key), "not-exist"), apistorage.GetOptions{}, &kkcorev1.Config{})
+	assert.True(t, apistorage.IsNotFound(err))
+}
+
+func TestFileStorageGuaranteedUpdate(t *testing.T) {
+	s, key := newTestStorage(t)
+	ctx := context.Background()
+
+	if err := s.Create(ctx, key, &kkcorev1.Config{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"}}, nil, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// concurrent updates are serialized, and no update is lost.
+	count := 20
+	wg := sync.WaitGroup{}
+	for range count {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.GuaranteedUpdate(ctx, key, &kkcorev1.Config{}, false, nil, func(input runtime.Object, _ apistorage.ResponseMeta) (runtime.Object, *uint64, error) {
+				config, ok := input.(*kkcorev1.Config)
+				if !ok {
+					return nil, nil, apierrors.NewBadRequest("not config")
+				}
+				if config.Labels == nil {
+					config.Labels = make(map[string]string)
+				}
+				n, _ := strconv.Atoi(config.Labels["count"])
+				config.Labels["count"] = strconv.Itoa(n + 1)
+
+				return config, nil, nil
+			}, nil); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	out := &kkcorev1.Config{}
+	if err := s.Get(ctx, key, apistorage.GetOptions{}, out); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, strconv.Itoa(count), out.Labels["count"])
+	assert.Equal(t, strconv.Itoa(count+1), out.ResourceVersion)
+	assert.Equal(t, uint64(count+1), s.resourceVersion())
+
+	// unchanged update keeps the resourceVersion.
+	if err := s.GuaranteedUpdate(ctx, key, out, false, nil, func(input runtime.Object, _ apistorage.ResponseMeta) (runtime.Object, *uint64, error) {
+		return input, nil, nil
+	}, nil); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, strconv.Itoa(count+1), out.ResourceVersion)
+
+	// precondition with stale resourceVersion fails.
+	staleRV := "1"
+	err := s.GuaranteedUpdate(ctx, key, out, false, &apistorage.Preconditions{ResourceVersion: &staleRV},
+		func(input runtime.Object, _ apistorage.ResponseMeta) (runtime.Object, *uint64, error) {
+			return input, nil, nil
+		}, nil)
+	assert.True(t, apistorage.IsInvalidObj(err))
+}
@@ -34,10 +34,12 @@ import (
 	_const "github.com/kubesphere/kubekey/v4/pkg/const"
 )
 
-// NewFileRESTOptionsGetter return fileRESTOptionsGetter
-func NewFileRESTOptionsGetter(gv schema.GroupVersion) apigeneric.RESTOptionsGetter {
+// NewFileRESTOptionsGetter return fileRESTOptionsGetter.
+// when fsync is true, the resource files are flushed to disk after each write.
+func NewFileRESTOptionsGetter(gv schema.GroupVersion, fsync bool) apigeneric.RESTOptionsGetter {
 	return &fileRESTOptionsGetter{
-		gv: gv,
+		gv:    gv,
+		fsync: fsync,
 		storageConfig: &storagebackend.Config{
 			Type:            "",
 			Prefix:          "/",
@@ -64,6 +66,7 @@ func newYamlCodec(gv schema.GroupVersion) runtime.Codec {
 type fileRESTOptionsGetter struct {
 	gv            schema.GroupVersion
 	storageConfig *storagebackend.Config
+	fsync         bool
 }
 
 // GetRESTOptions return apigeneric.RESTOptions
@@ -79,7 +82,7 @@ func (f fileRESTOptionsGetter) GetRESTOptions(resource schema.GroupResource) (ap
 			getAttrsFunc apistorage.AttrFunc,
 			triggerFuncs apistorage.IndexerFuncs,
 			indexers *cgtoolscache.Indexers) (apistorage.Interface, factory.DestroyFunc, error) {
-			s, d := newFileStorage(prefix, resource, storageConfig.Codec, newFunc)
+			s, d := newFileStorage(prefix, resource, storageConfig.Codec, newFunc, f.fsync)
 
 			cacherConfig := cacherstorage.Config{
 				Storage:        s,
@@ -36,6 +36,9 @@ type fileWatcher struct {
 	newFunc     func() runtime.Object
 	watcher     *fsnotify.Watcher
 	watchEvents chan watch.Event
+	// resources is the existing resource files. the resource file is replaced by rename when update,
+	// which is a create event. it's used to distinguish update from create.
+	resources map[string]struct{}
 }
 
 // newFileWatcher get fileWatcher
@@ -62,6 +65,8 @@ func newFileWatcher(prefix, path string, codec runtime.Codec, newFunc func() run
 
 		return nil, err
 	}
+	resources := make(map[string]struct{})
+	addResources(resources, path)
 	// add namespace dir to watcher
 	if prefix == path {
 		entry, err := os.ReadDir(prefix)
@@ -77,6 +82,7 @@ func newFileWatcher(prefix, path string, codec runtime.Codec, newFunc func() run
 
 					return nil, err
 				}
+				addResources(resources, filepath.Join(prefix, e.Name()))
 			}
 		}
 	}
@@ -87,6 +93,7 @@ func newFileWatcher(prefix, path string, codec runtime.Codec, newFunc func() run
 		watcher:     watcher,
 		newFunc:     newFunc,
 		watchEvents: make(chan watch.Event),
+		resources:   resources,
 	}
 
 	go w.watch()
@@ -111,6 +118,9 @@ func (w *fileWatcher) watch() {
 		select {
 		case event := <-w.watcher.Events:
 			klog.V(6).InfoS("receive watcher event", "event", event)
+			if event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) {
+				delete(w.resources, event.Name)
+			}
 			// Adjust the listening range. a watcher for a namespace.
 			// the watcher contains all resources in the namespace.
 			entry, err := os.Stat(event.Name)
@@ -180,8 +190,14 @@ func (w *fileWatcher) watchFile(event fsnotify.Event) error {
 
 	switch event.Op {
 	case fsnotify.Create:
+		eventType := watch.Added
+		if _, ok := w.resources[event.Name]; ok {
+			// the resource file is replaced.
+			eventType = watch.Modified
+		}
+		w.resources[event.Name] = struct{}{}
 		w.watchEvents <- watch.Event{
-			Type:   watch.Added,
+			Type:   eventType,
 			Object: obj,
 		}
 	case fsnotify.Write:
@@ -205,3 +221,18 @@ func (w *fileWatcher) watchFile(event fsnotify.Event) error {
 
 	return nil
 }
+
+// addResources add the resource files in dir to resources.
+func addResources(resources map[string]struct{}, dir string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		klog.V(6).ErrorS(err, "failed to read dir", "dir", dir)
+
+		return
+	}
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), yamlSuffix) {
+			resources[filepath.Join(dir, e.Name())] = struct{}{}
+		}
+	}
+}
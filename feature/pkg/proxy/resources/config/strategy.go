@@ -18,13 +18,16 @@ package config
 
 import (
 	"context"
+	"errors"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	apinames "k8s.io/apiserver/pkg/storage/names"
 	"sigs.k8s.io/structured-merge-diff/v4/fieldpath"
 
+	kkcorev1 "github.com/kubesphere/kubekey/v4/pkg/apis/core/v1"
 	_const "github.com/kubesphere/kubekey/v4/pkg/const"
+	"github.com/kubesphere/kubekey/v4/pkg/validation"
 )
 
 // ConfigStrategy implements behavior for Pods
@@ -49,10 +52,14 @@ func (t ConfigStrategy) PrepareForCreate(context.Context, runtime.Object) {
 	// do nothing
 }
 
-// Validate always pass
-func (t ConfigStrategy) Validate(context.Context, runtime.Object) field.ErrorList {
-	// do nothing
-	return nil
+// Validate spec of config
+func (t ConfigStrategy) Validate(_ context.Context, obj runtime.Object) field.ErrorList {
+	config, ok := obj.(*kkcorev1.Config)
+	if !ok {
+		return field.ErrorList{field.InternalError(field.NewPath("spec"), errors.New("the object is not Config"))}
+	}
+
+	return validation.ValidateConfig(config)
 }
 
 // WarningsOnCreate do no-thing
@@ -78,10 +85,9 @@ func (t ConfigStrategy) PrepareForUpdate(context.Context, runtime.Object, runtim
 	// do nothing
 }
 
-// ValidateUpdate do nothing
-func (t ConfigStrategy) ValidateUpdate(context.Context, runtime.Object, runtime.Object) field.ErrorList {
-	// do nothing
-	return nil
+// ValidateUpdate spec of config
+func (t ConfigStrategy) ValidateUpdate(ctx context.Context, obj, _ runtime.Object) field.ErrorList {
+	return t.Validate(ctx, obj)
 }
 
 // WarningsOnUpdate always nil
@@ -18,13 +18,16 @@ package inventory
 
 import (
 	"context"
+	"errors"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	apinames "k8s.io/apiserver/pkg/storage/names"
 	"sigs.k8s.io/structured-merge-diff/v4/fieldpath"
 
+	kkcorev1 "github.com/kubesphere/kubekey/v4/pkg/apis/core/v1"
 	_const "github.com/kubesphere/kubekey/v4/pkg/const"
+	"github.com/kubesphere/kubekey/v4/pkg/validation"
 )
 
 // inventoryStrategy implements behavior for Pods
@@ -49,10 +52,14 @@ func (t inventoryStrategy) PrepareForCreate(context.Context, runtime.Object) {
 	// do nothing
 }
 
-// Validate always pass
-func (t inventoryStrategy) Validate(context.Context, runtime.Object) field.ErrorList {
-	// do nothing
-	return nil
+// Validate hosts and groups of inventory
+func (t inventoryStrategy) Validate(_ context.Context, obj runtime.Object) field.ErrorList {
+	inventory, ok := obj.(*kkcorev1.Inventory)
+	if !ok {
+		return field.ErrorList{field.InternalError(field.NewPath("spec"), errors.New("the object is not Inventory"))}
+	}
+
+	return validation.ValidateInventory(inventory)
 }
 
 // WarningsOnCreate do no-thing
@@ -76,10 +83,9 @@ func (t inventoryStrategy) AllowCreateOnUpdate() bool {
 // PrepareForUpdate do no-thing
 func (t inventoryStrategy) PrepareForUpdate(context.Context, runtime.Object, runtime.Object) {}
 
-// ValidateUpdate do nothing
-func (t inventoryStrategy) ValidateUpdate(context.Context, runtime.Object, runtime.Object) field.ErrorList {
-	// do nothing
-	return nil
+// ValidateUpdate hosts and groups of inventory
+func (t inventoryStrategy) ValidateUpdate(ctx context.Context, obj, _ runtime.Object) field.ErrorList {
+	return t.Validate(ctx, obj)
 }
 
 // WarningsOnUpdate always nil
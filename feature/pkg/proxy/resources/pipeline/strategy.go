@@ -19,7 +19,6 @@ package pipeline
 import (
 	"context"
 	"errors"
-	"reflect"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/validation/field"
@@ -28,6 +27,7 @@ import (
 
 	kkcorev1 "github.com/kubesphere/kubekey/v4/pkg/apis/core/v1"
 	_const "github.com/kubesphere/kubekey/v4/pkg/const"
+	"github.com/kubesphere/kubekey/v4/pkg/validation"
 )
 
 // pipelineStrategy implements behavior for Pods
@@ -50,10 +50,14 @@ func (t pipelineStrategy) NamespaceScoped() bool {
 // PrepareForCreate do no-thing
 func (t pipelineStrategy) PrepareForCreate(context.Context, runtime.Object) {}
 
-// Validate always pass
-func (t pipelineStrategy) Validate(context.Context, runtime.Object) field.ErrorList {
-	// do nothing
-	return nil
+// Validate playbook and project of pipeline
+func (t pipelineStrategy) Validate(_ context.Context, obj runtime.Object) field.ErrorList {
+	pipeline, ok := obj.(*kkcorev1.Pipeline)
+	if !ok {
+		return field.ErrorList{field.InternalError(field.NewPath("spec"), errors.New("the object is not Pipeline"))}
+	}
+
+	return validation.ValidatePipeline(pipeline)
 }
 
 // WarningsOnCreate do no-thing
@@ -79,9 +83,8 @@ func (t pipelineStrategy) PrepareForUpdate(context.Context, runtime.Object, runt
 	// do nothing
 }
 
-// ValidateUpdate spec is immutable
+// ValidateUpdate spec is immutable, except spec.cancel which can be set to cancel the pipeline.
 func (t pipelineStrategy) ValidateUpdate(_ context.Context, obj, old runtime.Object) field.ErrorList {
-	// only support update status
 	pipeline, ok := obj.(*kkcorev1.Pipeline)
 	if !ok {
 		return field.ErrorList{field.InternalError(field.NewPath("spec"), errors.New("the object is not Task"))}
@@ -90,11 +93,8 @@ func (t pipelineStrategy) ValidateUpdate(_ context.Context, obj, old runtime.Obj
 	if !ok {
 		return field.ErrorList{field.InternalError(field.NewPath("spec"), errors.New("the object is not Task"))}
 	}
-	if !reflect.DeepEqual(pipeline.Spec, oldPipeline.Spec) {
-		return field.ErrorList{field.Forbidden(field.NewPath("spec"), "spec is immutable")}
-	}
 
-	return nil
+	return validation.ValidatePipelineUpdate(pipeline, oldPipeline)
 }
 
 // WarningsOnUpdate always nil
@@ -0,0 +1,78 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipeline
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kkcorev1 "github.com/kubesphere/kubekey/v4/pkg/apis/core/v1"
+)
+
+func TestPipelineStrategyValidateUpdate(t *testing.T) {
+	oldPipeline := &kkcorev1.Pipeline{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec:       kkcorev1.PipelineSpec{Playbook: "playbooks/create_cluster.yaml"},
+	}
+	testcases := []struct {
+		name   string
+		old    func() *kkcorev1.Pipeline
+		patch  func(*kkcorev1.Pipeline)
+		except int
+	}{
+		{
+			name:  "cancel the pipeline",
+			old:   oldPipeline.DeepCopy,
+			patch: func(p *kkcorev1.Pipeline) { p.Spec.Cancel = true },
+		},
+		{
+			name:  "update status",
+			old:   oldPipeline.DeepCopy,
+			patch: func(p *kkcorev1.Pipeline) { p.Status.Phase = kkcorev1.PipelinePhaseRunning },
+		},
+		{
+			name: "resume the cancelled pipeline",
+			old: func() *kkcorev1.Pipeline {
+				p := oldPipeline.DeepCopy()
+				p.Spec.Cancel = true
+
+				return p
+			},
+			patch:  func(p *kkcorev1.Pipeline) { p.Spec.Cancel = false },
+			except: 1,
+		},
+		{
+			name:   "update playbook",
+			old:    oldPipeline.DeepCopy,
+			patch:  func(p *kkcorev1.Pipeline) { p.Spec.Playbook = "playbooks/delete_cluster.yaml" },
+			except: 1,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			old := tc.old()
+			// the same as the patch of "kk pipeline cancel".
+			pipeline := old.DeepCopy()
+			tc.patch(pipeline)
+			assert.Len(t, Strategy.ValidateUpdate(context.Background(), pipeline, old), tc.except)
+		})
+	}
+}
@@ -53,6 +53,14 @@ import (
 	"github.com/kubesphere/kubekey/v4/pkg/proxy/resources/task"
 )
 
+// fsync flushes the resource files stored in local to disk after each write. It's set by SetFsync.
+var fsync bool
+
+// SetFsync sets whether to flush the resource files stored in local to disk after each write.
+func SetFsync(enable bool) {
+	fsync = enable
+}
+
 // NewConfig replace the restconfig transport to proxy transport
 func NewConfig(restconfig *rest.Config) (*rest.Config, error) {
 	var err error
@@ -91,7 +99,7 @@ func newProxyTransport(restConfig *rest.Config) (http.RoundTripper, error) {
 
 	// register kkcorev1alpha1 resources
 	kkv1alpha1 := newAPIIResources(kkcorev1alpha1.SchemeGroupVersion)
-	storage, err := task.NewStorage(internal.NewFileRESTOptionsGetter(kkcorev1alpha1.SchemeGroupVersion))
+	storage, err := task.NewStorage(internal.NewFileRESTOptionsGetter(kkcorev1alpha1.SchemeGroupVersion, fsync))
 	if err != nil {
 		klog.V(6).ErrorS(err, "failed to create storage")
 
@@ -122,7 +130,7 @@ func newProxyTransport(restConfig *rest.Config) (http.RoundTripper, error) {
 		// register kkcorev1 resources
 		kkv1 := newAPIIResources(kkcorev1.SchemeGroupVersion)
 		// add config
-		configStorage, err := config.NewStorage(internal.NewFileRESTOptionsGetter(kkcorev1.SchemeGroupVersion))
+		configStorage, err := config.NewStorage(internal.NewFileRESTOptionsGetter(kkcorev1.SchemeGroupVersion, fsync))
 		if err != nil {
 			klog.V(6).ErrorS(err, "failed to create storage")
 
@@ -137,7 +145,7 @@ func newProxyTransport(restConfig *rest.Config) (http.RoundTripper, error) {
 			return nil, err
 		}
 		// add inventory
-		inventoryStorage, err := inventory.NewStorage(internal.NewFileRESTOptionsGetter(kkcorev1.SchemeGroupVersion))
+		inventoryStorage, err := inventory.NewStorage(internal.NewFileRESTOptionsGetter(kkcorev1.SchemeGroupVersion, fsync))
 		if err != nil {
 			klog.V(6).ErrorS(err, "failed to create storage")
 
@@ -152,7 +160,7 @@ func newProxyTransport(restConfig *rest.Config) (http.RoundTripper, error) {
 			return nil, err
 		}
 		// add pipeline
-		pipelineStorage, err := pipeline.NewStorage(internal.NewFileRESTOptionsGetter(kkcorev1.SchemeGroupVersion))
+		pipelineStorage, err := pipeline.NewStorage(internal.NewFileRESTOptionsGetter(kkcorev1.SchemeGroupVersion, fsync))
 		if err != nil {
 			klog.V(6).ErrorS(err, "failed to create storage")
 
@@ -161,6 +169,7 @@ func newProxyTransport(restConfig *rest.Config) (http.RoundTripper, error) {
 		if err := kkv1.AddResource(resourceOptions{
 			path:    "pipelines",
 			storage: pipelineStorage.Pipeline,
+			admit:   newPipelineAdmit(configStorage.Config),
 		}); err != nil {
 			klog.V(6).ErrorS(err, "failed to add resource")
 
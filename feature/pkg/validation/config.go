@@ -0,0 +1,103 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	openapierrors "k8s.io/kube-openapi/pkg/validation/errors"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+	"k8s.io/kube-openapi/pkg/validation/validate"
+
+	kkcorev1 "github.com/kubesphere/kubekey/v4/pkg/apis/core/v1"
+	_const "github.com/kubesphere/kubekey/v4/pkg/const"
+	"github.com/kubesphere/kubekey/v4/pkg/project"
+)
+
+// ValidateConfig check the spec of config is a json object.
+func ValidateConfig(config *kkcorev1.Config) field.ErrorList {
+	if len(config.Spec.Raw) == 0 {
+		return nil
+	}
+	var spec map[string]any
+	if err := json.Unmarshal(config.Spec.Raw, &spec); err != nil {
+		return field.ErrorList{field.Invalid(field.NewPath("spec"), string(config.Spec.Raw), "spec should be an object")}
+	}
+
+	return nil
+}
+
+// ValidateConfigWithSchema check the spec of config matches the json schema.
+func ValidateConfigWithSchema(config *kkcorev1.Config, schema []byte) field.ErrorList {
+	specPath := field.NewPath("spec")
+	s := &spec.Schema{}
+	if err := json.Unmarshal(schema, s); err != nil {
+		return field.ErrorList{field.InternalError(specPath, fmt.Errorf("invalid json schema: %w", err))}
+	}
+	data := make(map[string]any)
+	if len(config.Spec.Raw) != 0 {
+		if err := json.Unmarshal(config.Spec.Raw, &data); err != nil {
+			return field.ErrorList{field.Invalid(specPath, string(config.Spec.Raw), "spec should be an object")}
+		}
+	}
+
+	var allErrs field.ErrorList
+	for _, err := range validate.NewSchemaValidator(s, nil, "", strfmt.Default).Validate(data).Errors {
+		var ve *openapierrors.Validation
+		if errors.As(err, &ve) && ve.Name != "" && ve.Name != "." {
+			allErrs = append(allErrs, field.Invalid(specPath.Child(ve.Name), ve.Value, err.Error()))
+
+			continue
+		}
+		allErrs = append(allErrs, field.Invalid(specPath, nil, err.Error()))
+	}
+
+	return allErrs
+}
+
+// ValidatePipelineConfig check the config referenced by pipeline matches the json schema in project.
+// It's checked when the pipeline is submitted, the project is fetched if it's not in work_dir.
+func ValidatePipelineConfig(ctx context.Context, pipeline *kkcorev1.Pipeline, config *kkcorev1.Config) field.ErrorList {
+	pj, err := project.New(ctx, *pipeline, false)
+	if err != nil {
+		return field.ErrorList{field.Invalid(field.NewPath("spec", "project"), pipeline.Spec.Project.Addr, err.Error())}
+	}
+
+	return ValidateProjectConfig(pj, config)
+}
+
+// ValidateProjectConfig check the config matches the json schema in project.
+// It's skipped when project has no json schema for config.
+func ValidateProjectConfig(pj project.Project, config *kkcorev1.Config) field.ErrorList {
+	schemaFile := filepath.Join(_const.ProjectSchemaDir, _const.ProjectSchemaConfigFile)
+	if _, err := pj.Stat(schemaFile, project.GetFileOption{}); err != nil {
+		// project has no json schema
+		return nil
+	}
+	schema, err := pj.ReadFile(schemaFile, project.GetFileOption{})
+	if err != nil {
+		return field.ErrorList{field.InternalError(field.NewPath("spec"), fmt.Errorf("read json schema error: %w", err))}
+	}
+
+	return ValidateConfigWithSchema(config, schema)
+}
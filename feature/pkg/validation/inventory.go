@@ -0,0 +1,87 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"slices"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	kkcorev1 "github.com/kubesphere/kubekey/v4/pkg/apis/core/v1"
+	_const "github.com/kubesphere/kubekey/v4/pkg/const"
+)
+
+// ValidateInventory check the hosts and groups in inventory.
+// The hosts and groups referenced by group should be defined in inventory, and groups should not be referenced cyclically.
+func ValidateInventory(inventory *kkcorev1.Inventory) field.ErrorList {
+	var allErrs field.ErrorList
+	groupsPath := field.NewPath("spec", "groups")
+
+	// sort group names to get stable error messages.
+	names := make([]string, 0, len(inventory.Spec.Groups))
+	for name := range inventory.Spec.Groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		group := inventory.Spec.Groups[name]
+		if name == _const.VariableGroupsAll {
+			allErrs = append(allErrs, field.Forbidden(groupsPath.Key(name), "group \"all\" is reserved, which contains all hosts"))
+		}
+		for i, h := range group.Hosts {
+			// localhost is always defined.
+			if _, ok := inventory.Spec.Hosts[h]; !ok && h != _const.VariableLocalHost {
+				allErrs = append(allErrs, field.NotFound(groupsPath.Key(name).Child("hosts").Index(i), h))
+			}
+		}
+		for i, g := range group.Groups {
+			if _, ok := inventory.Spec.Groups[g]; !ok {
+				allErrs = append(allErrs, field.NotFound(groupsPath.Key(name).Child("groups").Index(i), g))
+			}
+		}
+	}
+
+	// check cyclic reference. only report once for each cycle.
+	reported := make(map[string]bool)
+	for _, name := range names {
+		if cycle := findGroupCycle(inventory.Spec.Groups, name, nil); len(cycle) != 0 && !reported[cycle[0]] {
+			for _, g := range cycle {
+				reported[g] = true
+			}
+			allErrs = append(allErrs, field.Invalid(groupsPath.Key(name).Child("groups"), cycle, "groups should not be referenced cyclically"))
+		}
+	}
+
+	return allErrs
+}
+
+// findGroupCycle return the cycle path (start and end with the same group) if group is referenced cyclically.
+func findGroupCycle(groups map[string]kkcorev1.InventoryGroup, name string, path []string) []string {
+	if i := slices.Index(path, name); i != -1 {
+		return append(slices.Clone(path[i:]), name)
+	}
+	path = append(path, name)
+	for _, g := range groups[name].Groups {
+		if cycle := findGroupCycle(groups, g, path); len(cycle) != 0 {
+			return cycle
+		}
+	}
+
+	return nil
+}
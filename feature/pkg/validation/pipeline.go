@@ -0,0 +1,142 @@
+/*
+Copyright 2024 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	kkcorev1 "github.com/kubesphere/kubekey/v4/pkg/apis/core/v1"
+)
+
+// ValidatePipeline check the playbook and project in pipeline.
+func ValidatePipeline(pipeline *kkcorev1.Pipeline) field.ErrorList {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	// playbook
+	playbookPath := specPath.Child("playbook")
+	switch {
+	case pipeline.Spec.Playbook == "":
+		allErrs = append(allErrs, field.Required(playbookPath, ""))
+	case filepath.Ext(pipeline.Spec.Playbook) != ".yaml" && filepath.Ext(pipeline.Spec.Playbook) != ".yml":
+		allErrs = append(allErrs, field.Invalid(playbookPath, pipeline.Spec.Playbook, "playbook should be a yaml file"))
+	case isArchiveProject(pipeline.Spec.Project.Addr) || isGitProject(pipeline.Spec.Project.Addr) || isBuiltinProject(pipeline):
+		if filepath.IsAbs(pipeline.Spec.Playbook) {
+			allErrs = append(allErrs, field.Invalid(playbookPath, pipeline.Spec.Playbook, "playbook should be relative path base on project.addr"))
+		}
+	}
+
+	// project
+	projectPath := specPath.Child("project")
+	project := pipeline.Spec.Project
+	// project name is the directory name of git project in work_dir.
+	if strings.ContainsAny(project.Name, "/\\") || project.Name == "." || project.Name == ".." {
+		allErrs = append(allErrs, field.Invalid(projectPath.Child("name"), project.Name, "name should be a directory name"))
+	}
+	if project.Branch != "" && project.Tag != "" {
+		allErrs = append(allErrs, field.Forbidden(projectPath.Child("tag"), "branch and tag cannot be set at the same time"))
+	}
+	switch {
+	case isArchiveProject(project.Addr):
+		if project.Branch != "" || project.Tag != "" {
+			allErrs = append(allErrs, field.Forbidden(projectPath.Child("addr"), "branch and tag are not supported for oci or tarball project"))
+		}
+		if project.Checksum != "" && !checksumRegexp.MatchString(project.Checksum) {
+			allErrs = append(allErrs, field.Invalid(projectPath.Child("checksum"), project.Checksum, "checksum should be in format sha256:<hex>"))
+		}
+	case isGitProject(project.Addr):
+		if project.Checksum != "" {
+			allErrs = append(allErrs, field.Forbidden(projectPath.Child("checksum"), "checksum is only supported for oci or tarball project"))
+		}
+		if project.PublicKey != "" {
+			allErrs = append(allErrs, field.Forbidden(projectPath.Child("publicKey"), "publicKey is only supported for oci or tarball project"))
+		}
+	case project.Branch != "" || project.Tag != "" || project.Token != "" || project.Checksum != "" || project.PublicKey != "":
+		allErrs = append(allErrs, field.Forbidden(projectPath.Child("addr"), "branch, tag, token, checksum and publicKey are only supported for remote project"))
+	}
+
+	// reference
+	if pipeline.Spec.InventoryRef != nil && pipeline.Spec.InventoryRef.Name == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("inventoryRef", "name"), ""))
+	}
+	if pipeline.Spec.ConfigRef != nil && pipeline.Spec.ConfigRef.Name == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("configRef", "name"), ""))
+	}
+
+	// dependsOn
+	seen := make(map[string]bool)
+	for i, name := range pipeline.Spec.DependsOn {
+		switch {
+		case name == "":
+			allErrs = append(allErrs, field.Required(specPath.Child("dependsOn").Index(i), ""))
+		case name == pipeline.Name:
+			allErrs = append(allErrs, field.Invalid(specPath.Child("dependsOn").Index(i), name, "pipeline should not depend on itself"))
+		case seen[name]:
+			allErrs = append(allErrs, field.Duplicate(specPath.Child("dependsOn").Index(i), name))
+		}
+		seen[name] = true
+	}
+
+	return allErrs
+}
+
+// ValidatePipelineUpdate check the update of pipeline. the spec of pipeline is immutable,
+// except spec.cancel which can be set to cancel the pipeline.
+func ValidatePipelineUpdate(newPipeline, oldPipeline *kkcorev1.Pipeline) field.ErrorList {
+	var allErrs field.ErrorList
+	if !reflect.DeepEqual(mutablePipelineSpec(oldPipeline.Spec), mutablePipelineSpec(newPipeline.Spec)) {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec"), "spec is immutable except cancel"))
+	}
+	if oldPipeline.Spec.Cancel && !newPipeline.Spec.Cancel {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "cancel"), "a cancelled pipeline can not be resumed"))
+	}
+
+	return allErrs
+}
+
+// mutablePipelineSpec clears the fields of PipelineSpec which can be updated after the pipeline is created.
+func mutablePipelineSpec(spec kkcorev1.PipelineSpec) kkcorev1.PipelineSpec {
+	spec.Cancel = false
+
+	return spec
+}
+
+// checksumRegexp is the format of project checksum.
+var checksumRegexp = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+
+// isArchiveProject is the same with project.New
+func isArchiveProject(addr string) bool {
+	return strings.HasPrefix(addr, "oci://") ||
+		((strings.HasPrefix(addr, "https://") || strings.HasPrefix(addr, "http://")) &&
+			(strings.HasSuffix(addr, ".tgz") || strings.HasSuffix(addr, ".tar.gz")))
+}
+
+// isGitProject is the same with project.New
+func isGitProject(addr string) bool {
+	return strings.HasPrefix(addr, "https://") || strings.HasPrefix(addr, "http://") || strings.HasPrefix(addr, "git@")
+}
+
+func isBuiltinProject(pipeline *kkcorev1.Pipeline) bool {
+	_, ok := pipeline.Annotations[kkcorev1.BuiltinsProjectAnnotation]
+
+	return ok
+}
@@ -0,0 +1,213 @@
+/*
+Copyright 2023 The KubeSphere Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	kkcorev1 "github.com/kubesphere/kubekey/v4/pkg/apis/core/v1"
+)
+
+func TestValidateInventory(t *testing.T) {
+	testcases := []struct {
+		name      string
+		inventory *kkcorev1.Inventory
+		except    []string
+	}{
+		{
+			name: "valid",
+			inventory: &kkcorev1.Inventory{Spec: kkcorev1.InventorySpec{
+				Hosts: kkcorev1.InventoryHost{"node1": runtime.RawExtension{}},
+				Groups: map[string]kkcorev1.InventoryGroup{
+					"k8s_cluster":        {Groups: []string{"kube_control_plane"}},
+					"kube_control_plane": {Hosts: []string{"node1", "localhost"}},
+				},
+			}},
+		},
+		{
+			name: "reserved group all",
+			inventory: &kkcorev1.Inventory{Spec: kkcorev1.InventorySpec{
+				Groups: map[string]kkcorev1.InventoryGroup{"all": {}},
+			}},
+			except: []string{"spec.groups[all]"},
+		},
+		{
+			name: "host and group not found",
+			inventory: &kkcorev1.Inventory{Spec: kkcorev1.InventorySpec{
+				Groups: map[string]kkcorev1.InventoryGroup{
+					"etcd": {Hosts: []string{"node1"}, Groups: []string{"unknown"}},
+				},
+			}},
+			except: []string{"spec.groups[etcd].hosts[0]", "spec.groups[etcd].groups[0]"},
+		},
+		{
+			name: "cyclic groups",
+			inventory: &kkcorev1.Inventory{Spec: kkcorev1.InventorySpec{
+				Groups: map[string]kkcorev1.InventoryGroup{
+					"a": {Groups: []string{"b"}},
+					"b": {Groups: []string{"c"}},
+					"c": {Groups: []string{"a"}},
+				},
+			}},
+			except: []string{"spec.groups[a].groups"},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.except, errorFields(ValidateInventory(tc.inventory)))
+		})
+	}
+}
+
+func TestValidatePipeline(t *testing.T) {
+	testcases := []struct {
+		name     string
+		pipeline *kkcorev1.Pipeline
+		except   []string
+	}{
+		{
+			name: "valid",
+			pipeline: &kkcorev1.Pipeline{Spec: kkcorev1.PipelineSpec{
+				Playbook: "playbooks/create_cluster.yaml",
+				Project:  kkcorev1.PipelineProject{Addr: "https://github.com/kubesphere/kubekey.git", Branch: "main"},
+			}},
+		},
+		{
+			name:     "playbook required",
+			pipeline: &kkcorev1.Pipeline{},
+			except:   []string{"spec.playbook"},
+		},
+		{
+			name: "playbook not yaml",
+			pipeline: &kkcorev1.Pipeline{Spec: kkcorev1.PipelineSpec{
+				Playbook: "playbooks/create_cluster.json",
+			}},
+			except: []string{"spec.playbook"},
+		},
+		{
+			name: "absolute playbook in git project",
+			pipeline: &kkcorev1.Pipeline{Spec: kkcorev1.PipelineSpec{
+				Playbook: "/playbooks/create_cluster.yaml",
+				Project:  kkcorev1.PipelineProject{Addr: "https://github.com/kubesphere/kubekey.git"},
+			}},
+			except: []string{"spec.playbook"},
+		},
+		{
+			name: "branch in local project",
+			pipeline: &kkcorev1.Pipeline{Spec: kkcorev1.PipelineSpec{
+				Playbook: "/tmp/project/playbooks/create_cluster.yaml",
+				Project:  kkcorev1.PipelineProject{Name: "a/b", Branch: "main", Tag: "v1"},
+			}},
+			except: []string{"spec.project.name", "spec.project.tag", "spec.project.addr"},
+		},
+		{
+			name: "checksum in oci project",
+			pipeline: &kkcorev1.Pipeline{Spec: kkcorev1.PipelineSpec{
+				Playbook: "playbooks/create_cluster.yaml",
+				Project:  kkcorev1.PipelineProject{Addr: "oci://registry.example.com/kubekey/project:v1", Branch: "main", Checksum: "md5:abc"},
+			}},
+			except: []string{"spec.project.addr", "spec.project.checksum"},
+		},
+		{
+			name: "checksum in git project",
+			pipeline: &kkcorev1.Pipeline{Spec: kkcorev1.PipelineSpec{
+				Playbook: "playbooks/create_cluster.yaml",
+				Project:  kkcorev1.PipelineProject{Addr: "https://github.com/kubesphere/kubekey.git", Checksum: "sha256:abc"},
+			}},
+			except: []string{"spec.project.checksum"},
+		},
+		{
+			name: "publicKey in git project",
+			pipeline: &kkcorev1.Pipeline{Spec: kkcorev1.PipelineSpec{
+				Playbook: "playbooks/create_cluster.yaml",
+				Project:  kkcorev1.PipelineProject{Addr: "https://github.com/kubesphere/kubekey.git", PublicKey: "key"},
+			}},
+			except: []string{"spec.project.publicKey"},
+		},
+		{
+			name: "absolute playbook in tarball project",
+			pipeline: &kkcorev1.Pipeline{Spec: kkcorev1.PipelineSpec{
+				Playbook: "/playbooks/create_cluster.yaml",
+				Project:  kkcorev1.PipelineProject{Addr: "https://example.com/project.tgz", Token: "token"},
+			}},
+			except: []string{"spec.playbook"},
+		},
+		{
+			name: "invalid dependsOn",
+			pipeline: &kkcorev1.Pipeline{ObjectMeta: metav1.ObjectMeta{Name: "create-cluster"}, Spec: kkcorev1.PipelineSpec{
+				Playbook:  "/tmp/project/playbooks/create_cluster.yaml",
+				DependsOn: []string{"precheck", "", "create-cluster", "precheck"},
+			}},
+			except: []string{"spec.dependsOn[1]", "spec.dependsOn[2]", "spec.dependsOn[3]"},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.except, errorFields(ValidatePipeline(tc.pipeline)))
+		})
+	}
+}
+
+func TestValidateConfigWithSchema(t *testing.T) {
+	schema := []byte(`{"type":"object","properties":{"kube_version":{"type":"string","pattern":"^v\\d+\\.\\d+\\.\\d+"}}}`)
+	testcases := []struct {
+		name   string
+		config *kkcorev1.Config
+		except []string
+	}{
+		{
+			name:   "valid",
+			config: &kkcorev1.Config{Spec: runtime.RawExtension{Raw: []byte(`{"kube_version":"v1.23.15"}`)}},
+		},
+		{
+			name:   "empty spec",
+			config: &kkcorev1.Config{},
+		},
+		{
+			name:   "invalid pattern",
+			config: &kkcorev1.Config{Spec: runtime.RawExtension{Raw: []byte(`{"kube_version":"1.23"}`)}},
+			except: []string{"spec.kube_version"},
+		},
+		{
+			name:   "invalid type",
+			config: &kkcorev1.Config{Spec: runtime.RawExtension{Raw: []byte(`{"kube_version":1}`)}},
+			except: []string{"spec.kube_version"},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.except, errorFields(ValidateConfigWithSchema(tc.config, schema)))
+		})
+	}
+}
+
+func errorFields(allErrs field.ErrorList) []string {
+	var fields []string
+	for _, err := range allErrs {
+		fields = append(fields, err.Field)
+	}
+
+	return fields
+}
@@ -133,5 +133,25 @@ func New(ctx context.Context, client ctrlclient.Client, pipeline kkcorev1.Pipeli
 		v.value.Hosts[strings.TrimSuffix(k, ".json")] = h
 	}
 
+	// merge outputs of the pipelines which depends on. the runtime variables of this pipeline take priority.
+	for _, name := range pipeline.Spec.DependsOn {
+		dep := &kkcorev1.Pipeline{}
+		if err := client.Get(ctx, types.NamespacedName{Namespace: pipeline.Namespace, Name: name}, dep); err != nil {
+			klog.V(4).ErrorS(err, "get dependent pipeline error", "dependency", name, "pipeline", ctrlclient.ObjectKeyFromObject(&pipeline))
+
+			return nil, err
+		}
+		for hostname, outputs := range Extension2Variables(dep.Status.Outputs) {
+			h, ok := v.value.Hosts[hostname]
+			if !ok {
+				continue
+			}
+			if vars, ok := outputs.(map[string]any); ok {
+				h.RuntimeVars = combineVariables(vars, h.RuntimeVars)
+				v.value.Hosts[hostname] = h
+			}
+		}
+	}
+
 	return v, nil
 }
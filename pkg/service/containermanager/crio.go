@@ -0,0 +1,257 @@
+/*
+ Copyright 2024 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package containermanager
+
+import (
+	"encoding/base64"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	infrav1 "github.com/kubesys/kubekey/api/v1beta1"
+	"github.com/kubesys/kubekey/pkg/clients/ssh"
+	"github.com/kubesys/kubekey/pkg/scope"
+	"github.com/kubesys/kubekey/pkg/service/operation"
+	"github.com/kubesys/kubekey/pkg/service/operation/file"
+	"github.com/kubesys/kubekey/pkg/service/util"
+)
+
+const (
+	crioConfigDir     = "/etc/crio/crio.conf.d"
+	crioRegistriesDir = "/etc/containers/registries.conf.d"
+	crioConfigName    = "10-kubekey.conf"
+	crioAuthFile      = "/etc/crio/auth.json"
+
+	// crioInstallCmd installs the runtime binaries, the default configs and the systemd unit of the static bundle.
+	// The install script of the bundle is not used, it also overwrites the cni plugins in /opt/cni/bin and installs
+	// a bridge cni config which shadows the network plugin of the cluster.
+	crioInstallCmd = "install -m 755 bin/* /usr/local/bin/ && " +
+		"install -D -m 644 contrib/crio.service /usr/local/lib/systemd/system/crio.service && " +
+		"install -D -m 644 etc/crio.conf /etc/crio/crio.conf && " +
+		"install -D -m 644 etc/10-crun.conf /etc/crio/crio.conf.d/10-crun.conf && " +
+		"([ -f /etc/containers/policy.json ] || install -D -m 644 contrib/policy.json /etc/containers/policy.json)"
+)
+
+// CrioService is a ContainerManager service implementation for cri-o.
+type CrioService struct {
+	sshClient ssh.Interface
+
+	scope         scope.KKInstanceScope
+	instanceScope *scope.InstanceScope
+
+	templateFactory func(sshClient ssh.Interface, template *template.Template, data file.Data, dst string) (operation.Template, error)
+	crioFactory     func(sshClient ssh.Interface, version, arch string) (operation.Binary, error)
+}
+
+// NewCrioService returns a new CrioService given the remote instance container manager client.
+func NewCrioService(sshClient ssh.Interface, scope scope.KKInstanceScope, instanceScope *scope.InstanceScope) *CrioService {
+	return &CrioService{
+		sshClient:     sshClient,
+		scope:         scope,
+		instanceScope: instanceScope,
+	}
+}
+
+func (s *CrioService) getCrioService(sshClient ssh.Interface, version, arch string) (operation.Binary, error) {
+	if s.crioFactory != nil {
+		return s.crioFactory(sshClient, version, arch)
+	}
+	return file.NewCrio(sshClient, s.scope.RootFs(), version, arch)
+}
+
+func (s *CrioService) getTemplateService(template *template.Template, data file.Data, dst string) (operation.Template, error) {
+	if s.templateFactory != nil {
+		return s.templateFactory(s.sshClient, template, data, dst)
+	}
+	return file.NewTemplate(s.sshClient, s.scope.RootFs(), template, data, dst)
+}
+
+// Type returns the type crio of the container manager.
+func (s *CrioService) Type() string {
+	return file.CrioID
+}
+
+// Version returns the version of the container manager.
+func (s *CrioService) Version() string {
+	return s.instanceScope.KKInstance.Spec.ContainerManager.Version
+}
+
+// IsExist returns true if the container manager is installed.
+func (s *CrioService) IsExist() bool {
+	res, err := s.sshClient.SudoCmd(
+		"if [ -z $(which crio) ] || [ ! -e /var/run/crio/crio.sock ]; " +
+			"then echo 'not exist'; " +
+			"fi")
+	if err != nil {
+		return false
+	}
+	if strings.Contains(res, "not exist") {
+		return false
+	}
+	return true
+}
+
+// Get gets the static bundle of cri-o and copy it to the remote instance.
+// The bundle contains crio, conmon, crun, runc, pinns and crictl.
+func (s *CrioService) Get(timeout time.Duration) error {
+	crio, err := s.getCrioService(s.sshClient, s.Version(), s.instanceScope.Arch())
+	if err != nil {
+		return err
+	}
+
+	zone := s.scope.ComponentZone()
+	host := s.scope.ComponentHost()
+	overrideMap := make(map[string]infrav1.Override)
+	for _, o := range s.scope.ComponentOverrides() {
+		overrideMap[o.ID+o.Version+o.Arch] = o
+	}
+
+	override := overrideMap[crio.ID()+crio.Version()+crio.Arch()]
+	return util.DownloadAndCopy(s.instanceScope, crio, zone, host, override.Path, override.URL, override.Checksum.Value, timeout)
+}
+
+// Install installs the container manager and related components.
+func (s *CrioService) Install() error {
+	crio, err := s.getCrioService(s.sshClient, s.Version(), s.instanceScope.Arch())
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(crio.RemotePath())
+	if _, err := s.sshClient.SudoCmdf("tar Cxzf %s %s && cd %s/cri-o && %s && rm -rf %s/cri-o %s",
+		dir, crio.RemotePath(), dir, crioInstallCmd, dir, crio.RemotePath()); err != nil {
+		return err
+	}
+
+	if err := s.generateCrioConfig(); err != nil {
+		return err
+	}
+	if err := s.generateRegistriesConfig(); err != nil {
+		return err
+	}
+	if err := s.generateAuth(); err != nil {
+		return err
+	}
+	if _, err := s.sshClient.SudoCmd("systemctl daemon-reload && systemctl enable crio && systemctl start crio"); err != nil {
+		return err
+	}
+	return s.installCrictl()
+}
+
+func (s *CrioService) generateCrioConfig() error {
+	temp, err := template.ParseFS(f, "templates/crio.conf")
+	if err != nil {
+		return err
+	}
+
+	return s.render(temp, file.Data{
+		// todo: handle sandbox image
+		"Auth":     s.auth() != "",
+		"AuthFile": crioAuthFile,
+	}, filepath.Join(crioConfigDir, crioConfigName))
+}
+
+func (s *CrioService) generateRegistriesConfig() error {
+	temp, err := template.ParseFS(f, "templates/registries.conf")
+	if err != nil {
+		return err
+	}
+
+	return s.render(temp, file.Data{
+		"Mirrors":            s.mirrors(),
+		"InsecureRegistries": s.insecureRegistry(),
+	}, filepath.Join(crioRegistriesDir, crioConfigName))
+}
+
+func (s *CrioService) generateAuth() error {
+	auth := s.auth()
+	if auth == "" {
+		return nil
+	}
+	temp, err := template.ParseFS(f, "templates/auth.json")
+	if err != nil {
+		return err
+	}
+
+	return s.render(temp, file.Data{
+		"PrivateRegistry": s.scope.GlobalRegistry().PrivateRegistry,
+		"Auth":            auth,
+	}, crioAuthFile)
+}
+
+func (s *CrioService) installCrictl() error {
+	temp, err := template.ParseFS(f, "templates/crictl.yaml")
+	if err != nil {
+		return err
+	}
+
+	return s.render(temp, file.Data{
+		"Endpoint": s.instanceScope.ContainerManager().CRISocket,
+	}, filepath.Join("/etc/", temp.Name()))
+}
+
+func (s *CrioService) render(temp *template.Template, data file.Data, dst string) error {
+	svc, err := s.getTemplateService(temp, data, dst)
+	if err != nil {
+		return err
+	}
+	if err := svc.RenderToLocal(); err != nil {
+		return err
+	}
+	return svc.Copy(true)
+}
+
+// mirrors returns the mirrors of docker.io without the scheme, which is required by registries.conf.
+func (s *CrioService) mirrors() []string {
+	var m []string
+	if s.scope.GlobalRegistry() != nil {
+		for _, mirror := range s.scope.GlobalRegistry().RegistryMirrors {
+			mirror = strings.TrimPrefix(strings.TrimPrefix(mirror, "http://"), "https://")
+			m = append(m, strings.TrimSuffix(mirror, "/"))
+		}
+	}
+	return m
+}
+
+func (s *CrioService) insecureRegistry() []string {
+	var insecureRegistries []string
+	if s.scope.GlobalRegistry() != nil {
+		insecureRegistries = append(insecureRegistries, s.scope.GlobalRegistry().InsecureRegistries...)
+		auth := s.scope.GlobalRegistry().Auth
+		registry := s.scope.GlobalRegistry().PrivateRegistry
+		// registries.conf refuses to define a registry twice.
+		if registry != "" && (auth.InsecureSkipVerify || auth.PlainHTTP) && !slices.Contains(insecureRegistries, registry) {
+			insecureRegistries = append(insecureRegistries, registry)
+		}
+	}
+	return insecureRegistries
+}
+
+// auth returns the credential of the private registry in the format of docker config.json.
+func (s *CrioService) auth() string {
+	if s.scope.GlobalRegistry() == nil || s.scope.GlobalRegistry().PrivateRegistry == "" {
+		return ""
+	}
+	auth := s.scope.GlobalRegistry().Auth
+	if auth.Username == "" || auth.Password == "" {
+		return ""
+	}
+	return base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s", auth.Username, auth.Password)))
+}
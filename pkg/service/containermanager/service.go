@@ -45,6 +45,8 @@ func NewService(sshClient ssh.Interface, scope scope.KKInstanceScope, instanceSc
 		return NewContainerdService(sshClient, scope, instanceScope)
 	case file.DockerID:
 		return NewDockerService(sshClient, scope, instanceScope)
+	case file.CrioID:
+		return NewCrioService(sshClient, scope, instanceScope)
 	default:
 		return NewContainerdService(sshClient, scope, instanceScope)
 	}
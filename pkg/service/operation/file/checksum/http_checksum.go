@@ -114,7 +114,8 @@ func (h *HTTPChecksum) Get() error {
 		if err != nil || checksum == "" {
 			continue
 		}
-		if filename == h.FileName {
+		// the checksum file may list the file with a directory or in binary mode, e.g. "*dir/file".
+		if filepath.Base(strings.TrimPrefix(filename, "*")) == h.FileName {
 			h.value = checksum
 			return nil
 		}
@@ -0,0 +1,101 @@
+/*
+ Copyright 2024 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package file
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/kubesys/kubekey/pkg/clients/ssh"
+	"github.com/kubesys/kubekey/pkg/rootfs"
+	"github.com/kubesys/kubekey/pkg/service/operation/file/checksum"
+)
+
+// Crio info
+const (
+	CrioName        = "cri-o.%s.v%s.tar.gz"
+	CrioID          = "crio"
+	CrioURLPathTmpl = "/cri-o/artifacts/cri-o.%s.v%s.tar.gz"
+	// CrioChecksumSuffix is the suffix of the checksum file published beside the bundle.
+	CrioChecksumSuffix = ".sha256sum"
+)
+
+// Crio is a Binary for the static bundle of cri-o.
+type Crio struct {
+	*Binary
+	HTTPChecksum *checksum.HTTPChecksum
+}
+
+// NewCrio returns a new Crio.
+func NewCrio(sshClient ssh.Interface, rootFs rootfs.Interface, version, arch string) (*Crio, error) {
+	fileName := fmt.Sprintf(CrioName, arch, version)
+	file, err := NewFile(Params{
+		SSHClient:      sshClient,
+		RootFs:         rootFs,
+		Type:           FileBinary,
+		Name:           fileName,
+		LocalFullPath:  filepath.Join(rootFs.ClusterRootFsDir(), CrioID, version, arch, fileName),
+		RemoteFullPath: filepath.Join(BinDir, fileName),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	u := parseURL(DefaultDownloadHostGoogle, fmt.Sprintf(CrioURLPathTmpl, arch, version))
+	binary := NewBinary(BinaryParams{
+		File:    file,
+		ID:      CrioID,
+		Version: version,
+		Arch:    arch,
+		URL:     u,
+	})
+
+	// the internal checksum takes precedence, fall back to the checksum published by cri-o.
+	checksumURL := parseURL(DefaultDownloadHostGoogle, fmt.Sprintf(CrioURLPathTmpl, arch, version)+CrioChecksumSuffix)
+	httpChecksum := checksum.NewHTTPChecksum(checksumURL, fileName, rootFs)
+	binary.AppendChecksum(httpChecksum)
+
+	return &Crio{binary, httpChecksum}, nil
+}
+
+// SetURL override Binary's SetURL method to keep the checksum url beside the bundle.
+func (c *Crio) SetURL(urlStr string) {
+	c.Binary.SetURL(urlStr)
+	c.syncChecksumURL()
+}
+
+// SetHost override Binary's SetHost method to keep the checksum url beside the bundle.
+func (c *Crio) SetHost(host string) {
+	c.Binary.SetHost(host)
+	c.syncChecksumURL()
+}
+
+// SetPath override Binary's SetPath method to keep the checksum url beside the bundle.
+func (c *Crio) SetPath(pathStr string) {
+	c.Binary.SetPath(pathStr)
+	c.syncChecksumURL()
+}
+
+func (c *Crio) syncChecksumURL() {
+	u := *c.URL()
+	c.HTTPChecksum.SetHost(u.Scheme + "://" + u.Host)
+	c.HTTPChecksum.SetPath(u.Path + CrioChecksumSuffix)
+}
+
+// SetZone override Binary's SetZone method. The bundle of cri-o has no mirror in the cn zone,
+// use the override url of the component instead.
+func (c *Crio) SetZone(zone string) {}